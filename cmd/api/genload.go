@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	httpAdapter "github.com/evgenijurbanovskij/re-partners-assignment/internal/adapters/http"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/loadgen"
+)
+
+// runGenLoad implements "api gen-load": writes randomized valid solve
+// requests to stdout as NDJSON, one SolveRequest per line, for driving the
+// batch endpoint or benchmarks
+// Returns the process exit code
+func runGenLoad(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gen-load", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	count := flags.Int("count", 100, "number of requests to generate")
+	minAmount := flags.Int("min-amount", 1, "smallest amount")
+	maxAmount := flags.Int("max-amount", 100_000, "largest amount")
+	distribution := flags.String("distribution", loadgen.DistributionUniform,
+		"amount distribution: "+loadgen.DistributionUniform+" or "+loadgen.DistributionLog+" (favours small amounts)")
+	sizes := flags.String("sizes", "", "comma-separated size catalog to draw from (default "+joinInts(loadgen.DefaultSizePool)+")")
+	minSizes := flags.Int("min-sizes", 1, "fewest sizes per request")
+	maxSizes := flags.Int("max-sizes", 0, "most sizes per request (default all of the catalog)")
+	seed := flags.Uint64("seed", 0, "random seed for reproducible output (default time-based)")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := loadgen.Config{
+		Count:              *count,
+		MinAmount:          *minAmount,
+		MaxAmount:          *maxAmount,
+		AmountDistribution: *distribution,
+		MinSizes:           *minSizes,
+		MaxSizes:           *maxSizes,
+	}
+	if *sizes != "" {
+		for _, raw := range strings.Split(*sizes, ",") {
+			size, err := strconv.Atoi(strings.TrimSpace(raw))
+			if err != nil {
+				fmt.Fprintf(stderr, "gen-load: invalid size %q in -sizes\n", raw)
+				return 2
+			}
+			cfg.SizePool = append(cfg.SizePool, size)
+		}
+	}
+	if *seed == 0 {
+		*seed = uint64(time.Now().UnixNano())
+	}
+
+	items, err := loadgen.Generate(rand.New(rand.NewPCG(*seed, *seed)), cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "gen-load: %v\n", err)
+		return 2
+	}
+
+	out := bufio.NewWriter(stdout)
+	encoder := json.NewEncoder(out)
+	for _, item := range items {
+		if err := encoder.Encode(httpAdapter.SolveRequest{Sizes: item.Sizes, Amount: item.Amount}); err != nil {
+			fmt.Fprintf(stderr, "gen-load: %v\n", err)
+			return 1
+		}
+	}
+	if err := out.Flush(); err != nil {
+		fmt.Fprintf(stderr, "gen-load: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// joinInts formats values as a comma-separated list
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(value)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	httpAdapter "github.com/evgenijurbanovskij/re-partners-assignment/internal/adapters/http"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// decodeNDJSON decodes one SolveRequest per line
+func decodeNDJSON(t *testing.T, data []byte) []httpAdapter.SolveRequest {
+	t.Helper()
+
+	var requests []httpAdapter.SolveRequest
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var req httpAdapter.SolveRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			t.Fatalf("line %d is not a solve request: %v", len(requests)+1, err)
+		}
+		requests = append(requests, req)
+	}
+	return requests
+}
+
+func TestRunGenLoad(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"--count", "20", "--min-amount", "10", "--max-amount", "500", "--sizes", "3, 5,7", "--min-sizes", "2", "--max-sizes", "2", "--distribution", "log", "--seed", "42"}
+	if code := runGenLoad(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, stderr.String())
+	}
+
+	requests := decodeNDJSON(t, stdout.Bytes())
+	if len(requests) != 20 {
+		t.Fatalf("generated %d requests, want 20", len(requests))
+	}
+	for i, req := range requests {
+		if err := domain.ValidateSolverInput(req.Sizes, req.Amount); err != nil {
+			t.Errorf("request %d %+v is invalid: %v", i, req, err)
+		}
+		if req.Amount < 10 || req.Amount > 500 {
+			t.Errorf("request %d: amount %d outside [10, 500]", i, req.Amount)
+		}
+		if len(req.Sizes) != 2 {
+			t.Errorf("request %d: %d sizes, want 2", i, len(req.Sizes))
+		}
+		for _, size := range req.Sizes {
+			if !slices.Contains([]int{3, 5, 7}, size) {
+				t.Errorf("request %d: size %d not in -sizes", i, size)
+			}
+		}
+	}
+
+	// The same seed gives the same output
+	var again bytes.Buffer
+	if code := runGenLoad(args, &again, &stderr); code != 0 || !bytes.Equal(again.Bytes(), stdout.Bytes()) {
+		t.Errorf("same seed: exit code %d, output differs", code)
+	}
+}
+
+func TestRunGenLoad_Defaults(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := runGenLoad(nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, stderr.String())
+	}
+	if requests := decodeNDJSON(t, stdout.Bytes()); len(requests) != 100 {
+		t.Errorf("generated %d requests, want the default 100", len(requests))
+	}
+}
+
+func TestRunGenLoad_InvalidFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantStderr string
+	}{
+		{name: "unknown flag", args: []string{"--bogus"}, wantStderr: "flag provided but not defined"},
+		{name: "non-integer count", args: []string{"--count", "many"}, wantStderr: "invalid value"},
+		{name: "invalid size", args: []string{"--sizes", "250,abc"}, wantStderr: `invalid size "abc"`},
+		{name: "duplicate size", args: []string{"--sizes", "250,250"}, wantStderr: "size pool"},
+		{name: "empty amount range", args: []string{"--min-amount", "10", "--max-amount", "5"}, wantStderr: "exceeds max amount"},
+		{name: "unknown distribution", args: []string{"--distribution", "normal"}, wantStderr: "unknown amount distribution"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runGenLoad(tt.args, &stdout, &stderr); code != 2 {
+				t.Errorf("exit code = %d, want 2", code)
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("expected no output, got %q", stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
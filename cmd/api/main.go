@@ -6,32 +6,54 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"math"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jmoiron/sqlx"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	goredis "github.com/redis/go-redis/v9"
 
 	httpAdapter "github.com/evgenijurbanovskij/re-partners-assignment/internal/adapters/http"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/infra/config"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/infra/memory"
 	"github.com/evgenijurbanovskij/re-partners-assignment/internal/infra/postgres"
+	redisCache "github.com/evgenijurbanovskij/re-partners-assignment/internal/infra/redis"
 	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
 )
 
 const (
 	defaultPort    = "8080"
 	defaultVersion = "dev"
+
+	// calculationDrainTimeout bounds waiting for background calculation saves
+	// on shutdown; each save is itself bounded to 5s
+	calculationDrainTimeout = 10 * time.Second
 )
 
+// defaultWarmSizes - pack sizes warmed by CACHE_WARM_AMOUNTS unless
+// CACHE_WARM_SIZES is set (the sizes from the assignment brief)
+var defaultWarmSizes = []int{250, 500, 1000, 2000, 5000}
+
 type VersionResponse struct {
 	Version string `json:"version"`
 }
 
 func main() {
+	// Dev tooling subcommands run instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "gen-load" {
+		os.Exit(runGenLoad(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = defaultPort
@@ -48,7 +70,93 @@ func main() {
 		Level: slog.LevelInfo,
 	}))
 	logger := httpAdapter.NewSlogAdapter(slogLogger)
-	solver := usecase.NewDPSolver()
+	serviceConfig := config.Load()
+	appConfig := serviceConfig.App
+	dpSolver := usecase.NewDPSolverWithLimit(appConfig.SolverMaxTableSize).
+		WithMemoryBudget(getIntEnv("SOLVER_MEMORY_BUDGET_BYTES", 0)).
+		WithMaxTopK(appConfig.SolverMaxTopN)
+	var solver domain.Solver = dpSolver
+
+	// Optional brute-force verification of solver results (staging/debug only)
+	if os.Getenv("VERIFY_SOLVER") == "true" {
+		maxAmount := getIntEnv("VERIFY_SOLVER_MAX_AMOUNT", usecase.DefaultVerifyMaxAmount)
+		maxSizes := getIntEnv("VERIFY_SOLVER_MAX_SIZES", usecase.DefaultVerifyMaxSizes)
+		solver = usecase.NewVerifyingSolver(solver, maxAmount, maxSizes)
+		log.Printf("Solver verification enabled (amount <= %d, sizes <= %d)", maxAmount, maxSizes)
+	}
+
+	// Solver metrics (wrapped below the cache, so only computed solves are timed)
+	solver = usecase.NewInstrumentedSolver(solver)
+
+	// Optional Redis cache
+	var cachedSolver *redisCache.CachedSolver
+	var redisClient *goredis.Client
+	if redisEnabled := os.Getenv("REDIS_ENABLED"); redisEnabled == "true" {
+		log.Println("Redis cache enabled")
+
+		client := goredis.NewClient(&goredis.Options{
+			Addr:     fmt.Sprintf("%s:%s", getEnv("REDIS_HOST", "localhost"), getEnv("REDIS_PORT", "6379")),
+			Password: getEnv("REDIS_PASSWORD", ""),
+			DB:       getIntEnv("REDIS_DB", 0),
+			PoolSize: getIntEnv("REDIS_POOL_SIZE", 10),
+		})
+
+		pingCtx, pingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := client.Ping(pingCtx).Err()
+		pingCancel()
+		if err != nil {
+			log.Printf("Warning: failed to connect to Redis: %v", err)
+			log.Println("Running without cache")
+			client.Close()
+		} else {
+			log.Println("Redis connected successfully")
+			redisClient = client
+			cacheTTL := getDurationEnv("REDIS_CACHE_TTL", redisCache.DefaultTTL)
+			cachedSolver = redisCache.NewCachedSolver(solver, client, cacheTTL).
+				WithRetry(
+					getIntEnv("REDIS_RETRY_ATTEMPTS", redisCache.DefaultRetryAttempts),
+					getDurationEnv("REDIS_RETRY_BASE_DELAY", redisCache.DefaultRetryBaseDelay),
+				)
+			// Exact solutions may be kept longer than the others
+			if exactTTL := getDurationEnv("REDIS_CACHE_TTL_EXACT", 0); exactTTL > 0 {
+				cachedSolver.WithTTLFunc(redisCache.ExactTTL(exactTTL, cacheTTL))
+			}
+			solver = cachedSolver
+			log.Printf("Solver cache namespace: %s", domain.SolverVersion())
+
+			// Optionally precompute frequent amounts to avoid first-request latency
+			if warmAmounts := getIntListEnv("CACHE_WARM_AMOUNTS", nil); len(warmAmounts) > 0 {
+				warmSizes := getIntListEnv("CACHE_WARM_SIZES", defaultWarmSizes)
+				go func() {
+					warmCtx, warmCancel := context.WithTimeout(context.Background(), time.Minute)
+					defer warmCancel()
+
+					warmed, err := cachedSolver.Warm(warmCtx, warmSizes, warmAmounts)
+					if err != nil {
+						log.Printf("Warning: cache warm-up failed for some amounts: %v", err)
+					}
+					log.Printf("Warmed cache with %d of %d amounts for sizes %v", warmed, len(warmAmounts), warmSizes)
+				}()
+			}
+
+			// Optionally drop entries cached by previous solver versions
+			if os.Getenv("REDIS_CLEAR_STALE_VERSIONS") == "true" {
+				go func() {
+					clearCtx, clearCancel := context.WithTimeout(context.Background(), time.Minute)
+					defer clearCancel()
+
+					removed, err := cachedSolver.ClearStaleVersions(clearCtx)
+					if err != nil {
+						log.Printf("Warning: failed to clear stale cache entries: %v", err)
+						return
+					}
+					log.Printf("Removed %d stale cache entries", removed)
+				}()
+			}
+		}
+	} else {
+		log.Println("Redis cache disabled (set REDIS_ENABLED=true to enable)")
+	}
 
 	// Optional PostgreSQL connection
 	var db *sqlx.DB
@@ -75,6 +183,20 @@ func main() {
 			log.Println("Running without database (calculations will not be persisted)")
 		} else {
 			log.Println("PostgreSQL connected successfully")
+
+			// Fail fast if migrations were not applied; the cache metrics
+			// table is only needed when snapshots are enabled
+			var optionalTables []string
+			if os.Getenv("CACHE_METRICS_SNAPSHOT_ENABLED") == "true" {
+				optionalTables = append(optionalTables, postgres.CacheMetricsTable)
+			}
+			verifyCtx, verifyCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			err = postgres.VerifySchema(verifyCtx, db, optionalTables...)
+			verifyCancel()
+			if err != nil {
+				log.Fatalf("Database schema check failed: %v", err)
+			}
+
 			dbCleanup = func() {
 				if err := postgres.Close(db); err != nil {
 					log.Printf("Error closing database: %v", err)
@@ -86,12 +208,132 @@ func main() {
 	}
 
 	// Create handler with optional repository
-	packHandler := httpAdapter.NewPackHandler(solver, logger)
+	timeFormat, err := httpAdapter.ParseTimeFormat(appConfig.TimeFormat)
+	if err != nil {
+		log.Fatalf("Invalid TIME_FORMAT: %v", err)
+	}
+	environment := appConfig.Environment
+	cacheBypassAllowed := envListContains(os.Getenv("CACHE_BYPASS_ENVIRONMENTS"), environment)
+	if cacheBypassAllowed {
+		log.Printf("Cache bypass header allowed in environment %q", environment)
+	}
+	// In-flight solves, cancellable by correlation ID via DELETE /admin/solves/{correlation_id}
+	solveRegistry := httpAdapter.NewSolveRegistry(
+		getIntEnv("SOLVE_REGISTRY_MAX_SIZE", httpAdapter.DefaultSolveRegistrySize),
+		getDurationEnv("SOLVE_REGISTRY_TTL", httpAdapter.DefaultSolveRegistryTTL),
+		logger,
+	)
+	packHandler := httpAdapter.NewPackHandler(solver, logger).
+		WithSolveRegistry(solveRegistry).
+		WithSolveTimeout(appConfig.SolveTimeout).
+		WithSolveDurationHeader(getEnv("SOLVE_DURATION_HEADER", "true") == "true").
+		WithAllowZeroAmount(getEnv("ALLOW_ZERO_AMOUNT", "false") == "true").
+		WithRequireExactCoverage(getEnv("REQUIRE_EXACT_COVERAGE", "false") == "true").
+		WithLogSolveResults(getEnv("LOG_SOLVE_RESULTS", "false") == "true").
+		WithCacheBypass(cacheBypassAllowed).
+		WithStrictSolver(dpSolver).
+		WithRangeSolver(dpSolver).
+		WithDiagnosticSolver(dpSolver).
+		WithSeriesSolver(dpSolver).
+		WithTopKSolver(dpSolver).
+		WithMaxTopK(appConfig.SolverMaxTopN).
+		WithHighOverageRatio(getFloatEnv("SOLVE_HIGH_OVERAGE_RATIO", httpAdapter.DefaultHighOverageRatio)).
+		WithBatchConcurrency(getIntEnv("SOLVER_BATCH_CONCURRENCY", usecase.DefaultBatchConcurrency)).
+		WithMaxBodyBytes(int64(getIntEnv("SOLVE_MAX_BODY_BYTES", httpAdapter.DefaultMaxBodyBytes))).
+		WithSaveQueue(
+			getIntEnv("AUDIT_WORKERS", httpAdapter.DefaultSaveWorkers),
+			getIntEnv("AUDIT_QUEUE_SIZE", httpAdapter.DefaultSaveQueueSize),
+		).
+		WithOptionLimits(httpAdapter.OptionLimits{
+			MaxOverage: getIntEnv("SOLVE_MAX_OVERAGE_LIMIT", httpAdapter.DefaultOptionLimits().MaxOverage),
+		})
+	// Heuristics selectable per request with "algorithm"; dp stays the solver above
+	for _, algorithm := range usecase.Algorithms() {
+		packHandler = packHandler.WithAlgorithm(algorithm.Name, algorithm.Solver)
+	}
+	var repo *postgres.Repository
+	var packSetRepo domain.PackSizeRepository
+	var calculationHistory httpAdapter.CalculationHistory
 	if db != nil {
-		repo := postgres.NewRepository(db)
+		repo = postgres.NewRepository(db)
 		adapter := postgres.NewRepositoryAdapter(repo)
-		packHandler = packHandler.WithRepository(adapter)
+		packSetRepo = postgres.NewPackSizeRepositoryAdapter(repo)
+		calculationHistory = repo
+		packHandler = packHandler.
+			WithRepository(adapter).
+			WithPackSets(packSetRepo).
+			WithPersistSync(getEnv("PERSIST_SYNC", "false") == "true")
 		log.Println("Database repository integrated with API")
+	} else if os.Getenv("AUDIT_ENABLED") == "true" {
+		// Calculations and pack sets kept in memory only, lost on restart
+		memoryRepo := memory.NewRepository()
+		packSetRepo = memoryRepo
+		calculationHistory = memoryRepo
+		packHandler = packHandler.
+			WithRepository(memoryRepo).
+			WithPackSets(memoryRepo).
+			WithPersistSync(getEnv("PERSIST_SYNC", "false") == "true")
+		log.Println("In-memory audit repository integrated with API (not persisted across restarts)")
+	}
+
+	// Optional periodic cache metrics snapshots (requires both Redis and PostgreSQL)
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	var backgroundWG sync.WaitGroup
+	if os.Getenv("CACHE_METRICS_SNAPSHOT_ENABLED") == "true" {
+		if cachedSolver != nil && repo != nil {
+			interval := getDurationEnv("CACHE_METRICS_SNAPSHOT_INTERVAL", redisCache.DefaultSnapshotInterval)
+			snapshotter := redisCache.NewMetricsSnapshotter(cachedSolver, repo, interval)
+			backgroundWG.Add(1)
+			go func() {
+				defer backgroundWG.Done()
+				snapshotter.Run(backgroundCtx)
+			}()
+			log.Printf("Cache metrics snapshots enabled (interval %v)", interval)
+		} else {
+			log.Println("Warning: cache metrics snapshots require Redis and PostgreSQL, skipping")
+		}
+	}
+
+	// Optional API key authentication for API endpoints
+	var apiKeys httpAdapter.APIKeys
+	if os.Getenv("API_AUTH_ENABLED") == "true" {
+		apiKeys = loadAPIKeys()
+		log.Printf("API key authentication enabled (%d keys)", len(apiKeys))
+	} else {
+		log.Println("API key authentication disabled (set API_AUTH_ENABLED=true to enable)")
+	}
+
+	// Optional rate limiting: per API key identity when authenticated, otherwise per IP
+	var rateLimiter *httpAdapter.RateLimiter
+	if os.Getenv("RATE_LIMIT_ENABLED") == "true" {
+		keyLimits, err := httpAdapter.ParseRateLimits(os.Getenv("RATE_LIMIT_KEYS"))
+		if err != nil {
+			log.Fatalf("Invalid RATE_LIMIT_KEYS: %v", err)
+		}
+		defaultLimit := httpAdapter.RateLimit{
+			Rate:  getFloatEnv("RATE_LIMIT_RPS", 10),
+			Burst: getIntEnv("RATE_LIMIT_BURST", 20),
+		}
+		rateLimiter = httpAdapter.NewRateLimiter(defaultLimit).WithKeyLimits(keyLimits)
+		log.Printf("Rate limiting enabled (%.2f req/s, burst %d, %d per-key overrides)",
+			defaultLimit.Rate, defaultLimit.Burst, len(keyLimits))
+	}
+
+	// Maintenance mode: /packs/* return 503 while enabled
+	maintenance := httpAdapter.NewMaintenanceMode(os.Getenv("MAINTENANCE_MODE") == "true").
+		WithRetryAfter(getDurationEnv("MAINTENANCE_RETRY_AFTER", httpAdapter.DefaultMaintenanceRetryAfter))
+	if maintenance.Enabled() {
+		log.Println("Maintenance mode enabled")
+	}
+
+	// Admin endpoints are only registered when admin keys are configured
+	adminKeys, err := httpAdapter.ParseAPIKeys(os.Getenv("ADMIN_API_KEYS"))
+	if err != nil {
+		log.Fatalf("Invalid ADMIN_API_KEYS: %v", err)
+	}
+	if len(adminKeys) == 0 {
+		log.Println("Admin endpoints disabled (set ADMIN_API_KEYS to enable)")
 	}
 
 	// Create chi router
@@ -102,6 +344,7 @@ func main() {
 	r.Use(httpAdapter.RecoveryMiddleware(logger))
 	r.Use(httpAdapter.CorrelationIDMiddleware(logger))
 	r.Use(httpAdapter.MetricsMiddleware(logger))
+	r.Use(httpAdapter.MaintenanceMiddleware(maintenance, logger))
 
 	// Health check endpoint
 	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
@@ -109,6 +352,14 @@ func main() {
 		w.Write([]byte("OK"))
 	})
 
+	// Readiness and status endpoints (ping PostgreSQL and Redis when enabled)
+	healthHandler := httpAdapter.NewHealthHandler(db, redisClient, logger).
+		WithVersion(version).
+		WithSelfTest(dpSolver).
+		WithSolveRegistry(solveRegistry)
+	r.Get("/readyz", healthHandler.Ready)
+	r.Get("/status", healthHandler.Status)
+
 	// Version endpoint
 	r.Get("/version", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -117,30 +368,115 @@ func main() {
 	})
 
 	// Metrics endpoint (Prometheus format)
-	r.Handle("/metrics", promhttp.Handler())
+	r.Handle("/metrics", httpAdapter.MetricsHandler())
+
+	// OpenAPI description of the solve API
+	r.Handle("/openapi.json", httpAdapter.OpenAPIHandler())
+
+	// API endpoints (behind API key authentication when enabled)
+	r.Group(func(r chi.Router) {
+		if apiKeys != nil {
+			r.Use(httpAdapter.APIKeyMiddleware(apiKeys, logger))
+		}
+		if rateLimiter != nil {
+			r.Use(httpAdapter.RateLimitMiddleware(rateLimiter, logger))
+		}
+
+		// Pack solver endpoint
+		r.Post("/packs/solve", packHandler.SolvePacks)
+		r.Get("/packs/solve", packHandler.SolvePacksQuery)
+		r.Get("/packs/solve/stream", packHandler.SolvePacksStream)
+		r.Post("/packs/solve/combined", packHandler.SolveCombined)
+		r.Post("/packs/solve/batch", packHandler.SolveBatch)
+		r.Post("/packs/solve/series", packHandler.SolveSeries)
+		r.Post("/packs/prepare", packHandler.PrepareInput)
+
+		// Pack set endpoints (require PostgreSQL or the in-memory audit repository)
+		if packSetRepo != nil {
+			packSetHandler := httpAdapter.NewPackSetHandler(packSetRepo, logger).
+				WithService(usecase.NewService(solver, packSetRepo)).
+				WithSimulator(solver).
+				WithCalculationRecorder(packHandler).
+				WithCalculationHistory(calculationHistory, timeFormat)
+			if repo != nil {
+				packSetHandler = packSetHandler.WithAmountHistory(repo)
+			}
+			r.Post("/packsets", packSetHandler.Create)
+			r.Get("/packsets", packSetHandler.List)
+			r.Get("/packsets/{id}", packSetHandler.Get)
+			r.Put("/packsets/{id}", packSetHandler.Update)
+			r.Delete("/packsets/{id}", packSetHandler.Delete)
+			r.Post("/packsets/{id}/solve", packSetHandler.Solve)
+			r.Post("/packsets/{id}/simulate", packSetHandler.Simulate)
+			r.Get("/packsets/{id}/history", packSetHandler.History)
+			r.Post("/packsets/import.csv", packSetHandler.ImportCSV)
+		}
 
-	// Pack solver endpoint
-	r.Post("/packs/solve", packHandler.SolvePacks)
+		// Calculation history endpoints (require PostgreSQL)
+		if repo != nil {
+			calculationHandler := httpAdapter.NewCalculationHandler(repo, logger).WithTimeFormat(timeFormat)
+			r.Get("/calculations", calculationHandler.ListCalculations)
+			r.Get("/calculations/export", calculationHandler.Export)
+			r.Get("/calculations/stats/overage-histogram", calculationHandler.OverageHistogram)
+			r.Get("/calculations/usage", calculationHandler.PackSizeUsage)
+		}
+
+		// Solver cache statistics (require Redis)
+		if cachedSolver != nil {
+			cacheHandler := httpAdapter.NewCacheHandler(cachedSolver, logger)
+			r.Get("/cache/stats", cacheHandler.Stats)
+		}
+	})
+
+	// Admin endpoints (always behind admin API key authentication)
+	if len(adminKeys) > 0 {
+		adminHandler := httpAdapter.NewAdminHandler(maintenance, logger).WithSolveRegistry(solveRegistry)
+		r.Group(func(r chi.Router) {
+			r.Use(httpAdapter.APIKeyMiddleware(adminKeys, logger))
+			r.Post("/admin/maintenance", adminHandler.SetMaintenance)
+			r.Post("/admin/benchmark/compare", adminHandler.BenchmarkCompare)
+			r.Delete("/admin/solves/{correlation_id}", adminHandler.CancelSolve)
+		})
+	}
 
 	// Static files (web UI)
 	fs := http.FileServer(http.Dir("./web"))
 	r.Handle("/*", fs)
 
-	server := &http.Server{
+	serverCfg := httpAdapter.ServerConfig{
 		Addr:         fmt.Sprintf(":%s", port),
-		Handler:      r,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		TLSCertFile:  serviceConfig.Server.TLSCertFile,
+		TLSKeyFile:   serviceConfig.Server.TLSKeyFile,
+		H2C:          serviceConfig.Server.H2C,
+	}
+	if err := serverCfg.Validate(); err != nil {
+		log.Fatalf("Invalid server configuration: %v", err)
 	}
+	server := httpAdapter.NewServer(serverCfg, r)
 
 	// Channel to listen for errors coming from the listener.
 	serverErrors := make(chan error, 1)
 
 	// Start the server
 	go func() {
-		log.Printf("Server starting on port %s", port)
-		serverErrors <- server.ListenAndServe()
+		listener, err := net.Listen("tcp", serverCfg.Addr)
+		if err != nil {
+			serverErrors <- err
+			return
+		}
+
+		switch {
+		case serverCfg.TLSEnabled():
+			log.Printf("Server starting on port %s (TLS, HTTP/2 enabled)", port)
+		case serverCfg.H2C:
+			log.Printf("Server starting on port %s (h2c enabled)", port)
+		default:
+			log.Printf("Server starting on port %s", port)
+		}
+		serverErrors <- httpAdapter.Serve(server, listener, serverCfg)
 	}()
 
 	// Channel to listen for an interrupt or terminate signal from the OS.
@@ -167,6 +503,17 @@ func main() {
 			}
 		}
 
+		// Finish pending calculation saves before the database is closed
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), calculationDrainTimeout)
+		if err := packHandler.Drain(drainCtx); err != nil {
+			log.Printf("Warning: %v, calculations may be lost", err)
+		}
+		drainCancel()
+
+		// Stop background jobs before closing their dependencies
+		stopBackground()
+		backgroundWG.Wait()
+
 		// Close database if connected
 		if dbCleanup != nil {
 			dbCleanup()
@@ -184,3 +531,91 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getIntEnv gets environment variable as int or returns default value
+func getIntEnv(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// getFloatEnv gets environment variable as float64 or returns default value
+// Non-finite values (NaN, Inf) are ignored
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(floatValue) && !math.IsInf(floatValue, 0) {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getIntListEnv gets environment variable as a comma-separated list of ints
+// or returns default value; exits if the list is invalid
+func getIntListEnv(key string, defaultValue []int) []int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []int
+	for _, part := range strings.Split(value, ",") {
+		intValue, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			log.Fatalf("Invalid %s: %q is not an integer", key, part)
+		}
+		list = append(list, intValue)
+	}
+	return list
+}
+
+// getDurationEnv gets environment variable as duration or returns default value
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// loadAPIKeys loads API keys from API_KEYS and API_KEYS_FILE
+// Exits if the configuration is invalid or no keys are configured
+func loadAPIKeys() httpAdapter.APIKeys {
+	keys, err := httpAdapter.ParseAPIKeys(os.Getenv("API_KEYS"))
+	if err != nil {
+		log.Fatalf("Invalid API_KEYS: %v", err)
+	}
+
+	if path := os.Getenv("API_KEYS_FILE"); path != "" {
+		fileKeys, err := httpAdapter.LoadAPIKeysFile(path)
+		if err != nil {
+			log.Fatalf("Invalid API_KEYS_FILE: %v", err)
+		}
+		for key, identity := range fileKeys {
+			if _, exists := keys[key]; exists {
+				log.Fatalf("Duplicate API key for identity %q in API_KEYS_FILE", identity)
+			}
+			keys[key] = identity
+		}
+	}
+
+	if len(keys) == 0 {
+		log.Fatal("API_AUTH_ENABLED=true but no keys configured (set API_KEYS or API_KEYS_FILE)")
+	}
+
+	return keys
+}
+
+// envListContains reports whether a comma-separated environment variable value contains item
+func envListContains(list, item string) bool {
+	for _, value := range strings.Split(list, ",") {
+		if strings.TrimSpace(value) == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,3 @@
+-- Drop cache_metrics table
+DROP INDEX IF EXISTS idx_cache_metrics_recorded_at;
+DROP TABLE IF EXISTS cache_metrics;
@@ -0,0 +1,19 @@
+-- Create cache_metrics table
+CREATE TABLE IF NOT EXISTS cache_metrics (
+    id BIGSERIAL PRIMARY KEY,
+    hits BIGINT NOT NULL,
+    misses BIGINT NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT cache_metrics_hits_check CHECK (hits >= 0),
+    CONSTRAINT cache_metrics_misses_check CHECK (misses >= 0)
+);
+
+-- Create index on recorded_at for trending queries
+CREATE INDEX idx_cache_metrics_recorded_at ON cache_metrics(recorded_at DESC);
+
+-- Add comments to table
+COMMENT ON TABLE cache_metrics IS 'Periodic snapshots of solver cache hit/miss counters';
+COMMENT ON COLUMN cache_metrics.id IS 'Unique snapshot identifier';
+COMMENT ON COLUMN cache_metrics.hits IS 'Cumulative cache hits at snapshot time';
+COMMENT ON COLUMN cache_metrics.misses IS 'Cumulative cache misses at snapshot time';
+COMMENT ON COLUMN cache_metrics.recorded_at IS 'Snapshot time';
@@ -0,0 +1,2 @@
+-- Drop breakdown index
+DROP INDEX IF EXISTS idx_calculations_breakdown;
@@ -0,0 +1,2 @@
+-- Index breakdown keys for "calculations using size" lookups (breakdown ? '5000')
+CREATE INDEX IF NOT EXISTS idx_calculations_breakdown ON calculations USING GIN (breakdown);
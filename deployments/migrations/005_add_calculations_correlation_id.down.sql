@@ -0,0 +1,2 @@
+-- Drop correlation_id column
+ALTER TABLE calculations DROP COLUMN IF EXISTS correlation_id;
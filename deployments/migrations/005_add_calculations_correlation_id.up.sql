@@ -0,0 +1,4 @@
+-- Link calculations to the request that produced them (X-Correlation-ID)
+ALTER TABLE calculations ADD COLUMN IF NOT EXISTS correlation_id VARCHAR(255) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN calculations.correlation_id IS 'Correlation ID of the request that produced the calculation';
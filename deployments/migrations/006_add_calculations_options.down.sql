@@ -0,0 +1,2 @@
+-- Drop options column
+ALTER TABLE calculations DROP COLUMN IF EXISTS options;
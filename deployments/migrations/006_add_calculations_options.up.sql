@@ -0,0 +1,4 @@
+-- Store the request options each calculation was solved with (max_overage, priority, ...)
+ALTER TABLE calculations ADD COLUMN IF NOT EXISTS options JSONB NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN calculations.options IS 'Request options the calculation was solved with: {"option": "value"}';
@@ -0,0 +1,3 @@
+-- Drop immutability trigger
+DROP TRIGGER IF EXISTS calculations_immutable ON calculations;
+DROP FUNCTION IF EXISTS calculations_prevent_solution_update();
@@ -0,0 +1,23 @@
+-- Calculations are an audit trail: the solved input and its solution must never change
+-- pack_set_id stays mutable (ON DELETE SET NULL), as do options and correlation_id metadata
+CREATE OR REPLACE FUNCTION calculations_prevent_solution_update() RETURNS trigger AS $$
+BEGIN
+    IF NEW.pack_sizes IS DISTINCT FROM OLD.pack_sizes
+        OR NEW.amount IS DISTINCT FROM OLD.amount
+        OR NEW.breakdown IS DISTINCT FROM OLD.breakdown
+        OR NEW.total_packs IS DISTINCT FROM OLD.total_packs
+        OR NEW.overage IS DISTINCT FROM OLD.overage
+        OR NEW.calculated_at IS DISTINCT FROM OLD.calculated_at THEN
+        RAISE EXCEPTION 'calculation % is immutable', OLD.id
+            USING ERRCODE = 'restrict_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER calculations_immutable
+    BEFORE UPDATE ON calculations
+    FOR EACH ROW
+    EXECUTE FUNCTION calculations_prevent_solution_update();
+
+COMMENT ON FUNCTION calculations_prevent_solution_update() IS 'Rejects updates to the solved input and solution of a calculation';
@@ -0,0 +1,3 @@
+-- Drop dimensions column
+ALTER TABLE pack_sets DROP CONSTRAINT IF EXISTS pack_sets_dimensions_check;
+ALTER TABLE pack_sets DROP COLUMN IF EXISTS dimensions;
@@ -0,0 +1,6 @@
+-- Store optional outer dimensions per pack size, used to compute shipping volume
+ALTER TABLE pack_sets ADD COLUMN IF NOT EXISTS dimensions JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE pack_sets ADD CONSTRAINT pack_sets_dimensions_check CHECK (jsonb_typeof(dimensions) = 'object');
+
+COMMENT ON COLUMN pack_sets.dimensions IS 'Outer dimensions per pack size: {"size": {"length": l, "width": w, "height": h}}';
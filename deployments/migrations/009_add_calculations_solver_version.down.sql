@@ -0,0 +1,17 @@
+-- Restore the 007 immutability trigger, then drop solver_version column
+CREATE OR REPLACE FUNCTION calculations_prevent_solution_update() RETURNS trigger AS $$
+BEGIN
+    IF NEW.pack_sizes IS DISTINCT FROM OLD.pack_sizes
+        OR NEW.amount IS DISTINCT FROM OLD.amount
+        OR NEW.breakdown IS DISTINCT FROM OLD.breakdown
+        OR NEW.total_packs IS DISTINCT FROM OLD.total_packs
+        OR NEW.overage IS DISTINCT FROM OLD.overage
+        OR NEW.calculated_at IS DISTINCT FROM OLD.calculated_at THEN
+        RAISE EXCEPTION 'calculation % is immutable', OLD.id
+            USING ERRCODE = 'restrict_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE calculations DROP COLUMN IF EXISTS solver_version;
@@ -0,0 +1,22 @@
+-- Record which solver build produced each calculation (domain.SolverVersion at save time)
+-- Rows saved before this migration keep an empty version
+ALTER TABLE calculations ADD COLUMN IF NOT EXISTS solver_version VARCHAR(64) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN calculations.solver_version IS 'Solver version (VCS revision or module version) that produced the calculation';
+
+-- Provenance is part of the audit trail: extend the 007 immutability trigger to it
+CREATE OR REPLACE FUNCTION calculations_prevent_solution_update() RETURNS trigger AS $$
+BEGIN
+    IF NEW.pack_sizes IS DISTINCT FROM OLD.pack_sizes
+        OR NEW.amount IS DISTINCT FROM OLD.amount
+        OR NEW.breakdown IS DISTINCT FROM OLD.breakdown
+        OR NEW.total_packs IS DISTINCT FROM OLD.total_packs
+        OR NEW.overage IS DISTINCT FROM OLD.overage
+        OR NEW.calculated_at IS DISTINCT FROM OLD.calculated_at
+        OR NEW.solver_version IS DISTINCT FROM OLD.solver_version THEN
+        RAISE EXCEPTION 'calculation % is immutable', OLD.id
+            USING ERRCODE = 'restrict_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,3 @@
+-- Drop default_amount column
+ALTER TABLE pack_sets DROP CONSTRAINT IF EXISTS pack_sets_default_amount_check;
+ALTER TABLE pack_sets DROP COLUMN IF EXISTS default_amount;
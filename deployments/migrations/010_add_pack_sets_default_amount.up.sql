@@ -0,0 +1,6 @@
+-- Store an optional default amount per pack set, solved when POST /packsets/{id}/solve omits the amount
+ALTER TABLE pack_sets ADD COLUMN IF NOT EXISTS default_amount INTEGER;
+
+ALTER TABLE pack_sets ADD CONSTRAINT pack_sets_default_amount_check CHECK (default_amount IS NULL OR default_amount > 0);
+
+COMMENT ON COLUMN pack_sets.default_amount IS 'Amount of the most common order, used when a solve request gives none (NULL = none)';
@@ -0,0 +1,187 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+// MaintenanceRequest represents a request to toggle maintenance mode
+type MaintenanceRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// MaintenanceResponse represents the current maintenance mode state
+type MaintenanceResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// BenchmarkCompareRequest represents a request to compare solver algorithms
+type BenchmarkCompareRequest struct {
+	Sizes  []int `json:"sizes"`
+	Amount int   `json:"amount"`
+}
+
+// AlgorithmResult represents one algorithm's result in a comparison
+type AlgorithmResult struct {
+	Algorithm  string      `json:"algorithm"`
+	DurationMs float64     `json:"duration_ms"`
+	Solution   map[int]int `json:"solution,omitempty"` // size → count
+	Packs      int         `json:"packs"`
+	Overage    int         `json:"overage"`
+	Correct    bool        `json:"correct"`         // Same overage and packs as the baseline
+	Error      string      `json:"error,omitempty"` // Set when the algorithm failed
+}
+
+// BenchmarkCompareResponse represents the results of all algorithms for one input
+type BenchmarkCompareResponse struct {
+	Baseline string            `json:"baseline"` // Algorithm the others are checked against
+	Results  []AlgorithmResult `json:"results"`
+}
+
+// CancelSolveResponse represents a cancelled in-flight solve
+type CancelSolveResponse struct {
+	CorrelationID string `json:"correlation_id"`
+	Cancelled     bool   `json:"cancelled"`
+}
+
+// AdminHandler handles HTTP requests for operational controls
+type AdminHandler struct {
+	maintenance *MaintenanceMode
+	algorithms  []usecase.Algorithm // Compared by BenchmarkCompare; the first is the baseline
+	registry    *SolveRegistry      // Optional, enables CancelSolve
+	logger      Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(maintenance *MaintenanceMode, logger Logger) *AdminHandler {
+	return &AdminHandler{
+		maintenance: maintenance,
+		algorithms:  usecase.Algorithms(),
+		logger:      logger,
+	}
+}
+
+// WithSolveRegistry sets the registry of in-flight solves used by CancelSolve
+func (h *AdminHandler) WithSolveRegistry(registry *SolveRegistry) *AdminHandler {
+	h.registry = registry
+	return h
+}
+
+// SetMaintenance handles POST /admin/maintenance {"enabled":true}
+// The state is kept in memory only and resets to MAINTENANCE_MODE on restart
+func (h *AdminHandler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req MaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, h.logger, http.StatusBadRequest, "invalid JSON", map[string]interface{}{
+			"parse_error": err.Error(),
+		})
+		return
+	}
+	if req.Enabled == nil {
+		respondError(w, r, h.logger, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+			"field":   "enabled",
+			"value":   nil,
+			"message": "is required",
+		})
+		return
+	}
+
+	h.maintenance.SetEnabled(*req.Enabled)
+	h.logger.Info(ctx, "maintenance mode changed", map[string]interface{}{
+		"enabled":          *req.Enabled,
+		"api_key_identity": GetAPIKeyIdentity(ctx),
+		"correlation_id":   GetCorrelationID(ctx),
+	})
+
+	respondJSON(w, r, h.logger, http.StatusOK, MaintenanceResponse{Enabled: h.maintenance.Enabled()})
+}
+
+// BenchmarkCompare handles POST /admin/benchmark/compare {"sizes":[...],"amount":N}
+// Runs every registered algorithm on the input one after another and reports
+// its duration and result; correctness is judged against the first (exact)
+// algorithm, whose failure fails the request
+func (h *AdminHandler) BenchmarkCompare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req BenchmarkCompareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, h.logger, http.StatusBadRequest, "invalid JSON", map[string]interface{}{
+			"parse_error": err.Error(),
+		})
+		return
+	}
+	if err := domain.ValidateSolverInput(req.Sizes, req.Amount); err != nil {
+		respondErrorCode(w, r, h.logger, http.StatusUnprocessableEntity, errorCode(err), err.Error(), nil)
+		return
+	}
+
+	response := BenchmarkCompareResponse{
+		Baseline: h.algorithms[0].Name,
+		Results:  make([]AlgorithmResult, 0, len(h.algorithms)),
+	}
+
+	var baseline *domain.Solution
+	for i, algorithm := range h.algorithms {
+		start := time.Now()
+		solution, err := algorithm.Solver.Solve(ctx, req.Sizes, req.Amount)
+		result := AlgorithmResult{
+			Algorithm:  algorithm.Name,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		}
+
+		if err != nil {
+			if i == 0 {
+				respondSolverError(w, r, h.logger, err)
+				return
+			}
+			result.Error = err.Error()
+			response.Results = append(response.Results, result)
+			continue
+		}
+		if i == 0 {
+			baseline = solution
+		}
+
+		result.Solution = solution.Breakdown
+		result.Packs = solution.Packs
+		result.Overage = solution.Overage
+		result.Correct = solution.Overage == baseline.Overage && solution.Packs == baseline.Packs
+		response.Results = append(response.Results, result)
+	}
+
+	respondJSON(w, r, h.logger, http.StatusOK, response)
+}
+
+// CancelSolve handles DELETE /admin/solves/{correlation_id}
+// Cancels the in-flight solve of the request with that correlation ID; the
+// request then fails with 408. 404 if no such solve is in flight
+func (h *AdminHandler) CancelSolve(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if h.registry == nil {
+		respondError(w, r, h.logger, http.StatusNotImplemented, "cancelling solves is not supported", nil)
+		return
+	}
+
+	correlationID := r.PathValue("correlation_id")
+	if !h.registry.Cancel(ctx, correlationID) {
+		respondError(w, r, h.logger, http.StatusNotFound, "no in-flight solve with this correlation ID", map[string]interface{}{
+			"correlation_id": correlationID,
+		})
+		return
+	}
+
+	h.logger.Info(ctx, "solve cancelled", map[string]interface{}{
+		"cancelled_correlation_id": correlationID,
+		"api_key_identity":         GetAPIKeyIdentity(ctx),
+		"correlation_id":           GetCorrelationID(ctx),
+	})
+
+	respondJSON(w, r, h.logger, http.StatusOK, CancelSolveResponse{CorrelationID: correlationID, Cancelled: true})
+}
@@ -0,0 +1,224 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+func TestAdminHandler_SetMaintenance_TogglesBusinessEndpoints(t *testing.T) {
+	logger := &mockLogger{}
+	mode := NewMaintenanceMode(false)
+	admin := NewAdminHandler(mode, logger)
+	packHandler := NewPackHandler(usecase.NewDPSolver(), logger)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/maintenance", admin.SetMaintenance)
+	mux.HandleFunc("/packs/solve", packHandler.SolvePacks)
+	server := MaintenanceMiddleware(mode, logger)(mux)
+
+	solve := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve", bytes.NewBufferString(`{"sizes":[250,500],"amount":251}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w.Code
+	}
+	toggle := func(body string) MaintenanceResponse {
+		req := httptest.NewRequest(http.MethodPost, "/admin/maintenance", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("toggle %s: expected status 200, got %d: %s", body, w.Code, w.Body.String())
+		}
+		var resp MaintenanceResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	if code := solve(); code != http.StatusOK {
+		t.Fatalf("before toggle: expected 200, got %d", code)
+	}
+
+	if resp := toggle(`{"enabled":true}`); !resp.Enabled {
+		t.Errorf("expected enabled=true in response")
+	}
+	if code := solve(); code != http.StatusServiceUnavailable {
+		t.Errorf("maintenance on: expected 503, got %d", code)
+	}
+
+	if resp := toggle(`{"enabled":false}`); resp.Enabled {
+		t.Errorf("expected enabled=false in response")
+	}
+	if code := solve(); code != http.StatusOK {
+		t.Errorf("maintenance off: expected 200, got %d", code)
+	}
+}
+
+func TestAdminHandler_SetMaintenance_InvalidBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "invalid JSON", body: `{"enabled":`, wantStatus: http.StatusBadRequest},
+		{name: "wrong type", body: `{"enabled":"yes"}`, wantStatus: http.StatusBadRequest},
+		{name: "missing enabled", body: `{}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := NewMaintenanceMode(false)
+			handler := NewAdminHandler(mode, &mockLogger{})
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/maintenance", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			handler.SetMaintenance(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if mode.Enabled() {
+				t.Errorf("maintenance mode changed on invalid request")
+			}
+		})
+	}
+}
+
+func TestAdminHandler_BenchmarkCompare(t *testing.T) {
+	handler := NewAdminHandler(NewMaintenanceMode(false), &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/benchmark/compare", bytes.NewBufferString(`{"sizes":[250,500,1000],"amount":251}`))
+	w := httptest.NewRecorder()
+	handler.BenchmarkCompare(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp BenchmarkCompareResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Baseline != usecase.AlgorithmDP {
+		t.Errorf("baseline = %q, want %q", resp.Baseline, usecase.AlgorithmDP)
+	}
+
+	results := make(map[string]AlgorithmResult)
+	for _, result := range resp.Results {
+		results[result.Algorithm] = result
+	}
+	for _, name := range []string{usecase.AlgorithmDP, usecase.AlgorithmGreedy, usecase.AlgorithmHybrid} {
+		if _, ok := results[name]; !ok {
+			t.Errorf("missing result for %q in %+v", name, resp.Results)
+		}
+	}
+
+	// dp is correct by definition; greedy ships two 250s instead of one 500
+	if dp := results[usecase.AlgorithmDP]; !dp.Correct || dp.Packs != 1 || dp.Overage != 249 {
+		t.Errorf("dp result = %+v, want correct with 1 pack and overage 249", dp)
+	}
+	if greedy := results[usecase.AlgorithmGreedy]; greedy.Correct || greedy.Packs != 2 {
+		t.Errorf("greedy result = %+v, want incorrect with 2 packs", greedy)
+	}
+	if hybrid := results[usecase.AlgorithmHybrid]; !hybrid.Correct {
+		t.Errorf("hybrid result = %+v, want correct", hybrid)
+	}
+}
+
+func TestAdminHandler_BenchmarkCompare_InvalidInput(t *testing.T) {
+	handler := NewAdminHandler(NewMaintenanceMode(false), &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/benchmark/compare", bytes.NewBufferString(`{"sizes":[250,250],"amount":251}`))
+	w := httptest.NewRecorder()
+	handler.BenchmarkCompare(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d", w.Code)
+	}
+}
+
+// blockingSolver blocks until its context is done, signalling started first
+type blockingSolver struct {
+	started chan struct{}
+}
+
+func (s *blockingSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	close(s.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestAdminHandler_CancelSolve(t *testing.T) {
+	logger := &mockLogger{}
+	registry := NewSolveRegistry(10, time.Minute, logger)
+	solver := &blockingSolver{started: make(chan struct{})}
+	packHandler := NewPackHandler(solver, logger).WithSolveRegistry(registry)
+	admin := NewAdminHandler(NewMaintenanceMode(false), logger).WithSolveRegistry(registry)
+	server := CorrelationIDMiddleware(logger)(http.HandlerFunc(packHandler.SolvePacks))
+
+	cancel := func(correlationID string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/admin/solves/"+correlationID, nil)
+		req.SetPathValue("correlation_id", correlationID)
+		w := httptest.NewRecorder()
+		admin.CancelSolve(w, req)
+		return w.Code
+	}
+
+	done := make(chan int)
+	go func() {
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve", bytes.NewBufferString(`{"sizes":[250,500],"amount":251}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Correlation-ID", "slow-solve")
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		done <- w.Code
+	}()
+
+	select {
+	case <-solver.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("solve did not start")
+	}
+
+	if code := cancel("unknown"); code != http.StatusNotFound {
+		t.Errorf("unknown correlation ID: expected 404, got %d", code)
+	}
+	if code := cancel("slow-solve"); code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+
+	select {
+	case code := <-done:
+		if code != http.StatusRequestTimeout {
+			t.Errorf("cancelled solve: expected 408, got %d", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("solve was not cancelled")
+	}
+	if n := registry.Len(); n != 0 {
+		t.Errorf("registry holds %d entries after the solve finished, want 0", n)
+	}
+}
+
+func TestAdminHandler_CancelSolve_NoRegistry(t *testing.T) {
+	handler := NewAdminHandler(NewMaintenanceMode(false), &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/solves/abc", nil)
+	req.SetPathValue("correlation_id", "abc")
+	w := httptest.NewRecorder()
+	handler.CancelSolve(w, req)
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("expected status 501, got %d", w.Code)
+	}
+}
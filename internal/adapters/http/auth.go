@@ -0,0 +1,154 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// APIKeyHeader is the request header carrying the client API key
+const APIKeyHeader = "X-API-Key"
+
+const apiKeyIdentityKey contextKey = "api_key_identity"
+
+// APIKeys maps API keys to the identity they are attributed to
+type APIKeys map[string]string
+
+// ParseAPIKeys parses a comma-separated list of "identity:key" entries
+// An entry without an identity ("key") is attributed to a fingerprint of the key
+func ParseAPIKeys(list string) (APIKeys, error) {
+	keys := make(APIKeys)
+	for _, entry := range strings.Split(list, ",") {
+		if err := keys.add(entry); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// LoadAPIKeysFile reads API keys from a file with one "identity:key" entry per line
+// Blank lines and lines starting with "#" are ignored
+func LoadAPIKeysFile(path string) (APIKeys, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open API keys file: %w", err)
+	}
+	defer file.Close()
+
+	keys := make(APIKeys)
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := keys.add(line); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read API keys file: %w", err)
+	}
+
+	return keys, nil
+}
+
+// add parses a single "identity:key" or "key" entry; blank entries are skipped
+func (k APIKeys) add(entry string) error {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		return nil
+	}
+
+	identity, key, found := strings.Cut(entry, ":")
+	if !found {
+		key, identity = identity, keyFingerprint(identity)
+	}
+	identity = strings.TrimSpace(identity)
+	key = strings.TrimSpace(key)
+
+	if key == "" || identity == "" {
+		return fmt.Errorf("invalid API key entry %q: expected identity:key", entry)
+	}
+	if _, exists := k[key]; exists {
+		return fmt.Errorf("duplicate API key for identity %q", identity)
+	}
+
+	k[key] = identity
+	return nil
+}
+
+// lookup returns the identity for a key, comparing against every configured key
+// in constant time so the response time doesn't reveal partial matches
+func (k APIKeys) lookup(candidate string) (string, bool) {
+	identity, ok := "", false
+	for key, id := range k {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(candidate)) == 1 {
+			identity, ok = id, true
+		}
+	}
+	return identity, ok
+}
+
+// keyFingerprint returns a short non-secret identifier for a key
+func keyFingerprint(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return "key-" + hex.EncodeToString(hash[:4])
+}
+
+// APIKeyMiddleware rejects requests without a valid X-API-Key header with 401
+// and attaches the key identity to the request context
+// Chi-compatible middleware
+func APIKeyMiddleware(keys APIKeys, logger Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				logger.Warn(ctx, "missing API key", map[string]interface{}{
+					"path":           r.URL.Path,
+					"correlation_id": GetCorrelationID(ctx),
+				})
+				respondError(w, r, logger, http.StatusUnauthorized, "missing API key", nil)
+				return
+			}
+
+			identity, ok := keys.lookup(key)
+			if !ok {
+				logger.Warn(ctx, "invalid API key", map[string]interface{}{
+					"path":           r.URL.Path,
+					"correlation_id": GetCorrelationID(ctx),
+				})
+				respondError(w, r, logger, http.StatusUnauthorized, "invalid API key", nil)
+				return
+			}
+
+			ctx = context.WithValue(ctx, apiKeyIdentityKey, identity)
+			logger.Info(ctx, "request authenticated", map[string]interface{}{
+				"path":             r.URL.Path,
+				"api_key_identity": identity,
+				"correlation_id":   GetCorrelationID(ctx),
+			})
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// GetAPIKeyIdentity extracts the authenticated API key identity from context
+func GetAPIKeyIdentity(ctx context.Context) string {
+	if identity, ok := ctx.Value(apiKeyIdentityKey).(string); ok {
+		return identity
+	}
+	return ""
+}
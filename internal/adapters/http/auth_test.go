@@ -0,0 +1,107 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	keys, err := ParseAPIKeys("team-a:secret-a, team-b:secret-b")
+	if err != nil {
+		t.Fatalf("ParseAPIKeys() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		key          string
+		wantStatus   int
+		wantIdentity string
+	}{
+		{name: "valid key", key: "secret-b", wantStatus: http.StatusOK, wantIdentity: "team-b"},
+		{name: "invalid key", key: "secret-c", wantStatus: http.StatusUnauthorized},
+		{name: "identity is not a key", key: "team-a", wantStatus: http.StatusUnauthorized},
+		{name: "missing key", key: "", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIdentity string
+			called := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				gotIdentity = GetAPIKeyIdentity(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", nil)
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+
+			APIKeyMiddleware(keys, &mockLogger{})(next).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if called != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("next handler called = %v, want %v", called, tt.wantStatus == http.StatusOK)
+			}
+			if gotIdentity != tt.wantIdentity {
+				t.Errorf("identity = %q, want %q", gotIdentity, tt.wantIdentity)
+			}
+		})
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    APIKeys
+		wantErr bool
+	}{
+		{name: "identities", list: "a:k1,b:k2", want: APIKeys{"k1": "a", "k2": "b"}},
+		{name: "bare key gets fingerprint", list: "k1", want: APIKeys{"k1": keyFingerprint("k1")}},
+		{name: "blank entries skipped", list: " , a:k1 ,", want: APIKeys{"k1": "a"}},
+		{name: "empty list", list: "", want: APIKeys{}},
+		{name: "empty key", list: "a:", wantErr: true},
+		{name: "duplicate key", list: "a:k1,b:k1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAPIKeys(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAPIKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAPIKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAPIKeysFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	content := "# team keys\nteam-a:secret-a\n\nteam-b:secret-b\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadAPIKeysFile(path)
+	if err != nil {
+		t.Fatalf("LoadAPIKeysFile() error = %v", err)
+	}
+	want := APIKeys{"secret-a": "team-a", "secret-b": "team-b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadAPIKeysFile() = %v, want %v", got, want)
+	}
+
+	if _, err := LoadAPIKeysFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+// maxBatchItems - upper bound for items in a batch solve request
+const maxBatchItems = 1000
+
+// BatchSolveItem is one solve request of a batch
+type BatchSolveItem struct {
+	Sizes  []int `json:"sizes"`
+	Amount int   `json:"amount"`
+}
+
+// BatchSolveRequest represents the request body for a batch solve
+type BatchSolveRequest struct {
+	Items []BatchSolveItem `json:"items"`
+}
+
+// BatchSolveResult is the outcome of one batch item: the solve response or an error
+type BatchSolveResult struct {
+	Index int `json:"index"`
+	*SolveResponse
+	Error string `json:"error,omitempty"`
+}
+
+// BatchSolveResponse represents the results of a batch solve, in request order
+type BatchSolveResponse struct {
+	Results []BatchSolveResult `json:"results"`
+}
+
+// SolveBatch handles POST /packs/solve/batch
+// Items are solved concurrently (see WithBatchConcurrency); results keep the request order
+// With a series solver, items sharing their sizes are solved from one DP table
+// (see usecase.SolveBatchShared)
+// An invalid or unsolvable item only fails its own result
+func (h *PackHandler) SolveBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodPost {
+		h.respondError(w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+
+	var req BatchSolveRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if len(req.Items) == 0 || len(req.Items) > maxBatchItems {
+		h.respondError(w, r, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+			"field":   "items",
+			"value":   len(req.Items),
+			"message": fmt.Sprintf("must contain 1 to %d items", maxBatchItems),
+		})
+		return
+	}
+
+	results := make([]BatchSolveResult, len(req.Items))
+
+	// Only valid items are handed to the solver
+	var items []usecase.BatchItem
+	var indexes []int
+	for i, item := range req.Items {
+		results[i].Index = i
+		if err := domain.ValidateSolverInput(item.Sizes, item.Amount); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		items = append(items, usecase.BatchItem{Sizes: item.Sizes, Amount: item.Amount})
+		indexes = append(indexes, i)
+	}
+
+	var batchResults []usecase.BatchResult
+	if h.seriesSolver != nil {
+		batchResults = usecase.SolveBatchShared(ctx, h.solver, h.seriesSolver, items, h.batchConcurrency)
+	} else {
+		batchResults = usecase.SolveBatch(ctx, h.solver, items, h.batchConcurrency)
+	}
+
+	for j, result := range batchResults {
+		i := indexes[j]
+		if result.Err != nil {
+			results[i].Error = result.Err.Error()
+			continue
+		}
+		result.Solution.Normalize()
+		results[i].SolveResponse = &SolveResponse{
+			Solution:      result.Solution.Breakdown,
+			Overage:       result.Solution.Overage,
+			Packs:         result.Solution.Packs,
+			Amount:        result.Solution.Amount,
+			TotalItems:    result.Solution.TotalItems(),
+			DistinctSizes: result.Solution.DistinctSizes(),
+		}
+	}
+
+	// The client is gone or the request timed out: partial results are not returned
+	if err := ctx.Err(); err != nil {
+		h.handleSolverError(w, r, err)
+		return
+	}
+
+	h.respondJSON(w, r, http.StatusOK, BatchSolveResponse{Results: results})
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+func TestPackHandler_SolveBatch(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{}).WithBatchConcurrency(8)
+
+	// 200 valid items with distinct amounts and one invalid item in the middle
+	items := make([]string, 0, 201)
+	for i := 1; i <= 200; i++ {
+		items = append(items, fmt.Sprintf(`{"sizes":[250,500,1000],"amount":%d}`, i*250))
+		if i == 100 {
+			items = append(items, `{"sizes":[250,250],"amount":10}`)
+		}
+	}
+	body := `{"items":[` + strings.Join(items, ",") + `]}`
+
+	req := httptest.NewRequest(http.MethodPost, "/packs/solve/batch", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.SolveBatch(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp BatchSolveResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Results) != 201 {
+		t.Fatalf("expected 201 results, got %d", len(resp.Results))
+	}
+
+	for i, result := range resp.Results {
+		if result.Index != i {
+			t.Fatalf("result %d has index %d", i, result.Index)
+		}
+		if i == 100 {
+			if result.Error == "" || result.SolveResponse != nil {
+				t.Errorf("invalid item: expected only an error, got %+v", result)
+			}
+			continue
+		}
+
+		wantAmount := (i + 1) * 250
+		if i > 100 {
+			wantAmount = i * 250
+		}
+		if result.SolveResponse == nil || result.Amount != wantAmount || result.Overage != 0 {
+			t.Fatalf("result %d: expected exact solution for %d, got %+v", i, wantAmount, result)
+		}
+	}
+}
+
+func TestPackHandler_SolveBatch_InvalidItems(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	for _, body := range []string{`{"items":[]}`, `{"items":[` + strings.Repeat(`{},`, maxBatchItems) + `{}]}`} {
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve/batch", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.SolveBatch(w, req)
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status 422, got %d", w.Code)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package http
+
+import "encoding/json"
+
+// Breakdown formats selected via the "format" query parameter
+const (
+	formatList = "list" // Default: ListSolveResponse, byte-stable
+	formatMap  = "map"  // SolveResponse with solution as {"size": count}, kept for compatibility
+)
+
+// validBreakdownFormat reports whether format ("" for the default) is supported
+func validBreakdownFormat(format string) bool {
+	return format == "" || format == formatList || format == formatMap
+}
+
+// BreakdownEntry represents one pack size of an OrderedBreakdown
+type BreakdownEntry struct {
+	Size  int `json:"size"`
+	Count int `json:"count"`
+}
+
+// OrderedBreakdown is a size -> count breakdown serialized as a JSON array of
+// {"size": S, "count": C} in the given lineOrders key (size descending if
+// empty or unknown)
+type OrderedBreakdown struct {
+	Breakdown map[int]int
+	Order     string
+}
+
+// MarshalJSON implements json.Marshaler
+func (b OrderedBreakdown) MarshalJSON() ([]byte, error) {
+	lines := breakdownLines(b.Breakdown, b.Order)
+
+	entries := make([]BreakdownEntry, 0, len(lines))
+	for _, line := range lines {
+		entries = append(entries, BreakdownEntry{Size: line.Size, Count: line.Count})
+	}
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading the array MarshalJSON
+// writes; the order is not kept
+func (b *OrderedBreakdown) UnmarshalJSON(data []byte) error {
+	var entries []BreakdownEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	breakdown := make(map[int]int, len(entries))
+	for _, entry := range entries {
+		breakdown[entry.Size] = entry.Count
+	}
+	*b = OrderedBreakdown{Breakdown: breakdown}
+	return nil
+}
+
+// ListSolveResponse is SolveResponse with the solution (and lot solution and
+// alternatives) as ordered lists; the default unless ?format=map
+// Fields shadow those of the embedded response and are listed first so
+// "solution" stays the first key
+type ListSolveResponse struct {
+	Solution OrderedBreakdown `json:"solution"`
+	SolveResponse
+	Lot          *ListLotSolution          `json:"lot,omitempty"`
+	Alternatives []ListAlternativeSolution `json:"alternatives,omitempty"`
+}
+
+// ListLotSolution is LotSolution with the solution as an ordered list
+type ListLotSolution struct {
+	Solution OrderedBreakdown `json:"solution"`
+	LotSolution
+}
+
+// ListAlternativeSolution is AlternativeSolution with the solution as an ordered list
+type ListAlternativeSolution struct {
+	Solution OrderedBreakdown `json:"solution"`
+	AlternativeSolution
+}
+
+// newListSolveResponse converts a flat response to the list format, with
+// every breakdown in the given lineOrders key
+func newListSolveResponse(response SolveResponse, order string) ListSolveResponse {
+	list := ListSolveResponse{SolveResponse: response, Solution: OrderedBreakdown{Breakdown: response.Solution, Order: order}}
+	if response.Lot != nil {
+		list.Lot = &ListLotSolution{LotSolution: *response.Lot, Solution: OrderedBreakdown{Breakdown: response.Lot.Solution, Order: order}}
+	}
+	for _, alternative := range response.Alternatives {
+		list.Alternatives = append(list.Alternatives, ListAlternativeSolution{
+			AlternativeSolution: alternative,
+			Solution:            OrderedBreakdown{Breakdown: alternative.Solution, Order: order},
+		})
+	}
+	return list
+}
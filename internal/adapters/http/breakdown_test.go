@@ -0,0 +1,186 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+func TestOrderedBreakdown_MarshalJSON(t *testing.T) {
+	breakdown := OrderedBreakdown{Breakdown: map[int]int{250: 1, 5000: 2, 1000: 3, 500: 4, 2000: 5, 31: 6}}
+	want := `[{"size":5000,"count":2},{"size":2000,"count":5},{"size":1000,"count":3},` +
+		`{"size":500,"count":4},{"size":250,"count":1},{"size":31,"count":6}]`
+
+	for i := 0; i < 100; i++ {
+		got, err := json.Marshal(breakdown)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(got) != want {
+			t.Fatalf("marshal %d = %s, want %s", i, got, want)
+		}
+	}
+
+	if got, _ := json.Marshal(OrderedBreakdown{}); string(got) != "[]" {
+		t.Errorf("empty breakdown = %s, want []", got)
+	}
+
+	// Count ties are broken by size descending
+	breakdown.Order = "count:asc"
+	want = `[{"size":250,"count":1},{"size":5000,"count":2},{"size":1000,"count":3},` +
+		`{"size":500,"count":4},{"size":2000,"count":5},{"size":31,"count":6}]`
+	if got, _ := json.Marshal(breakdown); string(got) != want {
+		t.Errorf("count:asc = %s, want %s", got, want)
+	}
+}
+
+func TestOrderedBreakdown_UnmarshalJSON(t *testing.T) {
+	want := OrderedBreakdown{Breakdown: map[int]int{250: 1, 5000: 2, 1000: 3}}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got OrderedBreakdown
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !maps.Equal(got.Breakdown, want.Breakdown) {
+		t.Errorf("round trip = %v, want %v", got, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"250":1}`), &got); err == nil {
+		t.Error("expected an error for the map form")
+	}
+}
+
+func TestListSolveResponse_Deterministic(t *testing.T) {
+	response := SolveResponse{
+		Solution: map[int]int{5000: 2, 2000: 1, 250: 1},
+		Overage:  249,
+		Packs:    4,
+		Amount:   12001,
+		Lot:      &LotSolution{LotSize: 1000, Amount: 13000, Solution: map[int]int{5000: 2, 2000: 1, 1000: 1}, Packs: 4},
+	}
+
+	first, err := json.Marshal(newListSolveResponse(response, defaultLineOrder))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		got, _ := json.Marshal(newListSolveResponse(response, defaultLineOrder))
+		if !bytes.Equal(got, first) {
+			t.Fatalf("marshal %d = %s, want %s", i, got, first)
+		}
+	}
+
+	// Only the breakdowns change shape; every other field is kept
+	want := `{"solution":[{"size":5000,"count":2},{"size":2000,"count":1},{"size":250,"count":1}],` +
+		`"overage":249,"packs":4,"amount":12001,"total_items":0,"distinct_sizes":0,` +
+		`"lot":{"solution":[{"size":5000,"count":2},{"size":2000,"count":1},{"size":1000,"count":1}],"lot_size":1000,"amount":13000,"overage":0,"packs":4}}`
+	if string(first) != want {
+		t.Errorf("response = %s, want %s", first, want)
+	}
+}
+
+func TestListSolveResponse_Alternatives(t *testing.T) {
+	response := SolveResponse{
+		Solution:     map[int]int{500: 1},
+		Overage:      249,
+		Packs:        1,
+		Amount:       251,
+		Alternatives: []AlternativeSolution{{Solution: map[int]int{250: 2}, Overage: 249, Packs: 2}},
+	}
+
+	got, err := json.Marshal(newListSolveResponse(response, defaultLineOrder))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"solution":[{"size":500,"count":1}],"overage":249,"packs":1,"amount":251,"total_items":0,"distinct_sizes":0,` +
+		`"alternatives":[{"solution":[{"size":250,"count":2}],"overage":249,"packs":2}]}`
+	if string(got) != want {
+		t.Errorf("response = %s, want %s", got, want)
+	}
+}
+
+func TestPackHandler_SolvePacks_Format(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+	body := `{"sizes":[250,500,1000,2000,5000],"amount":12001}`
+
+	solve := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve"+query, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.SolvePacks(w, req)
+		return w
+	}
+
+	tests := []struct {
+		query        string
+		wantStatus   int
+		wantSolution string
+	}{
+		{query: "", wantStatus: http.StatusOK, wantSolution: `[{"size":5000,"count":2},{"size":2000,"count":1},{"size":250,"count":1}]`},
+		{query: "?format=map", wantStatus: http.StatusOK, wantSolution: `{"2000":1,"250":1,"5000":2}`},
+		{query: "?format=list", wantStatus: http.StatusOK, wantSolution: `[{"size":5000,"count":2},{"size":2000,"count":1},{"size":250,"count":1}]`},
+		{query: "?format=csv", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := solve(tt.query)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Solution json.RawMessage `json:"solution"`
+				Packs    int             `json:"packs"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if string(resp.Solution) != tt.wantSolution || resp.Packs != 4 {
+				t.Errorf("solution = %s (packs %d), want %s (packs 4)", resp.Solution, resp.Packs, tt.wantSolution)
+			}
+
+			// Repeated requests are byte-identical in either format
+			if again := solve(tt.query); !bytes.Equal(again.Body.Bytes(), w.Body.Bytes()) {
+				t.Errorf("repeated response = %s, want %s", again.Body.String(), w.Body.String())
+			}
+		})
+	}
+}
+
+// decodeSolveResponse decodes a solve response in the default list format
+// back into a SolveResponse, so tests can compare breakdowns as maps
+func decodeSolveResponse(t *testing.T, body io.Reader) SolveResponse {
+	t.Helper()
+	var list ListSolveResponse
+	if err := json.NewDecoder(body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	resp := list.SolveResponse
+	resp.Solution = list.Solution.Breakdown
+	if list.Lot != nil {
+		lot := list.Lot.LotSolution
+		lot.Solution = list.Lot.Solution.Breakdown
+		resp.Lot = &lot
+	}
+	for _, alternative := range list.Alternatives {
+		converted := alternative.AlternativeSolution
+		converted.Solution = alternative.Solution.Breakdown
+		resp.Alternatives = append(resp.Alternatives, converted)
+	}
+	return resp
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"net/http"
+)
+
+// CacheMetricsSource provides cumulative solver cache hit/miss counters
+type CacheMetricsSource interface {
+	GetMetrics() (hits, misses uint64)
+}
+
+// CacheStatsResponse represents the solver cache counters since startup
+type CacheStatsResponse struct {
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"` // hits / (hits + misses), 0 before the first lookup
+}
+
+// CacheHandler handles HTTP requests for the solver cache
+type CacheHandler struct {
+	source CacheMetricsSource
+	logger Logger
+}
+
+// NewCacheHandler creates a new cache handler
+func NewCacheHandler(source CacheMetricsSource, logger Logger) *CacheHandler {
+	return &CacheHandler{
+		source: source,
+		logger: logger,
+	}
+}
+
+// Stats handles GET /cache/stats
+func (h *CacheHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	hits, misses := h.source.GetMetrics()
+
+	response := CacheStatsResponse{Hits: hits, Misses: misses}
+	if total := hits + misses; total > 0 {
+		response.HitRatio = float64(hits) / float64(total)
+	}
+
+	respondJSON(w, r, h.logger, http.StatusOK, response)
+}
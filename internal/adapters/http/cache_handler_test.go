@@ -0,0 +1,51 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fixedCacheMetrics reports constant cache counters
+type fixedCacheMetrics struct {
+	hits, misses uint64
+}
+
+func (m *fixedCacheMetrics) GetMetrics() (hits, misses uint64) {
+	return m.hits, m.misses
+}
+
+func TestCacheHandler_Stats(t *testing.T) {
+	tests := []struct {
+		name   string
+		hits   uint64
+		misses uint64
+		want   CacheStatsResponse
+	}{
+		{name: "no lookups", want: CacheStatsResponse{}},
+		{name: "hits and misses", hits: 3, misses: 1, want: CacheStatsResponse{Hits: 3, Misses: 1, HitRatio: 0.75}},
+		{name: "misses only", misses: 2, want: CacheStatsResponse{Misses: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCacheHandler(&fixedCacheMetrics{hits: tt.hits, misses: tt.misses}, &mockLogger{})
+
+			req := httptest.NewRequest(http.MethodGet, "/cache/stats", nil)
+			w := httptest.NewRecorder()
+			handler.Stats(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", w.Code)
+			}
+			var resp CacheStatsResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp != tt.want {
+				t.Errorf("response = %+v, want %+v", resp, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+const (
+	// defaultHistogramBuckets - bucket count when the query parameter is omitted
+	defaultHistogramBuckets = 10
+
+	// maxHistogramBuckets - upper bound for the buckets query parameter
+	maxHistogramBuckets = 100
+)
+
+// CalculationStore interface for calculation history analytics
+type CalculationStore interface {
+	GetOverageHistogram(ctx context.Context, buckets int) ([]domain.OverageBucket, error)
+	ListCalculations(ctx context.Context, packSetID *int64, limit, offset int) ([]domain.StoredCalculation, error)
+	CountCalculations(ctx context.Context, packSetID *int64) (int64, error)
+	ListCalculationsUsingSize(ctx context.Context, size, limit, offset int) ([]domain.StoredCalculation, error)
+	ExportCalculations(ctx context.Context, filter domain.CalculationFilter, fn func(domain.StoredCalculation) error) error
+	GetPackSizeUsage(ctx context.Context) (map[int]int64, error)
+}
+
+// OverageHistogramResponse represents the overage distribution across calculations
+type OverageHistogramResponse struct {
+	Buckets []domain.OverageBucket `json:"buckets"`
+}
+
+// PackSizeUsageResponse represents the total packs of each size across calculations
+type PackSizeUsageResponse struct {
+	Usage map[int]int64 `json:"usage"` // Pack size -> total packs
+}
+
+// CalculationsResponse represents a page of stored calculations
+type CalculationsResponse struct {
+	Calculations []CalculationResponse `json:"calculations"`
+}
+
+// CalculationsPageResponse represents a page of stored calculations with the
+// total across all pages, so clients can tell whether more pages exist
+type CalculationsPageResponse struct {
+	Items  []CalculationResponse `json:"items"`
+	Total  int64                 `json:"total"`
+	Limit  int                   `json:"limit"`
+	Offset int                   `json:"offset"`
+}
+
+// CalculationHandler handles HTTP requests for stored calculations
+type CalculationHandler struct {
+	store      CalculationStore
+	logger     Logger
+	timeFormat TimeFormat // Serialization of calculated_at
+}
+
+// NewCalculationHandler creates a new calculation handler
+func NewCalculationHandler(store CalculationStore, logger Logger) *CalculationHandler {
+	return &CalculationHandler{
+		store:      store,
+		logger:     logger,
+		timeFormat: TimeFormatRFC3339,
+	}
+}
+
+// WithTimeFormat sets how timestamps are serialized in responses
+func (h *CalculationHandler) WithTimeFormat(format TimeFormat) *CalculationHandler {
+	h.timeFormat = format
+	return h
+}
+
+// OverageHistogram handles GET /calculations/stats/overage-histogram?buckets=N
+func (h *CalculationHandler) OverageHistogram(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	buckets := defaultHistogramBuckets
+	if raw := r.URL.Query().Get("buckets"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 || value > maxHistogramBuckets {
+			respondError(w, r, h.logger, http.StatusBadRequest, "buckets must be an integer between 1 and 100", map[string]interface{}{
+				"buckets": raw,
+			})
+			return
+		}
+		buckets = value
+	}
+
+	histogram, err := h.store.GetOverageHistogram(ctx, buckets)
+	if err != nil {
+		h.logger.Error(ctx, "failed to get overage histogram", map[string]interface{}{
+			"error": err.Error(),
+		})
+		respondError(w, r, h.logger, http.StatusInternalServerError, "internal server error", nil)
+		return
+	}
+
+	respondJSON(w, r, h.logger, http.StatusOK, OverageHistogramResponse{Buckets: histogram})
+}
+
+// ListCalculations handles GET /calculations?pack_set_id=1&limit=100&offset=0
+// Returns a page of calculations, newest first, optionally filtered by pack set
+// With uses_size=5000 it instead returns calculations whose breakdown uses the
+// given pack size, in the {"calculations": [...]} shape without a total
+func (h *CalculationHandler) ListCalculations(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("uses_size") {
+		h.listCalculationsUsingSize(w, r)
+		return
+	}
+
+	ctx := r.Context()
+
+	packSetID, ok := h.parsePackSetID(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := parsePagination(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	calculations, err := h.store.ListCalculations(ctx, packSetID, limit, offset)
+	if err != nil {
+		h.respondStoreError(w, r, "failed to list calculations", err)
+		return
+	}
+	total, err := h.store.CountCalculations(ctx, packSetID)
+	if err != nil {
+		h.respondStoreError(w, r, "failed to count calculations", err)
+		return
+	}
+
+	response := CalculationsPageResponse{
+		Items:  make([]CalculationResponse, 0, len(calculations)),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	for _, calculation := range calculations {
+		response.Items = append(response.Items, newCalculationResponse(calculation, h.timeFormat))
+	}
+	respondJSON(w, r, h.logger, http.StatusOK, response)
+}
+
+// listCalculationsUsingSize handles GET /calculations?uses_size=5000&limit=100&offset=0
+func (h *CalculationHandler) listCalculationsUsingSize(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	raw := r.URL.Query().Get("uses_size")
+	size, err := strconv.Atoi(raw)
+	if err != nil || size < 1 || size > domain.MaxPackSize {
+		respondError(w, r, h.logger, http.StatusBadRequest, "uses_size must be an integer between 1 and 1000000", map[string]interface{}{
+			"uses_size": raw,
+		})
+		return
+	}
+
+	limit, offset, ok := parsePagination(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	calculations, err := h.store.ListCalculationsUsingSize(ctx, size, limit, offset)
+	if err != nil {
+		h.logger.Error(ctx, "failed to list calculations", map[string]interface{}{
+			"uses_size": size,
+			"error":     err.Error(),
+		})
+		respondError(w, r, h.logger, http.StatusInternalServerError, "internal server error", nil)
+		return
+	}
+
+	response := CalculationsResponse{Calculations: make([]CalculationResponse, 0, len(calculations))}
+	for _, calculation := range calculations {
+		response.Calculations = append(response.Calculations, newCalculationResponse(calculation, h.timeFormat))
+	}
+	respondJSON(w, r, h.logger, http.StatusOK, response)
+}
+
+// Export handles GET /calculations/export?pack_set_id=1&from=2025-10-01T00:00:00Z&to=2025-11-01T00:00:00Z
+// Streams matching calculations, oldest first, as CSV; all filters are
+// optional, from is inclusive and to exclusive (RFC 3339)
+func (h *CalculationHandler) Export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	packSetID, ok := h.parsePackSetID(w, r)
+	if !ok {
+		return
+	}
+	filter := domain.CalculationFilter{PackSetID: packSetID}
+	for _, bound := range []struct {
+		param string
+		value *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := r.URL.Query().Get(bound.param)
+		if raw == "" {
+			continue
+		}
+		value, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			respondError(w, r, h.logger, http.StatusBadRequest, bound.param+" must be an RFC 3339 timestamp", map[string]interface{}{
+				bound.param: raw,
+			})
+			return
+		}
+		*bound.value = value
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		respondError(w, r, h.logger, http.StatusBadRequest, "from must be before to", map[string]interface{}{
+			"from": filter.From,
+			"to":   filter.To,
+		})
+		return
+	}
+
+	// The header row goes out with the first calculation, so a store error
+	// before any row still gets a JSON 500; later errors can only truncate
+	// HTTP/1.0 has no chunked encoding: those clients get the whole export,
+	// or a JSON 500, in one response with a Content-Length
+	var buffer *bytes.Buffer
+	var out io.Writer = w
+	if !r.ProtoAtLeast(1, 1) {
+		buffer = &bytes.Buffer{}
+		out = buffer
+	}
+	writer := csv.NewWriter(out)
+	setHeaders := func() {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="calculations.csv"`)
+	}
+	started := false
+	start := func() error {
+		started = true
+		if buffer == nil {
+			setHeaders()
+			w.WriteHeader(http.StatusOK)
+		}
+		return writer.Write(calculationsCSVHeader)
+	}
+
+	rows := 0
+	err := h.store.ExportCalculations(ctx, filter, func(calculation domain.StoredCalculation) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		rows++
+		return writer.Write(calculationCSVRow(calculation, h.timeFormat))
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err != nil && (!started || buffer != nil) {
+		h.respondStoreError(w, r, "failed to export calculations", err)
+		return
+	}
+
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	if buffer != nil {
+		setHeaders()
+		writeBuffered(w, http.StatusOK, buffer.Bytes())
+	}
+	if err != nil {
+		h.logger.Error(ctx, "calculations export truncated", map[string]interface{}{
+			"rows":  rows,
+			"error": err.Error(),
+		})
+	}
+}
+
+// calculationsCSVHeader is the header row of the calculations export
+var calculationsCSVHeader = []string{"id", "pack_set_id", "amount", "total_packs", "overage", "calculated_at", "breakdown"}
+
+// calculationCSVRow formats a calculation as a row under calculationsCSVHeader
+// pack_set_id is empty when the calculation used ad hoc sizes; breakdown is
+// the JSON object of pack size -> quantity
+func calculationCSVRow(calculation domain.StoredCalculation, format TimeFormat) []string {
+	packSetID := ""
+	if calculation.PackSetID != nil {
+		packSetID = strconv.FormatInt(*calculation.PackSetID, 10)
+	}
+	breakdown, _ := json.Marshal(calculation.Breakdown) // map[int]int always marshals
+
+	return []string{
+		strconv.FormatInt(calculation.ID, 10),
+		packSetID,
+		strconv.Itoa(calculation.Amount),
+		strconv.Itoa(calculation.TotalPacks),
+		strconv.Itoa(calculation.Overage),
+		Timestamp{Time: calculation.CalculatedAt, Format: format}.String(),
+		string(breakdown),
+	}
+}
+
+// parsePackSetID reads the optional pack_set_id query parameter, writing a
+// 400 response if it is invalid
+func (h *CalculationHandler) parsePackSetID(w http.ResponseWriter, r *http.Request) (*int64, bool) {
+	raw := r.URL.Query().Get("pack_set_id")
+	if raw == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 1 {
+		respondError(w, r, h.logger, http.StatusBadRequest, "pack_set_id must be a positive integer", map[string]interface{}{
+			"pack_set_id": raw,
+		})
+		return nil, false
+	}
+	return &id, true
+}
+
+// respondStoreError logs a store failure and responds with 500
+func (h *CalculationHandler) respondStoreError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	h.logger.Error(r.Context(), msg, map[string]interface{}{
+		"error": err.Error(),
+	})
+	respondError(w, r, h.logger, http.StatusInternalServerError, "internal server error", nil)
+}
+
+// PackSizeUsage handles GET /calculations/usage
+func (h *CalculationHandler) PackSizeUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	usage, err := h.store.GetPackSizeUsage(ctx)
+	if err != nil {
+		h.logger.Error(ctx, "failed to get pack size usage", map[string]interface{}{
+			"error": err.Error(),
+		})
+		respondError(w, r, h.logger, http.StatusInternalServerError, "internal server error", nil)
+		return
+	}
+
+	respondJSON(w, r, h.logger, http.StatusOK, PackSizeUsageResponse{Usage: usage})
+}
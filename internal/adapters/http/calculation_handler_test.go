@@ -0,0 +1,460 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// Mock calculation store for tests
+type mockCalculationStore struct {
+	histogram    []domain.OverageBucket
+	gotBuckets   int
+	calculations []domain.StoredCalculation
+	total        int64
+	gotPackSetID *int64
+	gotFilter    domain.CalculationFilter
+	gotSize      int
+	gotLimit     int
+	gotOffset    int
+	usage        map[int]int64
+	err          error
+}
+
+func (m *mockCalculationStore) GetOverageHistogram(ctx context.Context, buckets int) ([]domain.OverageBucket, error) {
+	m.gotBuckets = buckets
+	return m.histogram, nil
+}
+
+func (m *mockCalculationStore) ListCalculations(ctx context.Context, packSetID *int64, limit, offset int) ([]domain.StoredCalculation, error) {
+	m.gotPackSetID, m.gotLimit, m.gotOffset = packSetID, limit, offset
+	return m.calculations, m.err
+}
+
+func (m *mockCalculationStore) CountCalculations(ctx context.Context, packSetID *int64) (int64, error) {
+	return m.total, m.err
+}
+
+func (m *mockCalculationStore) ExportCalculations(ctx context.Context, filter domain.CalculationFilter, fn func(domain.StoredCalculation) error) error {
+	m.gotFilter = filter
+	if m.err != nil {
+		return m.err
+	}
+	for _, calculation := range m.calculations {
+		if err := fn(calculation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockCalculationStore) ListCalculationsUsingSize(ctx context.Context, size, limit, offset int) ([]domain.StoredCalculation, error) {
+	m.gotSize, m.gotLimit, m.gotOffset = size, limit, offset
+	return m.calculations, nil
+}
+
+func (m *mockCalculationStore) GetPackSizeUsage(ctx context.Context) (map[int]int64, error) {
+	return m.usage, m.err
+}
+
+func TestCalculationHandler_OverageHistogram(t *testing.T) {
+	store := &mockCalculationStore{
+		histogram: []domain.OverageBucket{
+			{Lower: 0, Upper: 49, Count: 12},
+			{Lower: 50, Upper: 99, Count: 3},
+		},
+	}
+	handler := NewCalculationHandler(store, &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/calculations/stats/overage-histogram?buckets=2", nil)
+	w := httptest.NewRecorder()
+
+	handler.OverageHistogram(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if store.gotBuckets != 2 {
+		t.Errorf("expected 2 buckets requested, got %d", store.gotBuckets)
+	}
+
+	var resp OverageHistogramResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Buckets) != 2 || resp.Buckets[0].Count != 12 {
+		t.Errorf("unexpected buckets: %+v", resp.Buckets)
+	}
+}
+
+func TestCalculationHandler_OverageHistogram_InvalidBuckets(t *testing.T) {
+	handler := NewCalculationHandler(&mockCalculationStore{}, &mockLogger{})
+
+	for _, buckets := range []string{"0", "101", "abc"} {
+		req := httptest.NewRequest(http.MethodGet, "/calculations/stats/overage-histogram?buckets="+buckets, nil)
+		w := httptest.NewRecorder()
+
+		handler.OverageHistogram(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("buckets=%s: expected status 400, got %d", buckets, w.Code)
+		}
+	}
+}
+
+func TestCalculationHandler_ListCalculations(t *testing.T) {
+	store := &mockCalculationStore{
+		calculations: []domain.StoredCalculation{
+			{ID: 7, PackSizes: []int{250, 5000}, Amount: 10000, Breakdown: map[int]int{5000: 2}, TotalPacks: 2, SolverVersion: "abc123def456"},
+		},
+	}
+	handler := NewCalculationHandler(store, &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/calculations?uses_size=5000&limit=10&offset=20", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListCalculations(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if store.gotSize != 5000 || store.gotLimit != 10 || store.gotOffset != 20 {
+		t.Errorf("store called with size=%d limit=%d offset=%d", store.gotSize, store.gotLimit, store.gotOffset)
+	}
+
+	var resp CalculationsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Calculations) != 1 || resp.Calculations[0].Breakdown[5000] != 2 {
+		t.Errorf("unexpected calculations: %+v", resp.Calculations)
+	}
+	if got := resp.Calculations[0].SolverVersion; got != "abc123def456" {
+		t.Errorf("solver_version = %q, want abc123def456", got)
+	}
+}
+
+func TestCalculationHandler_ListCalculations_Page(t *testing.T) {
+	store := &mockCalculationStore{
+		calculations: []domain.StoredCalculation{
+			{ID: 9, PackSizes: []int{250, 500}, Amount: 501, Breakdown: map[int]int{250: 1, 500: 1}, TotalPacks: 2},
+		},
+		total: 42,
+	}
+	handler := NewCalculationHandler(store, &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/calculations?pack_set_id=3&limit=1&offset=5", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListCalculations(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if store.gotPackSetID == nil || *store.gotPackSetID != 3 || store.gotLimit != 1 || store.gotOffset != 5 {
+		t.Errorf("store called with pack_set_id=%v limit=%d offset=%d", store.gotPackSetID, store.gotLimit, store.gotOffset)
+	}
+
+	var resp CalculationsPageResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Total != 42 || resp.Limit != 1 || resp.Offset != 5 {
+		t.Errorf("total=%d limit=%d offset=%d, want 42, 1, 5", resp.Total, resp.Limit, resp.Offset)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].ID != 9 {
+		t.Errorf("unexpected items: %+v", resp.Items)
+	}
+}
+
+func TestCalculationHandler_ListCalculations_PageDefaults(t *testing.T) {
+	store := &mockCalculationStore{}
+	handler := NewCalculationHandler(store, &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/calculations", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListCalculations(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if store.gotPackSetID != nil || store.gotLimit != 100 || store.gotOffset != 0 {
+		t.Errorf("store called with pack_set_id=%v limit=%d offset=%d", store.gotPackSetID, store.gotLimit, store.gotOffset)
+	}
+
+	// An empty page is still an array, not null
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(w.Body).Decode(&raw); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got := string(raw["items"]); got != "[]" {
+		t.Errorf("items = %s, want []", got)
+	}
+}
+
+func TestCalculationHandler_ListCalculations_StoreError(t *testing.T) {
+	handler := NewCalculationHandler(&mockCalculationStore{err: errors.New("connection refused")}, &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/calculations", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListCalculations(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+}
+
+func TestCalculationHandler_ListCalculations_InvalidQuery(t *testing.T) {
+	handler := NewCalculationHandler(&mockCalculationStore{}, &mockLogger{})
+
+	for _, query := range []string{"uses_size=", "uses_size=abc", "uses_size=0", "uses_size=5000&limit=0", "uses_size=5000&offset=-1", "pack_set_id=abc", "pack_set_id=0", "limit=1001"} {
+		req := httptest.NewRequest(http.MethodGet, "/calculations?"+query, nil)
+		w := httptest.NewRecorder()
+
+		handler.ListCalculations(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected status 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestCalculationHandler_ListCalculations_TimeFormat(t *testing.T) {
+	calculatedAt := time.Date(2025, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		format TimeFormat
+		want   string
+	}{
+		{format: TimeFormatRFC3339, want: `"2025-10-19T12:00:00Z"`},
+		{format: TimeFormatUnixMillis, want: `1760875200000`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			store := &mockCalculationStore{
+				calculations: []domain.StoredCalculation{{ID: 7, Breakdown: map[int]int{5000: 2}, CalculatedAt: calculatedAt}},
+			}
+			handler := NewCalculationHandler(store, &mockLogger{}).WithTimeFormat(tt.format)
+
+			req := httptest.NewRequest(http.MethodGet, "/calculations?uses_size=5000", nil)
+			w := httptest.NewRecorder()
+			handler.ListCalculations(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", w.Code)
+			}
+
+			var raw struct {
+				Calculations []map[string]json.RawMessage `json:"calculations"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&raw); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(raw.Calculations) != 1 {
+				t.Fatalf("expected 1 calculation, got %d", len(raw.Calculations))
+			}
+			if got := string(raw.Calculations[0]["calculated_at"]); got != tt.want {
+				t.Errorf("calculated_at = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculationHandler_Export(t *testing.T) {
+	packSetID := int64(3)
+	store := &mockCalculationStore{
+		calculations: []domain.StoredCalculation{{
+			ID:           42,
+			PackSetID:    &packSetID,
+			Amount:       10250,
+			Breakdown:    map[int]int{5000: 2, 250: 1},
+			TotalPacks:   3,
+			CalculatedAt: time.Date(2025, 10, 19, 12, 0, 0, 0, time.UTC),
+		}},
+	}
+	handler := NewCalculationHandler(store, &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/calculations/export?pack_set_id=3&from=2025-10-01T00:00:00Z&to=2025-11-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+
+	handler.Export(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", got)
+	}
+	wantFilter := domain.CalculationFilter{
+		PackSetID: &packSetID,
+		From:      time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if f := store.gotFilter; f.PackSetID == nil || *f.PackSetID != 3 || !f.From.Equal(wantFilter.From) || !f.To.Equal(wantFilter.To) {
+		t.Errorf("store called with filter %+v, want %+v", f, wantFilter)
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	want := [][]string{
+		{"id", "pack_set_id", "amount", "total_packs", "overage", "calculated_at", "breakdown"},
+		{"42", "3", "10250", "3", "0", "2025-10-19T12:00:00Z", `{"250":1,"5000":2}`},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("CSV = %q, want %q", records, want)
+	}
+}
+
+func TestCalculationHandler_Export_Empty(t *testing.T) {
+	handler := NewCalculationHandler(&mockCalculationStore{}, &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/calculations/export", nil)
+	w := httptest.NewRecorder()
+
+	handler.Export(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got, want := w.Body.String(), "id,pack_set_id,amount,total_packs,overage,calculated_at,breakdown\n"; got != want {
+		t.Errorf("body = %q, want only the header row %q", got, want)
+	}
+}
+
+func TestCalculationHandler_Export_HTTP10(t *testing.T) {
+	store := &mockCalculationStore{
+		calculations: []domain.StoredCalculation{{
+			ID:           42,
+			Amount:       250,
+			Breakdown:    map[int]int{250: 1},
+			TotalPacks:   1,
+			CalculatedAt: time.Date(2025, 10, 19, 12, 0, 0, 0, time.UTC),
+		}},
+	}
+	handler := NewCalculationHandler(store, &mockLogger{})
+
+	// HTTP/1.0 has no chunked encoding, so the export is sent with a Content-Length
+	req := httptest.NewRequest(http.MethodGet, "/calculations/export", nil)
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/1.0", 1, 0
+	w := httptest.NewRecorder()
+
+	handler.Export(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got, want := w.Header().Get("Content-Length"), strconv.Itoa(w.Body.Len()); got != want {
+		t.Errorf("Content-Length = %q, want %q", got, want)
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", got)
+	}
+	want := "id,pack_set_id,amount,total_packs,overage,calculated_at,breakdown\n" +
+		`42,,250,1,0,2025-10-19T12:00:00Z,"{""250"":1}"` + "\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestCalculationHandler_Export_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		wantStatus int
+	}{
+		{name: "invalid pack_set_id", query: "pack_set_id=0", wantStatus: http.StatusBadRequest},
+		{name: "invalid from", query: "from=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "empty range", query: "from=2025-11-01T00:00:00Z&to=2025-10-01T00:00:00Z", wantStatus: http.StatusBadRequest},
+		{name: "store error", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCalculationHandler(&mockCalculationStore{err: tt.err}, &mockLogger{})
+
+			req := httptest.NewRequest(http.MethodGet, "/calculations/export?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.Export(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				t.Errorf("Content-Type = %q, want a JSON error", ct)
+			}
+		})
+	}
+}
+
+func TestParseTimeFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    TimeFormat
+		wantErr bool
+	}{
+		{value: "", want: TimeFormatRFC3339},
+		{value: "rfc3339", want: TimeFormatRFC3339},
+		{value: "unix_ms", want: TimeFormatUnixMillis},
+		{value: "unix", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTimeFormat(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseTimeFormat(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseTimeFormat(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCalculationHandler_PackSizeUsage(t *testing.T) {
+	store := &mockCalculationStore{usage: map[int]int64{250: 3, 5000: 12}}
+	handler := NewCalculationHandler(store, &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/calculations/usage", nil)
+	w := httptest.NewRecorder()
+
+	handler.PackSizeUsage(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp PackSizeUsageResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Usage) != 2 || resp.Usage[250] != 3 || resp.Usage[5000] != 12 {
+		t.Errorf("unexpected usage: %v", resp.Usage)
+	}
+}
+
+func TestCalculationHandler_PackSizeUsage_StoreError(t *testing.T) {
+	handler := NewCalculationHandler(&mockCalculationStore{err: errors.New("connection refused")}, &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/calculations/usage", nil)
+	w := httptest.NewRecorder()
+
+	handler.PackSizeUsage(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+}
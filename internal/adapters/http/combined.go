@@ -0,0 +1,156 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+// maxLineItems - upper bound for line items in a combined solve request
+const maxLineItems = 1000
+
+// LineItem is one SKU requirement of a combined order
+type LineItem struct {
+	SKU    string `json:"sku"`
+	Amount int    `json:"amount"`
+}
+
+// CombinedSolveRequest represents the request body for a combined solve
+type CombinedSolveRequest struct {
+	Sizes []int      `json:"sizes"`
+	Items []LineItem `json:"items"`
+}
+
+// LineItemAllocation is the share of the combined packs allocated to a line item
+type LineItemAllocation struct {
+	SKU      string      `json:"sku"`
+	Amount   int         `json:"amount"`
+	Solution map[int]int `json:"solution"` // size → count
+	Packs    int         `json:"packs"`
+	Units    int         `json:"units"` // Items in the allocated packs; may differ from Amount
+}
+
+// CombinedSolveResponse represents the combined solution and its per-SKU allocation
+type CombinedSolveResponse struct {
+	Solution   map[int]int          `json:"solution"` // size → count
+	Overage    int                  `json:"overage"`
+	Packs      int                  `json:"packs"`
+	Amount     int                  `json:"amount"` // Sum of line item amounts
+	TotalItems int                  `json:"total_items"`
+	Allocation []LineItemAllocation `json:"allocation"`
+}
+
+// SolveCombined handles POST /packs/solve/combined
+// Solves the sum of all line items once, so overage is minimized across the whole order,
+// then allocates the packs back to line items in proportion to their amounts
+// (see usecase.AllocatePacks)
+func (h *PackHandler) SolveCombined(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodPost {
+		h.respondError(w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+
+	var req CombinedSolveRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	total, err := validateCombinedRequest(&req)
+	if err != nil {
+		h.respondError(w, r, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+			"errors": validationErrorDetails(err),
+		})
+		return
+	}
+
+	solution, err := h.solver.Solve(ctx, req.Sizes, total)
+	if err != nil {
+		h.handleSolverError(w, r, err)
+		return
+	}
+	solution.Normalize()
+
+	// Optional save to DB for audit
+	if h.repository != nil {
+		h.saveCalculationAsync(newCalculationRecord(ctx, req.Sizes, nil, solution, nil))
+	}
+
+	amounts := make([]int, len(req.Items))
+	for i, item := range req.Items {
+		amounts[i] = item.Amount
+	}
+
+	allocation := make([]LineItemAllocation, len(req.Items))
+	for i, breakdown := range usecase.AllocatePacks(solution.Breakdown, amounts) {
+		share := LineItemAllocation{
+			SKU:      req.Items[i].SKU,
+			Amount:   req.Items[i].Amount,
+			Solution: breakdown,
+		}
+		for size, count := range breakdown {
+			share.Packs += count
+			share.Units += size * count
+		}
+		allocation[i] = share
+	}
+
+	h.respondJSON(w, r, http.StatusOK, CombinedSolveResponse{
+		Solution:   solution.Breakdown,
+		Overage:    solution.Overage,
+		Packs:      solution.Packs,
+		Amount:     solution.Amount,
+		TotalItems: solution.TotalItems(),
+		Allocation: allocation,
+	})
+}
+
+// validateCombinedRequest validates the request and returns the combined amount
+// Returns an errors.Join of *domain.ValidationError listing every offending field
+func validateCombinedRequest(req *CombinedSolveRequest) (int, error) {
+	var errs []error
+
+	if err := domain.ValidatePackSizes(req.Sizes); err != nil {
+		errs = append(errs, domain.NewValidationError("sizes", req.Sizes, err.Error()))
+	}
+
+	switch {
+	case len(req.Items) == 0:
+		errs = append(errs, domain.NewValidationError("items", len(req.Items), "must not be empty"))
+	case len(req.Items) > maxLineItems:
+		errs = append(errs, domain.NewValidationError("items", len(req.Items), fmt.Sprintf("must not exceed %d line items", maxLineItems)))
+	}
+
+	total := 0
+	seen := make(map[string]bool, len(req.Items))
+	for i, item := range req.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		if item.SKU == "" {
+			errs = append(errs, domain.NewValidationError(field+".sku", item.SKU, "must not be empty"))
+		} else if seen[item.SKU] {
+			errs = append(errs, domain.NewValidationError(field+".sku", item.SKU, "duplicate sku"))
+		}
+		seen[item.SKU] = true
+
+		if err := domain.ValidateAmount(item.Amount); err != nil {
+			errs = append(errs, domain.NewValidationError(field+".amount", item.Amount, err.Error()))
+			continue
+		}
+		total += item.Amount
+	}
+
+	if len(errs) == 0 {
+		if err := domain.ValidateAmount(total); err != nil {
+			errs = append(errs, domain.NewValidationError("items", total, "combined amount: "+err.Error()))
+		}
+	}
+
+	if len(errs) > 0 {
+		return 0, errors.Join(errs...)
+	}
+	return total, nil
+}
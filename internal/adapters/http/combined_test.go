@@ -0,0 +1,67 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+func TestPackHandler_SolveCombined(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	body := `{"sizes":[250,500,1000,2000,5000],"items":[{"sku":"A","amount":7000},{"sku":"B","amount":5001}]}`
+	req := httptest.NewRequest(http.MethodPost, "/packs/solve/combined", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.SolveCombined(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp CombinedSolveResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	// Solved once for 12001: one shared 250 pack instead of one per SKU
+	if resp.Amount != 12001 || resp.TotalItems != 12250 || resp.Overage != 249 || resp.Packs != 4 {
+		t.Errorf("unexpected combined solution: %+v", resp)
+	}
+
+	want := []LineItemAllocation{
+		{SKU: "A", Amount: 7000, Solution: map[int]int{5000: 1, 2000: 1, 250: 1}, Packs: 3, Units: 7250},
+		{SKU: "B", Amount: 5001, Solution: map[int]int{5000: 1}, Packs: 1, Units: 5000},
+	}
+	if !reflect.DeepEqual(resp.Allocation, want) {
+		t.Errorf("allocation = %+v, want %+v", resp.Allocation, want)
+	}
+}
+
+func TestPackHandler_SolveCombined_ValidationError(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	body := `{"sizes":[250,500],"items":[{"sku":"A","amount":0},{"sku":"A","amount":10}]}`
+	req := httptest.NewRequest(http.MethodPost, "/packs/solve/combined", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.SolveCombined(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d", w.Code)
+	}
+
+	var resp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	errs, ok := resp.Details["errors"].([]interface{})
+	if !ok || len(errs) != 2 {
+		t.Errorf("expected amount and duplicate sku errors, got %v", resp.Details)
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// Machine-readable error codes sent in ErrorResponse.Code
+// Clients branch on these; unlike messages they never change
+const (
+	CodeInvalidInput     = "INVALID_INPUT"     // Solver input rejected (domain.ErrInvalidInput)
+	CodeValidationFailed = "VALIDATION_FAILED" // Request field validation failed
+	CodeNoSolution       = "NO_SOLUTION"       // No packing satisfies the request
+	CodeInputTooLarge    = "INPUT_TOO_LARGE"   // Over the solver memory budget or DP table limit
+	CodeTimeout          = "TIMEOUT"           // Solve timeout or request deadline exceeded
+	CodeCanceled         = "CANCELED"          // Request or solve cancelled
+	CodeBadRequest       = "BAD_REQUEST"       // Malformed request (e.g. invalid JSON)
+	CodeUnauthorized     = "UNAUTHORIZED"
+	CodeNotFound         = "NOT_FOUND"
+	CodeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+	CodeConflict         = "CONFLICT"
+	CodeRateLimited      = "RATE_LIMITED"
+	CodeInternal         = "INTERNAL"
+	CodeNotImplemented   = "NOT_IMPLEMENTED"
+	CodeUnavailable      = "UNAVAILABLE"
+)
+
+// errorCode maps an error to its code via errors.Is, "" if it has none
+// Checked in the same order as respondSolverError, so an error wrapping both
+// ErrNoSolution and ErrSearchTruncated is CodeInputTooLarge
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		return CodeInvalidInput
+	case errors.Is(err, domain.ErrMemoryBudgetExceeded), errors.Is(err, domain.ErrSearchTruncated):
+		return CodeInputTooLarge
+	case errors.Is(err, domain.ErrNoSolution), errors.Is(err, domain.ErrNoSolutionStrict):
+		return CodeNoSolution
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, domain.ErrPackSizeSetNotFound):
+		return CodeNotFound
+	case errors.Is(err, domain.ErrPackSizeSetAlreadyExists):
+		return CodeConflict
+	default:
+		return ""
+	}
+}
+
+// statusErrorCode returns the default code for responses not built from an error
+// 422 responses without one are request field validation failures
+func statusErrorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusRequestTimeout:
+		return CodeTimeout
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusInternalServerError:
+		return CodeInternal
+	case http.StatusNotImplemented:
+		return CodeNotImplemented
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	default:
+		// e.g. 413 -> REQUEST_ENTITY_TOO_LARGE
+		return strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+	}
+}
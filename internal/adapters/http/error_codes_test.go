@@ -0,0 +1,106 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: domain.NewSolverError([]int{0}, 1, "bad sizes", domain.ErrInvalidInput), want: CodeInvalidInput},
+		{err: domain.NewValidationError("amount", 0, "must be positive"), want: CodeInvalidInput},
+		{err: fmt.Errorf("wrapped: %w", domain.ErrNoSolution), want: CodeNoSolution},
+		{err: domain.ErrNoSolutionStrict, want: CodeNoSolution},
+		{err: domain.ErrMemoryBudgetExceeded, want: CodeInputTooLarge},
+		{err: fmt.Errorf("%w: %w", domain.ErrNoSolution, domain.ErrSearchTruncated), want: CodeInputTooLarge},
+		{err: context.DeadlineExceeded, want: CodeTimeout},
+		{err: context.Canceled, want: CodeCanceled},
+		{err: domain.ErrPackSizeSetNotFound, want: CodeNotFound},
+		{err: domain.ErrPackSizeSetAlreadyExists, want: CodeConflict},
+		{err: domain.ErrSolverMismatch, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := errorCode(tt.err); got != tt.want {
+			t.Errorf("errorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestPackHandler_SolvePacks_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		solverErr  error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid JSON", body: `{"sizes":`, wantStatus: http.StatusBadRequest, wantCode: CodeBadRequest},
+		{name: "validation failed", body: `{"sizes":[],"amount":251}`, wantStatus: http.StatusUnprocessableEntity, wantCode: CodeValidationFailed},
+		{name: "invalid amount", body: `{"sizes":[250,500],"amount":-1}`, wantStatus: http.StatusUnprocessableEntity, wantCode: CodeInvalidInput},
+		{name: "invalid input", solverErr: domain.NewSolverError([]int{250}, 251, "bad", domain.ErrInvalidInput), wantStatus: http.StatusUnprocessableEntity, wantCode: CodeInvalidInput},
+		{name: "no solution", solverErr: domain.ErrNoSolution, wantStatus: http.StatusUnprocessableEntity, wantCode: CodeNoSolution},
+		{name: "too large", solverErr: domain.ErrMemoryBudgetExceeded, wantStatus: http.StatusUnprocessableEntity, wantCode: CodeInputTooLarge},
+		{name: "timeout", solverErr: context.DeadlineExceeded, wantStatus: http.StatusRequestTimeout, wantCode: CodeTimeout},
+		{name: "canceled", solverErr: context.Canceled, wantStatus: http.StatusRequestTimeout, wantCode: CodeCanceled},
+		{name: "internal", solverErr: domain.ErrSolverMismatch, wantStatus: http.StatusInternalServerError, wantCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.body
+			if body == "" {
+				body = `{"sizes":[250,500],"amount":251}`
+			}
+			handler := NewPackHandler(&mockSolver{err: tt.solverErr}, &mockLogger{})
+
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			var resp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", resp.Code, tt.wantCode)
+			}
+			if resp.Error == "" {
+				t.Error("error field must be kept for backward compatibility")
+			}
+		})
+	}
+}
+
+func TestStatusErrorCode(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{status: http.StatusUnauthorized, want: CodeUnauthorized},
+		{status: http.StatusNotFound, want: CodeNotFound},
+		{status: http.StatusTooManyRequests, want: CodeRateLimited},
+		{status: http.StatusServiceUnavailable, want: CodeUnavailable},
+		{status: http.StatusRequestEntityTooLarge, want: "REQUEST_ENTITY_TOO_LARGE"},
+	}
+
+	for _, tt := range tests {
+		if got := statusErrorCode(tt.status); got != tt.want {
+			t.Errorf("statusErrorCode(%d) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
@@ -4,28 +4,224 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"maps"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
 )
 
 // SolveRequest represents a request to solve the packing problem
 type SolveRequest struct {
 	Sizes  []int `json:"sizes"`
 	Amount int   `json:"amount"`
+
+	// PackSetName solves with the sizes of the stored pack set of this name
+	// instead of inline "sizes"; the two are mutually exclusive
+	PackSetName string `json:"pack_set_name,omitempty"`
+
+	// PreferExact returns an exact solution when one exists and falls back to
+	// the minimal-overage solution otherwise; the response is annotated with "exact"
+	PreferExact bool `json:"prefer_exact,omitempty"`
+
+	// Strict accepts only a packing that hits the amount exactly;
+	// without one the request fails with 422 instead of returning overage
+	Strict bool `json:"strict,omitempty"`
+
+	// MaxOverage caps the acceptable overage; without a solution within the cap
+	// the request fails with 422
+	MaxOverage *int `json:"max_overage,omitempty"`
+
+	// LotSize additionally solves for the amount rounded up to the next multiple
+	// of the lot size; the result is returned in "lot" next to the raw solution
+	LotSize *int `json:"lot_size,omitempty"`
+
+	// Priority orders the optimization criteria: "overage_packs" (default) or
+	// "packs_overage" (fewest packs first, accepting more overage)
+	Priority string `json:"priority,omitempty"`
+
+	// Costs maps each size to the cost of one pack (e.g. {"250": 1.2, "5000": 20});
+	// the cheapest packing is returned instead, ties going to less overage
+	Costs map[int]float64 `json:"costs,omitempty"`
+
+	// Algorithm picks a registered solver, e.g. "greedy" to trade optimality
+	// for speed; empty or "dp" uses the default exact solver
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// DryRun solves without recording the calculation, even with a repository
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// AmountMin and AmountMax request the packing with the fewest packs whose
+	// total lies within [amount_min, amount_max]; used instead of "amount"
+	// Overage is measured against amount_min
+	AmountMin int `json:"amount_min,omitempty"`
+	AmountMax int `json:"amount_max,omitempty"`
+}
+
+// isRange reports whether the request specifies an amount range
+func (req *SolveRequest) isRange() bool {
+	return req.AmountMin != 0 || req.AmountMax != 0
 }
 
 // SolveResponse represents a response with the packing solution
 type SolveResponse struct {
+	Solution      map[int]int  `json:"solution"` // size → count
+	Overage       int          `json:"overage"`
+	Packs         int          `json:"packs"`
+	Amount        int          `json:"amount"`                 // Requested amount (amount_min for ranges)
+	TotalItems    int          `json:"total_items"`            // Items shipped: Amount + Overage
+	DistinctSizes int          `json:"distinct_sizes"`         // Number of different sizes in Solution
+	TotalVolume   *float64     `json:"total_volume,omitempty"` // Sum of count × pack volume; set only for dimensioned stored sets
+	Exact         *bool        `json:"exact,omitempty"`        // Set only when prefer_exact is requested
+	Lot           *LotSolution `json:"lot,omitempty"`          // Set only when lot_size is requested
+	Warnings      []string     `json:"warnings,omitempty"`     // Non-fatal observations, e.g. WarningHighOverage
+
+	CalculationID *int64            `json:"calculation_id,omitempty"` // Set only when saved synchronously (see WithPersistSync)
+	Diagnostics   *SolveDiagnostics `json:"diagnostics,omitempty"`    // Set only with ?diagnostics=true
+	PackSet       *PackSetRef       `json:"pack_set,omitempty"`       // Set only when solving by stored pack set
+
+	Alternatives []AlternativeSolution `json:"alternatives,omitempty"` // Next-best solutions, set only with ?k=N
+}
+
+// PackSetRef identifies the stored pack set a solve used
+type PackSetRef struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// AlternativeSolution represents a next-best solution for the requested amount
+type AlternativeSolution struct {
 	Solution map[int]int `json:"solution"` // size → count
 	Overage  int         `json:"overage"`
 	Packs    int         `json:"packs"`
 }
 
+// SolveDiagnostics explains a solution for debugging
+type SolveDiagnostics struct {
+	UnusedSizes []int `json:"unused_sizes"` // Sizes used by no optimal packing for this amount
+}
+
+// LotSolution represents the solution for the amount rounded up to a lot multiple
+type LotSolution struct {
+	LotSize  int         `json:"lot_size"`
+	Amount   int         `json:"amount"`   // Amount rounded up to a multiple of LotSize
+	Solution map[int]int `json:"solution"` // size → count
+	Overage  int         `json:"overage"`  // Relative to the rounded amount
+	Packs    int         `json:"packs"`
+}
+
+// NestedSolveResponse represents the solution shaped as explicit nodes
+// (solution { lines { size count units } packs overage }) for GraphQL gateways
+type NestedSolveResponse struct {
+	Solution      NestedSolution    `json:"solution"`
+	CalculationID *int64            `json:"calculation_id,omitempty"` // Set only when saved synchronously (see WithPersistSync)
+	Diagnostics   *SolveDiagnostics `json:"diagnostics,omitempty"`    // Set only with ?diagnostics=true
+	PackSet       *PackSetRef       `json:"pack_set,omitempty"`       // Set only when solving by stored pack set
+}
+
+// NestedSolution holds the solution lines and totals
+type NestedSolution struct {
+	Lines    []SolutionLine     `json:"lines"`
+	Packs    int                `json:"packs"`
+	Overage  int                `json:"overage"`
+	Exact    *bool              `json:"exact,omitempty"`    // Set only when prefer_exact is requested
+	Lot      *NestedLotSolution `json:"lot,omitempty"`      // Set only when lot_size is requested
+	Warnings []string           `json:"warnings,omitempty"` // Non-fatal observations, e.g. WarningHighOverage
+}
+
+// NestedLotSolution holds the lot-rounded solution in the nested shape
+type NestedLotSolution struct {
+	LotSize int            `json:"lot_size"`
+	Amount  int            `json:"amount"` // Amount rounded up to a multiple of LotSize
+	Lines   []SolutionLine `json:"lines"`
+	Packs   int            `json:"packs"`
+	Overage int            `json:"overage"` // Relative to the rounded amount
+}
+
+// SolutionLine represents a single pack size in the solution
+type SolutionLine struct {
+	Size  int `json:"size"`  // Pack size
+	Count int `json:"count"` // Number of packs of this size
+	Units int `json:"units"` // Items covered by these packs (size * count)
+}
+
+// Response shapes selected via the "shape" query parameter
+const (
+	shapeFlat   = "flat"   // Default: solution as size -> count map
+	shapeNested = "nested" // NestedSolveResponse
+)
+
+// lineOrders maps "sort" query parameter values to breakdown line orderings
+// Ties are broken by size descending so the output is always deterministic
+var lineOrders = map[string]func(a, b SolutionLine) bool{
+	"size:desc": func(a, b SolutionLine) bool { return a.Size > b.Size },
+	"size:asc":  func(a, b SolutionLine) bool { return a.Size < b.Size },
+	"count:desc": func(a, b SolutionLine) bool {
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Size > b.Size
+	},
+	"count:asc": func(a, b SolutionLine) bool {
+		if a.Count != b.Count {
+			return a.Count < b.Count
+		}
+		return a.Size > b.Size
+	},
+}
+
+// defaultLineOrder - breakdown ordering when "sort" is omitted
+const defaultLineOrder = "size:desc"
+
+// PrepareResponse represents canonical solver input returned by /packs/prepare
+type PrepareResponse struct {
+	Sizes    []int                  `json:"sizes"`
+	Amount   int                    `json:"amount"`
+	Warnings []usecase.InputWarning `json:"warnings"`
+}
+
+// SolveDurationHeader is the response header carrying the solver call duration in milliseconds
+const SolveDurationHeader = "X-Solve-Duration-Ms"
+
+// CacheBypassHeader asks the solver cache to recompute instead of serving a cached result
+// "true" skips the cache read; "refresh" also overwrites the cached entry
+const CacheBypassHeader = "X-Cache-Bypass"
+
+// cacheBypassModes maps CacheBypassHeader values to cache bypass modes
+var cacheBypassModes = map[string]domain.CacheBypass{
+	"true":    domain.CacheBypassRead,
+	"refresh": domain.CacheBypassRefresh,
+}
+
+// WarningHighOverage is reported when the overage exceeds the configured share of the amount
+const WarningHighOverage = "high_overage"
+
+// calculationSaveTimeout bounds a single calculation save
+const calculationSaveTimeout = 5 * time.Second
+
+// Defaults for the background calculation save pool (see WithSaveQueue)
+const (
+	DefaultSaveWorkers   = 4    // Calculations saved concurrently
+	DefaultSaveQueueSize = 1000 // Calculations waiting to be saved; more are dropped
+)
+
+// DefaultMaxBodyBytes - default maximum size of a JSON request body (1 MiB)
+const DefaultMaxBodyBytes = 1 << 20
+
+// DefaultHighOverageRatio - by default overage must exceed the amount itself to be flagged
+// (i.e. more than twice the required items are shipped), which only happens for small amounts
+const DefaultHighOverageRatio = 1.0
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error   string                 `json:"error"`
+	Code    string                 `json:"code"` // Machine-readable, see the Code* constants
 	Message string                 `json:"message,omitempty"`
 	Details map[string]interface{} `json:"details,omitempty"`
 }
@@ -37,18 +233,134 @@ type Repository interface {
 
 // PackHandler handles HTTP requests for solving the packing problem
 type PackHandler struct {
-	solver     domain.Solver
-	logger     Logger
-	repository Repository // Optional repository for audit
+	solver       domain.Solver
+	strictSolver domain.StrictSolver     // Solves exact-only requests; nil if unsupported
+	rangeSolver  domain.RangeSolver      // Solves amount ranges; nil if unsupported
+	diagSolver   domain.DiagnosticSolver // Explains solutions for ?diagnostics=true; nil if unsupported
+	seriesSolver domain.SeriesSolver     // Solves series of amounts; nil if unsupported
+	topKSolver   domain.TopKSolver       // Lists alternative solutions for ?k=N; nil if unsupported
+	logger       Logger
+	repository   Repository                // Optional repository for audit
+	packSets     domain.PackSizeRepository // Optional, resolves pack_set_name
+	registry     *SolveRegistry            // Optional, makes solves cancellable by correlation ID
+
+	saveWorkers      int              // Workers saving calculations in the background
+	saveQueue        chan interface{} // Calculation records waiting for a worker
+	startSaveWorkers sync.Once        // Starts the workers on the first background save
+	pendingSaves     sync.WaitGroup   // Queued and running background saves, awaited by Drain
+
+	optionLimits         OptionLimits  // Bounds applied to solve options
+	maxBodyBytes         int64         // Largest accepted JSON request body
+	batchConcurrency     int           // Batch items solved concurrently
+	maxTopK              int           // Largest accepted ?k=N
+	highOverageRatio     float64       // Overage/amount above which WarningHighOverage is reported (0 = never)
+	solveTimeout         time.Duration // Solver budget per request (0 = bounded only by the request context)
+	persistSync          bool          // Whether calculations are saved before responding
+	solveDurationHeader  bool          // Whether to set SolveDurationHeader on responses
+	cacheBypassAllowed   bool          // Whether CacheBypassHeader is honored
+	allowZeroAmount      bool          // Whether amount 0 returns domain.EmptySolution instead of 422
+	requireExactCoverage bool          // Whether amounts must be a multiple of the gcd of sizes
+	logSolveResults      bool          // Whether successful solves are logged at info level
+
+	algorithms map[string]domain.Solver // Solvers selectable with "algorithm" besides the default (usecase.AlgorithmDP)
 }
 
 // NewPackHandler creates a new handler
 func NewPackHandler(solver domain.Solver, logger Logger) *PackHandler {
+	strictSolver, _ := solver.(domain.StrictSolver)
+	rangeSolver, _ := solver.(domain.RangeSolver)
+	diagSolver, _ := solver.(domain.DiagnosticSolver)
+	seriesSolver, _ := solver.(domain.SeriesSolver)
+	topKSolver, _ := solver.(domain.TopKSolver)
+
 	return &PackHandler{
-		solver:     solver,
-		logger:     logger,
-		repository: nil, // No repository by default
+		solver:       solver,
+		strictSolver: strictSolver,
+		rangeSolver:  rangeSolver,
+		diagSolver:   diagSolver,
+		seriesSolver: seriesSolver,
+		topKSolver:   topKSolver,
+		logger:       logger,
+		repository:   nil, // No repository by default
+
+		optionLimits:        DefaultOptionLimits(),
+		batchConcurrency:    usecase.DefaultBatchConcurrency,
+		maxTopK:             usecase.DefaultMaxTopK,
+		highOverageRatio:    DefaultHighOverageRatio,
+		maxBodyBytes:        DefaultMaxBodyBytes,
+		solveDurationHeader: true,
+
+		saveWorkers: DefaultSaveWorkers,
+		saveQueue:   make(chan interface{}, DefaultSaveQueueSize),
+	}
+}
+
+// WithBatchConcurrency sets how many batch items are solved concurrently
+// Non-positive values fall back to usecase.DefaultBatchConcurrency
+func (h *PackHandler) WithBatchConcurrency(concurrency int) *PackHandler {
+	if concurrency <= 0 {
+		concurrency = usecase.DefaultBatchConcurrency
+	}
+	h.batchConcurrency = concurrency
+	return h
+}
+
+// WithOptionLimits sets the bounds applied to solve options
+func (h *PackHandler) WithOptionLimits(limits OptionLimits) *PackHandler {
+	h.optionLimits = limits
+	return h
+}
+
+// WithStrictSolver sets the solver used for exact-only requests
+// Needed when the main solver is wrapped (e.g. by a cache) and doesn't support strict mode itself
+func (h *PackHandler) WithStrictSolver(strictSolver domain.StrictSolver) *PackHandler {
+	h.strictSolver = strictSolver
+	return h
+}
+
+// WithRangeSolver sets the solver used for amount ranges
+// Needed when the main solver is wrapped (e.g. by a cache) and doesn't support ranges itself
+func (h *PackHandler) WithRangeSolver(rangeSolver domain.RangeSolver) *PackHandler {
+	h.rangeSolver = rangeSolver
+	return h
+}
+
+// WithSeriesSolver sets the solver used for series of amounts
+// Needed when the main solver is wrapped (e.g. by a cache) and doesn't support series itself
+func (h *PackHandler) WithSeriesSolver(seriesSolver domain.SeriesSolver) *PackHandler {
+	h.seriesSolver = seriesSolver
+	return h
+}
+
+// WithDiagnosticSolver sets the solver used for ?diagnostics=true
+// Needed when the main solver is wrapped (e.g. by a cache) and doesn't support diagnostics itself
+func (h *PackHandler) WithDiagnosticSolver(diagSolver domain.DiagnosticSolver) *PackHandler {
+	h.diagSolver = diagSolver
+	return h
+}
+
+// WithTopKSolver sets the solver used for ?k=N
+// Needed when the main solver is wrapped (e.g. by a cache) and doesn't list alternatives itself
+func (h *PackHandler) WithTopKSolver(topKSolver domain.TopKSolver) *PackHandler {
+	h.topKSolver = topKSolver
+	return h
+}
+
+// WithMaxTopK sets the largest accepted ?k=N; larger values are rejected with 422
+// Non-positive values fall back to usecase.DefaultMaxTopK
+func (h *PackHandler) WithMaxTopK(maxTopK int) *PackHandler {
+	if maxTopK <= 0 {
+		maxTopK = usecase.DefaultMaxTopK
 	}
+	h.maxTopK = maxTopK
+	return h
+}
+
+// WithSolveRegistry registers every solve under its correlation ID so it can
+// be cancelled (see AdminHandler.CancelSolve)
+func (h *PackHandler) WithSolveRegistry(registry *SolveRegistry) *PackHandler {
+	h.registry = registry
+	return h
 }
 
 // WithRepository adds an optional repository
@@ -57,6 +369,132 @@ func (h *PackHandler) WithRepository(repo Repository) *PackHandler {
 	return h
 }
 
+// WithPackSets sets the repository pack_set_name is resolved against
+// Without it requests naming a pack set return 501
+func (h *PackHandler) WithPackSets(packSets domain.PackSizeRepository) *PackHandler {
+	h.packSets = packSets
+	return h
+}
+
+// WithSaveQueue bounds background calculation saves to workers concurrent
+// saves and queueSize waiting ones; saves beyond that are dropped and counted
+// by calculation_save_dropped_total instead of blocking the response
+// Non-positive values fall back to DefaultSaveWorkers and DefaultSaveQueueSize
+// Must be called before the first request
+func (h *PackHandler) WithSaveQueue(workers, queueSize int) *PackHandler {
+	if workers <= 0 {
+		workers = DefaultSaveWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultSaveQueueSize
+	}
+	h.saveWorkers = workers
+	h.saveQueue = make(chan interface{}, queueSize)
+	return h
+}
+
+// WithPersistSync saves calculations before responding instead of in the background
+// A failed save then fails the request with 500, and successful responses carry calculation_id
+// Disabled by default (fire-and-forget saves)
+func (h *PackHandler) WithPersistSync(enabled bool) *PackHandler {
+	h.persistSync = enabled
+	return h
+}
+
+// WithSolveTimeout bounds the solver calls of a single solve request
+// independently of the server timeouts; exceeding it returns 408
+// A non-positive timeout disables the bound
+func (h *PackHandler) WithSolveTimeout(timeout time.Duration) *PackHandler {
+	h.solveTimeout = timeout
+	return h
+}
+
+// WithHighOverageRatio sets the overage/amount ratio above which WarningHighOverage is reported
+// A non-positive ratio disables the warning
+func (h *PackHandler) WithHighOverageRatio(ratio float64) *PackHandler {
+	h.highOverageRatio = ratio
+	return h
+}
+
+// WithSolveDurationHeader enables or disables the solve duration response header
+func (h *PackHandler) WithSolveDurationHeader(enabled bool) *PackHandler {
+	h.solveDurationHeader = enabled
+	return h
+}
+
+// WithCacheBypass allows clients to bypass the solver cache via CacheBypassHeader
+// Disabled by default; the header is ignored unless enabled
+func (h *PackHandler) WithCacheBypass(allowed bool) *PackHandler {
+	h.cacheBypassAllowed = allowed
+	return h
+}
+
+// WithAllowZeroAmount makes amount 0 ("nothing needed") return an empty solution
+// on /packs/solve instead of a validation error
+// Disabled by default; amount ranges still require amount_min > 0
+func (h *PackHandler) WithAllowZeroAmount(allowed bool) *PackHandler {
+	h.allowZeroAmount = allowed
+	return h
+}
+
+// WithRequireExactCoverage rejects amounts that are not a multiple of the gcd
+// of sizes with 422, since no packing can hit them exactly
+// Disabled by default; amount ranges are not checked
+func (h *PackHandler) WithRequireExactCoverage(required bool) *PackHandler {
+	h.requireExactCoverage = required
+	return h
+}
+
+// WithAlgorithm registers solver under name for requests with "algorithm": name
+// usecase.AlgorithmDP always selects the handler's own solver and cannot be replaced
+func (h *PackHandler) WithAlgorithm(name string, solver domain.Solver) *PackHandler {
+	if name == usecase.AlgorithmDP {
+		return h
+	}
+	if h.algorithms == nil {
+		h.algorithms = make(map[string]domain.Solver)
+	}
+	h.algorithms[name] = solver
+	return h
+}
+
+// algorithmSolver returns the solver registered for name ("" selects the default)
+func (h *PackHandler) algorithmSolver(name string) (domain.Solver, bool) {
+	if name == "" || name == usecase.AlgorithmDP {
+		return h.solver, true
+	}
+	solver, ok := h.algorithms[name]
+	return solver, ok
+}
+
+// algorithmNames returns the selectable algorithm names, sorted
+func (h *PackHandler) algorithmNames() []string {
+	names := []string{usecase.AlgorithmDP}
+	for name := range h.algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithLogSolveResults logs every successful POST /packs/solve at info level
+// with its sizes, amount, packs, overage and solver duration
+// Disabled by default, as it logs a line per request
+func (h *PackHandler) WithLogSolveResults(enabled bool) *PackHandler {
+	h.logSolveResults = enabled
+	return h
+}
+
+// WithMaxBodyBytes limits JSON request bodies to maxBytes; larger bodies fail with 413
+// Non-positive values fall back to DefaultMaxBodyBytes
+func (h *PackHandler) WithMaxBodyBytes(maxBytes int64) *PackHandler {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+	h.maxBodyBytes = maxBytes
+	return h
+}
+
 // SolvePacks handles POST /packs/solve
 func (h *PackHandler) SolvePacks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -67,22 +505,125 @@ func (h *PackHandler) SolvePacks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check Content-Type
-	contentType := r.Header.Get("Content-Type")
-	if contentType != "application/json" && contentType != "" {
-		h.respondError(w, r, http.StatusUnsupportedMediaType, "content type must be application/json", nil)
+	// Check requested response shape
+	shape := r.URL.Query().Get("shape")
+	if shape != "" && shape != shapeFlat && shape != shapeNested {
+		h.respondError(w, r, http.StatusBadRequest, "unsupported response shape", map[string]interface{}{
+			"shape":     shape,
+			"supported": []string{shapeFlat, shapeNested},
+		})
+		return
+	}
+
+	// Check requested breakdown format (flat shape only; nested lines are always a list)
+	format := r.URL.Query().Get("format")
+	if !validBreakdownFormat(format) {
+		h.respondError(w, r, http.StatusBadRequest, "unsupported breakdown format", map[string]interface{}{
+			"format":    format,
+			"supported": []string{formatList, formatMap},
+		})
+		return
+	}
+
+	// Check requested breakdown ordering
+	order := r.URL.Query().Get("sort")
+	if order == "" {
+		order = defaultLineOrder
+	}
+	if _, ok := lineOrders[order]; !ok {
+		h.respondError(w, r, http.StatusBadRequest, "unsupported sort key", map[string]interface{}{
+			"sort":      order,
+			"supported": []string{"size:desc", "size:asc", "count:desc", "count:asc"},
+		})
+		return
+	}
+	if order != defaultLineOrder && shape != shapeNested && format == formatMap {
+		h.respondError(w, r, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+			"field":   "sort",
+			"value":   order,
+			"message": "cannot be combined with format=map, whose solution has no order",
+		})
 		return
 	}
 
+	// Check optional diagnostics
+	diagnostics := false
+	if raw := r.URL.Query().Get("diagnostics"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			h.respondError(w, r, http.StatusBadRequest, "invalid diagnostics flag", map[string]interface{}{
+				"diagnostics": raw,
+				"supported":   []string{"true", "false"},
+			})
+			return
+		}
+		diagnostics = value
+	}
+
+	// Check optional number of solutions (the solution plus k-1 alternatives)
+	topK := 0
+	if raw := r.URL.Query().Get("k"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			h.respondError(w, r, http.StatusBadRequest, "k must be an integer", map[string]interface{}{
+				"k": raw,
+			})
+			return
+		}
+		if value < 1 || value > h.maxTopK {
+			h.respondError(w, r, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+				"field":   "k",
+				"value":   value,
+				"message": fmt.Sprintf("must be between 1 and %d", h.maxTopK),
+			})
+			return
+		}
+		topK = value
+	}
+
+	// Check optional cache bypass
+	if value := r.Header.Get(CacheBypassHeader); value != "" {
+		if !h.cacheBypassAllowed {
+			h.logger.Warn(ctx, "cache bypass requested but not allowed, ignoring", map[string]interface{}{
+				"value": value,
+			})
+		} else {
+			mode, ok := cacheBypassModes[value]
+			if !ok {
+				h.respondError(w, r, http.StatusBadRequest, "unsupported cache bypass value", map[string]interface{}{
+					"header":    CacheBypassHeader,
+					"value":     value,
+					"supported": []string{"true", "refresh"},
+				})
+				return
+			}
+			ctx = domain.WithCacheBypass(ctx, mode)
+		}
+	}
+
 	// Decode request
 	var req SolveRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondError(w, r, http.StatusBadRequest, "invalid JSON", map[string]interface{}{
-			"parse_error": err.Error(),
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	solver, ok := h.algorithmSolver(req.Algorithm)
+	if !ok {
+		h.respondError(w, r, http.StatusBadRequest, "unknown algorithm", map[string]interface{}{
+			"algorithm": req.Algorithm,
+			"supported": h.algorithmNames(),
 		})
 		return
 	}
 
+	// Replace a pack set reference with the stored sizes
+	var packSet *domain.PackSizeSet
+	if req.PackSetName != "" {
+		if packSet, ok = h.resolvePackSetName(w, r, &req); !ok {
+			return
+		}
+	}
+
 	// Validate request
 	if err := h.validateRequest(&req); err != nil {
 		var validationErr *domain.ValidationError
@@ -97,7 +638,7 @@ func (h *PackHandler) SolvePacks(w http.ResponseWriter, r *http.Request) {
 
 		// General validation error
 		if errors.Is(err, domain.ErrInvalidInput) {
-			h.respondError(w, r, http.StatusUnprocessableEntity, err.Error(), nil)
+			h.respondErrorCode(w, r, http.StatusUnprocessableEntity, CodeInvalidInput, err.Error(), nil)
 			return
 		}
 
@@ -109,113 +650,742 @@ func (h *PackHandler) SolvePacks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Call solver
-	solution, err := h.solver.Solve(ctx, req.Sizes, req.Amount)
+	// Validate options, reporting every offending option at once
+	opts, err := ParseAndValidateOptions(&req, h.optionLimits)
+	if err != nil {
+		h.respondError(w, r, http.StatusUnprocessableEntity, "invalid options", map[string]interface{}{
+			"errors": validationErrorDetails(err),
+		})
+		return
+	}
+
+	if req.isRange() && h.rangeSolver == nil {
+		h.respondError(w, r, http.StatusNotImplemented, "amount ranges are not supported", nil)
+		return
+	}
+	if opts.Strict && h.strictSolver == nil {
+		h.respondError(w, r, http.StatusNotImplemented, "strict mode is not supported", nil)
+		return
+	}
+	if diagnostics {
+		if req.isRange() || opts.Strict || len(opts.Costs) > 0 || opts.Algorithm != "" {
+			h.respondError(w, r, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+				"field":   "diagnostics",
+				"value":   true,
+				"message": "cannot be combined with an amount range, strict mode, costs or an algorithm",
+			})
+			return
+		}
+		if h.diagSolver == nil {
+			h.respondError(w, r, http.StatusNotImplemented, "diagnostics are not supported", nil)
+			return
+		}
+	}
+	// Alternatives are ranked by the default criteria only
+	if topK > 0 {
+		if req.isRange() || opts.Strict || opts.MaxOverage != nil || opts.Priority != domain.PriorityOveragePacks ||
+			len(opts.Costs) > 0 || opts.Algorithm != "" {
+			h.respondError(w, r, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+				"field":   "k",
+				"value":   topK,
+				"message": "cannot be combined with an amount range, strict mode, max_overage, priority, costs or an algorithm",
+			})
+			return
+		}
+		if shape == shapeNested {
+			h.respondError(w, r, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+				"field":   "k",
+				"value":   topK,
+				"message": "cannot be combined with shape=nested",
+			})
+			return
+		}
+		if h.topKSolver == nil {
+			h.respondError(w, r, http.StatusNotImplemented, "alternative solutions are not supported", nil)
+			return
+		}
+	}
+
+	// Solve-affecting options travel on the context (also keying the cache)
+	solveOptions := domain.SolveOptions{}
+	if opts.MaxOverage != nil {
+		solveOptions[domain.SolveOptionMaxOverage] = strconv.Itoa(*opts.MaxOverage)
+	}
+	if opts.Priority != domain.PriorityOveragePacks {
+		solveOptions[domain.SolveOptionPriority] = opts.Priority.String()
+	}
+	if len(opts.Costs) > 0 {
+		solveOptions[domain.SolveOptionCosts] = domain.FormatPackCosts(opts.Costs)
+	}
+	if len(solveOptions) > 0 {
+		ctx = domain.WithSolveOptions(ctx, solveOptions)
+	}
+
+	// Bound the solver calls (including the lot solve) by the solve budget
+	solveCtx, cancel := h.solveContext(ctx)
+	defer cancel()
+
+	// Call solver, measuring only the solver itself (not encoding)
+	solveStart := time.Now()
+	var solution *domain.Solution
+	if req.isRange() {
+		solution, err = h.rangeSolver.SolveRange(solveCtx, req.Sizes, req.AmountMin, req.AmountMax)
+	} else {
+		solution, err = h.solveAmount(solveCtx, solver, req.Sizes, req.Amount, opts.Strict)
+	}
+	durationMs := float64(time.Since(solveStart).Microseconds()) / 1000
+	if h.solveDurationHeader {
+		w.Header().Set(SolveDurationHeader, strconv.FormatFloat(durationMs, 'f', 3, 64))
+	}
 	if err != nil {
 		h.handleSolverError(w, r, err)
 		return
 	}
+	solution.Normalize()
 
-	// Optional save to DB for audit
-	if h.repository != nil {
-		// Create record for saving
-		record := map[string]interface{}{
-			"pack_sizes": req.Sizes,
-			"amount":     req.Amount,
-			"solution":   solution,
+	if h.logSolveResults {
+		fields := map[string]interface{}{
+			"correlation_id": GetCorrelationID(ctx),
+			"sizes":          req.Sizes,
+			"amount":         solution.Amount,
+			"packs":          solution.Packs,
+			"overage":        solution.Overage,
+			"duration_ms":    durationMs,
+		}
+		if req.isRange() {
+			fields["amount_max"] = req.AmountMax
 		}
+		h.logger.Info(ctx, "solve completed", fields)
+	}
 
-		// Async save (don't block response)
-		go func() {
-			saveCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
+	// Optionally solve again for the amount rounded up to the lot size
+	var lotSolution *domain.Solution
+	lotAmount := 0
+	if opts.LotSize != nil {
+		lotAmount = roundUpToLot(req.Amount, *opts.LotSize)
+		lotSolution = solution
+		if lotAmount != req.Amount {
+			lotSolution, err = h.solveAmount(solveCtx, solver, req.Sizes, lotAmount, opts.Strict)
+			if err != nil {
+				h.handleSolverError(w, r, err)
+				return
+			}
+			lotSolution.Normalize()
+		}
+	}
 
-			if _, err := h.repository.SaveCalculation(saveCtx, record); err != nil {
-				h.logger.Error(saveCtx, "failed to save calculation", map[string]interface{}{
-					"error": err.Error(),
-				})
+	// Optionally explain the solution
+	var solveDiagnostics *SolveDiagnostics
+	if diagnostics {
+		unused, err := h.diagSolver.UnusedSizes(solveCtx, req.Sizes, req.Amount)
+		if err != nil {
+			h.handleSolverError(w, r, err)
+			return
+		}
+		solveDiagnostics = &SolveDiagnostics{UnusedSizes: unused}
+	}
+
+	// Optionally list the next-best solutions
+	var alternatives []AlternativeSolution
+	if topK > 1 {
+		solutions, err := h.topKSolver.SolveTopK(solveCtx, req.Sizes, req.Amount, topK)
+		if err != nil {
+			h.handleSolverError(w, r, err)
+			return
+		}
+		alternatives = newAlternativeSolutions(solution, solutions, topK-1)
+	}
+
+	// Optional save to DB for audit (an empty solution has nothing to record)
+	var calculationID *int64
+	if h.repository != nil && req.DryRun {
+		h.logger.Debug(ctx, "dry run, calculation not saved", map[string]interface{}{
+			"correlation_id": GetCorrelationID(ctx),
+		})
+	} else if h.repository != nil && solution.Amount > 0 {
+		record := newCalculationRecord(ctx, req.Sizes, packSetID(packSet), solution, calculationOptions(solveOptions, &req, opts))
+
+		if h.persistSync {
+			id, err := h.saveCalculation(ctx, record)
+			if err != nil {
+				h.respondError(w, r, http.StatusInternalServerError, "failed to save calculation", nil)
+				return
 			}
-		}()
+			calculationID = &id
+		} else {
+			// Async save (don't block response)
+			h.saveCalculationAsync(record)
+		}
+	}
+
+	// The solver minimizes overage first, so the returned solution is exact
+	// whenever an exact solution exists and prefer_exact only adds the annotation
+	// ParseAndValidateOptions rejects prefer_exact with the options that reorder
+	// the criteria
+	var exact *bool
+	if opts.PreferExact {
+		isExact := domain.IsSolutionStrict(solution)
+		exact = &isExact
+	}
+
+	// Flag suspicious solutions without failing the request
+	var warnings []string
+	if h.isHighOverage(solution) {
+		warnings = append(warnings, WarningHighOverage)
+	}
+
+	// Build response in the requested shape
+	if shape == shapeNested {
+		nested := newNestedSolveResponse(solution, order)
+		nested.Solution.Exact = exact
+		nested.Solution.Warnings = warnings
+		nested.CalculationID = calculationID
+		nested.Diagnostics = solveDiagnostics
+		nested.PackSet = newPackSetRef(packSet)
+		if lotSolution != nil {
+			lotNested := newNestedSolveResponse(lotSolution, order)
+			nested.Solution.Lot = &NestedLotSolution{
+				LotSize: *opts.LotSize,
+				Amount:  lotAmount,
+				Lines:   lotNested.Solution.Lines,
+				Packs:   lotSolution.Packs,
+				Overage: lotSolution.Overage,
+			}
+		}
+		h.respondJSON(w, r, http.StatusOK, nested)
+		return
 	}
 
-	// Build response
 	response := SolveResponse{
-		Solution: solution.Breakdown,
-		Overage:  solution.Overage,
-		Packs:    solution.Packs,
+		Solution:      solution.Breakdown,
+		Overage:       solution.Overage,
+		Packs:         solution.Packs,
+		Amount:        solution.Amount,
+		TotalItems:    solution.TotalItems(),
+		DistinctSizes: solution.DistinctSizes(),
+		Exact:         exact,
+		Warnings:      warnings,
+
+		CalculationID: calculationID,
+		Diagnostics:   solveDiagnostics,
+		PackSet:       newPackSetRef(packSet),
+		Alternatives:  alternatives,
+	}
+	if lotSolution != nil {
+		response.Lot = &LotSolution{
+			LotSize:  *opts.LotSize,
+			Amount:   lotAmount,
+			Solution: lotSolution.Breakdown,
+			Overage:  lotSolution.Overage,
+			Packs:    lotSolution.Packs,
+		}
 	}
 
-	h.respondJSON(w, r, http.StatusOK, response)
+	if format == formatMap {
+		h.respondJSON(w, r, http.StatusOK, response)
+		return
+	}
+	h.respondJSON(w, r, http.StatusOK, newListSolveResponse(response, order))
 }
 
-// validateRequest validates the request
-func (h *PackHandler) validateRequest(req *SolveRequest) error {
-	// Validate sizes
-	if len(req.Sizes) == 0 {
-		return domain.NewValidationError("sizes", req.Sizes, "must not be empty")
+// newAlternativeSolutions converts up to limit of solutions, skipping the one
+// equal to the returned solution, to their response representation
+func newAlternativeSolutions(solution *domain.Solution, solutions []*domain.Solution, limit int) []AlternativeSolution {
+	alternatives := []AlternativeSolution{}
+	for _, alternative := range solutions {
+		if len(alternatives) == limit {
+			break
+		}
+		alternative.Normalize()
+		if maps.Equal(alternative.Breakdown, solution.Breakdown) {
+			continue
+		}
+		alternatives = append(alternatives, AlternativeSolution{
+			Solution: alternative.Breakdown,
+			Overage:  alternative.Overage,
+			Packs:    alternative.Packs,
+		})
 	}
+	return alternatives
+}
 
-	// Validate through domain
-	if err := domain.ValidateSolverInput(req.Sizes, req.Amount); err != nil {
-		return err
+// resolvePackSetName loads the sizes of the pack set named by req.PackSetName into req.Sizes
+// and returns the set, so the calculation can be linked to it
+// Responds and returns false if the sizes are also given inline or the set cannot be loaded
+func (h *PackHandler) resolvePackSetName(w http.ResponseWriter, r *http.Request, req *SolveRequest) (*domain.PackSizeSet, bool) {
+	if len(req.Sizes) > 0 {
+		h.respondError(w, r, http.StatusBadRequest, "sizes and pack_set_name are mutually exclusive", map[string]interface{}{
+			"pack_set_name": req.PackSetName,
+		})
+		return nil, false
+	}
+	if h.packSets == nil {
+		h.respondError(w, r, http.StatusNotImplemented, "pack sets are not supported", nil)
+		return nil, false
 	}
 
-	return nil
+	packSet, err := h.packSets.GetByName(r.Context(), req.PackSetName)
+	if errors.Is(err, domain.ErrPackSizeSetNotFound) {
+		h.respondError(w, r, http.StatusNotFound, "pack set not found", map[string]interface{}{
+			"pack_set_name": req.PackSetName,
+		})
+		return nil, false
+	}
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to load pack set", map[string]interface{}{
+			"pack_set_name": req.PackSetName,
+			"error":         err.Error(),
+		})
+		h.respondError(w, r, http.StatusInternalServerError, "internal server error", nil)
+		return nil, false
+	}
+
+	req.Sizes = packSet.Sizes
+	return packSet, true
 }
 
-// handleSolverError handles solver errors
-func (h *PackHandler) handleSolverError(w http.ResponseWriter, r *http.Request, err error) {
-	ctx := r.Context()
+// packSetID returns the ID of packSet, or nil for requests with inline sizes
+func packSetID(packSet *domain.PackSizeSet) *int64 {
+	if packSet == nil {
+		return nil
+	}
+	return packSet.ID
+}
+
+// newPackSetRef returns the reference echoed for packSet, or nil for requests with inline sizes
+func newPackSetRef(packSet *domain.PackSizeSet) *PackSetRef {
+	if packSet == nil || packSet.ID == nil {
+		return nil
+	}
+	ref := &PackSetRef{ID: *packSet.ID}
+	if packSet.Name != nil {
+		ref.Name = *packSet.Name
+	}
+	return ref
+}
+
+// isHighOverage reports whether the solution's overage exceeds the configured share of the amount
+func (h *PackHandler) isHighOverage(solution *domain.Solution) bool {
+	if h.highOverageRatio <= 0 || solution.Amount <= 0 {
+		return false
+	}
+	return float64(solution.Overage)/float64(solution.Amount) > h.highOverageRatio
+}
+
+// solveContext returns the context bounding a request's solver calls by the solve timeout
+// With a solve registry it is also cancellable by correlation ID until the
+// returned cancel function is called
+func (h *PackHandler) solveContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	solveCtx, cancel := ctx, context.CancelFunc(func() {})
+	if h.solveTimeout > 0 {
+		solveCtx, cancel = context.WithTimeout(ctx, h.solveTimeout)
+	}
+
+	correlationID := GetCorrelationID(ctx)
+	if h.registry == nil || correlationID == "" {
+		return solveCtx, cancel
+	}
+
+	solveCtx, registryCancel := context.WithCancel(solveCtx)
+	deregister := h.registry.Register(ctx, correlationID, registryCancel)
+	return solveCtx, func() {
+		deregister()
+		registryCancel()
+		cancel()
+	}
+}
+
+// solveAmount solves for a single amount with solver, honoring strict mode
+// (always with the strict solver; other algorithms are rejected with strict)
+// Amount 0 only passes validation with WithAllowZeroAmount and needs no solver
+func (h *PackHandler) solveAmount(ctx context.Context, solver domain.Solver, sizes []int, amount int, strict bool) (*domain.Solution, error) {
+	if amount == 0 {
+		return domain.EmptySolution(0), nil
+	}
+	if strict {
+		return h.strictSolver.SolveStrict(ctx, sizes, amount)
+	}
+	return solver.Solve(ctx, sizes, amount)
+}
+
+// roundUpToLot rounds amount up to the next multiple of lotSize
+func roundUpToLot(amount, lotSize int) int {
+	return (amount + lotSize - 1) / lotSize * lotSize
+}
+
+// newNestedSolveResponse converts a solution into the nested response shape
+// Lines are ordered by the given lineOrders key
+func newNestedSolveResponse(solution *domain.Solution, order string) NestedSolveResponse {
+	return NestedSolveResponse{
+		Solution: NestedSolution{
+			Lines:   breakdownLines(solution.Breakdown, order),
+			Packs:   solution.Packs,
+			Overage: solution.Overage,
+		},
+	}
+}
+
+// breakdownLines converts a breakdown into lines ordered by the given
+// lineOrders key (defaultLineOrder if unknown)
+func breakdownLines(breakdown map[int]int, order string) []SolutionLine {
+	lines := make([]SolutionLine, 0, len(breakdown))
+	for size, count := range breakdown {
+		lines = append(lines, SolutionLine{
+			Size:  size,
+			Count: count,
+			Units: size * count,
+		})
+	}
+
+	less, ok := lineOrders[order]
+	if !ok {
+		less = lineOrders[defaultLineOrder]
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return less(lines[i], lines[j])
+	})
+	return lines
+}
+
+// newCalculationRecord builds the record saved for a solved request
+// The correlation ID is read here, since background saves don't run with the request context
+// packSetID links the calculation to the stored set it was solved with (nil for inline sizes);
+// options may be nil for requests solved with the defaults
+func newCalculationRecord(ctx context.Context, sizes []int, packSetID *int64, solution *domain.Solution, options domain.SolveOptions) map[string]interface{} {
+	return map[string]interface{}{
+		"pack_set_id":    packSetID,
+		"pack_sizes":     sizes,
+		"amount":         solution.Amount,
+		"solution":       solution,
+		"correlation_id": GetCorrelationID(ctx),
+		"options":        options,
+	}
+}
+
+// Options recorded with a calculation besides the solve options: they are
+// applied around the solver, but are needed to reproduce the solution
+const (
+	calculationOptionStrict    = "strict"
+	calculationOptionAmountMin = "amount_min"
+	calculationOptionAmountMax = "amount_max"
+	calculationOptionAlgorithm = "algorithm"
+)
 
-	// Validation errors
-	if errors.Is(err, domain.ErrInvalidInput) {
-		h.respondError(w, r, http.StatusUnprocessableEntity, err.Error(), nil)
+// calculationOptions returns the options recorded with a calculation:
+// solveOptions plus strict mode, the amount range and the algorithm, when requested
+func calculationOptions(solveOptions domain.SolveOptions, req *SolveRequest, opts *SolveOptions) domain.SolveOptions {
+	options := make(domain.SolveOptions, len(solveOptions)+2)
+	for name, value := range solveOptions {
+		options[name] = value
+	}
+	if opts.Strict {
+		options[calculationOptionStrict] = "true"
+	}
+	if req.isRange() {
+		options[calculationOptionAmountMin] = strconv.Itoa(req.AmountMin)
+		options[calculationOptionAmountMax] = strconv.Itoa(req.AmountMax)
+	}
+	if opts.Algorithm != "" {
+		options[calculationOptionAlgorithm] = opts.Algorithm
+	}
+	return options
+}
+
+// RecordCalculation saves a calculation solved outside PackHandler (see
+// PackSetHandler.WithCalculationRecorder) through the background save queue
+// Does nothing without a repository or for an empty solution
+func (h *PackHandler) RecordCalculation(ctx context.Context, sizes []int, packSetID *int64, solution *domain.Solution) {
+	if h.repository == nil || solution.Amount <= 0 {
 		return
 	}
+	h.saveCalculationAsync(newCalculationRecord(ctx, sizes, packSetID, solution, nil))
+}
+
+// saveCalculation saves a calculation record before responding and returns its ID
+// Bounded by the same timeout as background saves, but cancelled with the request
+func (h *PackHandler) saveCalculation(ctx context.Context, record interface{}) (int64, error) {
+	saveCtx, cancel := context.WithTimeout(ctx, calculationSaveTimeout)
+	defer cancel()
+
+	id, err := h.repository.SaveCalculation(saveCtx, record)
+	if err != nil {
+		h.logger.Error(ctx, "failed to save calculation", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return 0, err
+	}
+	return id, nil
+}
+
+// saveCalculationAsync queues a calculation record for a background save worker
+// When the queue is full the record is dropped (calculation_save_dropped_total)
+// rather than blocking the response
+// Pending saves are reported by the calculation_save_queue_depth gauge and
+// awaited by Drain
+func (h *PackHandler) saveCalculationAsync(record interface{}) {
+	h.startSaveWorkers.Do(func() {
+		for range h.saveWorkers {
+			go h.runSaveWorker()
+		}
+	})
+
+	h.pendingSaves.Add(1)
+	select {
+	case h.saveQueue <- record:
+		calculationSaveEnqueuedTotal.Inc()
+		calculationSaveQueueDepth.Inc()
+	default:
+		h.pendingSaves.Done()
+		calculationSaveDroppedTotal.Inc()
+		h.logger.Warn(context.Background(), "calculation save queue full, dropping calculation", map[string]interface{}{
+			"queue_size": cap(h.saveQueue),
+		})
+	}
+}
+
+// runSaveWorker saves queued calculation records until the process exits
+func (h *PackHandler) runSaveWorker() {
+	for record := range h.saveQueue {
+		h.saveQueuedCalculation(record)
+	}
+}
+
+// saveQueuedCalculation saves one record taken from the queue
+func (h *PackHandler) saveQueuedCalculation(record interface{}) {
+	defer h.pendingSaves.Done()
+	defer calculationSaveQueueDepth.Dec()
+
+	saveCtx, cancel := context.WithTimeout(context.Background(), calculationSaveTimeout)
+	defer cancel()
+
+	if _, err := h.repository.SaveCalculation(saveCtx, record); err != nil {
+		h.logger.Error(saveCtx, "failed to save calculation", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
 
-	// No solution errors
-	if errors.Is(err, domain.ErrNoSolution) || errors.Is(err, domain.ErrNoSolutionStrict) {
-		h.respondError(w, r, http.StatusUnprocessableEntity, err.Error(), nil)
+// Drain waits for pending background calculation saves
+// Call it on shutdown after the server stops accepting requests, before the
+// repository is closed; returns ctx.Err() if saves are still pending at the deadline
+func (h *PackHandler) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.pendingSaves.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("calculation saves still pending: %w", ctx.Err())
+	}
+}
+
+// PrepareInput handles POST /packs/prepare
+// Validates and normalizes the request without solving, returning the canonical
+// input and warnings about adjustments made
+func (h *PackHandler) PrepareInput(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.respondError(w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
 		return
 	}
 
-	// Context errors
-	if errors.Is(err, context.Canceled) {
-		h.respondError(w, r, http.StatusRequestTimeout, "request canceled", nil)
+	var req SolveRequest
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
-	if errors.Is(err, context.DeadlineExceeded) {
-		h.respondError(w, r, http.StatusRequestTimeout, "request timeout", nil)
+	prepared, err := usecase.PrepareInput(req.Sizes, req.Amount)
+	if err != nil {
+		h.respondError(w, r, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+			"errors": validationErrorDetails(err),
+		})
 		return
 	}
 
-	// Unexpected error
-	h.logger.Error(ctx, "solver error", map[string]interface{}{
-		"error": err.Error(),
+	h.respondJSON(w, r, http.StatusOK, PrepareResponse{
+		Sizes:    prepared.Sizes,
+		Amount:   prepared.Amount,
+		Warnings: prepared.Warnings,
 	})
-	h.respondError(w, r, http.StatusInternalServerError, "internal server error", nil)
 }
 
-// respondJSON sends JSON response
-func (h *PackHandler) respondJSON(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+// decodeJSON checks Content-Type and decodes the JSON body into v
+// Writes an error response and returns false on failure
+func (h *PackHandler) decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	// Check Content-Type
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" && contentType != "" {
+		h.respondError(w, r, http.StatusUnsupportedMediaType, "content type must be application/json", nil)
+		return false
+	}
 
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		h.logger.Error(r.Context(), "failed to encode response", map[string]interface{}{
-			"error": err.Error(),
+	// Unknown fields (e.g. a misspelled "amounts") are rejected unless ?lenient=true
+	lenient := false
+	if raw := r.URL.Query().Get("lenient"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			h.respondError(w, r, http.StatusBadRequest, "invalid lenient flag", map[string]interface{}{
+				"lenient":   raw,
+				"supported": []string{"true", "false"},
+			})
+			return false
+		}
+		lenient = value
+	}
+
+	// Bound the body so a huge payload cannot exhaust memory
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodyBytes)
+
+	decoder := json.NewDecoder(r.Body)
+	if !lenient {
+		decoder.DisallowUnknownFields()
+	}
+
+	// Numeric fields are integers: out-of-range (1e400) and fractional numbers fail
+	// the type check, NaN and Infinity are not valid JSON and fail parsing
+	if err := decoder.Decode(v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.respondError(w, r, http.StatusRequestEntityTooLarge, "request body is too large", map[string]interface{}{
+				"max_bytes": maxBytesErr.Limit,
+			})
+			return false
+		}
+
+		if field, ok := unknownField(err); ok {
+			h.respondError(w, r, http.StatusBadRequest, "unknown field", map[string]interface{}{
+				"field": field,
+			})
+			return false
+		}
+
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			h.respondError(w, r, http.StatusBadRequest, "invalid field value", map[string]interface{}{
+				"field":    typeErr.Field,
+				"value":    typeErr.Value,
+				"expected": typeErr.Type.String(),
+			})
+			return false
+		}
+
+		h.respondError(w, r, http.StatusBadRequest, "invalid JSON", map[string]interface{}{
+			"parse_error": err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// unknownField extracts the field name from a DisallowUnknownFields error
+// encoding/json has no typed error for it, only the message `json: unknown field "name"`
+func unknownField(err error) (string, bool) {
+	quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return "", false
+	}
+	field, err := strconv.Unquote(quoted)
+	if err != nil {
+		return quoted, true
+	}
+	return field, true
+}
+
+// validationErrorDetails flattens (possibly joined) validation errors into response details
+func validationErrorDetails(err error) []map[string]interface{} {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	details := make([]map[string]interface{}, 0, len(errs))
+	for _, e := range errs {
+		var validationErr *domain.ValidationError
+		if errors.As(e, &validationErr) {
+			details = append(details, map[string]interface{}{
+				"field":   validationErr.Field,
+				"value":   validationErr.Value,
+				"message": validationErr.Message,
+			})
+			continue
+		}
+		details = append(details, map[string]interface{}{
+			"message": e.Error(),
 		})
 	}
+
+	return details
+}
+
+// validateRequest validates the request
+func (h *PackHandler) validateRequest(req *SolveRequest) error {
+	// Validate sizes
+	if len(req.Sizes) == 0 {
+		return domain.NewValidationError("sizes", req.Sizes, "must not be empty")
+	}
+
+	// Amount range replaces the single amount
+	if req.isRange() {
+		return validateAmountRange(req)
+	}
+
+	// Amount 0 is satisfied by no packs, but the sizes must still be valid
+	if req.Amount == 0 && h.allowZeroAmount {
+		return domain.ValidatePackSizes(req.Sizes)
+	}
+
+	// Validate through domain
+	if err := domain.ValidateSolverInput(req.Sizes, req.Amount); err != nil {
+		return err
+	}
+
+	if h.requireExactCoverage {
+		return domain.ValidateExactCoverage(req.Sizes, req.Amount)
+	}
+
+	return nil
+}
+
+// validateAmountRange validates a request using amount_min/amount_max
+func validateAmountRange(req *SolveRequest) error {
+	if err := domain.ValidatePackSizes(req.Sizes); err != nil {
+		return err
+	}
+	if req.Amount != 0 {
+		return domain.NewValidationError("amount", req.Amount, "must not be set together with amount_min/amount_max")
+	}
+	if req.AmountMin <= 0 {
+		return domain.NewValidationError("amount_min", req.AmountMin, "must be greater than 0")
+	}
+	if req.AmountMax > domain.MaxAmount {
+		return domain.NewValidationError("amount_max", req.AmountMax, fmt.Sprintf("must not exceed %d", domain.MaxAmount))
+	}
+	if req.AmountMax < req.AmountMin {
+		return domain.NewValidationError("amount_max", req.AmountMax, "must not be less than amount_min")
+	}
+	return nil
+}
+
+// handleSolverError handles solver errors
+func (h *PackHandler) handleSolverError(w http.ResponseWriter, r *http.Request, err error) {
+	respondSolverError(w, r, h.logger, err)
+}
+
+// respondJSON sends JSON response
+func (h *PackHandler) respondJSON(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	respondJSON(w, r, h.logger, status, data)
 }
 
 // respondError sends error response
 func (h *PackHandler) respondError(w http.ResponseWriter, r *http.Request, status int, message string, details map[string]interface{}) {
-	response := ErrorResponse{
-		Error:   http.StatusText(status),
-		Message: message,
-		Details: details,
-	}
+	respondError(w, r, h.logger, status, message, details)
+}
 
-	h.respondJSON(w, r, status, response)
+// respondErrorCode sends error response with a machine-readable code
+func (h *PackHandler) respondErrorCode(w http.ResponseWriter, r *http.Request, status int, code, message string, details map[string]interface{}) {
+	respondErrorCode(w, r, h.logger, status, code, message, details)
 }
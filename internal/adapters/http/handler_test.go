@@ -4,11 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
 )
 
 // Mock solver for tests
@@ -62,10 +73,7 @@ func TestPackHandler_SolvePacks_Success(t *testing.T) {
 		t.Errorf("expected status 200, got %d", w.Code)
 	}
 
-	var resp SolveResponse
-	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
-		t.Fatalf("failed to decode response: %v", err)
-	}
+	resp := decodeSolveResponse(t, w.Body)
 
 	if resp.Packs != 2 {
 		t.Errorf("expected 2 packs, got %d", resp.Packs)
@@ -73,6 +81,151 @@ func TestPackHandler_SolvePacks_Success(t *testing.T) {
 	if resp.Overage != 0 {
 		t.Errorf("expected 0 overage, got %d", resp.Overage)
 	}
+	if resp.Amount != 750 || resp.TotalItems != 750 {
+		t.Errorf("expected amount 750 and total_items 750, got %d and %d", resp.Amount, resp.TotalItems)
+	}
+	if resp.DistinctSizes != 2 {
+		t.Errorf("expected 2 distinct sizes, got %d", resp.DistinctSizes)
+	}
+	if resp.PackSet != nil {
+		t.Errorf("inline sizes: pack_set = %+v, want none", resp.PackSet)
+	}
+}
+
+func TestPackHandler_SolvePacks_ZeroAmount(t *testing.T) {
+	tests := []struct {
+		name       string
+		allowZero  bool
+		body       string
+		wantStatus int
+	}{
+		{name: "rejected by default", body: `{"sizes":[250,500],"amount":0}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "allowed", allowZero: true, body: `{"sizes":[250,500],"amount":0}`, wantStatus: http.StatusOK},
+		{name: "allowed in strict mode", allowZero: true, body: `{"sizes":[250,500],"amount":0,"strict":true}`, wantStatus: http.StatusOK},
+		{name: "allowed but sizes invalid", allowZero: true, body: `{"sizes":[250,250],"amount":0}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "negative still rejected", allowZero: true, body: `{"sizes":[250,500],"amount":-1}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The solver must not be called for amount 0
+			repo := &recordingRepository{id: 1, saved: make(chan interface{}, 1)}
+			handler := NewPackHandler(&mockSolver{err: errors.New("solver called")}, &mockLogger{}).
+				WithStrictSolver(usecase.NewDPSolver()).
+				WithRepository(repo).
+				WithAllowZeroAmount(tt.allowZero)
+
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			resp := decodeSolveResponse(t, w.Body)
+			if len(resp.Solution) != 0 || resp.Packs != 0 || resp.Overage != 0 || resp.Amount != 0 {
+				t.Errorf("expected empty solution, got %+v", resp)
+			}
+
+			select {
+			case record := <-repo.saved:
+				t.Errorf("empty solution was saved: %v", record)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolvePacks_RequireExactCoverage(t *testing.T) {
+	tests := []struct {
+		name       string
+		required   bool
+		body       string
+		wantStatus int
+	}{
+		{name: "disabled by default", body: `{"sizes":[250,500],"amount":251}`, wantStatus: http.StatusOK},
+		{name: "compatible amount", required: true, body: `{"sizes":[250,500],"amount":750}`, wantStatus: http.StatusOK},
+		{name: "incompatible amount", required: true, body: `{"sizes":[250,500],"amount":251}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "ranges not checked", required: true, body: `{"sizes":[250,500],"amount_min":251,"amount_max":500}`, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{}).
+				WithRequireExactCoverage(tt.required)
+
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				return
+			}
+
+			var errResp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&errResp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if errResp.Details["field"] != "amount" {
+				t.Errorf("field = %v, want amount", errResp.Details["field"])
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolvePacks_Algorithm(t *testing.T) {
+	dp := &mockSolver{solution: &domain.Solution{Breakdown: map[int]int{500: 1}, Packs: 1, Overage: 249, Amount: 251}}
+	greedy := &mockSolver{solution: &domain.Solution{Breakdown: map[int]int{250: 2}, Packs: 2, Overage: 249, Amount: 251}}
+	handler := NewPackHandler(dp, &mockLogger{}).
+		WithAlgorithm(usecase.AlgorithmGreedy, greedy).
+		WithAlgorithm(usecase.AlgorithmDP, greedy) // Ignored: dp is always the handler's solver
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       map[int]int
+	}{
+		{name: "default", body: `{"sizes":[250,500],"amount":251}`, wantStatus: http.StatusOK, want: map[int]int{500: 1}},
+		{name: "dp", body: `{"sizes":[250,500],"amount":251,"algorithm":"dp"}`, wantStatus: http.StatusOK, want: map[int]int{500: 1}},
+		{name: "greedy", body: `{"sizes":[250,500],"amount":251,"algorithm":"greedy"}`, wantStatus: http.StatusOK, want: map[int]int{250: 2}},
+		{name: "unknown", body: `{"sizes":[250,500],"amount":251,"algorithm":"fastest"}`, wantStatus: http.StatusBadRequest},
+		{name: "greedy with strict", body: `{"sizes":[250,500],"amount":251,"algorithm":"greedy","strict":true}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			switch tt.wantStatus {
+			case http.StatusOK:
+				resp := decodeSolveResponse(t, w.Body)
+				if !reflect.DeepEqual(resp.Solution, tt.want) {
+					t.Errorf("solution = %v, want %v", resp.Solution, tt.want)
+				}
+			case http.StatusBadRequest:
+				var errResp ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errResp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if !reflect.DeepEqual(errResp.Details["supported"], []interface{}{"dp", "greedy"}) {
+					t.Errorf("supported = %v, want [dp greedy]", errResp.Details["supported"])
+				}
+			}
+		})
+	}
 }
 
 func TestPackHandler_SolvePacks_ValidationError(t *testing.T) {
@@ -141,6 +294,152 @@ func TestPackHandler_SolvePacks_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestPackHandler_SolvePacks_BodyTooLarge(t *testing.T) {
+	mockSol := &mockSolver{solution: domain.NewSolution(map[int]int{500: 1}, 251)}
+	handler := NewPackHandler(mockSol, &mockLogger{}).WithMaxBodyBytes(64)
+
+	// Valid JSON, padded with whitespace beyond the limit
+	body := `{"sizes": [250, 500], "amount": 251` + strings.Repeat(" ", 64) + `}`
+	req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.SolvePacks(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413, got %d: %s", w.Code, w.Body.String())
+	}
+	var errResp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&errResp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if errResp.Code != "REQUEST_ENTITY_TOO_LARGE" || errResp.Details["max_bytes"] != float64(64) {
+		t.Errorf("response = %+v, want REQUEST_ENTITY_TOO_LARGE with max_bytes 64", errResp)
+	}
+
+	// The same request within the limit succeeds
+	req = httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(`{"sizes": [250, 500], "amount": 251}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+
+	handler.SolvePacks(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 within the limit, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPackHandler_SolvePacks_UnknownField(t *testing.T) {
+	mockSol := &mockSolver{solution: domain.NewSolution(map[int]int{500: 1}, 251)}
+	handler := NewPackHandler(mockSol, &mockLogger{})
+
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+		wantField  string
+	}{
+		{name: "misspelled field", target: "/packs/solve", body: `{"sizes": [250, 500], "amounts": 251}`, wantStatus: http.StatusBadRequest, wantField: "amounts"},
+		{name: "known fields", target: "/packs/solve", body: `{"sizes": [250, 500], "amount": 251}`, wantStatus: http.StatusOK},
+		{name: "lenient", target: "/packs/solve?lenient=true", body: `{"sizes": [250, 500], "amount": 251, "note": "x"}`, wantStatus: http.StatusOK},
+		{name: "invalid lenient flag", target: "/packs/solve?lenient=maybe", body: `{"sizes": [250, 500], "amount": 251}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantField == "" {
+				return
+			}
+			var errResp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&errResp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if errResp.Message != "unknown field" || errResp.Details["field"] != tt.wantField {
+				t.Errorf("response = %+v, want unknown field %q", errResp, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolvePacks_PackSetName(t *testing.T) {
+	name := "uk-standard"
+	repo := &mockPackSizeRepository{}
+	if _, err := repo.Create(context.Background(), &domain.PackSizeSet{Name: &name, Sizes: []int{300}}); err != nil {
+		t.Fatalf("failed to create pack set: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		body          string
+		packSets      domain.PackSizeRepository
+		wantStatus    int
+		wantBreakdown map[int]int
+	}{
+		{
+			name:          "resolves stored sizes",
+			body:          `{"pack_set_name": "uk-standard", "amount": 750}`,
+			packSets:      repo,
+			wantStatus:    http.StatusOK,
+			wantBreakdown: map[int]int{300: 3},
+		},
+		{
+			name:       "unknown name",
+			body:       `{"pack_set_name": "us-standard", "amount": 750}`,
+			packSets:   repo,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "with inline sizes",
+			body:       `{"pack_set_name": "uk-standard", "sizes": [250, 500], "amount": 750}`,
+			packSets:   repo,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "no repository",
+			body:       `{"pack_set_name": "uk-standard", "amount": 750}`,
+			wantStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+			if tt.packSets != nil {
+				handler.WithPackSets(tt.packSets)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantBreakdown == nil {
+				return
+			}
+			resp := decodeSolveResponse(t, w.Body)
+			if !reflect.DeepEqual(resp.Solution, tt.wantBreakdown) {
+				t.Errorf("solution = %v, want %v", resp.Solution, tt.wantBreakdown)
+			}
+			if want := (PackSetRef{ID: 1, Name: name}); resp.PackSet == nil || *resp.PackSet != want {
+				t.Errorf("pack_set = %+v, want %+v", resp.PackSet, want)
+			}
+		})
+	}
+}
+
 func TestPackHandler_SolvePacks_MethodNotAllowed(t *testing.T) {
 	mockSol := &mockSolver{}
 	handler := NewPackHandler(mockSol, &mockLogger{})
@@ -154,3 +453,1479 @@ func TestPackHandler_SolvePacks_MethodNotAllowed(t *testing.T) {
 		t.Errorf("expected status 405, got %d", w.Code)
 	}
 }
+
+func TestPackHandler_SolvePacks_NestedShape(t *testing.T) {
+	mockSol := &mockSolver{
+		solution: &domain.Solution{
+			Breakdown: map[int]int{250: 1, 5000: 2},
+			Packs:     3,
+			Overage:   249,
+			Amount:    10001,
+		},
+	}
+	handler := NewPackHandler(mockSol, &mockLogger{})
+
+	body, _ := json.Marshal(SolveRequest{Sizes: []int{250, 500, 5000}, Amount: 10001})
+	req := httptest.NewRequest(http.MethodPost, "/packs/solve?shape=nested", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.SolvePacks(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	want := `{"solution":{"lines":[{"size":5000,"count":2,"units":10000},{"size":250,"count":1,"units":250}],"packs":3,"overage":249}}`
+	if got := strings.TrimSpace(w.Body.String()); got != want {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestPackHandler_SolvePacks_UnsupportedShape(t *testing.T) {
+	handler := NewPackHandler(&mockSolver{}, &mockLogger{})
+
+	body, _ := json.Marshal(SolveRequest{Sizes: []int{250}, Amount: 250})
+	req := httptest.NewRequest(http.MethodPost, "/packs/solve?shape=tree", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.SolvePacks(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+}
+
+// Mock repository that blocks until released
+type blockingRepository struct {
+	release chan struct{}
+}
+
+func (m *blockingRepository) SaveCalculation(ctx context.Context, record interface{}) (int64, error) {
+	<-m.release
+	return 1, nil
+}
+
+func TestPackHandler_SaveQueueDepthMetric(t *testing.T) {
+	mockSol := &mockSolver{
+		solution: &domain.Solution{Breakdown: map[int]int{250: 1}, Packs: 1, Amount: 250},
+	}
+	repo := &blockingRepository{release: make(chan struct{})}
+	handler := NewPackHandler(mockSol, &mockLogger{}).WithRepository(repo)
+
+	baseDepth := testutil.ToFloat64(calculationSaveQueueDepth)
+	baseEnqueued := testutil.ToFloat64(calculationSaveEnqueuedTotal)
+
+	// Fill the queue with saves that cannot complete yet
+	const pending = 3
+	for i := 0; i < pending; i++ {
+		body, _ := json.Marshal(SolveRequest{Sizes: []int{250}, Amount: 250})
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.SolvePacks(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+	}
+
+	if got := testutil.ToFloat64(calculationSaveQueueDepth) - baseDepth; got != pending {
+		t.Errorf("queue depth = %v, want %d", got, pending)
+	}
+	if got := testutil.ToFloat64(calculationSaveEnqueuedTotal) - baseEnqueued; got != pending {
+		t.Errorf("enqueued total = %v, want %d", got, pending)
+	}
+
+	// Drain the queue
+	close(repo.release)
+	deadline := time.Now().Add(time.Second)
+	for testutil.ToFloat64(calculationSaveQueueDepth) != baseDepth {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue depth did not drain, got %v", testutil.ToFloat64(calculationSaveQueueDepth))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// slowRepository saves after a delay (and, if set, once release is closed),
+// counting completed saves
+type slowRepository struct {
+	delay   time.Duration
+	release chan struct{}
+	saved   atomic.Int32
+}
+
+func (m *slowRepository) SaveCalculation(ctx context.Context, record interface{}) (int64, error) {
+	if m.release != nil {
+		<-m.release
+	}
+	time.Sleep(m.delay)
+	return int64(m.saved.Add(1)), nil
+}
+
+func TestPackHandler_Drain(t *testing.T) {
+	record := newCalculationRecord(context.Background(), []int{250}, nil, domain.NewSolution(map[int]int{250: 1}, 250), nil)
+
+	t.Run("waits for pending saves", func(t *testing.T) {
+		repo := &slowRepository{delay: 50 * time.Millisecond}
+		handler := NewPackHandler(&mockSolver{}, &mockLogger{}).WithRepository(repo)
+
+		const pending = 3
+		for range pending {
+			handler.saveCalculationAsync(record)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := handler.Drain(ctx); err != nil {
+			t.Fatalf("Drain() error = %v", err)
+		}
+		if got := repo.saved.Load(); got != pending {
+			t.Errorf("saved = %d after Drain, want %d", got, pending)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		repo := &blockingRepository{release: make(chan struct{})}
+		handler := NewPackHandler(&mockSolver{}, &mockLogger{}).WithRepository(repo)
+		handler.saveCalculationAsync(record)
+		defer close(repo.release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := handler.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Drain() error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("nothing pending", func(t *testing.T) {
+		handler := NewPackHandler(&mockSolver{}, &mockLogger{})
+		if err := handler.Drain(context.Background()); err != nil {
+			t.Errorf("Drain() error = %v", err)
+		}
+	})
+}
+
+func TestPackHandler_SaveQueue_DropsWhenFull(t *testing.T) {
+	record := newCalculationRecord(context.Background(), []int{250}, nil, domain.NewSolution(map[int]int{250: 1}, 250), nil)
+	repo := &slowRepository{release: make(chan struct{})}
+	const queueSize = 2
+	handler := NewPackHandler(&mockSolver{}, &mockLogger{}).
+		WithRepository(repo).
+		WithSaveQueue(1, queueSize)
+
+	baseDropped := testutil.ToFloat64(calculationSaveDroppedTotal)
+	baseEnqueued := testutil.ToFloat64(calculationSaveEnqueuedTotal)
+
+	// Occupy the only worker, then fill the queue
+	handler.saveCalculationAsync(record)
+	deadline := time.Now().Add(time.Second)
+	for len(handler.saveQueue) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("worker did not pick up the first save")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for range queueSize {
+		handler.saveCalculationAsync(record)
+	}
+
+	// Further saves are dropped without blocking
+	const dropped = 3
+	for range dropped {
+		handler.saveCalculationAsync(record)
+	}
+
+	if got := testutil.ToFloat64(calculationSaveDroppedTotal) - baseDropped; got != dropped {
+		t.Errorf("dropped total = %v, want %d", got, dropped)
+	}
+	if got := testutil.ToFloat64(calculationSaveEnqueuedTotal) - baseEnqueued; got != 1+queueSize {
+		t.Errorf("enqueued total = %v, want %d", got, 1+queueSize)
+	}
+
+	// Enqueued saves still complete
+	close(repo.release)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := handler.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if got := repo.saved.Load(); got != 1+queueSize {
+		t.Errorf("saved = %d, want %d", got, 1+queueSize)
+	}
+}
+
+func TestPackHandler_SolvePacks_SolveDurationHeader(t *testing.T) {
+	mockSol := &mockSolver{
+		solution: &domain.Solution{Breakdown: map[int]int{250: 1}, Packs: 1, Amount: 250},
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+	}{
+		{name: "enabled by default", enabled: true},
+		{name: "disabled", enabled: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPackHandler(mockSol, &mockLogger{}).WithSolveDurationHeader(tt.enabled)
+
+			body, _ := json.Marshal(SolveRequest{Sizes: []int{250}, Amount: 250})
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			value := w.Header().Get(SolveDurationHeader)
+			if !tt.enabled {
+				if value != "" {
+					t.Errorf("expected no %s header, got %q", SolveDurationHeader, value)
+				}
+				return
+			}
+
+			duration, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatalf("%s header is not numeric: %q", SolveDurationHeader, value)
+			}
+			if duration < 0 {
+				t.Errorf("%s header is negative: %v", SolveDurationHeader, duration)
+			}
+		})
+	}
+}
+
+func TestPackHandler_PrepareInput(t *testing.T) {
+	handler := NewPackHandler(&mockSolver{}, &mockLogger{})
+
+	t.Run("canonical output with warnings", func(t *testing.T) {
+		body := []byte(`{"sizes":[500,250,500,-1],"amount":751}`)
+		req := httptest.NewRequest(http.MethodPost, "/packs/prepare", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.PrepareInput(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+
+		var resp PrepareResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Sizes) != 2 || resp.Sizes[0] != 250 || resp.Sizes[1] != 500 {
+			t.Errorf("expected sizes [250 500], got %v", resp.Sizes)
+		}
+		if len(resp.Warnings) != 3 {
+			t.Errorf("expected 3 warnings, got %+v", resp.Warnings)
+		}
+	})
+
+	t.Run("structured validation errors", func(t *testing.T) {
+		body := []byte(`{"sizes":[0],"amount":-1}`)
+		req := httptest.NewRequest(http.MethodPost, "/packs/prepare", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.PrepareInput(w, req)
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status 422, got %d", w.Code)
+		}
+
+		var resp ErrorResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		errs, ok := resp.Details["errors"].([]interface{})
+		if !ok || len(errs) != 2 {
+			t.Errorf("expected 2 field errors, got %v", resp.Details)
+		}
+	})
+}
+
+func TestPackHandler_SolvePacks_PreferExact(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	tests := []struct {
+		name           string
+		body           string
+		wantExact      *bool
+		wantOverage    int
+		wantAmount     int
+		wantTotalItems int
+	}{
+		{
+			name:           "exact available",
+			body:           `{"sizes":[250,500,1000],"amount":1250,"prefer_exact":true}`,
+			wantExact:      boolPtr(true),
+			wantOverage:    0,
+			wantAmount:     1250,
+			wantTotalItems: 1250,
+		},
+		{
+			name:           "exact not available falls back to minimal overage",
+			body:           `{"sizes":[250,500,1000],"amount":251,"prefer_exact":true}`,
+			wantExact:      boolPtr(false),
+			wantOverage:    249,
+			wantAmount:     251,
+			wantTotalItems: 500,
+		},
+		{
+			name:           "not requested",
+			body:           `{"sizes":[250,500,1000],"amount":251}`,
+			wantExact:      nil,
+			wantOverage:    249,
+			wantAmount:     251,
+			wantTotalItems: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", w.Code)
+			}
+
+			resp := decodeSolveResponse(t, w.Body)
+
+			if resp.Overage != tt.wantOverage {
+				t.Errorf("overage = %d, want %d", resp.Overage, tt.wantOverage)
+			}
+			if resp.Amount != tt.wantAmount || resp.TotalItems != tt.wantTotalItems {
+				t.Errorf("amount = %d, total_items = %d, want %d and %d", resp.Amount, resp.TotalItems, tt.wantAmount, tt.wantTotalItems)
+			}
+			if (resp.Exact == nil) != (tt.wantExact == nil) || (resp.Exact != nil && *resp.Exact != *tt.wantExact) {
+				t.Errorf("exact = %v, want %v", resp.Exact, tt.wantExact)
+			}
+		})
+	}
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestPackHandler_SolvePacks_SortLines(t *testing.T) {
+	mockSol := &mockSolver{
+		solution: &domain.Solution{
+			Breakdown: map[int]int{250: 1, 500: 3, 1000: 3},
+			Packs:     7,
+			Overage:   0,
+			Amount:    4750,
+		},
+	}
+	handler := NewPackHandler(mockSol, &mockLogger{})
+
+	tests := []struct {
+		sort      string
+		wantSizes []int
+	}{
+		{sort: "", wantSizes: []int{1000, 500, 250}},
+		{sort: "size:desc", wantSizes: []int{1000, 500, 250}},
+		{sort: "size:asc", wantSizes: []int{250, 500, 1000}},
+		{sort: "count:desc", wantSizes: []int{1000, 500, 250}},
+		{sort: "count:asc", wantSizes: []int{250, 1000, 500}},
+	}
+
+	for _, tt := range tests {
+		t.Run("sort="+tt.sort, func(t *testing.T) {
+			body, _ := json.Marshal(SolveRequest{Sizes: []int{250, 500, 1000}, Amount: 4750})
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve?shape=nested&sort="+tt.sort, bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", w.Code)
+			}
+
+			var resp NestedSolveResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			gotSizes := make([]int, 0, len(resp.Solution.Lines))
+			for _, line := range resp.Solution.Lines {
+				gotSizes = append(gotSizes, line.Size)
+			}
+			if !reflect.DeepEqual(gotSizes, tt.wantSizes) {
+				t.Errorf("line sizes = %v, want %v", gotSizes, tt.wantSizes)
+			}
+		})
+
+		t.Run("list/sort="+tt.sort, func(t *testing.T) {
+			body, _ := json.Marshal(SolveRequest{Sizes: []int{250, 500, 1000}, Amount: 4750})
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve?sort="+tt.sort, bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", w.Code)
+			}
+
+			var resp struct {
+				Solution []BreakdownEntry `json:"solution"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			gotSizes := make([]int, 0, len(resp.Solution))
+			for _, entry := range resp.Solution {
+				gotSizes = append(gotSizes, entry.Size)
+			}
+			if !reflect.DeepEqual(gotSizes, tt.wantSizes) {
+				t.Errorf("solution sizes = %v, want %v", gotSizes, tt.wantSizes)
+			}
+		})
+	}
+
+	t.Run("map format", func(t *testing.T) {
+		tests := []struct {
+			query      string
+			wantStatus int
+		}{
+			{query: "?format=map", wantStatus: http.StatusOK},
+			{query: "?format=map&sort=size:desc", wantStatus: http.StatusOK},
+			{query: "?format=map&sort=size:asc", wantStatus: http.StatusUnprocessableEntity},
+			{query: "?format=map&sort=count:desc&shape=nested", wantStatus: http.StatusOK},
+		}
+
+		for _, tt := range tests {
+			body, _ := json.Marshal(SolveRequest{Sizes: []int{250, 500, 1000}, Amount: 4750})
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve"+tt.query, bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s: expected status %d, got %d: %s", tt.query, tt.wantStatus, w.Code, w.Body.String())
+			}
+		}
+	})
+
+	t.Run("invalid sort key", func(t *testing.T) {
+		body, _ := json.Marshal(SolveRequest{Sizes: []int{250}, Amount: 250})
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve?sort=units:asc", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.SolvePacks(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestPackHandler_SolvePacks_LotSize(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	t.Run("raw vs lot-rounded for amount 100, lot 12", func(t *testing.T) {
+		lotSize := 12
+		body, _ := json.Marshal(SolveRequest{Sizes: []int{5, 12}, Amount: 100, LotSize: &lotSize})
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.SolvePacks(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		resp := decodeSolveResponse(t, w.Body)
+
+		// Raw: 100 = 5×12 + 8×5
+		if !reflect.DeepEqual(resp.Solution, map[int]int{12: 5, 5: 8}) || resp.Packs != 13 || resp.Overage != 0 {
+			t.Errorf("raw solution = %v (packs %d, overage %d), want map[5:8 12:5] (packs 13, overage 0)",
+				resp.Solution, resp.Packs, resp.Overage)
+		}
+
+		// Lot-rounded: 108 = 9×12
+		if resp.Lot == nil {
+			t.Fatal("expected lot solution in response")
+		}
+		if resp.Lot.LotSize != 12 || resp.Lot.Amount != 108 {
+			t.Errorf("lot = (size %d, amount %d), want (12, 108)", resp.Lot.LotSize, resp.Lot.Amount)
+		}
+		if !reflect.DeepEqual(resp.Lot.Solution, map[int]int{12: 9}) || resp.Lot.Packs != 9 || resp.Lot.Overage != 0 {
+			t.Errorf("lot solution = %v (packs %d, overage %d), want map[12:9] (packs 9, overage 0)",
+				resp.Lot.Solution, resp.Lot.Packs, resp.Lot.Overage)
+		}
+	})
+
+	t.Run("nested shape includes lot lines", func(t *testing.T) {
+		lotSize := 12
+		body, _ := json.Marshal(SolveRequest{Sizes: []int{5, 12}, Amount: 100, LotSize: &lotSize})
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve?shape=nested", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.SolvePacks(w, req)
+
+		var resp NestedSolveResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		want := []SolutionLine{{Size: 12, Count: 9, Units: 108}}
+		if resp.Solution.Lot == nil || !reflect.DeepEqual(resp.Solution.Lot.Lines, want) {
+			t.Errorf("lot = %+v, want lines %v", resp.Solution.Lot, want)
+		}
+	})
+
+	t.Run("omitted without lot_size", func(t *testing.T) {
+		body, _ := json.Marshal(SolveRequest{Sizes: []int{5, 12}, Amount: 100})
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.SolvePacks(w, req)
+
+		if strings.Contains(w.Body.String(), `"lot"`) {
+			t.Errorf("expected no lot field, got %s", w.Body.String())
+		}
+	})
+
+	for _, lotSize := range []int{0, -12} {
+		t.Run("invalid lot_size "+strconv.Itoa(lotSize), func(t *testing.T) {
+			body := `{"sizes":[5,12],"amount":100,"lot_size":` + strconv.Itoa(lotSize) + `}`
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("expected status 422, got %d", w.Code)
+			}
+		})
+	}
+}
+
+// bypassRecordingSolver records the cache bypass mode seen by the solver
+type bypassRecordingSolver struct {
+	mockSolver
+	bypass domain.CacheBypass
+}
+
+func (m *bypassRecordingSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	m.bypass = domain.CacheBypassFromContext(ctx)
+	return m.mockSolver.Solve(ctx, sizes, amount)
+}
+
+func TestPackHandler_SolvePacks_CacheBypass(t *testing.T) {
+	tests := []struct {
+		name       string
+		allowed    bool
+		header     string
+		wantStatus int
+		wantBypass domain.CacheBypass
+	}{
+		{name: "no header", allowed: true, wantStatus: http.StatusOK, wantBypass: domain.CacheBypassNone},
+		{name: "read bypass", allowed: true, header: "true", wantStatus: http.StatusOK, wantBypass: domain.CacheBypassRead},
+		{name: "refresh", allowed: true, header: "refresh", wantStatus: http.StatusOK, wantBypass: domain.CacheBypassRefresh},
+		{name: "not allowed is ignored", allowed: false, header: "true", wantStatus: http.StatusOK, wantBypass: domain.CacheBypassNone},
+		{name: "unsupported value", allowed: true, header: "yes", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solver := &bypassRecordingSolver{mockSolver: mockSolver{
+				solution: &domain.Solution{Breakdown: map[int]int{250: 1}, Packs: 1, Amount: 250},
+			}}
+			handler := NewPackHandler(solver, &mockLogger{}).WithCacheBypass(tt.allowed)
+
+			body, _ := json.Marshal(SolveRequest{Sizes: []int{250}, Amount: 250})
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", bytes.NewReader(body))
+			if tt.header != "" {
+				req.Header.Set(CacheBypassHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if solver.bypass != tt.wantBypass {
+				t.Errorf("solver saw bypass %v, want %v", solver.bypass, tt.wantBypass)
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolvePacks_MemoryBudgetExceeded(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver().WithMemoryBudget(1024), &mockLogger{})
+
+	body, _ := json.Marshal(SolveRequest{Sizes: []int{250, 500, 1000}, Amount: 12001})
+	req := httptest.NewRequest(http.MethodPost, "/packs/solve", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.SolvePacks(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d", w.Code)
+	}
+}
+
+func TestPackHandler_SolvePacks_AmountRange(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantPacks   map[int]int
+		wantOverage int
+	}{
+		{
+			name:       "exact mid-range sum",
+			body:       `{"sizes":[23,31,53],"amount_min":100,"amount_max":110}`,
+			wantStatus: http.StatusOK, wantPacks: map[int]int{53: 2}, wantOverage: 6,
+		},
+		{
+			name:       "nothing fits",
+			body:       `{"sizes":[500],"amount_min":100,"amount_max":400}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "inverted range",
+			body:       `{"sizes":[250],"amount_min":500,"amount_max":400}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "missing amount_min",
+			body:       `{"sizes":[250],"amount_max":400}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "combined with amount",
+			body:       `{"sizes":[250],"amount":300,"amount_min":250,"amount_max":400}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			resp := decodeSolveResponse(t, w.Body)
+			if !reflect.DeepEqual(resp.Solution, tt.wantPacks) || resp.Overage != tt.wantOverage {
+				t.Errorf("got %v (overage %d), want %v (overage %d)", resp.Solution, resp.Overage, tt.wantPacks, tt.wantOverage)
+			}
+		})
+	}
+
+	t.Run("solver without range support", func(t *testing.T) {
+		handler := NewPackHandler(&mockSolver{}, &mockLogger{})
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve",
+			strings.NewReader(`{"sizes":[250],"amount_min":250,"amount_max":400}`))
+		w := httptest.NewRecorder()
+
+		handler.SolvePacks(w, req)
+
+		if w.Code != http.StatusNotImplemented {
+			t.Errorf("expected status 501, got %d", w.Code)
+		}
+	})
+}
+
+func TestPackHandler_SolvePacks_Strict(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       map[int]int
+	}{
+		{name: "no exact combination", body: `{"sizes":[3,5],"amount":7,"strict":true}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "exact combination", body: `{"sizes":[3,5],"amount":8,"strict":true}`, wantStatus: http.StatusOK, want: map[int]int{5: 1, 3: 1}},
+		{name: "non-strict falls back to overage", body: `{"sizes":[3,5],"amount":7}`, wantStatus: http.StatusOK, want: map[int]int{5: 1, 3: 1}},
+		{name: "strict with range", body: `{"sizes":[3,5],"amount_min":7,"amount_max":9,"strict":true}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.want == nil {
+				return
+			}
+
+			resp := decodeSolveResponse(t, w.Body)
+			if !reflect.DeepEqual(resp.Solution, tt.want) {
+				t.Errorf("solution = %v, want %v", resp.Solution, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolvePacks_MaxOverage(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "cap excludes optimum", body: `{"sizes":[250,500,1000],"amount":251,"max_overage":100}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "cap allows optimum", body: `{"sizes":[250,500,1000],"amount":251,"max_overage":249}`, wantStatus: http.StatusOK},
+		{name: "negative cap", body: `{"sizes":[250],"amount":251,"max_overage":-1}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolvePacks_InvalidOptions(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	body := `{"sizes":[250,500],"amount":251,"max_overage":-1,"lot_size":0}`
+	req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.SolvePacks(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	errs, ok := resp.Details["errors"].([]interface{})
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expected 2 option errors, got %v", resp.Details)
+	}
+	for i, field := range []string{"max_overage", "lot_size"} {
+		if got := errs[i].(map[string]interface{})["field"]; got != field {
+			t.Errorf("error %d field = %v, want %s", i, got, field)
+		}
+	}
+}
+
+func TestPackHandler_SolvePacks_HighOverageWarning(t *testing.T) {
+	tests := []struct {
+		name         string
+		ratio        float64
+		body         string
+		wantWarnings []string
+	}{
+		{name: "overage above amount", ratio: DefaultHighOverageRatio, body: `{"sizes":[250,500],"amount":100}`, wantWarnings: []string{WarningHighOverage}},
+		{name: "overage below amount", ratio: DefaultHighOverageRatio, body: `{"sizes":[250,500],"amount":251}`, wantWarnings: nil},
+		{name: "lower ratio", ratio: 0.5, body: `{"sizes":[250,500],"amount":251}`, wantWarnings: []string{WarningHighOverage}},
+		{name: "disabled", ratio: 0, body: `{"sizes":[250,500],"amount":1}`, wantWarnings: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{}).WithHighOverageRatio(tt.ratio)
+
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", w.Code)
+			}
+
+			resp := decodeSolveResponse(t, w.Body)
+			if !reflect.DeepEqual(resp.Warnings, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", resp.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+// Mock repository recording saved records
+type recordingRepository struct {
+	id    int64
+	err   error
+	saved chan interface{}
+}
+
+func (m *recordingRepository) SaveCalculation(ctx context.Context, record interface{}) (int64, error) {
+	m.saved <- record
+	return m.id, m.err
+}
+
+func TestPackHandler_SolvePacks_DryRun(t *testing.T) {
+	for _, persistSync := range []bool{false, true} {
+		t.Run(fmt.Sprintf("persist_sync=%v", persistSync), func(t *testing.T) {
+			repo := &recordingRepository{id: 1, saved: make(chan interface{}, 1)}
+			handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{}).
+				WithRepository(repo).
+				WithPersistSync(persistSync)
+
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(`{"sizes":[250,500],"amount":251,"dry_run":true}`))
+			w := httptest.NewRecorder()
+			handler.SolvePacks(w, req)
+			handler.Drain(context.Background())
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			resp := decodeSolveResponse(t, w.Body)
+			if !reflect.DeepEqual(resp.Solution, map[int]int{500: 1}) || resp.CalculationID != nil {
+				t.Errorf("response = %+v, want solution map[500:1] without calculation_id", resp)
+			}
+
+			select {
+			case record := <-repo.saved:
+				t.Errorf("dry run was saved: %v", record)
+			default:
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolvePacks_NormalizesSolution(t *testing.T) {
+	// A solver leaving a zero-count entry and stale totals behind
+	mockSol := &mockSolver{
+		solution: &domain.Solution{Breakdown: map[int]int{250: 0, 500: 1}, Packs: 2, Overage: 249, Amount: 251},
+	}
+	repo := &recordingRepository{id: 1, saved: make(chan interface{}, 1)}
+	handler := NewPackHandler(mockSol, &mockLogger{}).WithRepository(repo).WithPersistSync(true)
+
+	req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(`{"sizes":[250,500],"amount":251}`))
+	w := httptest.NewRecorder()
+	handler.SolvePacks(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	resp := decodeSolveResponse(t, w.Body)
+	if !reflect.DeepEqual(resp.Solution, map[int]int{500: 1}) || resp.Packs != 1 || resp.Overage != 249 || resp.DistinctSizes != 1 {
+		t.Errorf("response = %+v, want only 500×1 with 1 pack and 249 overage", resp)
+	}
+
+	saved := (<-repo.saved).(map[string]interface{})["solution"].(*domain.Solution)
+	if _, ok := saved.Breakdown[250]; ok || saved.Packs != 1 {
+		t.Errorf("saved solution = %+v, want the zero entry stripped", saved)
+	}
+}
+
+func TestPackHandler_SolvePacks_Persist(t *testing.T) {
+	solution := &domain.Solution{Breakdown: map[int]int{250: 1}, Packs: 1, Amount: 250}
+	body := `{"sizes":[250],"amount":250}`
+
+	solve := func(handler *PackHandler) (*httptest.ResponseRecorder, SolveResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.SolvePacks(w, req)
+
+		var resp SolveResponse
+		if w.Code == http.StatusOK {
+			resp = decodeSolveResponse(t, w.Body)
+		}
+		return w, resp
+	}
+
+	t.Run("sync success", func(t *testing.T) {
+		repo := &recordingRepository{id: 42, saved: make(chan interface{}, 1)}
+		handler := NewPackHandler(&mockSolver{solution: solution}, &mockLogger{}).
+			WithRepository(repo).
+			WithPersistSync(true)
+
+		w, resp := solve(handler)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		if len(repo.saved) != 1 {
+			t.Error("expected the calculation to be saved before responding")
+		}
+		if resp.CalculationID == nil || *resp.CalculationID != 42 {
+			t.Errorf("calculation_id = %v, want 42", resp.CalculationID)
+		}
+	})
+
+	t.Run("sync failure", func(t *testing.T) {
+		repo := &recordingRepository{err: errors.New("connection refused"), saved: make(chan interface{}, 1)}
+		handler := NewPackHandler(&mockSolver{solution: solution}, &mockLogger{}).
+			WithRepository(repo).
+			WithPersistSync(true)
+
+		w, _ := solve(handler)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("async fire-and-forget", func(t *testing.T) {
+		repo := &recordingRepository{err: errors.New("connection refused"), saved: make(chan interface{})}
+		handler := NewPackHandler(&mockSolver{solution: solution}, &mockLogger{}).WithRepository(repo)
+
+		// The unbuffered channel blocks the save until the test receives it,
+		// so the response must be written without waiting for persistence
+		w, resp := solve(handler)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 despite the failing save, got %d", w.Code)
+		}
+		if resp.CalculationID != nil {
+			t.Errorf("expected no calculation_id, got %d", *resp.CalculationID)
+		}
+
+		select {
+		case <-repo.saved:
+		case <-time.After(time.Second):
+			t.Error("expected the calculation to be saved in the background")
+		}
+	})
+}
+
+func TestPackHandler_SolvePacks_NonFiniteNumbers(t *testing.T) {
+	handler := NewPackHandler(&mockSolver{}, &mockLogger{})
+
+	tests := []struct {
+		name        string
+		amount      string
+		wantMessage string
+	}{
+		{name: "Inf", amount: "1e400", wantMessage: "invalid field value"},
+		{name: "-Inf", amount: "-1e400", wantMessage: "invalid field value"},
+		{name: "fraction", amount: "1.5", wantMessage: "invalid field value"},
+		{name: "NaN literal", amount: "NaN", wantMessage: "invalid JSON"},
+		{name: "Infinity literal", amount: "Infinity", wantMessage: "invalid JSON"},
+		{name: "-Infinity literal", amount: "-Infinity", wantMessage: "invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"sizes":[250],"amount":` + tt.amount + `}`
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", w.Code)
+			}
+			var resp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", resp.Message, tt.wantMessage)
+			}
+			if tt.wantMessage == "invalid field value" && resp.Details["field"] != "amount" {
+				t.Errorf("field = %v, want amount", resp.Details["field"])
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolvePacks_RecordCarriesCorrelationID(t *testing.T) {
+	repo := &recordingRepository{id: 1, saved: make(chan interface{}, 1)}
+	packHandler := NewPackHandler(&mockSolver{
+		solution: &domain.Solution{Breakdown: map[int]int{250: 1}, Packs: 1, Amount: 250},
+	}, &mockLogger{}).WithRepository(repo)
+	handler := CorrelationIDMiddleware(&mockLogger{})(http.HandlerFunc(packHandler.SolvePacks))
+
+	req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(`{"sizes":[250],"amount":250}`))
+	req.Header.Set("X-Correlation-ID", "req-123")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	select {
+	case record := <-repo.saved:
+		if got := record.(map[string]interface{})["correlation_id"]; got != "req-123" {
+			t.Errorf("record correlation_id = %v, want req-123", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("calculation was not saved")
+	}
+}
+
+// infoLogger records the fields of info messages
+type infoLogger struct {
+	mockLogger
+	mu      sync.Mutex
+	entries map[string][]map[string]interface{} // Message -> fields of each call
+}
+
+func (l *infoLogger) Info(ctx context.Context, msg string, fields map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.entries == nil {
+		l.entries = make(map[string][]map[string]interface{})
+	}
+	l.entries[msg] = append(l.entries[msg], fields)
+}
+
+func TestPackHandler_SolvePacks_LogSolveResults(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			logger := &infoLogger{}
+			packHandler := NewPackHandler(usecase.NewDPSolver(), logger).WithLogSolveResults(enabled)
+			handler := CorrelationIDMiddleware(&mockLogger{})(http.HandlerFunc(packHandler.SolvePacks))
+
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(`{"sizes":[250,500],"amount":251}`))
+			req.Header.Set("X-Correlation-ID", "req-123")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			entries := logger.entries["solve completed"]
+			if !enabled {
+				if len(entries) != 0 {
+					t.Errorf("solve logged while disabled: %v", entries)
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 solve log, got %d", len(entries))
+			}
+
+			fields := entries[0]
+			if fields["correlation_id"] != "req-123" || !reflect.DeepEqual(fields["sizes"], []int{250, 500}) ||
+				fields["amount"] != 251 || fields["packs"] != 1 || fields["overage"] != 249 {
+				t.Errorf("fields = %v, want correlation_id, sizes, amount, packs and overage of the solve", fields)
+			}
+			if duration, ok := fields["duration_ms"].(float64); !ok || duration < 0 {
+				t.Errorf("duration_ms = %v, want a non-negative float", fields["duration_ms"])
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolvePacks_RecordCarriesOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want domain.SolveOptions
+	}{
+		{name: "defaults", body: `{"sizes":[250],"amount":250}`, want: domain.SolveOptions{}},
+		{
+			name: "solve options",
+			body: `{"sizes":[250],"amount":250,"max_overage":100,"priority":"packs_overage"}`,
+			want: domain.SolveOptions{domain.SolveOptionMaxOverage: "100", domain.SolveOptionPriority: "packs_overage"},
+		},
+		{name: "strict", body: `{"sizes":[250],"amount":250,"strict":true}`, want: domain.SolveOptions{"strict": "true"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &recordingRepository{id: 1, saved: make(chan interface{}, 1)}
+			handler := NewPackHandler(&mockSolver{
+				solution: &domain.Solution{Breakdown: map[int]int{250: 1}, Packs: 1, Amount: 250},
+			}, &mockLogger{}).WithRepository(repo).WithStrictSolver(usecase.NewDPSolver())
+
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.SolvePacks(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			select {
+			case record := <-repo.saved:
+				got := record.(map[string]interface{})["options"]
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("record options = %#v, want %#v", got, tt.want)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("calculation was not saved")
+			}
+		})
+	}
+}
+
+// slowSolver blocks until the context ends, like a solver stuck on a pathological input
+type slowSolver struct{}
+
+func (s *slowSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestPackHandler_SolvePacks_SolveTimeout(t *testing.T) {
+	handler := NewPackHandler(&slowSolver{}, &mockLogger{}).WithSolveTimeout(20 * time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodPost, "/packs/solve", bytes.NewBufferString(`{"sizes":[250,500],"amount":251}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	start := time.Now()
+	handler.SolvePacks(w, req)
+
+	if w.Code != http.StatusRequestTimeout {
+		t.Fatalf("expected status 408, got %d: %s", w.Code, w.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("solve budget not enforced: request took %v", elapsed)
+	}
+	var resp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Message != "request timeout" {
+		t.Errorf("message = %q, want %q", resp.Message, "request timeout")
+	}
+}
+
+func TestPackHandler_SolvePacks_Diagnostics(t *testing.T) {
+	tests := []struct {
+		name       string
+		solver     domain.Solver
+		query      string
+		body       string
+		wantStatus int
+		wantUnused []int // nil means no diagnostics block expected
+	}{
+		{
+			name:       "dominated sizes reported",
+			solver:     usecase.NewDPSolver(),
+			query:      "?diagnostics=true",
+			body:       `{"sizes":[250,500,1000,2000,5000],"amount":12001}`,
+			wantStatus: http.StatusOK,
+			wantUnused: []int{500, 1000},
+		},
+		{
+			name:       "off by default",
+			solver:     usecase.NewDPSolver(),
+			body:       `{"sizes":[250,500,1000,2000,5000],"amount":12001}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid flag",
+			solver:     usecase.NewDPSolver(),
+			query:      "?diagnostics=maybe",
+			body:       `{"sizes":[250,500],"amount":251}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "with amount range",
+			solver:     usecase.NewDPSolver(),
+			query:      "?diagnostics=true",
+			body:       `{"sizes":[250,500],"amount_min":251,"amount_max":300}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "unsupported solver",
+			solver:     &mockSolver{solution: domain.NewSolution(map[int]int{500: 1}, 251)},
+			query:      "?diagnostics=true",
+			body:       `{"sizes":[250,500],"amount":251}`,
+			wantStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPackHandler(tt.solver, &mockLogger{})
+
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve"+tt.query, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			resp := decodeSolveResponse(t, w.Body)
+			if tt.wantUnused == nil {
+				if resp.Diagnostics != nil {
+					t.Errorf("expected no diagnostics, got %+v", resp.Diagnostics)
+				}
+				return
+			}
+			if resp.Diagnostics == nil {
+				t.Fatal("expected diagnostics block")
+			}
+			if !reflect.DeepEqual(resp.Diagnostics.UnusedSizes, tt.wantUnused) {
+				t.Errorf("unused_sizes = %v, want %v", resp.Diagnostics.UnusedSizes, tt.wantUnused)
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolvePacks_TopK(t *testing.T) {
+	tests := []struct {
+		name       string
+		solver     domain.Solver
+		maxTopK    int // 0 keeps the default cap
+		query      string
+		body       string
+		wantStatus int
+		want       []AlternativeSolution // nil means no alternatives expected
+	}{
+		{
+			name:       "next-best solutions",
+			solver:     usecase.NewDPSolver(),
+			query:      "?k=3",
+			body:       `{"sizes":[250,500,1000,2000,5000],"amount":12001}`,
+			wantStatus: http.StatusOK,
+			want: []AlternativeSolution{
+				{Solution: map[int]int{5000: 2, 1000: 2, 250: 1}, Overage: 249, Packs: 5},
+				{Solution: map[int]int{5000: 2, 1000: 1, 500: 2, 250: 1}, Overage: 249, Packs: 6},
+			},
+		},
+		{
+			name:       "fewer than k",
+			solver:     usecase.NewDPSolver(),
+			query:      "?k=10",
+			body:       `{"sizes":[250,500],"amount":251}`,
+			wantStatus: http.StatusOK,
+			want:       []AlternativeSolution{{Solution: map[int]int{250: 2}, Overage: 249, Packs: 2}},
+		},
+		{
+			name:       "k of one",
+			solver:     usecase.NewDPSolver(),
+			query:      "?k=1",
+			body:       `{"sizes":[250,500],"amount":251}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "k above the cap",
+			solver:     usecase.NewDPSolver(),
+			query:      "?k=11",
+			body:       `{"sizes":[250,500],"amount":251}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "k of zero",
+			solver:     usecase.NewDPSolver(),
+			query:      "?k=0",
+			body:       `{"sizes":[250,500],"amount":251}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "k at a configured cap",
+			solver:     usecase.NewDPSolver(),
+			maxTopK:    2,
+			query:      "?k=2",
+			body:       `{"sizes":[250,500,1000,2000,5000],"amount":12001}`,
+			wantStatus: http.StatusOK,
+			want:       []AlternativeSolution{{Solution: map[int]int{5000: 2, 1000: 2, 250: 1}, Overage: 249, Packs: 5}},
+		},
+		{
+			name:       "k above a configured cap",
+			solver:     usecase.NewDPSolver(),
+			maxTopK:    2,
+			query:      "?k=3",
+			body:       `{"sizes":[250,500,1000,2000,5000],"amount":12001}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "invalid k",
+			solver:     usecase.NewDPSolver(),
+			query:      "?k=three",
+			body:       `{"sizes":[250,500],"amount":251}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "nested shape",
+			solver:     usecase.NewDPSolver(),
+			query:      "?k=3&shape=nested",
+			body:       `{"sizes":[250,500],"amount":251}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "with max overage",
+			solver:     usecase.NewDPSolver(),
+			query:      "?k=3",
+			body:       `{"sizes":[250,500],"amount":251,"max_overage":300}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "unsupported solver",
+			solver:     &mockSolver{solution: domain.NewSolution(map[int]int{500: 1}, 251)},
+			query:      "?k=3",
+			body:       `{"sizes":[250,500],"amount":251}`,
+			wantStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPackHandler(tt.solver, &mockLogger{})
+			if tt.maxTopK > 0 {
+				handler.WithMaxTopK(tt.maxTopK)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve"+tt.query, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			resp := decodeSolveResponse(t, w.Body)
+			if resp.Packs == 0 || resp.Overage != 249 {
+				t.Errorf("unexpected main solution: %+v", resp)
+			}
+			if !reflect.DeepEqual(resp.Alternatives, tt.want) {
+				t.Errorf("alternatives = %+v, want %+v", resp.Alternatives, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolvePacks_Priority(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority string
+		want     map[int]int
+	}{
+		{name: "default", want: map[int]int{500: 1, 250: 1}},
+		{name: "overage first", priority: "overage_packs", want: map[int]int{500: 1, 250: 1}},
+		{name: "packs first", priority: "packs_overage", want: map[int]int{1000: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+			body, _ := json.Marshal(SolveRequest{Sizes: []int{250, 500, 1000}, Amount: 750, Priority: tt.priority})
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			resp := decodeSolveResponse(t, w.Body)
+			if !reflect.DeepEqual(resp.Solution, tt.want) {
+				t.Errorf("solution = %v, want %v", resp.Solution, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolvePacks_Costs(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       map[int]int
+	}{
+		{name: "without costs", body: `{"sizes":[250,5000],"amount":251}`, wantStatus: http.StatusOK, want: map[int]int{250: 2}},
+		{name: "bulk pack is cheaper", body: `{"sizes":[250,5000],"amount":251,"costs":{"250":100,"5000":10}}`, wantStatus: http.StatusOK, want: map[int]int{5000: 1}},
+		{name: "missing size", body: `{"sizes":[250,5000],"amount":251,"costs":{"250":100}}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.SolvePacks(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.want == nil {
+				return
+			}
+			resp := decodeSolveResponse(t, w.Body)
+			if !reflect.DeepEqual(resp.Solution, tt.want) {
+				t.Errorf("solution = %v, want %v", resp.Solution, tt.want)
+			}
+		})
+	}
+}
+
+// An amount equal to a size is a one-pack exact solution in every mode that
+// minimizes overage or packs; only costs can make smaller packs preferable
+func TestPackHandler_SolvePacks_AmountEqualsSizeModes(t *testing.T) {
+	onePack := func(amount int) map[int]int { return map[int]int{amount: 1} }
+
+	modes := []struct {
+		name    string
+		options string // Extra request fields
+		want    func(amount int) map[int]int
+	}{
+		{name: "default", want: onePack},
+		{name: "strict", options: `"strict":true`, want: onePack},
+		{name: "prefer exact", options: `"prefer_exact":true`, want: onePack},
+		{name: "under (no overage)", options: `"max_overage":0`, want: onePack},
+		{name: "packs first", options: `"priority":"packs_overage"`, want: onePack},
+		{name: "weighted, proportional costs", options: `"costs":{"250":1,"500":2,"1000":4}`, want: onePack},
+		{
+			name:    "weighted, small packs cheaper",
+			options: `"costs":{"250":1,"500":5,"1000":10}`,
+			want:    func(amount int) map[int]int { return map[int]int{250: amount / 250} },
+		},
+	}
+
+	for _, mode := range modes {
+		for _, amount := range []int{250, 500, 1000} {
+			t.Run(fmt.Sprintf("%s/%d", mode.name, amount), func(t *testing.T) {
+				handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+				body := fmt.Sprintf(`{"sizes":[250,500,1000],"amount":%d`, amount)
+				if mode.options != "" {
+					body += "," + mode.options
+				}
+				req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(body+"}"))
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+
+				handler.SolvePacks(w, req)
+
+				if w.Code != http.StatusOK {
+					t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+				}
+				resp := decodeSolveResponse(t, w.Body)
+				want := mode.want(amount)
+				if !reflect.DeepEqual(resp.Solution, want) || resp.Overage != 0 {
+					t.Errorf("solution = %v (overage %d), want %v (overage 0)", resp.Solution, resp.Overage, want)
+				}
+			})
+		}
+	}
+}
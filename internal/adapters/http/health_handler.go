@@ -0,0 +1,247 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/infra/postgres"
+)
+
+// readinessPingTimeout bounds each dependency ping of a readiness check
+// and the solver self-test of a status summary
+const readinessPingTimeout = 2 * time.Second
+
+// Subsystem names, as reported by Ready and Status
+const (
+	componentPostgres = "postgres"
+	componentRedis    = "redis"
+	componentSolver   = "solver"
+)
+
+// Subsystem states reported by Status
+const (
+	subsystemOK       = "ok"
+	subsystemDown     = "down"
+	subsystemDisabled = "disabled"
+)
+
+// Overall states reported by Status
+const (
+	statusOK       = "ok"
+	statusDegraded = "degraded"
+)
+
+// Solver self-test input (the documented 12001 example) and its known optimum
+var (
+	selfTestSizes     = []int{250, 500, 1000, 2000, 5000}
+	selfTestAmount    = 12001
+	selfTestBreakdown = map[int]int{5000: 2, 2000: 1, 250: 1}
+)
+
+// ComponentFailure describes a dependency that failed its readiness ping
+type ComponentFailure struct {
+	Component string `json:"component"`
+	Error     string `json:"error"`
+}
+
+// ReadinessResponse represents the result of a readiness check
+type ReadinessResponse struct {
+	Status string             `json:"status"`           // "ready" or "not ready"
+	Failed []ComponentFailure `json:"failed,omitempty"` // Set when not ready
+}
+
+// readinessCheck pings one dependency
+type readinessCheck struct {
+	component string
+	ping      func(ctx context.Context) error
+}
+
+// SubsystemStatus describes one subsystem in a status summary
+type SubsystemStatus struct {
+	Status string `json:"status"`          // "ok", "down" or "disabled"
+	Error  string `json:"error,omitempty"` // Set when down
+}
+
+// LoadStatus reports current in-flight work and queue depths
+type LoadStatus struct {
+	HTTPRequestsInFlight      int `json:"http_requests_in_flight"`
+	SolvesInFlight            int `json:"solves_in_flight"` // Tracked by the solve registry
+	CalculationSaveQueueDepth int `json:"calculation_save_queue_depth"`
+}
+
+// StatusResponse represents the health summary of all subsystems
+type StatusResponse struct {
+	Status        string                     `json:"status"` // "ok", or "degraded" when any subsystem is down
+	Version       string                     `json:"version"`
+	UptimeSeconds float64                    `json:"uptime_seconds"`
+	Subsystems    map[string]SubsystemStatus `json:"subsystems"` // postgres, redis, solver
+	Load          LoadStatus                 `json:"load"`
+}
+
+// HealthHandler handles readiness probes against the service dependencies
+// and the status summary
+type HealthHandler struct {
+	checks    []readinessCheck
+	logger    Logger
+	version   string
+	startedAt time.Time
+	solver    domain.Solver  // Optional, self-tested by Status
+	registry  *SolveRegistry // Optional, in-flight solves reported by Status
+}
+
+// NewHealthHandler creates a new health handler
+// db and redisClient are optional: a nil dependency is not checked
+func NewHealthHandler(db *sqlx.DB, redisClient *goredis.Client, logger Logger) *HealthHandler {
+	var checks []readinessCheck
+	if db != nil {
+		checks = append(checks, readinessCheck{
+			component: componentPostgres,
+			ping:      func(ctx context.Context) error { return postgres.Ping(ctx, db) },
+		})
+	}
+	if redisClient != nil {
+		checks = append(checks, readinessCheck{
+			component: componentRedis,
+			ping:      func(ctx context.Context) error { return redisClient.Ping(ctx).Err() },
+		})
+	}
+
+	return &HealthHandler{
+		checks:    checks,
+		logger:    logger,
+		startedAt: time.Now(),
+	}
+}
+
+// WithVersion sets the version reported by Status
+func (h *HealthHandler) WithVersion(version string) *HealthHandler {
+	h.version = version
+	return h
+}
+
+// WithSelfTest sets the solver Status runs a known input through
+// Pass the computing solver, not a cached one, so the test exercises it
+func (h *HealthHandler) WithSelfTest(solver domain.Solver) *HealthHandler {
+	h.solver = solver
+	return h
+}
+
+// WithSolveRegistry sets the registry whose size Status reports as in-flight solves
+func (h *HealthHandler) WithSolveRegistry(registry *SolveRegistry) *HealthHandler {
+	h.registry = registry
+	return h
+}
+
+// Ready handles GET /readyz
+// Pings every configured dependency; any failure returns 503 listing the
+// failed components, so the instance is taken out of load balancing
+// Liveness stays on /healthz, which checks nothing
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	failed := h.runChecks(ctx)
+	if len(failed) > 0 {
+		h.logger.Warn(ctx, "readiness check failed", map[string]interface{}{
+			"failed":         failed,
+			"correlation_id": GetCorrelationID(ctx),
+		})
+		respondJSON(w, r, h.logger, http.StatusServiceUnavailable, ReadinessResponse{Status: "not ready", Failed: failed})
+		return
+	}
+
+	respondJSON(w, r, h.logger, http.StatusOK, ReadinessResponse{Status: "ready"})
+}
+
+// Status handles GET /status
+// A single summary for dashboards: uptime, version, dependency pings (as
+// /readyz), a solver self-test and current load; always 200, with "degraded"
+// status when any subsystem is down
+func (h *HealthHandler) Status(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	subsystems := map[string]SubsystemStatus{
+		componentPostgres: {Status: subsystemDisabled},
+		componentRedis:    {Status: subsystemDisabled},
+		componentSolver:   {Status: subsystemDisabled},
+	}
+	for _, check := range h.checks {
+		subsystems[check.component] = SubsystemStatus{Status: subsystemOK}
+	}
+	for _, failure := range h.runChecks(ctx) {
+		subsystems[failure.Component] = SubsystemStatus{Status: subsystemDown, Error: failure.Error}
+	}
+	if h.solver != nil {
+		subsystems[componentSolver] = SubsystemStatus{Status: subsystemOK}
+		if err := h.selfTest(ctx); err != nil {
+			subsystems[componentSolver] = SubsystemStatus{Status: subsystemDown, Error: err.Error()}
+		}
+	}
+
+	response := StatusResponse{
+		Status:        statusOK,
+		Version:       h.version,
+		UptimeSeconds: time.Since(h.startedAt).Seconds(),
+		Subsystems:    subsystems,
+		Load: LoadStatus{
+			HTTPRequestsInFlight:      int(gaugeValue(httpRequestsInFlight)),
+			CalculationSaveQueueDepth: int(gaugeValue(calculationSaveQueueDepth)),
+		},
+	}
+	if h.registry != nil {
+		response.Load.SolvesInFlight = h.registry.Len()
+	}
+	for _, subsystem := range subsystems {
+		if subsystem.Status == subsystemDown {
+			response.Status = statusDegraded
+		}
+	}
+
+	respondJSON(w, r, h.logger, http.StatusOK, response)
+}
+
+// runChecks pings every configured dependency and returns the failures
+func (h *HealthHandler) runChecks(ctx context.Context) []ComponentFailure {
+	var failed []ComponentFailure
+	for _, check := range h.checks {
+		pingCtx, cancel := context.WithTimeout(ctx, readinessPingTimeout)
+		err := check.ping(pingCtx)
+		cancel()
+		if err != nil {
+			failed = append(failed, ComponentFailure{Component: check.component, Error: err.Error()})
+		}
+	}
+	return failed
+}
+
+// selfTest solves the self-test input and checks the known optimum
+func (h *HealthHandler) selfTest(ctx context.Context) error {
+	testCtx, cancel := context.WithTimeout(ctx, readinessPingTimeout)
+	defer cancel()
+
+	solution, err := h.solver.Solve(testCtx, selfTestSizes, selfTestAmount)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(solution.Breakdown, selfTestBreakdown) {
+		return fmt.Errorf("self-test: got %v for %d, want %v", solution.Breakdown, selfTestAmount, selfTestBreakdown)
+	}
+	return nil
+}
+
+// gaugeValue reads the current value of a gauge
+func gaugeValue(gauge prometheus.Gauge) float64 {
+	var metric dto.Metric
+	if err := gauge.Write(&metric); err != nil {
+		return 0
+	}
+	return metric.GetGauge().GetValue()
+}
@@ -0,0 +1,215 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+// stubPing returns a ping that always returns err
+func stubPing(err error) func(ctx context.Context) error {
+	return func(ctx context.Context) error { return err }
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     []readinessCheck
+		wantStatus int
+		wantFailed []string
+	}{
+		{name: "no dependencies", wantStatus: http.StatusOK},
+		{
+			name: "all healthy",
+			checks: []readinessCheck{
+				{component: componentPostgres, ping: stubPing(nil)},
+				{component: componentRedis, ping: stubPing(nil)},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "redis down",
+			checks: []readinessCheck{
+				{component: componentPostgres, ping: stubPing(nil)},
+				{component: componentRedis, ping: stubPing(errors.New("connection refused"))},
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantFailed: []string{componentRedis},
+		},
+		{
+			name: "both down",
+			checks: []readinessCheck{
+				{component: componentPostgres, ping: stubPing(errors.New("connection refused"))},
+				{component: componentRedis, ping: stubPing(errors.New("i/o timeout"))},
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantFailed: []string{componentPostgres, componentRedis},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHealthHandler(nil, nil, &mockLogger{})
+			handler.checks = tt.checks
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			w := httptest.NewRecorder()
+			handler.Ready(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			var resp ReadinessResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(resp.Failed) != len(tt.wantFailed) {
+				t.Fatalf("failed = %+v, want components %v", resp.Failed, tt.wantFailed)
+			}
+			for i, component := range tt.wantFailed {
+				if resp.Failed[i].Component != component || resp.Failed[i].Error == "" {
+					t.Errorf("failed[%d] = %+v, want component %q with an error", i, resp.Failed[i], component)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthHandler_Ready_UnreachableRedis(t *testing.T) {
+	// Nothing listens on port 1; no retries keep the test fast
+	client := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer client.Close()
+
+	handler := NewHealthHandler(nil, client, &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	w := httptest.NewRecorder()
+	handler.Ready(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", w.Code)
+	}
+	var resp ReadinessResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Failed) != 1 || resp.Failed[0].Component != componentRedis {
+		t.Errorf("failed = %+v, want only %q", resp.Failed, componentRedis)
+	}
+}
+
+func TestHealthHandler_Status(t *testing.T) {
+	tests := []struct {
+		name           string
+		checks         []readinessCheck
+		solver         domain.Solver
+		wantStatus     string
+		wantSubsystems map[string]string
+	}{
+		{
+			name:       "nothing configured",
+			wantStatus: statusOK,
+			wantSubsystems: map[string]string{
+				componentPostgres: subsystemDisabled,
+				componentRedis:    subsystemDisabled,
+				componentSolver:   subsystemDisabled,
+			},
+		},
+		{
+			name: "all healthy",
+			checks: []readinessCheck{
+				{component: componentPostgres, ping: stubPing(nil)},
+				{component: componentRedis, ping: stubPing(nil)},
+			},
+			solver:     usecase.NewDPSolver(),
+			wantStatus: statusOK,
+			wantSubsystems: map[string]string{
+				componentPostgres: subsystemOK,
+				componentRedis:    subsystemOK,
+				componentSolver:   subsystemOK,
+			},
+		},
+		{
+			name: "redis and solver down",
+			checks: []readinessCheck{
+				{component: componentPostgres, ping: stubPing(nil)},
+				{component: componentRedis, ping: stubPing(errors.New("connection refused"))},
+			},
+			solver:     &mockSolver{err: domain.ErrNoSolution},
+			wantStatus: statusDegraded,
+			wantSubsystems: map[string]string{
+				componentPostgres: subsystemOK,
+				componentRedis:    subsystemDown,
+				componentSolver:   subsystemDown,
+			},
+		},
+		{
+			name:       "wrong self-test result",
+			solver:     &mockSolver{solution: domain.NewSolution(map[int]int{5000: 3}, 12001)},
+			wantStatus: statusDegraded,
+			wantSubsystems: map[string]string{
+				componentPostgres: subsystemDisabled,
+				componentRedis:    subsystemDisabled,
+				componentSolver:   subsystemDown,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewSolveRegistry(10, time.Minute, &mockLogger{})
+			registry.Register(context.Background(), "in-flight", func() {})
+			handler := NewHealthHandler(nil, nil, &mockLogger{}).
+				WithVersion("1.2.3").
+				WithSolveRegistry(registry)
+			handler.checks = tt.checks
+			if tt.solver != nil {
+				handler.WithSelfTest(tt.solver)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/status", nil)
+			w := httptest.NewRecorder()
+			handler.Status(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", w.Code)
+			}
+			var resp StatusResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if resp.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", resp.Status, tt.wantStatus)
+			}
+			if resp.Version != "1.2.3" || resp.UptimeSeconds < 0 {
+				t.Errorf("version = %q, uptime = %v", resp.Version, resp.UptimeSeconds)
+			}
+			if resp.Load.SolvesInFlight != 1 {
+				t.Errorf("solves in flight = %d, want 1", resp.Load.SolvesInFlight)
+			}
+			if len(resp.Subsystems) != len(tt.wantSubsystems) {
+				t.Errorf("subsystems = %+v, want %v", resp.Subsystems, tt.wantSubsystems)
+			}
+			for name, want := range tt.wantSubsystems {
+				got := resp.Subsystems[name]
+				if got.Status != want {
+					t.Errorf("%s status = %q, want %q", name, got.Status, want)
+				}
+				if (got.Status == subsystemDown) != (got.Error != "") {
+					t.Errorf("%s: error %q does not match status %q", name, got.Error, got.Status)
+				}
+			}
+		})
+	}
+}
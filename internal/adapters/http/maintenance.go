@@ -0,0 +1,75 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaintenanceRetryAfter - Retry-After sent while maintenance mode is on
+const DefaultMaintenanceRetryAfter = 60 * time.Second
+
+// maintenancePathPrefix - business endpoints rejected during maintenance
+const maintenancePathPrefix = "/packs/"
+
+// MaintenanceMode holds the maintenance flag; safe for concurrent use
+// so it can be flipped at runtime while requests are being served
+type MaintenanceMode struct {
+	enabled    atomic.Bool
+	retryAfter time.Duration
+}
+
+// NewMaintenanceMode creates a maintenance flag with the given initial state
+func NewMaintenanceMode(enabled bool) *MaintenanceMode {
+	m := &MaintenanceMode{retryAfter: DefaultMaintenanceRetryAfter}
+	m.enabled.Store(enabled)
+	return m
+}
+
+// WithRetryAfter sets the Retry-After advertised while maintenance mode is on
+func (m *MaintenanceMode) WithRetryAfter(retryAfter time.Duration) *MaintenanceMode {
+	if retryAfter > 0 {
+		m.retryAfter = retryAfter
+	}
+	return m
+}
+
+// Enabled reports whether maintenance mode is on
+func (m *MaintenanceMode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// SetEnabled turns maintenance mode on or off
+func (m *MaintenanceMode) SetEnabled(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+// MaintenanceMiddleware rejects /packs/* requests with 503 and Retry-After while
+// maintenance mode is on; other paths (/healthz, /metrics, ...) are always served
+// Chi-compatible middleware
+func MaintenanceMiddleware(mode *MaintenanceMode, logger Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if !mode.Enabled() || !strings.HasPrefix(r.URL.Path, maintenancePathPrefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			logger.Info(ctx, "request rejected during maintenance", map[string]interface{}{
+				"path":           r.URL.Path,
+				"correlation_id": GetCorrelationID(ctx),
+			})
+
+			retryAfter := int(mode.retryAfter.Round(time.Second) / time.Second)
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			respondError(w, r, logger, http.StatusServiceUnavailable, "service under maintenance", nil)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
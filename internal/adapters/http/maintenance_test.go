@@ -0,0 +1,54 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name           string
+		enabled        bool
+		path           string
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{name: "on rejects solve", enabled: true, path: "/packs/solve", wantStatus: http.StatusServiceUnavailable, wantRetryAfter: "120"},
+		{name: "on rejects stream", enabled: true, path: "/packs/solve/stream", wantStatus: http.StatusServiceUnavailable, wantRetryAfter: "120"},
+		{name: "on keeps healthz", enabled: true, path: "/healthz", wantStatus: http.StatusOK},
+		{name: "on keeps metrics", enabled: true, path: "/metrics", wantStatus: http.StatusOK},
+		{name: "off serves solve", enabled: false, path: "/packs/solve", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := NewMaintenanceMode(tt.enabled).WithRetryAfter(2 * time.Minute)
+			handler := MaintenanceMiddleware(mode, &mockLogger{})(ok)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			if tt.wantStatus == http.StatusServiceUnavailable {
+				var resp ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.Message != "service under maintenance" {
+					t.Errorf("message = %q, want %q", resp.Message, "service under maintenance")
+				}
+			}
+		})
+	}
+}
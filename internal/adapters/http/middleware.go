@@ -6,9 +6,10 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/metrics"
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // contextKey type for context keys
@@ -21,31 +22,64 @@ const (
 
 // Prometheus metrics
 var (
-	httpRequestsTotal = promauto.NewCounterVec(
+	httpRequestsTotal = metrics.Register(prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "http_requests_total",
 			Help: "Total number of HTTP requests",
 		},
 		[]string{"method", "path", "status"},
-	)
+	))
 
-	httpRequestDuration = promauto.NewHistogramVec(
+	httpRequestDuration = metrics.Register(prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "http_request_duration_seconds",
 			Help:    "HTTP request duration in seconds",
 			Buckets: prometheus.DefBuckets,
 		},
 		[]string{"method", "path"},
-	)
+	))
 
-	httpRequestsInFlight = promauto.NewGauge(
+	httpRequestsInFlight = metrics.Register(prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "http_requests_in_flight",
 			Help: "Current number of HTTP requests being served",
 		},
-	)
+	))
+
+	calculationSaveQueueDepth = metrics.Register(prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "calculation_save_queue_depth",
+			Help: "Current number of calculations waiting to be saved asynchronously",
+		},
+	))
+
+	calculationSaveEnqueuedTotal = metrics.Register(prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "calculation_save_enqueued_total",
+			Help: "Total number of calculations enqueued for asynchronous saving",
+		},
+	))
+
+	calculationSaveDroppedTotal = metrics.Register(prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "calculation_save_dropped_total",
+			Help: "Total number of calculations not saved because the asynchronous save queue was full",
+		},
+	))
 )
 
+// MetricsHandler serves the default registry for Prometheus scraping
+// OpenMetrics is negotiated for clients that request it via the Accept header
+// (application/openmetrics-text), enabling exemplars; others get the text format
+func MetricsHandler() http.Handler {
+	return promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		}),
+	)
+}
+
 // CorrelationIDMiddleware adds a correlation ID to each request
 // If the X-Correlation-ID header is present, its value is used
 // Otherwise, a new UUID is generated
@@ -162,6 +196,21 @@ func (rw *responseWriter) Write(data []byte) (int, error) {
 	return rw.ResponseWriter.Write(data)
 }
 
+// Flush implements http.Flusher so streaming handlers (SSE) work behind this middleware
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		if !rw.written {
+			rw.WriteHeader(http.StatusOK)
+		}
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // RecoveryMiddleware recovers from panics
 // Chi-compatible middleware
 func RecoveryMiddleware(logger Logger) func(http.Handler) http.Handler {
@@ -177,7 +226,7 @@ func RecoveryMiddleware(logger Logger) func(http.Handler) http.Handler {
 
 					w.Header().Set("Content-Type", "application/json")
 					w.WriteHeader(http.StatusInternalServerError)
-					w.Write([]byte(`{"error":"Internal Server Error","message":"an unexpected error occurred"}`))
+					w.Write([]byte(`{"error":"Internal Server Error","code":"` + CodeInternal + `","message":"an unexpected error occurred"}`))
 				}
 			}()
 
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	w := httptest.NewRecorder()
+	OpenAPIHandler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var doc struct {
+		OpenAPI    string                     `json:"openapi"`
+		Paths      map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+
+	if doc.OpenAPI == "" {
+		t.Error("missing openapi version")
+	}
+	if _, ok := doc.Paths["/packs/solve"]; !ok {
+		t.Errorf("paths = %v, want /packs/solve", doc.Paths)
+	}
+
+	// Every JSON field of the request and response types is described
+	schemaFields := map[string]interface{}{
+		"SolveRequest":  SolveRequest{},
+		"SolveResponse": SolveResponse{},
+		"ErrorResponse": ErrorResponse{},
+	}
+	for name, value := range schemaFields {
+		schema, ok := doc.Components.Schemas[name]
+		if !ok {
+			t.Errorf("missing schema %s", name)
+			continue
+		}
+		for _, field := range jsonFieldNames(t, value) {
+			if _, ok := schema.Properties[field]; !ok {
+				t.Errorf("schema %s is missing property %q", name, field)
+			}
+		}
+	}
+}
+
+// jsonFieldNames returns the JSON keys of a struct's fields, including omitempty ones
+func jsonFieldNames(t *testing.T, value interface{}) []string {
+	t.Helper()
+
+	typ := reflect.TypeOf(value)
+	names := make([]string, 0, typ.NumField())
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		t.Fatalf("%T has no JSON fields", value)
+	}
+	return names
+}
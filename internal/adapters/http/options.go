@@ -0,0 +1,141 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+// OptionLimits holds configured bounds for solve options
+type OptionLimits struct {
+	// MaxOverage clamps max_overage; larger caps never change the result
+	// since the solver's natural bound is smallest size - 1
+	MaxOverage int
+}
+
+// DefaultOptionLimits returns limits that only clamp meaningless values
+func DefaultOptionLimits() OptionLimits {
+	return OptionLimits{
+		MaxOverage: domain.MaxPackSize - 1,
+	}
+}
+
+// SolveOptions holds validated solve options with defaults applied
+type SolveOptions struct {
+	PreferExact bool // Annotate the response with "exact"
+	Strict      bool // Accept only exact packings
+	MaxOverage  *int // Overage cap (nil = solver's natural bound), clamped to OptionLimits
+	LotSize     *int // Also solve for the amount rounded up to a multiple of LotSize
+
+	Priority domain.Priority // Order of the optimization criteria
+	Costs    map[int]float64 // Per-pack costs to minimize (nil = minimize overage)
+
+	Algorithm string // Registered solver to use ("" = the default DP solver)
+}
+
+// ParseAndValidateOptions validates the options of a solve request against limits
+// Returns the options with defaults applied and values clamped, or an errors.Join
+// of *domain.ValidationError listing every offending option
+// Expects sizes and amount (or the amount range) to be validated already
+func ParseAndValidateOptions(req *SolveRequest, limits OptionLimits) (*SolveOptions, error) {
+	opts := &SolveOptions{
+		PreferExact: req.PreferExact,
+		Strict:      req.Strict,
+	}
+	var errs []error
+
+	if req.MaxOverage != nil {
+		maxOverage := *req.MaxOverage
+		switch {
+		case maxOverage < 0:
+			errs = append(errs, domain.NewValidationError("max_overage", maxOverage, "must not be negative"))
+		case req.isRange():
+			errs = append(errs, domain.NewValidationError("max_overage", maxOverage, "cannot be combined with an amount range"))
+		default:
+			if limits.MaxOverage > 0 && maxOverage > limits.MaxOverage {
+				maxOverage = limits.MaxOverage
+			}
+			opts.MaxOverage = &maxOverage
+		}
+	}
+
+	if req.LotSize != nil {
+		lotSize := *req.LotSize
+		switch {
+		case lotSize <= 0:
+			errs = append(errs, domain.NewValidationError("lot_size", lotSize, "must be greater than 0"))
+		case req.isRange():
+			errs = append(errs, domain.NewValidationError("lot_size", lotSize, "cannot be combined with an amount range"))
+		case lotSize > domain.MaxAmount || roundUpToLot(req.Amount, lotSize) > domain.MaxAmount:
+			errs = append(errs, domain.NewValidationError("lot_size", lotSize, "rounded amount exceeds maximum"))
+		default:
+			opts.LotSize = &lotSize
+		}
+	}
+
+	if req.Priority != "" {
+		priority, err := domain.ParsePriority(req.Priority)
+		switch {
+		case err != nil:
+			errs = append(errs, domain.NewValidationError("priority", req.Priority,
+				fmt.Sprintf("must be %s or %s", domain.PriorityOveragePacks, domain.PriorityPacksOverage)))
+		case priority != domain.PriorityOveragePacks && req.isRange():
+			errs = append(errs, domain.NewValidationError("priority", req.Priority, "cannot be combined with an amount range"))
+		case priority != domain.PriorityOveragePacks && req.Strict:
+			errs = append(errs, domain.NewValidationError("priority", req.Priority, "cannot be combined with strict"))
+		case priority != domain.PriorityOveragePacks && req.PreferExact:
+			// Fewest packs first can skip an exact packing prefer_exact promises
+			errs = append(errs, domain.NewValidationError("priority", req.Priority, "cannot be combined with prefer_exact"))
+		default:
+			opts.Priority = priority
+		}
+	}
+
+	if len(req.Costs) > 0 {
+		switch {
+		case req.isRange():
+			errs = append(errs, domain.NewValidationError("costs", req.Costs, "cannot be combined with an amount range"))
+		case req.Strict:
+			errs = append(errs, domain.NewValidationError("costs", req.Costs, "cannot be combined with strict"))
+		case req.Priority != "" && req.Priority != domain.PriorityOveragePacks.String():
+			errs = append(errs, domain.NewValidationError("costs", req.Costs, "cannot be combined with priority "+req.Priority))
+		case req.PreferExact:
+			// The cheapest packing may have overage while an exact one exists
+			errs = append(errs, domain.NewValidationError("costs", req.Costs, "cannot be combined with prefer_exact"))
+		default:
+			if err := domain.ValidatePackCosts(req.Sizes, req.Costs); err != nil {
+				errs = append(errs, domain.NewValidationError("costs", req.Costs, err.Error()))
+			} else {
+				opts.Costs = req.Costs
+			}
+		}
+	}
+
+	// Other algorithms implement only plain Solve and ignore solve options
+	if req.Algorithm != "" && req.Algorithm != usecase.AlgorithmDP {
+		switch {
+		case req.isRange():
+			errs = append(errs, domain.NewValidationError("algorithm", req.Algorithm, "cannot be combined with an amount range"))
+		case req.Strict:
+			errs = append(errs, domain.NewValidationError("algorithm", req.Algorithm, "cannot be combined with strict"))
+		case req.MaxOverage != nil || len(req.Costs) > 0 || (req.Priority != "" && req.Priority != domain.PriorityOveragePacks.String()):
+			errs = append(errs, domain.NewValidationError("algorithm", req.Algorithm, "cannot be combined with max_overage, priority or costs"))
+		case req.PreferExact:
+			// Heuristics can miss an exact packing prefer_exact promises
+			errs = append(errs, domain.NewValidationError("algorithm", req.Algorithm, "cannot be combined with prefer_exact"))
+		default:
+			opts.Algorithm = req.Algorithm
+		}
+	}
+
+	if req.Strict && req.isRange() {
+		errs = append(errs, domain.NewValidationError("strict", req.Strict, "cannot be combined with an amount range"))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return opts, nil
+}
@@ -0,0 +1,161 @@
+package http
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestParseAndValidateOptions(t *testing.T) {
+	limits := OptionLimits{MaxOverage: 100}
+
+	t.Run("defaults", func(t *testing.T) {
+		opts, err := ParseAndValidateOptions(&SolveRequest{Sizes: []int{5}, Amount: 10}, limits)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if opts.PreferExact || opts.Strict || opts.MaxOverage != nil || opts.LotSize != nil {
+			t.Errorf("expected zero options, got %+v", opts)
+		}
+	})
+
+	t.Run("clamps max_overage", func(t *testing.T) {
+		opts, err := ParseAndValidateOptions(&SolveRequest{Sizes: []int{5}, Amount: 10, MaxOverage: intPtr(500)}, limits)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if opts.MaxOverage == nil || *opts.MaxOverage != 100 {
+			t.Errorf("MaxOverage = %v, want 100", opts.MaxOverage)
+		}
+	})
+
+	t.Run("passes valid options through", func(t *testing.T) {
+		req := &SolveRequest{Sizes: []int{5}, Amount: 10, PreferExact: true, Strict: true, MaxOverage: intPtr(3), LotSize: intPtr(4), Priority: "overage_packs"}
+		opts, err := ParseAndValidateOptions(req, limits)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !opts.PreferExact || !opts.Strict || *opts.MaxOverage != 3 || *opts.LotSize != 4 {
+			t.Errorf("unexpected options %+v", opts)
+		}
+	})
+
+	t.Run("parses priority", func(t *testing.T) {
+		opts, err := ParseAndValidateOptions(&SolveRequest{Sizes: []int{5}, Amount: 10, Priority: "packs_overage"}, limits)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if opts.Priority != domain.PriorityPacksOverage {
+			t.Errorf("Priority = %v, want packs_overage", opts.Priority)
+		}
+	})
+
+	t.Run("passes costs through", func(t *testing.T) {
+		opts, err := ParseAndValidateOptions(&SolveRequest{Sizes: []int{5, 7}, Amount: 10, Costs: map[int]float64{5: 1, 7: 1.5}}, limits)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(opts.Costs) != 2 || opts.Costs[7] != 1.5 {
+			t.Errorf("Costs = %v, want map[5:1 7:1.5]", opts.Costs)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		req        SolveRequest
+		wantFields []string
+	}{
+		{
+			name:       "negative max_overage and zero lot_size",
+			req:        SolveRequest{Sizes: []int{5}, Amount: 10, MaxOverage: intPtr(-1), LotSize: intPtr(0)},
+			wantFields: []string{"max_overage", "lot_size"},
+		},
+		{
+			name:       "all options combined with a range",
+			req:        SolveRequest{Sizes: []int{5}, AmountMin: 10, AmountMax: 20, Strict: true, MaxOverage: intPtr(1), LotSize: intPtr(2)},
+			wantFields: []string{"max_overage", "lot_size", "strict"},
+		},
+		{
+			name:       "unknown priority",
+			req:        SolveRequest{Sizes: []int{5}, Amount: 10, Priority: "cheapest"},
+			wantFields: []string{"priority"},
+		},
+		{
+			name:       "packs_overage with strict",
+			req:        SolveRequest{Sizes: []int{5}, Amount: 10, Strict: true, Priority: "packs_overage"},
+			wantFields: []string{"priority"},
+		},
+		{
+			name:       "packs_overage with prefer_exact",
+			req:        SolveRequest{Sizes: []int{2, 7}, Amount: 6, PreferExact: true, Priority: "packs_overage"},
+			wantFields: []string{"priority"},
+		},
+		{
+			name:       "costs missing a size",
+			req:        SolveRequest{Sizes: []int{5, 7}, Amount: 10, Costs: map[int]float64{5: 1}},
+			wantFields: []string{"costs"},
+		},
+		{
+			name:       "costs with packs_overage",
+			req:        SolveRequest{Sizes: []int{5}, Amount: 10, Priority: "packs_overage", Costs: map[int]float64{5: 1}},
+			wantFields: []string{"costs"},
+		},
+		{
+			name:       "costs with strict",
+			req:        SolveRequest{Sizes: []int{5}, Amount: 10, Strict: true, Costs: map[int]float64{5: 1}},
+			wantFields: []string{"costs"},
+		},
+		{
+			name:       "costs with prefer_exact",
+			req:        SolveRequest{Sizes: []int{250, 500}, Amount: 500, PreferExact: true, Costs: map[int]float64{250: 1, 500: 5}},
+			wantFields: []string{"costs"},
+		},
+		{
+			name:       "algorithm with strict",
+			req:        SolveRequest{Sizes: []int{5}, Amount: 10, Strict: true, Algorithm: "greedy"},
+			wantFields: []string{"algorithm"},
+		},
+		{
+			name:       "algorithm with max_overage",
+			req:        SolveRequest{Sizes: []int{5}, Amount: 10, MaxOverage: intPtr(3), Algorithm: "greedy"},
+			wantFields: []string{"algorithm"},
+		},
+		{
+			name:       "algorithm with prefer_exact",
+			req:        SolveRequest{Sizes: []int{5}, Amount: 10, PreferExact: true, Algorithm: "greedy"},
+			wantFields: []string{"algorithm"},
+		},
+		{
+			name:       "lot_size rounding past maximum",
+			req:        SolveRequest{Sizes: []int{5}, Amount: domain.MaxAmount, LotSize: intPtr(7)},
+			wantFields: []string{"lot_size"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseAndValidateOptions(&tt.req, limits)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			details := validationErrorDetails(err)
+			if len(details) != len(tt.wantFields) {
+				t.Fatalf("expected %d errors, got %v", len(tt.wantFields), details)
+			}
+			for i, field := range tt.wantFields {
+				if details[i]["field"] != field {
+					t.Errorf("error %d field = %v, want %s", i, details[i]["field"], field)
+				}
+			}
+
+			var validationErr *domain.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Errorf("expected *domain.ValidationError in %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,530 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// maxCSVImportSize - maximum accepted size of a CSV upload (1 MiB)
+const maxCSVImportSize = 1 << 20
+
+// ImportedPackSet describes a CSV row that was stored
+type ImportedPackSet struct {
+	Line  int    `json:"line"`
+	ID    *int64 `json:"id,omitempty"`
+	Name  string `json:"name"`
+	Sizes []int  `json:"sizes"`
+}
+
+// ImportRowError describes a CSV row that was rejected
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportCSVResponse represents the result of a CSV import
+type ImportCSVResponse struct {
+	Imported []ImportedPackSet `json:"imported"`
+	Errors   []ImportRowError  `json:"errors"`
+}
+
+// PackSetRequest represents the request body for creating or replacing a pack set
+type PackSetRequest struct {
+	Name          string                    `json:"name"`
+	Sizes         []int                     `json:"sizes"`
+	Dimensions    map[int]domain.Dimensions `json:"dimensions,omitempty"`     // Optional, pack size -> outer dimensions
+	DefaultAmount *int                      `json:"default_amount,omitempty"` // Optional, amount used when a solve omits one
+}
+
+// PackSetResponse represents a stored pack set
+type PackSetResponse struct {
+	ID            int64                     `json:"id"`
+	Name          string                    `json:"name"`
+	Sizes         []int                     `json:"sizes"`
+	Dimensions    map[int]domain.Dimensions `json:"dimensions,omitempty"`
+	DefaultAmount *int                      `json:"default_amount,omitempty"`
+}
+
+// PackSetsResponse represents a page of stored pack sets
+type PackSetsResponse struct {
+	PackSets []PackSetResponse `json:"pack_sets"`
+}
+
+// PackSetSolveRequest represents the request body for solving against a stored pack set
+type PackSetSolveRequest struct {
+	Amount *int `json:"amount,omitempty"` // Optional, defaults to the set's default amount
+}
+
+// PackSetHandler handles HTTP requests for stored pack size sets
+type PackSetHandler struct {
+	repository domain.PackSizeRepository
+	service    domain.SolverService // Optional, enables Solve
+	simulator  domain.Solver        // Optional, enables Simulate
+	history    AmountHistory        // Optional, past amounts for Simulate
+	logger     Logger
+
+	calculations CalculationHistory  // Optional, enables History
+	timeFormat   TimeFormat          // Serialization of calculated_at in History
+	recorder     CalculationRecorder // Optional, records Solve results against the set
+}
+
+// CalculationRecorder saves calculations solved against a stored pack set
+type CalculationRecorder interface {
+	RecordCalculation(ctx context.Context, sizes []int, packSetID *int64, solution *domain.Solution)
+}
+
+// NewPackSetHandler creates a new pack set handler
+func NewPackSetHandler(repository domain.PackSizeRepository, logger Logger) *PackSetHandler {
+	return &PackSetHandler{
+		repository: repository,
+		logger:     logger,
+		timeFormat: TimeFormatRFC3339,
+	}
+}
+
+// WithService sets the solver service used by Solve
+func (h *PackSetHandler) WithService(service domain.SolverService) *PackSetHandler {
+	h.service = service
+	return h
+}
+
+// WithCalculationRecorder sets where Solve records its calculations, linked to
+// the set so they show up in History (e.g. the PackHandler, sharing its save queue)
+func (h *PackSetHandler) WithCalculationRecorder(recorder CalculationRecorder) *PackSetHandler {
+	h.recorder = recorder
+	return h
+}
+
+// Create handles POST /packsets
+func (h *PackSetHandler) Create(w http.ResponseWriter, r *http.Request) {
+	packSet, ok := h.decodePackSet(w, r, nil)
+	if !ok {
+		return
+	}
+
+	created, err := h.repository.Create(r.Context(), packSet)
+	if err != nil {
+		h.respondRepositoryError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, h.logger, http.StatusCreated, newPackSetResponse(created))
+}
+
+// Get handles GET /packsets/{id}
+func (h *PackSetHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parseID(w, r)
+	if !ok {
+		return
+	}
+
+	packSet, err := h.repository.GetByID(r.Context(), id)
+	if err != nil {
+		h.respondRepositoryError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, h.logger, http.StatusOK, newPackSetResponse(packSet))
+}
+
+// List handles GET /packsets?limit=100&offset=0
+func (h *PackSetHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parsePagination(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	packSets, err := h.repository.List(r.Context(), limit, offset)
+	if err != nil {
+		h.respondRepositoryError(w, r, err)
+		return
+	}
+
+	response := PackSetsResponse{PackSets: make([]PackSetResponse, 0, len(packSets))}
+	for _, packSet := range packSets {
+		response.PackSets = append(response.PackSets, newPackSetResponse(packSet))
+	}
+
+	respondJSON(w, r, h.logger, http.StatusOK, response)
+}
+
+// Update handles PUT /packsets/{id}
+// Replaces the name, sizes and dimensions of an existing set
+func (h *PackSetHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parseID(w, r)
+	if !ok {
+		return
+	}
+
+	packSet, ok := h.decodePackSet(w, r, &id)
+	if !ok {
+		return
+	}
+
+	if err := h.repository.Update(r.Context(), packSet); err != nil {
+		h.respondRepositoryError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, h.logger, http.StatusOK, newPackSetResponse(packSet))
+}
+
+// Delete handles DELETE /packsets/{id}
+func (h *PackSetHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parseID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repository.Delete(r.Context(), id); err != nil {
+		h.respondRepositoryError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Solve handles POST /packsets/{id}/solve
+// Solves {"amount": N} against the sizes of the stored set; the response
+// carries total_volume when every size used has dimensions
+func (h *PackSetHandler) Solve(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		respondError(w, r, h.logger, http.StatusNotImplemented, "solving pack sets is not supported", nil)
+		return
+	}
+
+	id, ok := h.parseID(w, r)
+	if !ok {
+		return
+	}
+
+	// Breakdown format, as for /packs/solve
+	format := r.URL.Query().Get("format")
+	if !validBreakdownFormat(format) {
+		respondError(w, r, h.logger, http.StatusBadRequest, "unsupported breakdown format", map[string]interface{}{
+			"format":    format,
+			"supported": []string{formatList, formatMap},
+		})
+		return
+	}
+
+	var req PackSetSolveRequest
+	// An empty body solves for the set's default amount
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, r, h.logger, http.StatusBadRequest, "invalid JSON", map[string]interface{}{
+			"parse_error": err.Error(),
+		})
+		return
+	}
+
+	if req.Amount != nil {
+		if err := domain.ValidateAmount(*req.Amount); err != nil {
+			respondError(w, r, h.logger, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+				"field":   "amount",
+				"value":   *req.Amount,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	// Loaded here rather than through SolveWithPackSizeSet, as the
+	// dimensions are needed for the total volume
+	packSet, err := h.repository.GetByID(r.Context(), id)
+	if err != nil {
+		h.respondRepositoryError(w, r, err)
+		return
+	}
+
+	amount := req.Amount
+	if amount == nil {
+		amount = packSet.DefaultAmount
+	}
+	if amount == nil {
+		respondError(w, r, h.logger, http.StatusBadRequest, "amount is required: the pack set has no default amount", nil)
+		return
+	}
+
+	solution, err := h.service.SolveWithCache(r.Context(), packSet.Sizes, *amount)
+	if err != nil {
+		respondSolverError(w, r, h.logger, err)
+		return
+	}
+	solution.Normalize()
+
+	if h.recorder != nil {
+		h.recorder.RecordCalculation(r.Context(), packSet.Sizes, packSet.ID, solution)
+	}
+
+	response := SolveResponse{
+		Solution:      solution.Breakdown,
+		Overage:       solution.Overage,
+		Packs:         solution.Packs,
+		Amount:        solution.Amount,
+		TotalItems:    solution.TotalItems(),
+		DistinctSizes: solution.DistinctSizes(),
+		PackSet:       newPackSetRef(packSet),
+	}
+	if volume, ok := packSet.TotalVolume(solution.Breakdown); ok {
+		response.TotalVolume = &volume
+	}
+	if format == formatMap {
+		respondJSON(w, r, h.logger, http.StatusOK, response)
+		return
+	}
+	respondJSON(w, r, h.logger, http.StatusOK, newListSolveResponse(response, defaultLineOrder))
+}
+
+// parseID reads the {id} path parameter, writing a 400 response if it is invalid
+func (h *PackSetHandler) parseID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	raw := r.PathValue("id")
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		respondError(w, r, h.logger, http.StatusBadRequest, "id must be a positive integer", map[string]interface{}{
+			"id": raw,
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// decodePackSet decodes and validates a PackSetRequest body into a PackSizeSet with the given id
+// Writes an error response and returns false on failure
+func (h *PackSetHandler) decodePackSet(w http.ResponseWriter, r *http.Request, id *int64) (*domain.PackSizeSet, bool) {
+	var req PackSetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, r, h.logger, http.StatusBadRequest, "invalid JSON", map[string]interface{}{
+			"parse_error": err.Error(),
+		})
+		return nil, false
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		respondError(w, r, h.logger, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+			"field":   "name",
+			"value":   req.Name,
+			"message": "must not be empty",
+		})
+		return nil, false
+	}
+
+	packSet, err := domain.NewPackSizeSet(req.Sizes, id, &name)
+	if err != nil {
+		respondError(w, r, h.logger, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+			"field":   "sizes",
+			"value":   req.Sizes,
+			"message": err.Error(),
+		})
+		return nil, false
+	}
+
+	if err := domain.ValidateDimensions(packSet.Sizes, req.Dimensions); err != nil {
+		respondError(w, r, h.logger, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+			"field":   "dimensions",
+			"value":   req.Dimensions,
+			"message": err.Error(),
+		})
+		return nil, false
+	}
+	if len(req.Dimensions) > 0 {
+		packSet.Dimensions = req.Dimensions
+	}
+
+	if req.DefaultAmount != nil {
+		if err := domain.ValidateAmount(*req.DefaultAmount); err != nil {
+			respondError(w, r, h.logger, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+				"field":   "default_amount",
+				"value":   *req.DefaultAmount,
+				"message": err.Error(),
+			})
+			return nil, false
+		}
+		packSet.DefaultAmount = req.DefaultAmount
+	}
+
+	return packSet, true
+}
+
+// respondRepositoryError maps repository errors to HTTP responses
+func (h *PackSetHandler) respondRepositoryError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrPackSizeSetNotFound):
+		respondError(w, r, h.logger, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, domain.ErrPackSizeSetAlreadyExists):
+		respondError(w, r, h.logger, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, domain.ErrInvalidInput):
+		respondErrorCode(w, r, h.logger, http.StatusUnprocessableEntity, CodeInvalidInput, err.Error(), nil)
+	default:
+		h.logger.Error(r.Context(), "pack set repository error", map[string]interface{}{
+			"path":  r.URL.Path,
+			"error": err.Error(),
+		})
+		respondError(w, r, h.logger, http.StatusInternalServerError, "internal server error", nil)
+	}
+}
+
+// newPackSetResponse converts a domain.PackSizeSet to its response representation
+func newPackSetResponse(packSet *domain.PackSizeSet) PackSetResponse {
+	response := PackSetResponse{Sizes: packSet.Sizes, Dimensions: packSet.Dimensions, DefaultAmount: packSet.DefaultAmount}
+	if packSet.ID != nil {
+		response.ID = *packSet.ID
+	}
+	if packSet.Name != nil {
+		response.Name = *packSet.Name
+	}
+	return response
+}
+
+// ImportCSV handles POST /packsets/import.csv
+// Accepts a CSV (raw body or multipart field "file") with header "name,sizes",
+// where sizes is a semicolon-separated list, e.g. "standard,250;500;1000"
+// Valid rows are stored; invalid rows are reported with their line numbers
+func (h *PackSetHandler) ImportCSV(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Limit upload size
+	r.Body = http.MaxBytesReader(w, r.Body, maxCSVImportSize)
+
+	// Accept both multipart uploads and raw CSV bodies
+	var source io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			h.respondUploadError(w, r, err)
+			return
+		}
+		defer file.Close()
+		source = file
+	}
+
+	reader := csv.NewReader(source)
+	reader.FieldsPerRecord = -1 // Field count is validated per row
+	reader.TrimLeadingSpace = true
+
+	// Check header
+	header, err := reader.Read()
+	if err != nil {
+		h.respondUploadError(w, r, err)
+		return
+	}
+	if len(header) != 2 || !strings.EqualFold(strings.TrimSpace(header[0]), "name") || !strings.EqualFold(strings.TrimSpace(header[1]), "sizes") {
+		respondError(w, r, h.logger, http.StatusBadRequest, "CSV header must be \"name,sizes\"", nil)
+		return
+	}
+
+	response := ImportCSVResponse{
+		Imported: []ImportedPackSet{},
+		Errors:   []ImportRowError{},
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Malformed CSV cannot be resynchronized, stop at the first parse error
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				response.Errors = append(response.Errors, ImportRowError{Line: parseErr.Line, Error: parseErr.Err.Error()})
+				break
+			}
+			h.respondUploadError(w, r, err)
+			return
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		packSet, err := parsePackSetRow(record)
+		if err != nil {
+			response.Errors = append(response.Errors, ImportRowError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		created, err := h.repository.Create(ctx, packSet)
+		if err != nil {
+			if !errors.Is(err, domain.ErrPackSizeSetAlreadyExists) && !errors.Is(err, domain.ErrInvalidInput) {
+				h.logger.Error(ctx, "failed to import pack set", map[string]interface{}{
+					"line":  line,
+					"error": err.Error(),
+				})
+			}
+			response.Errors = append(response.Errors, ImportRowError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		response.Imported = append(response.Imported, ImportedPackSet{
+			Line:  line,
+			ID:    created.ID,
+			Name:  *packSet.Name,
+			Sizes: created.Sizes,
+		})
+	}
+
+	// Nothing stored: the upload as a whole is rejected
+	status := http.StatusOK
+	if len(response.Imported) == 0 && len(response.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	respondJSON(w, r, h.logger, status, response)
+}
+
+// parsePackSetRow converts a "name,sizes" CSV record into a validated PackSizeSet
+func parsePackSetRow(record []string) (*domain.PackSizeSet, error) {
+	if len(record) != 2 {
+		return nil, fmt.Errorf("%w: expected 2 columns, got %d", domain.ErrInvalidInput, len(record))
+	}
+
+	name := strings.TrimSpace(record[0])
+	if name == "" {
+		return nil, domain.NewValidationError("name", record[0], "must not be empty")
+	}
+
+	parts := strings.Split(record[1], ";")
+	sizes := make([]int, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		size, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, domain.NewValidationError("sizes", part, "must be an integer")
+		}
+		sizes = append(sizes, size)
+	}
+
+	return domain.NewPackSizeSet(sizes, nil, &name)
+}
+
+// respondUploadError maps upload read errors to HTTP responses
+func (h *PackSetHandler) respondUploadError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		respondError(w, r, h.logger, http.StatusRequestEntityTooLarge, "CSV file is too large", map[string]interface{}{
+			"max_bytes": maxBytesErr.Limit,
+		})
+		return
+	}
+
+	if err == io.EOF {
+		respondError(w, r, h.logger, http.StatusBadRequest, "CSV file is empty", nil)
+		return
+	}
+
+	respondError(w, r, h.logger, http.StatusBadRequest, "invalid CSV upload", map[string]interface{}{
+		"parse_error": err.Error(),
+	})
+}
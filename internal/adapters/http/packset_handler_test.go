@@ -0,0 +1,439 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+// Mock pack size repository for tests
+type mockPackSizeRepository struct {
+	sets   []*domain.PackSizeSet
+	nextID int64
+}
+
+func (m *mockPackSizeRepository) Create(ctx context.Context, ps *domain.PackSizeSet) (*domain.PackSizeSet, error) {
+	for _, existing := range m.sets {
+		if *existing.Name == *ps.Name {
+			return nil, fmt.Errorf("%w: %s", domain.ErrPackSizeSetAlreadyExists, *ps.Name)
+		}
+	}
+	m.nextID++
+	id := m.nextID
+	created := &domain.PackSizeSet{ID: &id, Name: ps.Name, Sizes: ps.Sizes, Dimensions: ps.Dimensions, DefaultAmount: ps.DefaultAmount}
+	m.sets = append(m.sets, created)
+	return created, nil
+}
+
+func (m *mockPackSizeRepository) GetByID(ctx context.Context, id int64) (*domain.PackSizeSet, error) {
+	for _, ps := range m.sets {
+		if *ps.ID == id {
+			return ps, nil
+		}
+	}
+	return nil, domain.ErrPackSizeSetNotFound
+}
+
+func (m *mockPackSizeRepository) GetByName(ctx context.Context, name string) (*domain.PackSizeSet, error) {
+	for _, ps := range m.sets {
+		if *ps.Name == name {
+			return ps, nil
+		}
+	}
+	return nil, domain.ErrPackSizeSetNotFound
+}
+
+func (m *mockPackSizeRepository) List(ctx context.Context, limit, offset int) ([]*domain.PackSizeSet, error) {
+	if offset >= len(m.sets) {
+		return []*domain.PackSizeSet{}, nil
+	}
+	return m.sets[offset:min(offset+limit, len(m.sets))], nil
+}
+
+func (m *mockPackSizeRepository) Update(ctx context.Context, ps *domain.PackSizeSet) error {
+	for _, existing := range m.sets {
+		if *existing.Name == *ps.Name && *existing.ID != *ps.ID {
+			return fmt.Errorf("%w: %s", domain.ErrPackSizeSetAlreadyExists, *ps.Name)
+		}
+	}
+	for i, existing := range m.sets {
+		if *existing.ID == *ps.ID {
+			m.sets[i] = ps
+			return nil
+		}
+	}
+	return domain.ErrPackSizeSetNotFound
+}
+
+func (m *mockPackSizeRepository) Delete(ctx context.Context, id int64) error {
+	for i, ps := range m.sets {
+		if *ps.ID == id {
+			m.sets = append(m.sets[:i], m.sets[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrPackSizeSetNotFound
+}
+
+func TestPackSetHandler_ImportCSV(t *testing.T) {
+	repo := &mockPackSizeRepository{}
+	handler := NewPackSetHandler(repo, &mockLogger{})
+
+	csvBody := "name,sizes\n" +
+		"standard,250;500;1000\n" +
+		"broken,250;-5\n"
+
+	req := httptest.NewRequest(http.MethodPost, "/packsets/import.csv", strings.NewReader(csvBody))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+
+	handler.ImportCSV(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp ImportCSVResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(resp.Imported) != 1 || resp.Imported[0].Line != 2 || resp.Imported[0].Name != "standard" {
+		t.Errorf("unexpected imported rows: %+v", resp.Imported)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Line != 3 {
+		t.Errorf("unexpected row errors: %+v", resp.Errors)
+	}
+	if len(repo.sets) != 1 {
+		t.Errorf("expected 1 stored set, got %d", len(repo.sets))
+	}
+}
+
+func TestPackSetHandler_ImportCSV_Rejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "only invalid rows",
+			body:       "name,sizes\n,250\n",
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "wrong header",
+			body:       "title,values\nstandard,250\n",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "file too large",
+			body:       "name,sizes\n" + strings.Repeat("x", maxCSVImportSize),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPackSetHandler(&mockPackSizeRepository{}, &mockLogger{})
+
+			req := httptest.NewRequest(http.MethodPost, "/packsets/import.csv", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.ImportCSV(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestPackSetHandler_CRUD(t *testing.T) {
+	repo := &mockPackSizeRepository{}
+	handler := NewPackSetHandler(repo, &mockLogger{})
+
+	// serve runs a handler with the {id} path value set (as the router does)
+	serve := func(fn http.HandlerFunc, method, target, id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if id != "" {
+			req.SetPathValue("id", id)
+		}
+		w := httptest.NewRecorder()
+		fn(w, req)
+		return w
+	}
+
+	w := serve(handler.Create, http.MethodPost, "/packsets", "", `{"name":"standard","sizes":[250,500,1000]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created PackSetResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ID != 1 || created.Name != "standard" || len(created.Sizes) != 3 {
+		t.Errorf("unexpected created set: %+v", created)
+	}
+
+	if w := serve(handler.Create, http.MethodPost, "/packsets", "", `{"name":"standard","sizes":[5]}`); w.Code != http.StatusConflict {
+		t.Errorf("duplicate create: expected status 409, got %d", w.Code)
+	}
+	serve(handler.Create, http.MethodPost, "/packsets", "", `{"name":"small","sizes":[5,12]}`)
+
+	w = serve(handler.Get, http.MethodGet, "/packsets/1", "1", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"standard"`) {
+		t.Errorf("get: expected standard set, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serve(handler.List, http.MethodGet, "/packsets?limit=1&offset=1", "", "")
+	var page PackSetsResponse
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(page.PackSets) != 1 || page.PackSets[0].Name != "small" {
+		t.Errorf("list: unexpected page %+v", page.PackSets)
+	}
+
+	w = serve(handler.Update, http.MethodPut, "/packsets/1", "1", `{"name":"standard","sizes":[250,500]}`)
+	if w.Code != http.StatusOK || len(repo.sets[0].Sizes) != 2 {
+		t.Errorf("update: expected status 200 and 2 sizes, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(handler.Update, http.MethodPut, "/packsets/1", "1", `{"name":"small","sizes":[250]}`); w.Code != http.StatusConflict {
+		t.Errorf("update to taken name: expected status 409, got %d", w.Code)
+	}
+
+	if w := serve(handler.Delete, http.MethodDelete, "/packsets/1", "1", ""); w.Code != http.StatusNoContent {
+		t.Errorf("delete: expected status 204, got %d", w.Code)
+	}
+	if w := serve(handler.Get, http.MethodGet, "/packsets/1", "1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("get deleted: expected status 404, got %d", w.Code)
+	}
+}
+
+func TestPackSetHandler_InvalidRequests(t *testing.T) {
+	handler := NewPackSetHandler(&mockPackSizeRepository{}, &mockLogger{})
+
+	tests := []struct {
+		name       string
+		fn         http.HandlerFunc
+		target     string
+		id         string
+		body       string
+		wantStatus int
+	}{
+		{name: "invalid id", fn: handler.Get, id: "abc", wantStatus: http.StatusBadRequest},
+		{name: "missing set", fn: handler.Delete, id: "42", wantStatus: http.StatusNotFound},
+		{name: "update missing set", fn: handler.Update, id: "42", body: `{"name":"x","sizes":[5]}`, wantStatus: http.StatusNotFound},
+		{name: "invalid JSON", fn: handler.Create, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "empty name", fn: handler.Create, body: `{"name":" ","sizes":[5]}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "invalid sizes", fn: handler.Create, body: `{"name":"x","sizes":[5,5]}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "invalid limit", fn: handler.List, target: "/packsets?limit=0", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := tt.target
+			if target == "" {
+				target = "/packsets"
+			}
+			req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(tt.body))
+			if tt.id != "" {
+				req.SetPathValue("id", tt.id)
+			}
+			w := httptest.NewRecorder()
+
+			tt.fn(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPackSetHandler_Solve(t *testing.T) {
+	repo := &mockPackSizeRepository{}
+	name := "standard"
+	repo.Create(context.Background(), &domain.PackSizeSet{Name: &name, Sizes: []int{250, 500, 1000}})
+	handler := NewPackSetHandler(repo, &mockLogger{}).
+		WithService(usecase.NewService(usecase.NewDPSolver(), repo))
+
+	solve := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/packsets/"+id+"/solve", strings.NewReader(body))
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		handler.Solve(w, req)
+		return w
+	}
+
+	w := solve("1", `{"amount":251}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	resp := decodeSolveResponse(t, w.Body)
+	if resp.Solution[500] != 1 || resp.Overage != 249 {
+		t.Errorf("unexpected solution: %+v", resp)
+	}
+	if resp.TotalVolume != nil {
+		t.Errorf("set without dimensions: total_volume = %v, want none", *resp.TotalVolume)
+	}
+	if want := (PackSetRef{ID: 1, Name: name}); resp.PackSet == nil || *resp.PackSet != want {
+		t.Errorf("pack_set = %+v, want %+v", resp.PackSet, want)
+	}
+
+	if w := solve("42", `{"amount":251}`); w.Code != http.StatusNotFound {
+		t.Errorf("missing set: expected status 404, got %d", w.Code)
+	}
+	if w := solve("1", `{"amount":0}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid amount: expected status 422, got %d", w.Code)
+	}
+}
+
+func TestPackSetHandler_SolveDefaultAmount(t *testing.T) {
+	repo := &mockPackSizeRepository{}
+	handler := NewPackSetHandler(repo, &mockLogger{}).
+		WithService(usecase.NewService(usecase.NewDPSolver(), repo))
+
+	for _, body := range []string{
+		`{"name":"defaulted","sizes":[250,500,1000],"default_amount":751}`,
+		`{"name":"plain","sizes":[250,500,1000]}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/packsets", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.Create(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create: expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/packsets/1", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+	handler.Get(w, req)
+	var packSet PackSetResponse
+	if err := json.NewDecoder(w.Body).Decode(&packSet); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if packSet.DefaultAmount == nil || *packSet.DefaultAmount != 751 {
+		t.Errorf("default_amount = %v, want 751", packSet.DefaultAmount)
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		wantStatus int
+		wantAmount int
+	}{
+		{name: "default amount", id: "1", body: `{}`, wantStatus: http.StatusOK, wantAmount: 751},
+		{name: "empty body", id: "1", body: "", wantStatus: http.StatusOK, wantAmount: 751},
+		{name: "explicit amount wins", id: "1", body: `{"amount":251}`, wantStatus: http.StatusOK, wantAmount: 251},
+		{name: "no default", id: "2", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "explicit amount without default", id: "2", body: `{"amount":251}`, wantStatus: http.StatusOK, wantAmount: 251},
+		{name: "invalid explicit amount", id: "1", body: `{"amount":0}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/packsets/"+tt.id+"/solve", strings.NewReader(tt.body))
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			handler.Solve(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			resp := decodeSolveResponse(t, w.Body)
+			if resp.Amount != tt.wantAmount {
+				t.Errorf("amount = %d, want %d", resp.Amount, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestPackSetHandler_Create_InvalidDefaultAmount(t *testing.T) {
+	handler := NewPackSetHandler(&mockPackSizeRepository{}, &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodPost, "/packsets", strings.NewReader(`{"name":"a","sizes":[250],"default_amount":0}`))
+	w := httptest.NewRecorder()
+	handler.Create(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPackSetHandler_SolveDimensioned(t *testing.T) {
+	repo := &mockPackSizeRepository{}
+	handler := NewPackSetHandler(repo, &mockLogger{}).
+		WithService(usecase.NewService(usecase.NewDPSolver(), repo))
+
+	// 250: 20x10x10 = 2000, 500: 20x20x10 = 4000; 1000 has no dimensions
+	body := `{"name":"boxes","sizes":[250,500,1000],"dimensions":{"250":{"length":20,"width":10,"height":10},"500":{"length":20,"width":20,"height":10}}}`
+	req := httptest.NewRequest(http.MethodPost, "/packsets", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.Create(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created PackSetResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.Dimensions[500].Volume() != 4000 {
+		t.Errorf("created dimensions = %+v", created.Dimensions)
+	}
+
+	mixedVolume := 6000.0
+	tests := []struct {
+		amount     int
+		wantVolume *float64
+	}{
+		{amount: 750, wantVolume: &mixedVolume}, // 500 + 250
+		{amount: 1000, wantVolume: nil},         // 1000, undimensioned
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/packsets/1/solve", strings.NewReader(fmt.Sprintf(`{"amount":%d}`, tt.amount)))
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+		handler.Solve(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("amount %d: expected status 200, got %d: %s", tt.amount, w.Code, w.Body.String())
+		}
+		resp := decodeSolveResponse(t, w.Body)
+		switch {
+		case tt.wantVolume == nil && resp.TotalVolume != nil:
+			t.Errorf("amount %d: total_volume = %v, want none", tt.amount, *resp.TotalVolume)
+		case tt.wantVolume != nil && (resp.TotalVolume == nil || *resp.TotalVolume != *tt.wantVolume):
+			t.Errorf("amount %d: total_volume = %v, want %v", tt.amount, resp.TotalVolume, *tt.wantVolume)
+		}
+	}
+}
+
+func TestPackSetHandler_Create_InvalidDimensions(t *testing.T) {
+	handler := NewPackSetHandler(&mockPackSizeRepository{}, &mockLogger{})
+
+	for _, body := range []string{
+		`{"name":"a","sizes":[250],"dimensions":{"500":{"length":1,"width":1,"height":1}}}`,
+		`{"name":"a","sizes":[250],"dimensions":{"250":{"length":1,"width":0,"height":1}}}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/packsets", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.Create(w, req)
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected status 422, got %d", body, w.Code)
+		}
+	}
+}
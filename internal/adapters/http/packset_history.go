@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// CalculationHistory provides the stored calculations of a pack set
+type CalculationHistory interface {
+	ListCalculations(ctx context.Context, packSetID *int64, limit, offset int) ([]domain.StoredCalculation, error)
+	CountCalculations(ctx context.Context, packSetID *int64) (int64, error)
+}
+
+// WithCalculationHistory sets the calculation store History pages through,
+// formatting timestamps with format
+func (h *PackSetHandler) WithCalculationHistory(calculations CalculationHistory, format TimeFormat) *PackSetHandler {
+	h.calculations = calculations
+	h.timeFormat = format
+	return h
+}
+
+// History handles GET /packsets/{id}/history?limit=100&offset=0
+// Returns a page of the calculations recorded against the set, newest first,
+// in the GET /calculations shape
+func (h *PackSetHandler) History(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if h.calculations == nil {
+		respondError(w, r, h.logger, http.StatusNotImplemented, "pack set history is not supported", nil)
+		return
+	}
+
+	id, ok := h.parseID(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := parsePagination(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	// A deleted or unknown set is a 404, not an empty history
+	if _, err := h.repository.GetByID(ctx, id); err != nil {
+		h.respondRepositoryError(w, r, err)
+		return
+	}
+
+	calculations, err := h.calculations.ListCalculations(ctx, &id, limit, offset)
+	if err != nil {
+		h.respondRepositoryError(w, r, err)
+		return
+	}
+	total, err := h.calculations.CountCalculations(ctx, &id)
+	if err != nil {
+		h.respondRepositoryError(w, r, err)
+		return
+	}
+
+	response := CalculationsPageResponse{
+		Items:  make([]CalculationResponse, 0, len(calculations)),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	for _, calculation := range calculations {
+		response.Items = append(response.Items, newCalculationResponse(calculation, h.timeFormat))
+	}
+	respondJSON(w, r, h.logger, http.StatusOK, response)
+}
@@ -0,0 +1,146 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/infra/memory"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+func TestPackSetHandler_History(t *testing.T) {
+	repo := &mockPackSizeRepository{}
+	name := "standard"
+	repo.Create(context.Background(), &domain.PackSizeSet{Name: &name, Sizes: []int{250, 500}})
+
+	packSetID := int64(1)
+	store := &mockCalculationStore{
+		calculations: []domain.StoredCalculation{
+			{ID: 9, PackSetID: &packSetID, PackSizes: []int{250, 500}, Amount: 751, Breakdown: map[int]int{500: 1, 250: 2}, TotalPacks: 3, Overage: 249},
+			{ID: 4, PackSetID: &packSetID, PackSizes: []int{250, 500}, Amount: 500, Breakdown: map[int]int{500: 1}, TotalPacks: 1},
+		},
+		total: 12,
+	}
+	handler := NewPackSetHandler(repo, &mockLogger{}).WithCalculationHistory(store, TimeFormatRFC3339)
+
+	history := func(id, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/packsets/"+id+"/history"+query, nil)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		handler.History(w, req)
+		return w
+	}
+
+	t.Run("page of the set", func(t *testing.T) {
+		w := history("1", "?limit=2&offset=10")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if store.gotPackSetID == nil || *store.gotPackSetID != 1 || store.gotLimit != 2 || store.gotOffset != 10 {
+			t.Errorf("store called with pack_set_id=%v limit=%d offset=%d", store.gotPackSetID, store.gotLimit, store.gotOffset)
+		}
+
+		var resp CalculationsPageResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Items) != 2 || resp.Items[0].ID != 9 || resp.Items[1].ID != 4 {
+			t.Fatalf("items = %+v, want calculations 9 then 4", resp.Items)
+		}
+		if resp.Items[0].Breakdown[250] != 2 || resp.Total != 12 || resp.Limit != 2 || resp.Offset != 10 {
+			t.Errorf("unexpected page: %+v", resp)
+		}
+	})
+
+	t.Run("unknown set", func(t *testing.T) {
+		if w := history("2", ""); w.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		if w := history("1", "?limit=0"); w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/packsets/1/history", nil)
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+		NewPackSetHandler(repo, &mockLogger{}).History(w, req)
+
+		if w.Code != http.StatusNotImplemented {
+			t.Errorf("expected status 501, got %d", w.Code)
+		}
+	})
+}
+
+func TestPackSetHandler_History_RecordsSolves(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepository()
+	standard, other := "standard", "other"
+	if _, err := repo.Create(ctx, &domain.PackSizeSet{Name: &standard, Sizes: []int{250, 500, 1000}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := repo.Create(ctx, &domain.PackSizeSet{Name: &other, Sizes: []int{23, 31}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	packHandler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{}).
+		WithRepository(repo).
+		WithPackSets(repo)
+	packSetHandler := NewPackSetHandler(repo, &mockLogger{}).
+		WithService(usecase.NewService(usecase.NewDPSolver(), repo)).
+		WithCalculationRecorder(packHandler).
+		WithCalculationHistory(repo, TimeFormatRFC3339)
+
+	post := func(handler http.HandlerFunc, path, id, body string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST %s: expected status 200, got %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+	post(packSetHandler.Solve, "/packsets/1/solve", "1", `{"amount":251}`)
+	post(packHandler.SolvePacks, "/packs/solve", "", `{"pack_set_name":"standard","amount":1250}`)
+	post(packHandler.SolvePacks, "/packs/solve", "", `{"pack_set_name":"other","amount":500}`)
+	post(packHandler.SolvePacks, "/packs/solve", "", `{"sizes":[250,500,1000],"amount":750}`) // Inline: no set
+	if err := packHandler.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/packsets/1/history", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+	packSetHandler.History(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp CalculationsPageResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Total != 2 || len(resp.Items) != 2 {
+		t.Fatalf("history = %+v, want the 2 solves against the set", resp)
+	}
+	amounts := map[int]bool{}
+	for _, item := range resp.Items {
+		if item.PackSetID == nil || *item.PackSetID != 1 {
+			t.Errorf("item %d pack_set_id = %v, want 1", item.ID, item.PackSetID)
+		}
+		amounts[item.Amount] = true
+	}
+	if !amounts[251] || !amounts[1250] {
+		t.Errorf("history amounts = %v, want 251 and 1250", amounts)
+	}
+}
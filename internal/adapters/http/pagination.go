@@ -0,0 +1,45 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultPageLimit - page size when the limit query parameter is omitted
+	defaultPageLimit = 100
+
+	// maxPageLimit - upper bound for the limit query parameter
+	maxPageLimit = 1000
+)
+
+// parsePagination reads the limit (1..1000, default 100) and offset (>= 0) query parameters
+// Writes a 400 response and returns false on invalid values
+func parsePagination(w http.ResponseWriter, r *http.Request, logger Logger) (limit, offset int, ok bool) {
+	query := r.URL.Query()
+
+	limit = defaultPageLimit
+	if raw := query.Get("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 || value > maxPageLimit {
+			respondError(w, r, logger, http.StatusBadRequest, "limit must be an integer between 1 and 1000", map[string]interface{}{
+				"limit": raw,
+			})
+			return 0, 0, false
+		}
+		limit = value
+	}
+
+	if raw := query.Get("offset"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			respondError(w, r, logger, http.StatusBadRequest, "offset must be a non-negative integer", map[string]interface{}{
+				"offset": raw,
+			})
+			return 0, 0, false
+		}
+		offset = value
+	}
+
+	return limit, offset, true
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// SolvePacksQuery handles GET /packs/solve?sizes=250,500,1000&amount=1250
+// A lightweight variant of POST /packs/solve for links and simple clients:
+// only sizes and amount are supported; POST remains the canonical route
+func (h *PackHandler) SolvePacksQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		h.respondError(w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+
+	req, ok := h.parseSolveQuery(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.validateRequest(&req); err != nil {
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			h.respondError(w, r, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+				"field":   validationErr.Field,
+				"value":   validationErr.Value,
+				"message": validationErr.Message,
+			})
+			return
+		}
+		h.respondErrorCode(w, r, http.StatusUnprocessableEntity, errorCode(err), err.Error(), nil)
+		return
+	}
+
+	solveCtx, cancel := h.solveContext(ctx)
+	defer cancel()
+
+	solution, err := h.solveAmount(solveCtx, h.solver, req.Sizes, req.Amount, false)
+	if err != nil {
+		h.handleSolverError(w, r, err)
+		return
+	}
+	solution.Normalize()
+
+	// Optional save to DB for audit (an empty solution has nothing to record)
+	if h.repository != nil && solution.Amount > 0 {
+		h.saveCalculationAsync(newCalculationRecord(ctx, req.Sizes, nil, solution, nil))
+	}
+
+	var warnings []string
+	if h.isHighOverage(solution) {
+		warnings = append(warnings, WarningHighOverage)
+	}
+
+	h.respondJSON(w, r, http.StatusOK, SolveResponse{
+		Solution:      solution.Breakdown,
+		Overage:       solution.Overage,
+		Packs:         solution.Packs,
+		Amount:        solution.Amount,
+		TotalItems:    solution.TotalItems(),
+		DistinctSizes: solution.DistinctSizes(),
+		Warnings:      warnings,
+	})
+}
+
+// parseSolveQuery reads sizes (comma-separated) and amount from the query string
+// Malformed values are answered with 400 naming the offending parameter
+// Missing sizes are left to validateRequest (422), like an empty JSON list
+func (h *PackHandler) parseSolveQuery(w http.ResponseWriter, r *http.Request) (SolveRequest, bool) {
+	query := r.URL.Query()
+
+	var req SolveRequest
+	if raw := query.Get("sizes"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			size, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				h.respondError(w, r, http.StatusBadRequest, "invalid query parameters", map[string]interface{}{
+					"parameter": "sizes",
+					"value":     raw,
+					"message":   "must be a comma-separated list of integers, got " + strconv.Quote(part),
+				})
+				return req, false
+			}
+			req.Sizes = append(req.Sizes, size)
+		}
+	}
+
+	raw := query.Get("amount")
+	amount, err := strconv.Atoi(raw)
+	if err != nil {
+		message := "must be an integer"
+		if raw == "" {
+			message = "is required"
+		}
+		h.respondError(w, r, http.StatusBadRequest, "invalid query parameters", map[string]interface{}{
+			"parameter": "amount",
+			"value":     raw,
+			"message":   message,
+		})
+		return req, false
+	}
+	req.Amount = amount
+
+	return req, true
+}
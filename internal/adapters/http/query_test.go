@@ -0,0 +1,75 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+func TestPackHandler_SolvePacksQuery(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/packs/solve?sizes=250,500,1000&amount=1250", nil)
+	w := httptest.NewRecorder()
+
+	handler.SolvePacksQuery(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp SolveResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := map[int]int{1000: 1, 250: 1}
+	if !reflect.DeepEqual(resp.Solution, want) || resp.Overage != 0 || resp.Packs != 2 {
+		t.Errorf("response = %+v, want solution %v with overage 0 and 2 packs", resp, want)
+	}
+}
+
+func TestPackHandler_SolvePacksQuery_InvalidQuery(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	tests := []struct {
+		name          string
+		query         string
+		wantStatus    int
+		wantParameter string
+	}{
+		{name: "non-numeric size", query: "sizes=250,abc&amount=100", wantStatus: http.StatusBadRequest, wantParameter: "sizes"},
+		{name: "empty size", query: "sizes=250,,500&amount=100", wantStatus: http.StatusBadRequest, wantParameter: "sizes"},
+		{name: "non-numeric amount", query: "sizes=250&amount=1e3", wantStatus: http.StatusBadRequest, wantParameter: "amount"},
+		{name: "missing amount", query: "sizes=250", wantStatus: http.StatusBadRequest, wantParameter: "amount"},
+		{name: "missing sizes", query: "amount=100", wantStatus: http.StatusUnprocessableEntity},
+		{name: "duplicate sizes", query: "sizes=250,250&amount=100", wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/packs/solve?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.SolvePacksQuery(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantParameter == "" {
+				return
+			}
+
+			var resp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Details["parameter"] != tt.wantParameter {
+				t.Errorf("details = %v, want parameter %q", resp.Details, tt.wantParameter)
+			}
+		})
+	}
+}
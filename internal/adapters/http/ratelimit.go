@@ -0,0 +1,203 @@
+package http
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Per-API-key metrics; api_key is a fingerprint of the key, never the key itself
+var (
+	apiKeyRequestsTotal = metrics.Register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "requests_total",
+			Help: "Total number of API requests by API key fingerprint",
+		},
+		[]string{"api_key"},
+	))
+
+	apiKeyRateLimitedTotal = metrics.Register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limited_requests_total",
+			Help: "Total number of API requests rejected by the rate limiter by API key fingerprint",
+		},
+		[]string{"api_key"},
+	))
+)
+
+// anonymousAPIKey is the api_key metric label for unauthenticated requests
+const anonymousAPIKey = "anonymous"
+
+// idleBucketTTL - buckets unused for this long are dropped
+const idleBucketTTL = 10 * time.Minute
+
+// RateLimit defines a token bucket: Rate requests per second with bursts up to Burst
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// tokenBucket holds the state of a single client's bucket
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// RateLimiter limits requests per client using token buckets
+// Clients are identified by API key identity when authenticated, otherwise by IP
+type RateLimiter struct {
+	defaultLimit RateLimit
+	keyLimits    map[string]RateLimit // Per-identity overrides
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewRateLimiter creates a rate limiter applying defaultLimit to every client
+func NewRateLimiter(defaultLimit RateLimit) *RateLimiter {
+	return &RateLimiter{
+		defaultLimit: defaultLimit,
+		keyLimits:    make(map[string]RateLimit),
+		buckets:      make(map[string]*tokenBucket),
+		now:          time.Now,
+	}
+}
+
+// WithKeyLimits sets per-identity limits overriding the default limit
+func (l *RateLimiter) WithKeyLimits(limits map[string]RateLimit) *RateLimiter {
+	l.keyLimits = limits
+	return l
+}
+
+// limitFor returns the limit for an API key identity ("" for unauthenticated clients)
+func (l *RateLimiter) limitFor(identity string) RateLimit {
+	if limit, ok := l.keyLimits[identity]; ok && identity != "" {
+		return limit
+	}
+	return l.defaultLimit
+}
+
+// Allow consumes a token from the bucket for client and reports whether the request may proceed
+func (l *RateLimiter) Allow(client string, limit RateLimit) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), lastSeen: now}
+		l.buckets[client] = bucket
+	}
+
+	// Refill tokens for the time elapsed since the last request
+	elapsed := now.Sub(bucket.lastSeen).Seconds()
+	bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+elapsed*limit.Rate)
+	bucket.lastSeen = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// sweep drops idle buckets at most once per idleBucketTTL so memory stays bounded
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleBucketTTL {
+		return
+	}
+	l.lastSweep = now
+
+	for client, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) >= idleBucketTTL {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// ParseRateLimits parses comma-separated "identity=rate:burst" entries
+func ParseRateLimits(list string) (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit)
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		identity, spec, found := strings.Cut(entry, "=")
+		rateStr, burstStr, hasBurst := strings.Cut(spec, ":")
+		if !found || !hasBurst || strings.TrimSpace(identity) == "" {
+			return nil, fmt.Errorf("invalid rate limit entry %q: expected identity=rate:burst", entry)
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateStr), 64)
+		if err != nil || math.IsNaN(rate) || math.IsInf(rate, 0) || rate <= 0 {
+			return nil, fmt.Errorf("invalid rate in entry %q: must be a positive finite number", entry)
+		}
+		burst, err := strconv.Atoi(strings.TrimSpace(burstStr))
+		if err != nil || burst <= 0 {
+			return nil, fmt.Errorf("invalid burst in entry %q: must be a positive integer", entry)
+		}
+
+		limits[strings.TrimSpace(identity)] = RateLimit{Rate: rate, Burst: burst}
+	}
+	return limits, nil
+}
+
+// RateLimitMiddleware rejects requests over the client's rate limit with 429
+// Authenticated requests are limited per API key identity (see APIKeyMiddleware),
+// unauthenticated ones per client IP
+// Chi-compatible middleware
+func RateLimitMiddleware(limiter *RateLimiter, logger Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			identity := GetAPIKeyIdentity(ctx)
+			client := "ip:" + clientIP(r)
+			keyLabel := anonymousAPIKey
+			if identity != "" {
+				client = "key:" + identity
+				keyLabel = keyFingerprint(r.Header.Get(APIKeyHeader))
+			}
+
+			apiKeyRequestsTotal.WithLabelValues(keyLabel).Inc()
+
+			if !limiter.Allow(client, limiter.limitFor(identity)) {
+				apiKeyRateLimitedTotal.WithLabelValues(keyLabel).Inc()
+				logger.Warn(ctx, "rate limit exceeded", map[string]interface{}{
+					"client":         client,
+					"path":           r.URL.Path,
+					"correlation_id": GetCorrelationID(ctx),
+				})
+				w.Header().Set("Retry-After", "1")
+				respondError(w, r, logger, http.StatusTooManyRequests, "rate limit exceeded", nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// clientIP returns the host part of the request's remote address
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// newTestRateLimiter returns a limiter with a frozen clock
+func newTestRateLimiter(limit RateLimit) (*RateLimiter, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(limit)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestRateLimitMiddleware_IndependentKeyBuckets(t *testing.T) {
+	keys, _ := ParseAPIKeys("team-a:secret-a,team-b:secret-b")
+	limiter, _ := newTestRateLimiter(RateLimit{Rate: 1, Burst: 2})
+	logger := &mockLogger{}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := APIKeyMiddleware(keys, logger)(RateLimitMiddleware(limiter, logger)(ok))
+
+	send := func(key string) int {
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve", nil)
+		req.Header.Set(APIKeyHeader, key)
+		req.RemoteAddr = "10.0.0.1:1234" // Same IP for both keys
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	limitedBefore := testutil.ToFloat64(apiKeyRateLimitedTotal.WithLabelValues(keyFingerprint("secret-a")))
+
+	// Exhaust team-a's bucket
+	for i := 0; i < 2; i++ {
+		if code := send("secret-a"); code != http.StatusOK {
+			t.Fatalf("team-a request %d: expected 200, got %d", i+1, code)
+		}
+	}
+	if code := send("secret-a"); code != http.StatusTooManyRequests {
+		t.Errorf("team-a over limit: expected 429, got %d", code)
+	}
+
+	// team-b has its own bucket despite sharing the IP
+	for i := 0; i < 2; i++ {
+		if code := send("secret-b"); code != http.StatusOK {
+			t.Errorf("team-b request %d: expected 200, got %d", i+1, code)
+		}
+	}
+
+	if got := testutil.ToFloat64(apiKeyRateLimitedTotal.WithLabelValues(keyFingerprint("secret-a"))) - limitedBefore; got != 1 {
+		t.Errorf("rate limited count for team-a = %v, want 1", got)
+	}
+}
+
+func TestRateLimitMiddleware_IPFallback(t *testing.T) {
+	limiter, now := newTestRateLimiter(RateLimit{Rate: 1, Burst: 1})
+	handler := RateLimitMiddleware(limiter, &mockLogger{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send("10.0.0.1:1000"); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if code := send("10.0.0.1:2000"); code != http.StatusTooManyRequests {
+		t.Errorf("same IP over limit: expected 429, got %d", code)
+	}
+	if code := send("10.0.0.2:1000"); code != http.StatusOK {
+		t.Errorf("other IP: expected 200, got %d", code)
+	}
+
+	// Bucket refills over time
+	*now = now.Add(time.Second)
+	if code := send("10.0.0.1:1000"); code != http.StatusOK {
+		t.Errorf("after refill: expected 200, got %d", code)
+	}
+}
+
+func TestRateLimiter_KeyLimits(t *testing.T) {
+	limiter, _ := newTestRateLimiter(RateLimit{Rate: 1, Burst: 1})
+	limiter.WithKeyLimits(map[string]RateLimit{"team-a": {Rate: 1, Burst: 3}})
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if limiter.Allow("key:team-a", limiter.limitFor("team-a")) {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("team-a allowed %d requests, want 3", allowed)
+	}
+
+	if got := limiter.limitFor("team-b"); got != (RateLimit{Rate: 1, Burst: 1}) {
+		t.Errorf("limitFor(team-b) = %+v, want default", got)
+	}
+}
+
+func TestParseRateLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    map[string]RateLimit
+		wantErr bool
+	}{
+		{name: "entries", list: "team-a=50:100, team-b=0.5:2", want: map[string]RateLimit{
+			"team-a": {Rate: 50, Burst: 100},
+			"team-b": {Rate: 0.5, Burst: 2},
+		}},
+		{name: "empty", list: "", want: map[string]RateLimit{}},
+		{name: "missing burst", list: "team-a=50", wantErr: true},
+		{name: "zero rate", list: "team-a=0:10", wantErr: true},
+		{name: "NaN rate", list: "team-a=NaN:10", wantErr: true},
+		{name: "infinite rate", list: "team-a=Inf:10", wantErr: true},
+		{name: "invalid burst", list: "team-a=5:x", wantErr: true},
+		{name: "missing identity", list: "=5:10", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRateLimits(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRateLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRateLimits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// respondJSON sends JSON response
+func respondJSON(w http.ResponseWriter, r *http.Request, logger Logger, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logger.Error(r.Context(), "failed to encode response", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// respondError sends error response with the default code for status
+func respondError(w http.ResponseWriter, r *http.Request, logger Logger, status int, message string, details map[string]interface{}) {
+	respondErrorCode(w, r, logger, status, "", message, details)
+}
+
+// respondErrorCode sends error response with a machine-readable code
+// An empty code falls back to the default code for status
+func respondErrorCode(w http.ResponseWriter, r *http.Request, logger Logger, status int, code, message string, details map[string]interface{}) {
+	if code == "" {
+		code = statusErrorCode(status)
+	}
+	response := ErrorResponse{
+		Error:   http.StatusText(status),
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+
+	respondJSON(w, r, logger, status, response)
+}
+
+// respondSolverError maps solver errors to HTTP responses
+func respondSolverError(w http.ResponseWriter, r *http.Request, logger Logger, err error) {
+	ctx := r.Context()
+
+	// Validation errors
+	if errors.Is(err, domain.ErrInvalidInput) {
+		respondErrorCode(w, r, logger, http.StatusUnprocessableEntity, errorCode(err), err.Error(), nil)
+		return
+	}
+
+	// Request too large for the solver memory budget or DP table limit
+	if errors.Is(err, domain.ErrMemoryBudgetExceeded) || errors.Is(err, domain.ErrSearchTruncated) {
+		respondErrorCode(w, r, logger, http.StatusUnprocessableEntity, errorCode(err), err.Error(), nil)
+		return
+	}
+
+	// No solution errors
+	if errors.Is(err, domain.ErrNoSolution) || errors.Is(err, domain.ErrNoSolutionStrict) {
+		respondErrorCode(w, r, logger, http.StatusUnprocessableEntity, errorCode(err), err.Error(), nil)
+		return
+	}
+
+	// Context errors
+	if errors.Is(err, context.Canceled) {
+		respondErrorCode(w, r, logger, http.StatusRequestTimeout, CodeCanceled, "request canceled", nil)
+		return
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		respondError(w, r, logger, http.StatusRequestTimeout, "request timeout", nil)
+		return
+	}
+
+	// Unexpected error
+	logger.Error(ctx, "solver error", map[string]interface{}{
+		"error": err.Error(),
+	})
+	respondError(w, r, logger, http.StatusInternalServerError, "internal server error", nil)
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// maxSeriesCount - upper bound for amounts in a series solve request
+const maxSeriesCount = 1000
+
+// SeriesSolveRequest represents the request body for solving an arithmetic
+// series of amounts: start, start+step, ..., start+(count-1)*step
+type SeriesSolveRequest struct {
+	Sizes []int `json:"sizes"`
+	Start int   `json:"start"`
+	Step  int   `json:"step"`
+	Count int   `json:"count"`
+}
+
+// SeriesPoint is the optimal packing summary for one amount of the series
+type SeriesPoint struct {
+	Amount  int `json:"amount"`
+	Packs   int `json:"packs"`
+	Overage int `json:"overage"`
+}
+
+// SeriesSolveResponse represents the solutions of a series, in amount order
+type SeriesSolveResponse struct {
+	Series []SeriesPoint `json:"series"`
+}
+
+// SolveSeries handles POST /packs/solve/series
+// All amounts are solved with one DP table up to the largest (see domain.SeriesSolver)
+func (h *PackHandler) SolveSeries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodPost {
+		h.respondError(w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+
+	if h.seriesSolver == nil {
+		h.respondError(w, r, http.StatusNotImplemented, "series solving is not supported", nil)
+		return
+	}
+
+	var req SeriesSolveRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	amounts, err := seriesAmounts(&req)
+	if err != nil {
+		h.respondError(w, r, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+			"errors": validationErrorDetails(err),
+		})
+		return
+	}
+
+	solveCtx, cancel := h.solveContext(ctx)
+	defer cancel()
+
+	solutions, err := h.seriesSolver.SolveSeries(solveCtx, req.Sizes, amounts)
+	if err != nil {
+		h.handleSolverError(w, r, err)
+		return
+	}
+
+	series := make([]SeriesPoint, len(solutions))
+	for i, solution := range solutions {
+		series[i] = SeriesPoint{
+			Amount:  solution.Amount,
+			Packs:   solution.Packs,
+			Overage: solution.Overage,
+		}
+	}
+
+	h.respondJSON(w, r, http.StatusOK, SeriesSolveResponse{Series: series})
+}
+
+// seriesAmounts validates a series request and returns its amounts
+// Returns an errors.Join of *domain.ValidationError listing every offending field
+func seriesAmounts(req *SeriesSolveRequest) ([]int, error) {
+	var errs []error
+
+	if err := domain.ValidatePackSizes(req.Sizes); err != nil {
+		errs = append(errs, domain.NewValidationError("sizes", req.Sizes, err.Error()))
+	}
+	if err := domain.ValidateAmount(req.Start); err != nil {
+		errs = append(errs, domain.NewValidationError("start", req.Start, err.Error()))
+	}
+	if req.Step < 1 || req.Step > domain.MaxAmount {
+		errs = append(errs, domain.NewValidationError("step", req.Step, fmt.Sprintf("must be between 1 and %d", domain.MaxAmount)))
+	}
+	if req.Count < 1 || req.Count > maxSeriesCount {
+		errs = append(errs, domain.NewValidationError("count", req.Count, fmt.Sprintf("must be between 1 and %d", maxSeriesCount)))
+	}
+
+	// Bounded fields keep the last amount far from overflowing
+	if len(errs) == 0 {
+		last := req.Start + (req.Count-1)*req.Step
+		if err := domain.ValidateAmount(last); err != nil {
+			errs = append(errs, domain.NewValidationError("count", req.Count, "last amount: "+err.Error()))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	amounts := make([]int, req.Count)
+	for i := range amounts {
+		amounts[i] = req.Start + i*req.Step
+	}
+	return amounts, nil
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+func TestPackHandler_SolveSeries(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	tests := []struct {
+		name string
+		body string
+		want []SeriesPoint
+	}{
+		{
+			name: "demand steps",
+			body: `{"sizes":[250,500,1000,2000,5000],"start":1000,"step":1000,"count":10}`,
+			want: []SeriesPoint{
+				{Amount: 1000, Packs: 1}, {Amount: 2000, Packs: 1}, {Amount: 3000, Packs: 2},
+				{Amount: 4000, Packs: 2}, {Amount: 5000, Packs: 1}, {Amount: 6000, Packs: 2},
+				{Amount: 7000, Packs: 2}, {Amount: 8000, Packs: 3}, {Amount: 9000, Packs: 3},
+				{Amount: 10000, Packs: 2},
+			},
+		},
+		{
+			name: "with overage",
+			body: `{"sizes":[250,500,1000],"start":251,"step":500,"count":3}`,
+			want: []SeriesPoint{
+				{Amount: 251, Packs: 1, Overage: 249},
+				{Amount: 751, Packs: 1, Overage: 249},
+				{Amount: 1251, Packs: 2, Overage: 249},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve/series", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.SolveSeries(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var resp SeriesSolveResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !reflect.DeepEqual(resp.Series, tt.want) {
+				t.Errorf("series = %+v, want %+v", resp.Series, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolveSeries_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantFields []string
+	}{
+		{
+			name:       "invalid fields",
+			body:       `{"sizes":[250],"start":0,"step":0,"count":1001}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: []string{"start", "step", "count"},
+		},
+		{
+			name:       "last amount too large",
+			body:       `{"sizes":[250],"start":1,"step":1000000000,"count":2}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: []string{"count"},
+		},
+		{
+			// Valid amounts, but the shared table would exceed the DP size limit
+			name:       "table too large",
+			body:       `{"sizes":[250],"start":1000,"step":10000000,"count":2}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+			req := httptest.NewRequest(http.MethodPost, "/packs/solve/series", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.SolveSeries(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantFields == nil {
+				return
+			}
+
+			var resp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			details, _ := resp.Details["errors"].([]interface{})
+			var fields []string
+			for _, detail := range details {
+				fields = append(fields, detail.(map[string]interface{})["field"].(string))
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestPackHandler_SolveSeries_Unsupported(t *testing.T) {
+	handler := NewPackHandler(&mockSolver{}, &mockLogger{})
+
+	req := httptest.NewRequest(http.MethodPost, "/packs/solve/series", strings.NewReader(`{"sizes":[250],"start":250,"step":250,"count":2}`))
+	w := httptest.NewRecorder()
+
+	handler.SolveSeries(w, req)
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("expected status 501, got %d", w.Code)
+	}
+}
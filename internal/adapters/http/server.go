@@ -0,0 +1,88 @@
+package http
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// ServerConfig holds HTTP server settings
+type ServerConfig struct {
+	Addr         string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+
+	// TLS is enabled when both files are set; HTTP/2 is then negotiated via ALPN
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// H2C serves HTTP/2 over plaintext (behind a TLS-terminating proxy)
+	// Ignored when TLS is enabled
+	H2C bool
+}
+
+// TLSEnabled reports whether TLS certificate and key are configured
+func (c ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// Validate checks TLS settings so misconfiguration fails at startup
+// Both TLS files must be set together, exist and contain a valid key pair
+func (c ServerConfig) Validate() error {
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	if !c.TLSEnabled() {
+		return nil
+	}
+
+	for _, file := range []string{c.TLSCertFile, c.TLSKeyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("TLS file not accessible: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("TLS file %s is a directory", file)
+		}
+	}
+
+	if _, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile); err != nil {
+		return fmt.Errorf("invalid TLS key pair: %w", err)
+	}
+
+	return nil
+}
+
+// NewServer creates an HTTP server for the given config
+// Without TLS or H2C the server speaks HTTP/1.1 only
+func NewServer(cfg ServerConfig, handler http.Handler) *http.Server {
+	if cfg.H2C && !cfg.TLSEnabled() {
+		// h2c handler upgrades prior-knowledge and Upgrade: h2c requests, HTTP/1.1 still works
+		handler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: cfg.IdleTimeout})
+	}
+
+	return &http.Server{
+		Addr:         cfg.Addr,
+		Handler:      handler,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+}
+
+// Serve accepts connections on listener, using TLS when configured
+// net/http enables HTTP/2 automatically for TLS servers
+func Serve(server *http.Server, listener net.Listener, cfg ServerConfig) error {
+	if cfg.TLSEnabled() {
+		return server.ServeTLS(listener, cfg.TLSCertFile, cfg.TLSKeyFile)
+	}
+	return server.Serve(listener)
+}
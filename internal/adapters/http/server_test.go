@@ -0,0 +1,211 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// writeSelfSignedCert generates a self-signed certificate for 127.0.0.1
+// and returns paths to the PEM-encoded certificate and key files
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	return certFile, keyFile
+}
+
+// startTestServer starts a server for cfg on an ephemeral port and returns its address
+func startTestServer(t *testing.T, cfg ServerConfig) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := NewServer(cfg, handler)
+
+	go Serve(server, listener, cfg)
+	t.Cleanup(func() { server.Close() })
+
+	return listener.Addr().String()
+}
+
+func TestServer_NegotiatesHTTP2WithTLS(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t)
+	addr := startTestServer(t, ServerConfig{TLSCertFile: certFile, TLSKeyFile: keyFile})
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			ForceAttemptHTTP2: true,
+		},
+		Timeout: 5 * time.Second,
+	}
+
+	resp, err := client.Get("https://" + addr + "/")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.ProtoMajor != 2 {
+		t.Errorf("expected HTTP/2, got %s", resp.Proto)
+	}
+}
+
+func TestServer_DefaultsToHTTP1(t *testing.T) {
+	addr := startTestServer(t, ServerConfig{})
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.ProtoMajor != 1 || resp.ProtoMinor != 1 {
+		t.Errorf("expected HTTP/1.1, got %s", resp.Proto)
+	}
+}
+
+func TestServer_H2C(t *testing.T) {
+	addr := startTestServer(t, ServerConfig{H2C: true})
+
+	// Prior-knowledge HTTP/2 over plaintext
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+		Timeout: 5 * time.Second,
+	}
+
+	resp, err := client.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.ProtoMajor != 2 {
+		t.Errorf("expected HTTP/2, got %s", resp.Proto)
+	}
+}
+
+func TestServer_ServesOverTLS(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t)
+	cfg := ServerConfig{TLSCertFile: certFile, TLSKeyFile: keyFile}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	addr := startTestServer(t, cfg)
+
+	// Trust only the generated certificate
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		t.Fatalf("failed to read certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		Timeout:   5 * time.Second,
+	}
+
+	resp, err := client.Get("https://" + addr + "/")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.TLS == nil {
+		t.Error("expected TLS connection")
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerConfig_Validate(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t)
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+
+	tests := []struct {
+		name    string
+		cfg     ServerConfig
+		wantErr bool
+	}{
+		{name: "TLS disabled", cfg: ServerConfig{}, wantErr: false},
+		{name: "valid key pair", cfg: ServerConfig{TLSCertFile: certFile, TLSKeyFile: keyFile}, wantErr: false},
+		{name: "only certificate set", cfg: ServerConfig{TLSCertFile: certFile}, wantErr: true},
+		{name: "missing key file", cfg: ServerConfig{TLSCertFile: certFile, TLSKeyFile: missing}, wantErr: true},
+		{name: "swapped files", cfg: ServerConfig{TLSCertFile: keyFile, TLSKeyFile: certFile}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+// Simulation limits
+const (
+	defaultSimulationLimit = 1000  // Recent calculations simulated when no amounts are given
+	maxSimulationAmounts   = 10000 // Upper bound for both "amounts" and "limit"
+)
+
+// Simulation amount sources reported in SimulateResponse.Source
+const (
+	simulationSourceRequest = "request"
+	simulationSourceHistory = "history"
+)
+
+// AmountHistory provides the amounts of past calculations
+type AmountHistory interface {
+	GetRecentAmounts(ctx context.Context, limit int) ([]int, error)
+}
+
+// SimulateRequest represents the request body for simulating a pack set
+// Either amounts are given, or the latest limit recorded calculation amounts are used
+type SimulateRequest struct {
+	Amounts []int `json:"amounts,omitempty"`
+	Limit   int   `json:"limit,omitempty"` // Recent calculations to use, default 1000
+}
+
+// SimulateResponse represents aggregate metrics of a pack set over many amounts
+type SimulateResponse struct {
+	PackSetID    int64   `json:"pack_set_id"`
+	Sizes        []int   `json:"sizes"`
+	Source       string  `json:"source"` // "request" or "history"
+	Amounts      int     `json:"amounts"`
+	Solved       int     `json:"solved"`
+	Failed       int     `json:"failed"`
+	AvgOverage   float64 `json:"avg_overage"`
+	AvgPacks     float64 `json:"avg_packs"`
+	ExactPercent float64 `json:"exact_percent"` // Solved amounts with zero overage, 0..100
+}
+
+// WithSimulator sets the solver used by Simulate
+func (h *PackSetHandler) WithSimulator(solver domain.Solver) *PackSetHandler {
+	h.simulator = solver
+	return h
+}
+
+// WithAmountHistory sets the source of past amounts Simulate falls back to
+func (h *PackSetHandler) WithAmountHistory(history AmountHistory) *PackSetHandler {
+	h.history = history
+	return h
+}
+
+// Simulate handles POST /packsets/{id}/simulate
+// Solves every amount (given, or taken from recent calculations) with the
+// sizes of the stored set and returns aggregate overage and pack metrics
+func (h *PackSetHandler) Simulate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if h.simulator == nil {
+		respondError(w, r, h.logger, http.StatusNotImplemented, "simulating pack sets is not supported", nil)
+		return
+	}
+
+	id, ok := h.parseID(w, r)
+	if !ok {
+		return
+	}
+
+	// An empty body simulates the recent history with the default limit
+	var req SimulateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, r, h.logger, http.StatusBadRequest, "invalid JSON", map[string]interface{}{
+			"parse_error": err.Error(),
+		})
+		return
+	}
+
+	if err := validateSimulateRequest(&req); err != nil {
+		respondError(w, r, h.logger, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+			"errors": validationErrorDetails(err),
+		})
+		return
+	}
+
+	packSet, err := h.repository.GetByID(ctx, id)
+	if err != nil {
+		h.respondRepositoryError(w, r, err)
+		return
+	}
+
+	amounts, source := req.Amounts, simulationSourceRequest
+	if len(amounts) == 0 {
+		if h.history == nil {
+			respondError(w, r, h.logger, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+				"field":   "amounts",
+				"value":   req.Amounts,
+				"message": "must not be empty when no calculation history is available",
+			})
+			return
+		}
+
+		limit := req.Limit
+		if limit == 0 {
+			limit = defaultSimulationLimit
+		}
+		amounts, err = h.history.GetRecentAmounts(ctx, limit)
+		if err != nil {
+			h.respondRepositoryError(w, r, err)
+			return
+		}
+		if len(amounts) == 0 {
+			respondError(w, r, h.logger, http.StatusUnprocessableEntity, "no recorded calculations to simulate", nil)
+			return
+		}
+		source = simulationSourceHistory
+	}
+
+	report, err := usecase.Simulate(ctx, h.simulator, packSet.Sizes, amounts, usecase.DefaultBatchConcurrency)
+	if err != nil {
+		respondSolverError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, h.logger, http.StatusOK, SimulateResponse{
+		PackSetID:    id,
+		Sizes:        packSet.Sizes,
+		Source:       source,
+		Amounts:      report.Amounts,
+		Solved:       report.Solved,
+		Failed:       report.Failed,
+		AvgOverage:   report.AvgOverage,
+		AvgPacks:     report.AvgPacks,
+		ExactPercent: report.ExactPercent,
+	})
+}
+
+// validateSimulateRequest returns an errors.Join of *domain.ValidationError
+// listing every offending field
+func validateSimulateRequest(req *SimulateRequest) error {
+	var errs []error
+
+	if len(req.Amounts) > maxSimulationAmounts {
+		errs = append(errs, domain.NewValidationError("amounts", len(req.Amounts), fmt.Sprintf("must contain at most %d amounts", maxSimulationAmounts)))
+	}
+	for i, amount := range req.Amounts {
+		if err := domain.ValidateAmount(amount); err != nil {
+			errs = append(errs, domain.NewValidationError(fmt.Sprintf("amounts[%d]", i), amount, err.Error()))
+		}
+	}
+	if req.Limit < 0 || req.Limit > maxSimulationAmounts {
+		errs = append(errs, domain.NewValidationError("limit", req.Limit, fmt.Sprintf("must be between 1 and %d", maxSimulationAmounts)))
+	}
+
+	return errors.Join(errs...)
+}
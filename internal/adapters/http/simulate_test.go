@@ -0,0 +1,113 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+// fixedAmountHistory returns the same recent amounts for any limit
+type fixedAmountHistory struct {
+	amounts []int
+	limit   int // Last requested limit
+}
+
+func (h *fixedAmountHistory) GetRecentAmounts(ctx context.Context, limit int) ([]int, error) {
+	h.limit = limit
+	return h.amounts, nil
+}
+
+func TestPackSetHandler_Simulate(t *testing.T) {
+	repo := &mockPackSizeRepository{}
+	name := "standard"
+	repo.Create(context.Background(), &domain.PackSizeSet{Name: &name, Sizes: []int{250, 500, 1000}})
+	history := &fixedAmountHistory{amounts: []int{250, 251}}
+	handler := NewPackSetHandler(repo, &mockLogger{}).
+		WithSimulator(usecase.NewDPSolver()).
+		WithAmountHistory(history)
+
+	simulate := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/packsets/"+id+"/simulate", strings.NewReader(body))
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		handler.Simulate(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) SimulateResponse {
+		t.Helper()
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp SimulateResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("provided amounts", func(t *testing.T) {
+		// 250, 500 and 1000 are exact; 251 ships a 500 and 1001 a 1000 + 250
+		resp := decode(simulate("1", `{"amounts":[250,251,500,1000,1001]}`))
+
+		want := SimulateResponse{
+			PackSetID:    1,
+			Sizes:        []int{250, 500, 1000},
+			Source:       simulationSourceRequest,
+			Amounts:      5,
+			Solved:       5,
+			AvgOverage:   99.6,
+			AvgPacks:     1.2,
+			ExactPercent: 60,
+		}
+		if resp.Source != want.Source || resp.Amounts != want.Amounts || resp.Solved != want.Solved || resp.Failed != 0 ||
+			resp.AvgOverage != want.AvgOverage || resp.AvgPacks != want.AvgPacks || resp.ExactPercent != want.ExactPercent {
+			t.Errorf("response = %+v, want %+v", resp, want)
+		}
+	})
+
+	t.Run("recent history", func(t *testing.T) {
+		resp := decode(simulate("1", `{"limit":2}`))
+
+		if resp.Source != simulationSourceHistory || resp.Amounts != 2 || resp.AvgOverage != 124.5 || resp.ExactPercent != 50 {
+			t.Errorf("response = %+v, want history of 2 amounts with avg overage 124.5 and 50%% exact", resp)
+		}
+		if history.limit != 2 {
+			t.Errorf("history limit = %d, want 2", history.limit)
+		}
+	})
+
+	t.Run("empty body uses the default limit", func(t *testing.T) {
+		decode(simulate("1", ""))
+		if history.limit != defaultSimulationLimit {
+			t.Errorf("history limit = %d, want %d", history.limit, defaultSimulationLimit)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			id         string
+			body       string
+			wantStatus int
+		}{
+			{name: "missing set", id: "42", body: `{"amounts":[251]}`, wantStatus: http.StatusNotFound},
+			{name: "invalid amount", id: "1", body: `{"amounts":[251,0]}`, wantStatus: http.StatusUnprocessableEntity},
+			{name: "invalid limit", id: "1", body: `{"limit":-1}`, wantStatus: http.StatusUnprocessableEntity},
+			{name: "invalid JSON", id: "1", body: `{"amounts":`, wantStatus: http.StatusBadRequest},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if w := simulate(tt.id, tt.body); w.Code != tt.wantStatus {
+					t.Errorf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Solve registry defaults
+const (
+	DefaultSolveRegistrySize = 1000            // Maximum in-flight solves tracked
+	DefaultSolveRegistryTTL  = 5 * time.Minute // Entries older than this are evicted
+)
+
+// Solve registry eviction reasons, logged as "reason"
+const (
+	evictionExpired  = "expired"
+	evictionCapacity = "capacity"
+)
+
+// solveEntry is an in-flight solve that can be cancelled
+type solveEntry struct {
+	correlationID string
+	cancel        context.CancelFunc
+	registeredAt  time.Time
+}
+
+// SolveRegistry tracks the cancel functions of in-flight solves by correlation ID
+// Bounded by size and age so missed deregistrations cannot grow it without
+// limit: entries older than the TTL and, when full, the oldest entries are
+// force-evicted (and logged); an evicted solve keeps running but can no
+// longer be cancelled through the registry
+// Safe for concurrent use
+type SolveRegistry struct {
+	maxSize int
+	ttl     time.Duration
+	logger  Logger
+
+	mu      sync.Mutex
+	order   *list.List               // *solveEntry, oldest first
+	entries map[string]*list.Element // Correlation ID -> element of order
+	now     func() time.Time
+}
+
+// NewSolveRegistry creates a registry of at most maxSize entries kept for at most ttl
+// Non-positive values fall back to DefaultSolveRegistrySize and DefaultSolveRegistryTTL
+func NewSolveRegistry(maxSize int, ttl time.Duration, logger Logger) *SolveRegistry {
+	if maxSize <= 0 {
+		maxSize = DefaultSolveRegistrySize
+	}
+	if ttl <= 0 {
+		ttl = DefaultSolveRegistryTTL
+	}
+	return &SolveRegistry{
+		maxSize: maxSize,
+		ttl:     ttl,
+		logger:  logger,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// Register tracks cancel under correlationID, replacing an entry with the same ID
+// The returned function removes the entry; it is a no-op once the entry was
+// evicted or replaced, so it is safe to defer
+func (s *SolveRegistry) Register(ctx context.Context, correlationID string, cancel context.CancelFunc) (deregister func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.evictExpired(ctx, now)
+
+	if element, ok := s.entries[correlationID]; ok {
+		s.remove(element)
+	}
+	for s.order.Len() >= s.maxSize {
+		s.evict(ctx, s.order.Front(), evictionCapacity, now)
+	}
+
+	element := s.order.PushBack(&solveEntry{correlationID: correlationID, cancel: cancel, registeredAt: now})
+	s.entries[correlationID] = element
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.entries[correlationID] == element {
+			s.remove(element)
+		}
+	}
+}
+
+// Cancel cancels the in-flight solve registered under correlationID
+// Reports false if there is none (never registered, finished or evicted)
+func (s *SolveRegistry) Cancel(ctx context.Context, correlationID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpired(ctx, s.now())
+
+	element, ok := s.entries[correlationID]
+	if !ok {
+		return false
+	}
+	s.remove(element)
+	element.Value.(*solveEntry).cancel()
+	return true
+}
+
+// Len returns the number of tracked solves
+func (s *SolveRegistry) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// evictExpired drops entries older than the TTL; entries are in registration
+// order, so only the front needs checking
+func (s *SolveRegistry) evictExpired(ctx context.Context, now time.Time) {
+	for element := s.order.Front(); element != nil; element = s.order.Front() {
+		if now.Sub(element.Value.(*solveEntry).registeredAt) < s.ttl {
+			return
+		}
+		s.evict(ctx, element, evictionExpired, now)
+	}
+}
+
+// evict force-removes an entry and logs it
+func (s *SolveRegistry) evict(ctx context.Context, element *list.Element, reason string, now time.Time) {
+	entry := element.Value.(*solveEntry)
+	s.remove(element)
+	s.logger.Warn(ctx, "solve registry entry evicted", map[string]interface{}{
+		"evicted_correlation_id": entry.correlationID,
+		"reason":                 reason,
+		"age_ms":                 now.Sub(entry.registeredAt).Milliseconds(),
+		"max_size":               s.maxSize,
+	})
+}
+
+// remove deletes an entry without cancelling it
+func (s *SolveRegistry) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.entries, element.Value.(*solveEntry).correlationID)
+}
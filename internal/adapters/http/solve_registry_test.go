@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// evictionLogger records the fields of "solve registry entry evicted" warnings
+type evictionLogger struct {
+	mockLogger
+	mu        sync.Mutex
+	evictions []map[string]interface{}
+}
+
+func (l *evictionLogger) Warn(ctx context.Context, msg string, fields map[string]interface{}) {
+	if msg != "solve registry entry evicted" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.evictions = append(l.evictions, fields)
+}
+
+// newTestSolveRegistry returns a registry with a frozen clock
+func newTestSolveRegistry(maxSize int, ttl time.Duration) (*SolveRegistry, *evictionLogger, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger := &evictionLogger{}
+	registry := NewSolveRegistry(maxSize, ttl, logger)
+	registry.now = func() time.Time { return now }
+	return registry, logger, &now
+}
+
+func TestSolveRegistry_EvictsOldestBeyondCap(t *testing.T) {
+	ctx := context.Background()
+	registry, logger, now := newTestSolveRegistry(3, time.Hour)
+
+	cancelled := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("req-%d", i)
+		registry.Register(ctx, id, func() { cancelled[id] = true })
+		*now = now.Add(time.Second)
+	}
+
+	if n := registry.Len(); n != 3 {
+		t.Fatalf("Len() = %d, want 3", n)
+	}
+	if len(logger.evictions) != 2 {
+		t.Fatalf("logged %d evictions, want 2", len(logger.evictions))
+	}
+	for i, fields := range logger.evictions {
+		if want := fmt.Sprintf("req-%d", i); fields["evicted_correlation_id"] != want || fields["reason"] != evictionCapacity {
+			t.Errorf("eviction %d = %v, want %s evicted for capacity", i, fields, want)
+		}
+	}
+
+	// Evicted entries are forgotten without being cancelled
+	if registry.Cancel(ctx, "req-0") {
+		t.Error("Cancel(req-0) = true for an evicted entry")
+	}
+	if !registry.Cancel(ctx, "req-4") || !cancelled["req-4"] {
+		t.Error("Cancel(req-4) did not cancel the newest entry")
+	}
+	if cancelled["req-0"] || cancelled["req-1"] {
+		t.Errorf("evicted solves were cancelled: %v", cancelled)
+	}
+}
+
+func TestSolveRegistry_EvictsExpired(t *testing.T) {
+	ctx := context.Background()
+	registry, logger, now := newTestSolveRegistry(10, time.Minute)
+
+	registry.Register(ctx, "stale", func() {})
+	*now = now.Add(30 * time.Second)
+	registry.Register(ctx, "fresh", func() {})
+	*now = now.Add(45 * time.Second)
+
+	if registry.Cancel(ctx, "stale") {
+		t.Error("Cancel(stale) = true after the TTL")
+	}
+	if !registry.Cancel(ctx, "fresh") {
+		t.Error("Cancel(fresh) = false before the TTL")
+	}
+	if len(logger.evictions) != 1 || logger.evictions[0]["reason"] != evictionExpired {
+		t.Errorf("evictions = %v, want one expired entry", logger.evictions)
+	}
+}
+
+func TestSolveRegistry_Deregister(t *testing.T) {
+	ctx := context.Background()
+	registry, _, _ := newTestSolveRegistry(10, time.Minute)
+
+	deregisterOld := registry.Register(ctx, "req", func() {})
+	deregisterNew := registry.Register(ctx, "req", func() {})
+
+	// A replaced entry's deregistration must not drop its replacement
+	deregisterOld()
+	if n := registry.Len(); n != 1 {
+		t.Fatalf("Len() = %d after stale deregister, want 1", n)
+	}
+	deregisterNew()
+	if n := registry.Len(); n != 0 {
+		t.Errorf("Len() = %d after deregister, want 0", n)
+	}
+}
@@ -0,0 +1,207 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// StreamWriteTimeout bounds the write of each SSE event; every event extends
+// the write deadline, so a stream may outlive the server's WriteTimeout
+const StreamWriteTimeout = 15 * time.Second
+
+// ProgressEvent is the payload of an SSE "progress" event
+type ProgressEvent struct {
+	Done    int     `json:"done"`    // Sums scanned
+	Total   int     `json:"total"`   // Sums to scan (maxSum + 1)
+	Percent float64 `json:"percent"` // Done / Total * 100
+}
+
+// SolvePacksStream handles GET /packs/solve/stream?sizes=250,500&amount=12001
+// Streams Server-Sent Events: "progress" events while the DP table is filled,
+// then a single "result" event (SolveResponse) or "error" event (ErrorResponse)
+// Invalid input is rejected with a regular JSON error before the stream starts
+// HTTP/1.0 clients get the same events in one response with a Content-Length
+func (h *PackHandler) SolvePacksStream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		h.respondError(w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+
+	req, ok := h.parseSolveQuery(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.validateRequest(&req); err != nil {
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			h.respondError(w, r, http.StatusUnprocessableEntity, "validation failed", map[string]interface{}{
+				"field":   validationErr.Field,
+				"value":   validationErr.Value,
+				"message": validationErr.Message,
+			})
+			return
+		}
+		h.respondErrorCode(w, r, http.StatusUnprocessableEntity, errorCode(err), err.Error(), nil)
+		return
+	}
+
+	events := &eventWriter{h: h, w: w, r: r}
+	if !r.ProtoAtLeast(1, 1) {
+		// HTTP/1.0 has no chunked encoding: send every event at once when done
+		events.buffer = &bytes.Buffer{}
+		defer events.flushBuffer()
+	} else {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			h.respondError(w, r, http.StatusInternalServerError, "streaming not supported", nil)
+			return
+		}
+		events.flusher = flusher
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	if events.flusher != nil {
+		h.extendWriteDeadline(w, r)
+		w.WriteHeader(http.StatusOK)
+		events.flusher.Flush()
+	}
+
+	// Bound the solve by the solve budget and make it cancellable by correlation ID
+	solveCtx, cancel := h.solveContext(ctx)
+	defer cancel()
+
+	// Keep only the latest progress update so a slow client never blocks the solver
+	progress := make(chan ProgressEvent, 1)
+	solveCtx = domain.WithProgress(solveCtx, func(done, total int) {
+		event := ProgressEvent{Done: done, Total: total, Percent: float64(done) * 100 / float64(total)}
+		select {
+		case <-progress:
+		default:
+		}
+		progress <- event
+	})
+
+	type solveResult struct {
+		solution *domain.Solution
+		err      error
+	}
+	result := make(chan solveResult, 1)
+	go func() {
+		solution, err := h.solver.Solve(solveCtx, req.Sizes, req.Amount)
+		if err == nil {
+			solution.Normalize()
+		}
+		result <- solveResult{solution: solution, err: err}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event := <-progress:
+			events.write("progress", event)
+
+		case res := <-result:
+			// Deliver the final progress update before the result
+			select {
+			case event := <-progress:
+				events.write("progress", event)
+			default:
+			}
+
+			if res.err != nil {
+				code := errorCode(res.err)
+				if code == "" {
+					code = CodeInternal
+				}
+				events.write("error", ErrorResponse{
+					Error:   "solve failed",
+					Code:    code,
+					Message: res.err.Error(),
+				})
+				return
+			}
+
+			if h.repository != nil {
+				h.saveCalculationAsync(newCalculationRecord(ctx, req.Sizes, nil, res.solution, nil))
+			}
+
+			events.write("result", SolveResponse{
+				Solution:      res.solution.Breakdown,
+				Overage:       res.solution.Overage,
+				Packs:         res.solution.Packs,
+				Amount:        res.solution.Amount,
+				TotalItems:    res.solution.TotalItems(),
+				DistinctSizes: res.solution.DistinctSizes(),
+			})
+			return
+		}
+	}
+}
+
+// eventWriter writes SSE events, flushing each one, or buffers them all for
+// clients that cannot receive a streamed response
+type eventWriter struct {
+	h       *PackHandler
+	w       http.ResponseWriter
+	r       *http.Request
+	flusher http.Flusher  // Set when streaming
+	buffer  *bytes.Buffer // Set when buffering (HTTP/1.0)
+}
+
+// write writes a single SSE event with a JSON payload and flushes it
+func (e *eventWriter) write(event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		e.h.logger.Error(e.r.Context(), "failed to encode event", map[string]interface{}{
+			"event": event,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if e.buffer != nil {
+		fmt.Fprintf(e.buffer, "event: %s\ndata: %s\n\n", event, payload)
+		return
+	}
+	e.h.extendWriteDeadline(e.w, e.r)
+	fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event, payload)
+	e.flusher.Flush()
+}
+
+// flushBuffer writes the buffered events as one response with a Content-Length
+func (e *eventWriter) flushBuffer() {
+	e.h.extendWriteDeadline(e.w, e.r)
+	writeBuffered(e.w, http.StatusOK, e.buffer.Bytes())
+}
+
+// writeBuffered writes a complete body with its Content-Length, for clients
+// (HTTP/1.0) that cannot receive a chunked response
+func writeBuffered(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// extendWriteDeadline gives the next write StreamWriteTimeout to complete
+// Writers without deadlines (e.g. httptest.ResponseRecorder) are left as they are
+func (h *PackHandler) extendWriteDeadline(w http.ResponseWriter, r *http.Request) {
+	err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(StreamWriteTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.Debug(r.Context(), "failed to extend stream write deadline", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/usecase"
+)
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readEvents reads all SSE events from a stream
+func readEvents(t *testing.T, resp *http.Response) []sseEvent {
+	t.Helper()
+
+	var events []sseEvent
+	var current sseEvent
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			events = append(events, current)
+			current = sseEvent{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read stream: %v", err)
+	}
+	return events
+}
+
+func TestPackHandler_SolvePacksStream(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+	// Behind the metrics middleware to check its ResponseWriter supports flushing
+	server := httptest.NewServer(MetricsMiddleware(&mockLogger{})(http.HandlerFunc(handler.SolvePacksStream)))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/packs/solve/stream?sizes=23,31,53&amount=500000")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %q", ct)
+	}
+	// Connection management is left to net/http; the header is invalid on HTTP/2
+	if conn := resp.Header.Get("Connection"); conn != "" {
+		t.Errorf("expected no Connection header, got %q", conn)
+	}
+
+	events := readEvents(t, resp)
+	if len(events) < 2 {
+		t.Fatalf("expected progress and result events, got %v", events)
+	}
+
+	// Final event is the result
+	last := events[len(events)-1]
+	if last.name != "result" {
+		t.Fatalf("expected final result event, got %q: %s", last.name, last.data)
+	}
+	var result SolveResponse
+	if err := json.Unmarshal([]byte(last.data), &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	want := map[int]int{23: 2, 31: 7, 53: 9429}
+	if !reflect.DeepEqual(result.Solution, want) || result.Overage != 0 {
+		t.Errorf("result = %v (overage %d), want %v (overage 0)", result.Solution, result.Overage, want)
+	}
+
+	// Progress events precede the result and end at 100%
+	progress := events[len(events)-2]
+	if progress.name != "progress" {
+		t.Fatalf("expected progress event before result, got %q", progress.name)
+	}
+	var event ProgressEvent
+	if err := json.Unmarshal([]byte(progress.data), &event); err != nil {
+		t.Fatalf("failed to decode progress: %v", err)
+	}
+	if event.Percent != 100 || event.Done != event.Total {
+		t.Errorf("last progress = %+v, want 100%%", event)
+	}
+}
+
+func TestPackHandler_SolvePacksStream_HTTP10(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+	server := httptest.NewServer(MetricsMiddleware(&mockLogger{})(http.HandlerFunc(handler.SolvePacksStream)))
+	defer server.Close()
+
+	// HTTP/1.0 has no chunked encoding, so the client must get a Content-Length
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := fmt.Fprint(conn, "GET /packs/solve/stream?sizes=23,31,53&amount=500000 HTTP/1.0\r\n\r\n"); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	if len(resp.TransferEncoding) != 0 {
+		t.Errorf("expected no Transfer-Encoding, got %v", resp.TransferEncoding)
+	}
+	if resp.ContentLength <= 0 {
+		t.Fatalf("expected a Content-Length, got %d", resp.ContentLength)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %q", ct)
+	}
+
+	// The same events as a stream, ending with the result
+	events := readEvents(t, resp)
+	if len(events) < 2 || events[len(events)-2].name != "progress" || events[len(events)-1].name != "result" {
+		t.Fatalf("expected progress and result events, got %v", events)
+	}
+	var result SolveResponse
+	if err := json.Unmarshal([]byte(events[len(events)-1].data), &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if want := map[int]int{23: 2, 31: 7, 53: 9429}; !reflect.DeepEqual(result.Solution, want) {
+		t.Errorf("result = %v, want %v", result.Solution, want)
+	}
+}
+
+// slowProgressSolver reports progress every step for steps steps, then
+// returns solution
+type slowProgressSolver struct {
+	steps    int
+	step     time.Duration
+	solution *domain.Solution
+}
+
+func (s *slowProgressSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	onProgress := domain.ProgressFromContext(ctx)
+	for i := 1; i <= s.steps; i++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.step):
+		}
+		if onProgress != nil {
+			onProgress(i, s.steps)
+		}
+	}
+	return s.solution.Copy(), nil
+}
+
+func TestPackHandler_SolvePacksStream_OutlivesWriteTimeout(t *testing.T) {
+	solver := &slowProgressSolver{steps: 6, step: 50 * time.Millisecond, solution: domain.NewSolution(map[int]int{500: 1}, 251)}
+	handler := NewPackHandler(solver, &mockLogger{})
+	server := httptest.NewUnstartedServer(MetricsMiddleware(&mockLogger{})(http.HandlerFunc(handler.SolvePacksStream)))
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/packs/solve/stream?sizes=250,500&amount=251")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	events := readEvents(t, resp)
+	if len(events) == 0 || events[len(events)-1].name != "result" {
+		t.Fatalf("expected a final result event after the server write timeout, got %v", events)
+	}
+}
+
+func TestPackHandler_SolvePacksStream_Cancel(t *testing.T) {
+	logger := &mockLogger{}
+	registry := NewSolveRegistry(10, time.Minute, logger)
+	solver := &blockingSolver{started: make(chan struct{})}
+	handler := NewPackHandler(solver, logger).WithSolveRegistry(registry)
+	server := httptest.NewServer(CorrelationIDMiddleware(logger)(http.HandlerFunc(handler.SolvePacksStream)))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/packs/solve/stream?sizes=250,500&amount=251", nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("X-Correlation-ID", "slow-stream")
+
+	done := make(chan []sseEvent, 1)
+	go func() {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Errorf("request failed: %v", err)
+			done <- nil
+			return
+		}
+		defer resp.Body.Close()
+		done <- readEvents(t, resp)
+	}()
+
+	select {
+	case <-solver.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("solve did not start")
+	}
+	if !registry.Cancel(context.Background(), "slow-stream") {
+		t.Fatal("stream solve was not registered")
+	}
+
+	select {
+	case events := <-done:
+		if len(events) == 0 || events[len(events)-1].name != "error" {
+			t.Fatalf("expected a final error event, got %v", events)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream solve was not cancelled")
+	}
+	if n := registry.Len(); n != 0 {
+		t.Errorf("registry holds %d entries after the stream finished, want 0", n)
+	}
+}
+
+func TestPackHandler_SolvePacksStream_InvalidInput(t *testing.T) {
+	handler := NewPackHandler(usecase.NewDPSolver(), &mockLogger{})
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "invalid size", query: "sizes=23,x&amount=100", wantStatus: http.StatusBadRequest},
+		{name: "missing amount", query: "sizes=23,31", wantStatus: http.StatusBadRequest},
+		{name: "missing sizes", query: "amount=100", wantStatus: http.StatusUnprocessableEntity},
+		{name: "negative amount", query: "sizes=23&amount=-1", wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/packs/solve/stream?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.SolvePacksStream(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// TimeFormat selects how timestamps are serialized in responses
+type TimeFormat string
+
+const (
+	// TimeFormatRFC3339 serializes timestamps as RFC 3339 strings (default)
+	TimeFormatRFC3339 TimeFormat = "rfc3339"
+
+	// TimeFormatUnixMillis serializes timestamps as Unix epoch milliseconds
+	TimeFormatUnixMillis TimeFormat = "unix_ms"
+)
+
+// ParseTimeFormat parses a TIME_FORMAT value; empty selects TimeFormatRFC3339
+func ParseTimeFormat(value string) (TimeFormat, error) {
+	switch format := TimeFormat(value); format {
+	case "":
+		return TimeFormatRFC3339, nil
+	case TimeFormatRFC3339, TimeFormatUnixMillis:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported time format %q: expected %s or %s", value, TimeFormatRFC3339, TimeFormatUnixMillis)
+	}
+}
+
+// Timestamp is a time serialized in the configured TimeFormat
+type Timestamp struct {
+	Time   time.Time
+	Format TimeFormat
+}
+
+// MarshalJSON implements json.Marshaler
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.Format == TimeFormatUnixMillis {
+		return strconv.AppendInt(nil, t.Time.UnixMilli(), 10), nil
+	}
+	return t.Time.MarshalJSON()
+}
+
+// String formats the time as MarshalJSON does, without JSON quoting
+func (t Timestamp) String() string {
+	if t.Format == TimeFormatUnixMillis {
+		return strconv.FormatInt(t.Time.UnixMilli(), 10)
+	}
+	return t.Time.Format(time.RFC3339Nano)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting either format
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if millis, err := strconv.ParseInt(string(data), 10, 64); err == nil {
+		t.Time, t.Format = time.UnixMilli(millis).UTC(), TimeFormatUnixMillis
+		return nil
+	}
+	t.Format = TimeFormatRFC3339
+	return t.Time.UnmarshalJSON(data)
+}
+
+// CalculationResponse represents a stored calculation
+type CalculationResponse struct {
+	ID           int64       `json:"id"`
+	PackSizes    []int       `json:"pack_sizes"`
+	Amount       int         `json:"amount"`
+	Breakdown    map[int]int `json:"breakdown"` // Pack size -> quantity
+	TotalPacks   int         `json:"total_packs"`
+	Overage      int         `json:"overage"`
+	CalculatedAt Timestamp   `json:"calculated_at"`
+
+	PackSetID     *int64            `json:"pack_set_id,omitempty"`    // Stored set the calculation was solved with, if any
+	CorrelationID string            `json:"correlation_id,omitempty"` // Request that produced the calculation
+	Options       map[string]string `json:"options,omitempty"`        // Request options the calculation was solved with
+	SolverVersion string            `json:"solver_version,omitempty"` // Solver build that produced it; absent for older calculations
+}
+
+// newCalculationResponse converts a stored calculation, formatting timestamps with format
+func newCalculationResponse(calculation domain.StoredCalculation, format TimeFormat) CalculationResponse {
+	return CalculationResponse{
+		ID:            calculation.ID,
+		PackSizes:     calculation.PackSizes,
+		Amount:        calculation.Amount,
+		Breakdown:     calculation.Breakdown,
+		TotalPacks:    calculation.TotalPacks,
+		Overage:       calculation.Overage,
+		CalculatedAt:  Timestamp{Time: calculation.CalculatedAt, Format: format},
+		PackSetID:     calculation.PackSetID,
+		CorrelationID: calculation.CorrelationID,
+		Options:       calculation.Options,
+		SolverVersion: calculation.SolverVersion,
+	}
+}
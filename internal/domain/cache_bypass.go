@@ -0,0 +1,27 @@
+package domain
+
+import "context"
+
+// CacheBypass controls how a caching solver treats a single request
+type CacheBypass int
+
+const (
+	CacheBypassNone    CacheBypass = iota // Normal read-through caching
+	CacheBypassRead                       // Skip the cache read and recompute; don't write the result
+	CacheBypassRefresh                    // Skip the cache read, recompute and overwrite the cached entry
+)
+
+type cacheBypassKey struct{}
+
+// WithCacheBypass returns a context asking caching solvers to bypass the cache
+func WithCacheBypass(ctx context.Context, mode CacheBypass) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, mode)
+}
+
+// CacheBypassFromContext returns the cache bypass mode set on the context
+func CacheBypassFromContext(ctx context.Context) CacheBypass {
+	if mode, ok := ctx.Value(cacheBypassKey{}).(CacheBypass); ok {
+		return mode
+	}
+	return CacheBypassNone
+}
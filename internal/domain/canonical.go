@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SolveOptions holds solve-affecting options (e.g. strategy, max overage)
+// Every option that can change the solution must be included so that
+// requests differing only in options never share a cache entry
+type SolveOptions map[string]string
+
+// Solve option names understood by solvers
+const (
+	SolveOptionMaxOverage = "max_overage" // Maximum acceptable overage (non-negative integer)
+	SolveOptionPriority   = "priority"    // Optimization priority (Priority.String())
+	SolveOptionCosts      = "costs"       // Per-pack costs to minimize (FormatPackCosts)
+)
+
+type solveOptionsKey struct{}
+
+// WithSolveOptions returns a context carrying solve options for solvers and caches
+func WithSolveOptions(ctx context.Context, options SolveOptions) context.Context {
+	return context.WithValue(ctx, solveOptionsKey{}, options)
+}
+
+// SolveOptionsFromContext returns the solve options set on the context (nil if none)
+func SolveOptionsFromContext(ctx context.Context) SolveOptions {
+	options, _ := ctx.Value(solveOptionsKey{}).(SolveOptions)
+	return options
+}
+
+// CanonicalKey returns a stable string identifying a solve request
+// Sizes are sorted (the solver ignores their order) but not deduplicated: the
+// solver rejects duplicate sizes, so such a request must not share the key of
+// a valid one; options are included in key order, so equal requests always
+// produce equal keys
+func CanonicalKey(sizes []int, amount int, options SolveOptions) string {
+	sorted := make([]int, len(sizes))
+	copy(sorted, sizes)
+	sort.Ints(sorted)
+
+	parts := make([]string, len(sorted))
+	for i, size := range sorted {
+		parts[i] = strconv.Itoa(size)
+	}
+
+	values := url.Values{}
+	values.Set("sizes", strings.Join(parts, ","))
+	values.Set("amount", strconv.Itoa(amount))
+	for name, value := range options {
+		values.Set("opt."+name, value)
+	}
+
+	// Encode sorts by key and escapes values, so the result is unambiguous
+	return values.Encode()
+}
+
+// CanonicalHash returns the hex SHA-256 of CanonicalKey, for use in storage keys
+func CanonicalHash(sizes []int, amount int, options SolveOptions) string {
+	hash := sha256.Sum256([]byte(CanonicalKey(sizes, amount, options)))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,68 @@
+package domain
+
+import "testing"
+
+func TestCanonicalKey(t *testing.T) {
+	base := CanonicalKey([]int{250, 500, 1000}, 12001, SolveOptions{"strategy": "dp", "max_overage": "100"})
+
+	t.Run("identical requests match", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			sizes   []int
+			options SolveOptions
+		}{
+			{name: "same input", sizes: []int{250, 500, 1000}, options: SolveOptions{"strategy": "dp", "max_overage": "100"}},
+			{name: "different size order", sizes: []int{1000, 250, 500}, options: SolveOptions{"strategy": "dp", "max_overage": "100"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if got := CanonicalKey(tt.sizes, 12001, tt.options); got != base {
+					t.Errorf("CanonicalKey() = %q, want %q", got, base)
+				}
+			})
+		}
+	})
+
+	t.Run("differing requests differ", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			sizes   []int
+			amount  int
+			options SolveOptions
+		}{
+			{name: "different strategy", sizes: []int{250, 500, 1000}, amount: 12001, options: SolveOptions{"strategy": "greedy", "max_overage": "100"}},
+			{name: "different max overage", sizes: []int{250, 500, 1000}, amount: 12001, options: SolveOptions{"strategy": "dp", "max_overage": "0"}},
+			{name: "missing option", sizes: []int{250, 500, 1000}, amount: 12001, options: SolveOptions{"strategy": "dp"}},
+			{name: "no options", sizes: []int{250, 500, 1000}, amount: 12001, options: nil},
+			{name: "different amount", sizes: []int{250, 500, 1000}, amount: 12002, options: SolveOptions{"strategy": "dp", "max_overage": "100"}},
+			{name: "different sizes", sizes: []int{250, 500}, amount: 12001, options: SolveOptions{"strategy": "dp", "max_overage": "100"}},
+			{name: "duplicate sizes", sizes: []int{250, 250, 500, 1000}, amount: 12001, options: SolveOptions{"strategy": "dp", "max_overage": "100"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if got := CanonicalKey(tt.sizes, tt.amount, tt.options); got == base {
+					t.Errorf("CanonicalKey() = %q, must differ from base", got)
+				}
+			})
+		}
+	})
+
+	t.Run("option values cannot forge other options", func(t *testing.T) {
+		a := CanonicalKey([]int{250}, 1, SolveOptions{"a": "1&opt.b=2"})
+		b := CanonicalKey([]int{250}, 1, SolveOptions{"a": "1", "b": "2"})
+		if a == b {
+			t.Errorf("keys must differ, both are %q", a)
+		}
+	})
+
+	t.Run("hash follows key", func(t *testing.T) {
+		h1 := CanonicalHash([]int{500, 250}, 751, nil)
+		h2 := CanonicalHash([]int{250, 500}, 751, nil)
+		h3 := CanonicalHash([]int{250, 500}, 751, SolveOptions{"strategy": "dp"})
+		if h1 != h2 || h1 == h3 {
+			t.Errorf("unexpected hashes: %s %s %s", h1, h2, h3)
+		}
+	})
+}
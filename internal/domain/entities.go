@@ -3,13 +3,40 @@ package domain
 import (
 	"errors"
 	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Input limits enforced by validation
+const (
+	MaxPackSize      = 1_000_000     // Largest allowed pack size
+	MaxAmount        = 1_000_000_000 // Reasonable maximum for amount
+	MaxDistinctSizes = 100           // Most pack sizes in one set; bounds the DP inner loop
+	MaxPackCost      = 1_000_000     // Largest allowed cost of one pack; keeps cost totals precise
 )
 
 // PackSizeSet represents a set of pack sizes
 type PackSizeSet struct {
-	ID    *int64  // Optional identifier
-	Name  *string // Optional set name
-	Sizes []int   // Pack sizes
+	ID            *int64             // Optional identifier
+	Name          *string            // Optional set name
+	Sizes         []int              // Pack sizes
+	Dimensions    map[int]Dimensions // Optional: pack size -> outer dimensions
+	DefaultAmount *int               // Optional: amount solved when a request gives none
+}
+
+// Dimensions are the outer dimensions of a pack, in any one consistent unit
+type Dimensions struct {
+	Length float64 `json:"length"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Volume returns the pack volume, in the cube of the dimension unit
+func (d Dimensions) Volume() float64 {
+	return d.Length * d.Width * d.Height
 }
 
 // Solution represents a packing problem solution
@@ -37,12 +64,16 @@ func NewPackSizeSet(sizes []int, id *int64, name *string) (*PackSizeSet, error)
 // - sizes must be unique
 // - sizes must be greater than 0
 // - sizes must not exceed 1e6
+// - there must be at most MaxDistinctSizes sizes
 func ValidatePackSizes(sizes []int) error {
 	if len(sizes) == 0 {
 		return fmt.Errorf("%w: sizes cannot be empty", ErrInvalidInput)
 	}
 
-	const maxSize = 1_000_000
+	// Checked first, so an oversized request is rejected without a scan
+	if len(sizes) > MaxDistinctSizes {
+		return fmt.Errorf("%w: at most %d sizes are allowed, got %d", ErrInvalidInput, MaxDistinctSizes, len(sizes))
+	}
 
 	seen := make(map[int]bool)
 	for _, size := range sizes {
@@ -52,8 +83,8 @@ func ValidatePackSizes(sizes []int) error {
 		}
 
 		// Check for maximum size
-		if size > maxSize {
-			return fmt.Errorf("%w: size must not exceed %d, got %d", ErrInvalidInput, maxSize, size)
+		if size > MaxPackSize {
+			return fmt.Errorf("%w: size must not exceed %d, got %d", ErrInvalidInput, MaxPackSize, size)
 		}
 
 		// Check for uniqueness
@@ -66,20 +97,58 @@ func ValidatePackSizes(sizes []int) error {
 	return nil
 }
 
+// ValidateDimensions checks that every dimensioned size is one of sizes and
+// that all its dimensions are positive; sizes without dimensions are allowed
+func ValidateDimensions(sizes []int, dimensions map[int]Dimensions) error {
+	for size, d := range dimensions {
+		if !slices.Contains(sizes, size) {
+			return fmt.Errorf("%w: dimensions given for size %d, which is not in the set", ErrInvalidInput, size)
+		}
+		if !(d.Length > 0 && d.Width > 0 && d.Height > 0) || math.IsInf(d.Volume(), 0) {
+			return fmt.Errorf("%w: dimensions of size %d must be positive and finite, got %gx%gx%g", ErrInvalidInput, size, d.Length, d.Width, d.Height)
+		}
+	}
+	return nil
+}
+
 // Validate checks the validity of the size set
 func (p *PackSizeSet) Validate() error {
-	return ValidatePackSizes(p.Sizes)
+	if err := ValidatePackSizes(p.Sizes); err != nil {
+		return err
+	}
+	if p.DefaultAmount != nil {
+		if err := ValidateAmount(*p.DefaultAmount); err != nil {
+			return fmt.Errorf("default amount: %w", err)
+		}
+	}
+	return ValidateDimensions(p.Sizes, p.Dimensions)
 }
 
-// NewSolution creates a new solution
-func NewSolution(breakdown map[int]int, amount int) *Solution {
-	totalPacks := 0
-	totalItems := 0
+// TotalVolume returns the summed volume of the packs in breakdown
+// Reports false unless every used size has dimensions
+func (p *PackSizeSet) TotalVolume(breakdown map[int]int) (float64, bool) {
+	if len(p.Dimensions) == 0 {
+		return 0, false
+	}
 
+	volume := 0.0
 	for size, count := range breakdown {
-		totalPacks += count
-		totalItems += size * count
+		if count == 0 {
+			continue
+		}
+		d, ok := p.Dimensions[size]
+		if !ok {
+			return 0, false
+		}
+		volume += float64(count) * d.Volume()
 	}
+	return volume, true
+}
+
+// NewSolution creates a new solution
+// Totals that overflow int saturate at math.MaxInt (see TotalItems)
+func NewSolution(breakdown map[int]int, amount int) *Solution {
+	totalPacks, totalItems, _ := sumBreakdown(breakdown)
 
 	overage := 0
 	if totalItems > amount {
@@ -94,31 +163,140 @@ func NewSolution(breakdown map[int]int, amount int) *Solution {
 	}
 }
 
+// sumBreakdown returns the total packs and items of a breakdown
+// Entries with a non-positive size or count contribute nothing; totals that
+// overflow int saturate at math.MaxInt and ok is false
+func sumBreakdown(breakdown map[int]int) (packs, items int, ok bool) {
+	ok = true
+	for size, count := range breakdown {
+		if size <= 0 || count <= 0 {
+			continue
+		}
+		var packsOK, itemsOK bool
+		packs, packsOK = mulAdd(packs, 1, count)
+		items, itemsOK = mulAdd(items, size, count)
+		ok = ok && packsOK && itemsOK
+	}
+	return packs, items, ok
+}
+
+// mulAdd returns total + size*count for non-negative operands, saturating at
+// math.MaxInt instead of wrapping; ok is false when it saturates
+// Checked on the platform int width, so 32-bit builds saturate at 2^31-1
+func mulAdd(total, size, count int) (int, bool) {
+	hi, product := bits.Mul(uint(size), uint(count))
+	if hi != 0 || product > uint(math.MaxInt-total) {
+		return math.MaxInt, false
+	}
+	return total + int(product), true
+}
+
 // IsValid checks if the solution is correct
 func (s *Solution) IsValid() bool {
 	if s.Breakdown == nil {
 		return false
 	}
 
-	totalItems := 0
 	for size, count := range s.Breakdown {
 		if size <= 0 || count < 0 {
 			return false
 		}
-		totalItems += size * count
 	}
 
-	// Solution must cover required amount
-	return totalItems >= s.Amount
+	// Totals must fit in int, and the solution must cover required amount
+	_, totalItems, ok := sumBreakdown(s.Breakdown)
+	return ok && totalItems >= s.Amount
 }
 
 // TotalItems returns the total number of items in the solution
+// Entries with a non-positive count contribute nothing; a total beyond
+// math.MaxInt is capped at math.MaxInt rather than wrapping (Validate rejects it)
 func (s *Solution) TotalItems() int {
-	total := 0
+	_, total, _ := sumBreakdown(s.Breakdown)
+	return total
+}
+
+// DistinctSizes returns the number of different pack sizes used in the solution
+func (s *Solution) DistinctSizes() int {
+	distinct := 0
+	for _, count := range s.Breakdown {
+		if count > 0 {
+			distinct++
+		}
+	}
+	return distinct
+}
+
+// Explain renders the solution for logs and UI display, e.g.
+// "selected 5000×2 + 2000×1 + 250×1 for 0 overage in 4 packs."
+// Sizes are listed largest first, so the text is deterministic
+func (s *Solution) Explain() string {
+	sizes := make([]int, 0, len(s.Breakdown))
 	for size, count := range s.Breakdown {
-		total += size * count
+		if count > 0 {
+			sizes = append(sizes, size)
+		}
 	}
-	return total
+	slices.SortFunc(sizes, func(a, b int) int { return b - a })
+
+	terms := make([]string, len(sizes))
+	for i, size := range sizes {
+		terms[i] = fmt.Sprintf("%d×%d", size, s.Breakdown[size])
+	}
+	selected := strings.Join(terms, " + ")
+	if selected == "" {
+		selected = "no packs"
+	}
+
+	packs := "packs"
+	if s.Packs == 1 {
+		packs = "pack"
+	}
+	return fmt.Sprintf("selected %s for %d overage in %d %s.", selected, s.Overage, s.Packs, packs)
+}
+
+// Normalize removes breakdown entries with a count <= 0 and recomputes
+// Packs and Overage from the remaining entries, so responses and stored
+// calculations never list unused sizes
+func (s *Solution) Normalize() {
+	if s == nil {
+		return
+	}
+
+	for size, count := range s.Breakdown {
+		if count <= 0 {
+			delete(s.Breakdown, size)
+		}
+	}
+
+	packs, items, _ := sumBreakdown(s.Breakdown)
+	s.Packs = packs
+	s.Overage = max(items-s.Amount, 0)
+}
+
+// MarginalShortfall returns, for each of the given sizes present in the breakdown,
+// the shortfall (items missing from the required amount) created by removing
+// one pack of that size; 0 means the pack can be dropped without a shortfall
+func (s *Solution) MarginalShortfall(sizes []int) map[int]int {
+	result := make(map[int]int)
+	if s == nil {
+		return result
+	}
+
+	total := s.TotalItems()
+	for _, size := range sizes {
+		if s.Breakdown[size] <= 0 {
+			continue
+		}
+
+		shortfall := s.Amount - (total - size)
+		if shortfall < 0 {
+			shortfall = 0
+		}
+		result[size] = shortfall
+	}
+
+	return result
 }
 
 // ValidateAmount checks the validity of the required amount
@@ -127,9 +305,8 @@ func ValidateAmount(amount int) error {
 		return fmt.Errorf("%w: amount must be greater than 0, got %d", ErrInvalidInput, amount)
 	}
 
-	const maxAmount = 1_000_000_000 // Reasonable maximum for amount
-	if amount > maxAmount {
-		return fmt.Errorf("%w: amount must not exceed %d, got %d", ErrInvalidInput, maxAmount, amount)
+	if amount > MaxAmount {
+		return fmt.Errorf("%w: amount must not exceed %d, got %d", ErrInvalidInput, MaxAmount, amount)
 	}
 
 	return nil
@@ -148,16 +325,132 @@ func ValidateSolverInput(sizes []int, amount int) error {
 	return nil
 }
 
+// SizesGCD returns the greatest common divisor of all sizes (0 for empty input)
+// Every total reachable with the sizes is a multiple of it
+func SizesGCD(sizes []int) int {
+	result := 0
+	for _, size := range sizes {
+		for size != 0 {
+			result, size = size, result%size
+		}
+	}
+	return result
+}
+
+// ValidateExactCoverage checks the RequireExactCoverage policy: the gcd of
+// sizes must divide amount, otherwise no packing can hit it exactly
+// Passing is necessary but not sufficient for an exact solution (e.g. 7 with
+// sizes 3 and 5), it only rules out amounts that are never reachable
+func ValidateExactCoverage(sizes []int, amount int) error {
+	if divisor := SizesGCD(sizes); divisor > 1 && amount%divisor != 0 {
+		return NewValidationError("amount", amount,
+			fmt.Sprintf("must be a multiple of %d (the greatest common divisor of sizes) to be packed exactly", divisor))
+	}
+	return nil
+}
+
 // IsSolutionStrict checks if the solution is exact (without overage)
 func IsSolutionStrict(solution *Solution) bool {
 	return solution != nil && solution.Overage == 0
 }
 
+// Priority selects the order of the optimization criteria
+type Priority int
+
+const (
+	PriorityOveragePacks Priority = iota // Less overage, then fewer packs (default, as in the brief)
+	PriorityPacksOverage                 // Fewer packs, then less overage
+)
+
+// String returns the priority name used in requests and solve options
+func (p Priority) String() string {
+	if p == PriorityPacksOverage {
+		return "packs_overage"
+	}
+	return "overage_packs"
+}
+
+// ParsePriority parses a priority name; empty selects PriorityOveragePacks
+func ParsePriority(name string) (Priority, error) {
+	switch name {
+	case "", PriorityOveragePacks.String():
+		return PriorityOveragePacks, nil
+	case PriorityPacksOverage.String():
+		return PriorityPacksOverage, nil
+	default:
+		return PriorityOveragePacks, fmt.Errorf("%w: priority must be %s or %s, got %q",
+			ErrInvalidInput, PriorityOveragePacks, PriorityPacksOverage, name)
+	}
+}
+
+// ValidatePackCosts checks per-pack costs for a cost-minimizing solve: every
+// size needs a finite cost in 0..MaxPackCost, and costs for other sizes are
+// rejected (most likely a typo)
+func ValidatePackCosts(sizes []int, costs map[int]float64) error {
+	for _, size := range sizes {
+		if _, ok := costs[size]; !ok {
+			return fmt.Errorf("%w: missing cost for size %d", ErrInvalidInput, size)
+		}
+	}
+	for size, cost := range costs {
+		if !slices.Contains(sizes, size) {
+			return fmt.Errorf("%w: cost given for unknown size %d", ErrInvalidInput, size)
+		}
+		if math.IsNaN(cost) || cost < 0 || cost > MaxPackCost {
+			return fmt.Errorf("%w: cost of size %d must be between 0 and %d, got %v", ErrInvalidInput, size, MaxPackCost, cost)
+		}
+	}
+	return nil
+}
+
+// FormatPackCosts encodes costs as a solve option value, e.g. "250:1.5,500:2"
+// Sizes are sorted, so equal costs always produce equal values (and cache keys)
+func FormatPackCosts(costs map[int]float64) string {
+	sizes := make([]int, 0, len(costs))
+	for size := range costs {
+		sizes = append(sizes, size)
+	}
+	slices.Sort(sizes)
+
+	parts := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		parts = append(parts, strconv.Itoa(size)+":"+strconv.FormatFloat(costs[size], 'g', -1, 64))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParsePackCosts decodes a FormatPackCosts value; the costs are not validated
+func ParsePackCosts(value string) (map[int]float64, error) {
+	costs := make(map[int]float64)
+	for _, part := range strings.Split(value, ",") {
+		rawSize, rawCost, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid pack cost %q, want size:cost", ErrInvalidInput, part)
+		}
+		size, err := strconv.Atoi(rawSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid pack cost size %q", ErrInvalidInput, rawSize)
+		}
+		cost, err := strconv.ParseFloat(rawCost, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid pack cost %q", ErrInvalidInput, rawCost)
+		}
+		costs[size] = cost
+	}
+	return costs, nil
+}
+
 // CompareSolutions compares two solutions and returns the better one
 // Criteria (by priority):
 // 1. Less overage
 // 2. Fewer packs
 func CompareSolutions(s1, s2 *Solution) *Solution {
+	return CompareSolutionsBy(s1, s2, PriorityOveragePacks)
+}
+
+// CompareSolutionsBy compares two solutions under the given priority and returns the better one
+// On a full tie s2 is returned
+func CompareSolutionsBy(s1, s2 *Solution, priority Priority) *Solution {
 	if s1 == nil {
 		return s2
 	}
@@ -165,16 +458,18 @@ func CompareSolutions(s1, s2 *Solution) *Solution {
 		return s1
 	}
 
-	// Priority 1: less overage
-	if s1.Overage != s2.Overage {
-		if s1.Overage < s2.Overage {
+	first, second := s1.Overage-s2.Overage, s1.Packs-s2.Packs
+	if priority == PriorityPacksOverage {
+		first, second = second, first
+	}
+
+	if first != 0 {
+		if first < 0 {
 			return s1
 		}
 		return s2
 	}
-
-	// Priority 2: fewer packs
-	if s1.Packs < s2.Packs {
+	if second < 0 {
 		return s1
 	}
 
@@ -224,9 +519,6 @@ func (s *Solution) Validate() error {
 		return fmt.Errorf("invalid amount: %d", s.Amount)
 	}
 
-	totalPacks := 0
-	totalItems := 0
-
 	for size, count := range s.Breakdown {
 		if size <= 0 {
 			return fmt.Errorf("invalid pack size: %d", size)
@@ -234,8 +526,11 @@ func (s *Solution) Validate() error {
 		if count < 0 {
 			return fmt.Errorf("invalid pack count: %d for size %d", count, size)
 		}
-		totalPacks += count
-		totalItems += size * count
+	}
+
+	totalPacks, totalItems, ok := sumBreakdown(s.Breakdown)
+	if !ok {
+		return fmt.Errorf("pack totals overflow int (max %d)", math.MaxInt)
 	}
 
 	if totalPacks != s.Packs {
@@ -2,6 +2,10 @@ package domain
 
 import (
 	"errors"
+	"math"
+	"math/bits"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -41,6 +45,17 @@ func TestValidatePackSizes(t *testing.T) {
 			wantErr: true,
 			errType: ErrInvalidInput,
 		},
+		{
+			name:    "exactly max distinct sizes",
+			sizes:   sequentialSizes(MaxDistinctSizes),
+			wantErr: false,
+		},
+		{
+			name:    "over max distinct sizes",
+			sizes:   sequentialSizes(MaxDistinctSizes + 1),
+			wantErr: true,
+			errType: ErrInvalidInput,
+		},
 	}
 
 	for _, tt := range tests {
@@ -57,6 +72,75 @@ func TestValidatePackSizes(t *testing.T) {
 	}
 }
 
+// sequentialSizes returns the distinct sizes 1..n
+func sequentialSizes(n int) []int {
+	sizes := make([]int, n)
+	for i := range sizes {
+		sizes[i] = i + 1
+	}
+	return sizes
+}
+
+func TestValidateDimensions(t *testing.T) {
+	sizes := []int{250, 500}
+
+	tests := []struct {
+		name       string
+		dimensions map[int]Dimensions
+		wantErr    bool
+	}{
+		{name: "no dimensions", dimensions: nil},
+		{name: "some sizes", dimensions: map[int]Dimensions{250: {Length: 30, Width: 20, Height: 10}}},
+		{name: "unknown size", dimensions: map[int]Dimensions{1000: {Length: 30, Width: 20, Height: 10}}, wantErr: true},
+		{name: "zero dimension", dimensions: map[int]Dimensions{250: {Length: 30, Width: 0, Height: 10}}, wantErr: true},
+		{name: "negative dimension", dimensions: map[int]Dimensions{500: {Length: -1, Width: 20, Height: 10}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDimensions(sizes, tt.dimensions)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateDimensions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("ValidateDimensions() error type = %v, want %v", err, ErrInvalidInput)
+			}
+		})
+	}
+}
+
+func TestPackSizeSetTotalVolume(t *testing.T) {
+	set := &PackSizeSet{
+		Sizes: []int{250, 500, 1000},
+		Dimensions: map[int]Dimensions{
+			250: {Length: 20, Width: 10, Height: 10}, // 2000
+			500: {Length: 20, Width: 20, Height: 10}, // 4000
+		},
+	}
+
+	tests := []struct {
+		name       string
+		set        *PackSizeSet
+		breakdown  map[int]int
+		wantVolume float64
+		wantOK     bool
+	}{
+		{name: "dimensioned sizes", set: set, breakdown: map[int]int{500: 2, 250: 1}, wantVolume: 10000, wantOK: true},
+		{name: "unused undimensioned size", set: set, breakdown: map[int]int{250: 3, 1000: 0}, wantVolume: 6000, wantOK: true},
+		{name: "undimensioned size used", set: set, breakdown: map[int]int{1000: 1, 250: 1}},
+		{name: "set without dimensions", set: &PackSizeSet{Sizes: []int{250}}, breakdown: map[int]int{250: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volume, ok := tt.set.TotalVolume(tt.breakdown)
+			if ok != tt.wantOK || volume != tt.wantVolume {
+				t.Errorf("TotalVolume() = %v, %v, want %v, %v", volume, ok, tt.wantVolume, tt.wantOK)
+			}
+		})
+	}
+}
+
 func TestNewSolution(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -111,6 +195,171 @@ func TestNewSolution(t *testing.T) {
 	}
 }
 
+func TestSolutionExplain(t *testing.T) {
+	tests := []struct {
+		name      string
+		breakdown map[int]int
+		amount    int
+		want      string
+	}{
+		{
+			name:      "zero overage",
+			breakdown: map[int]int{250: 1, 5000: 2, 2000: 1},
+			amount:    12250,
+			want:      "selected 5000×2 + 2000×1 + 250×1 for 0 overage in 4 packs.",
+		},
+		{
+			name:      "overage",
+			breakdown: map[int]int{250: 1, 5000: 2, 2000: 1},
+			amount:    12001,
+			want:      "selected 5000×2 + 2000×1 + 250×1 for 249 overage in 4 packs.",
+		},
+		{
+			name:      "single pack",
+			breakdown: map[int]int{500: 1, 250: 0},
+			amount:    251,
+			want:      "selected 500×1 for 249 overage in 1 pack.",
+		},
+		{
+			name:      "empty breakdown",
+			breakdown: map[int]int{},
+			want:      "selected no packs for 0 overage in 0 packs.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Repeated to catch map iteration order leaking into the text
+			for range 10 {
+				if got := NewSolution(tt.breakdown, tt.amount).Explain(); got != tt.want {
+					t.Fatalf("Explain() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSolutionNormalize(t *testing.T) {
+	tests := []struct {
+		name          string
+		solution      *Solution
+		wantBreakdown map[int]int
+		wantPacks     int
+		wantOverage   int
+	}{
+		{
+			name:          "zero entry",
+			solution:      NewSolution(map[int]int{250: 0, 500: 1}, 251),
+			wantBreakdown: map[int]int{500: 1},
+			wantPacks:     1,
+			wantOverage:   249,
+		},
+		{
+			name:          "negative entry and stale totals",
+			solution:      &Solution{Breakdown: map[int]int{250: -1, 5000: 2, 2000: 1}, Packs: 7, Overage: 3, Amount: 12001},
+			wantBreakdown: map[int]int{5000: 2, 2000: 1},
+			wantPacks:     3,
+			wantOverage:   0,
+		},
+		{
+			name:          "already normalized",
+			solution:      NewSolution(map[int]int{250: 1, 5000: 2, 2000: 1}, 12001),
+			wantBreakdown: map[int]int{250: 1, 5000: 2, 2000: 1},
+			wantPacks:     4,
+			wantOverage:   249,
+		},
+		{
+			name:          "only zero entries",
+			solution:      &Solution{Breakdown: map[int]int{250: 0}, Amount: 0},
+			wantBreakdown: map[int]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.solution.Normalize()
+
+			if !reflect.DeepEqual(tt.solution.Breakdown, tt.wantBreakdown) {
+				t.Errorf("breakdown = %v, want %v", tt.solution.Breakdown, tt.wantBreakdown)
+			}
+			if tt.solution.Packs != tt.wantPacks || tt.solution.Overage != tt.wantOverage {
+				t.Errorf("packs = %d, overage = %d, want %d and %d", tt.solution.Packs, tt.solution.Overage, tt.wantPacks, tt.wantOverage)
+			}
+		})
+	}
+
+	// A nil solution is left alone
+	var solution *Solution
+	solution.Normalize()
+}
+
+func TestSolutionTotalsOverflow(t *testing.T) {
+	half := math.MaxInt/2 + 1 // Two of these overflow int on any platform
+
+	type overflowCase struct {
+		name      string
+		breakdown map[int]int
+		wantItems int
+		wantValid bool
+	}
+	tests := []overflowCase{
+		{
+			name:      "at the boundary",
+			breakdown: map[int]int{math.MaxInt: 1},
+			wantItems: math.MaxInt,
+			wantValid: true,
+		},
+		{
+			name:      "product overflows",
+			breakdown: map[int]int{half: 2},
+			wantItems: math.MaxInt,
+		},
+		{
+			name:      "sum overflows",
+			breakdown: map[int]int{half: 1, half - 1: 1, 1: 1},
+			wantItems: math.MaxInt,
+		},
+		{
+			name:      "pack count overflows",
+			breakdown: map[int]int{1: math.MaxInt, 2: 1},
+			wantItems: math.MaxInt,
+		},
+	}
+	if bits.UintSize == 64 {
+		// 2^23 packs of 2^40 items overflow int64; the shift is written via
+		// UintSize so that 32-bit builds still compile
+		tests = append(tests, overflowCase{
+			name:      "64-bit product overflows",
+			breakdown: map[int]int{1 << (bits.UintSize - 24): 1 << 23},
+			wantItems: math.MaxInt,
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solution := NewSolution(tt.breakdown, 1)
+
+			if got := solution.TotalItems(); got != tt.wantItems {
+				t.Errorf("TotalItems() = %d, want %d", got, tt.wantItems)
+			}
+			if solution.Overage < 0 || solution.Packs < 0 {
+				t.Errorf("packs = %d, overage = %d, want non-negative totals", solution.Packs, solution.Overage)
+			}
+
+			err := solution.Validate()
+			if tt.wantValid && err != nil {
+				t.Errorf("Validate() error = %v, want nil", err)
+			}
+			if !tt.wantValid && (err == nil || !strings.Contains(err.Error(), "overflow")) {
+				t.Errorf("Validate() error = %v, want an overflow error", err)
+			}
+			if solution.IsValid() != tt.wantValid {
+				t.Errorf("IsValid() = %v, want %v", solution.IsValid(), tt.wantValid)
+			}
+		})
+	}
+}
+
 func TestSolutionIsValid(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -196,6 +445,45 @@ func TestValidateAmount(t *testing.T) {
 	}
 }
 
+func TestValidateExactCoverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		sizes   []int
+		amount  int
+		wantErr bool
+	}{
+		{name: "multiple of gcd", sizes: []int{250, 500}, amount: 750},
+		{name: "not a multiple of gcd", sizes: []int{250, 500}, amount: 251, wantErr: true},
+		{name: "gcd of coprime sizes is 1", sizes: []int{4, 7}, amount: 5},
+		{name: "single size", sizes: []int{6}, amount: 12},
+		{name: "single size not dividing", sizes: []int{6}, amount: 13, wantErr: true},
+		{name: "gcd below smallest size", sizes: []int{6, 9}, amount: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateExactCoverage(tt.sizes, tt.amount)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateExactCoverage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != "amount" {
+				t.Errorf("expected ValidationError for amount, got %v", err)
+			}
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+
+	if got := SizesGCD([]int{250, 500, 1000}); got != 250 {
+		t.Errorf("SizesGCD = %d, want 250", got)
+	}
+}
+
 func TestCompareSolutions(t *testing.T) {
 	tests := []struct {
 		name string
@@ -232,3 +520,147 @@ func TestCompareSolutions(t *testing.T) {
 		})
 	}
 }
+
+func TestCompareSolutionsBy_PacksOverage(t *testing.T) {
+	tests := []struct {
+		name string
+		s1   *Solution
+		s2   *Solution
+		want *Solution
+	}{
+		{
+			name: "s1 has fewer packs despite more overage",
+			s1:   &Solution{Overage: 250, Packs: 1},
+			s2:   &Solution{Overage: 0, Packs: 2},
+			want: &Solution{Overage: 250, Packs: 1},
+		},
+		{
+			name: "same packs, s2 has less overage",
+			s1:   &Solution{Overage: 20, Packs: 2},
+			s2:   &Solution{Overage: 10, Packs: 2},
+			want: &Solution{Overage: 10, Packs: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareSolutionsBy(tt.s1, tt.s2, PriorityPacksOverage)
+			if got.Overage != tt.want.Overage || got.Packs != tt.want.Packs {
+				t.Errorf("CompareSolutionsBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Priority
+		wantErr bool
+	}{
+		{name: "", want: PriorityOveragePacks},
+		{name: "overage_packs", want: PriorityOveragePacks},
+		{name: "packs_overage", want: PriorityPacksOverage},
+		{name: "packs", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePriority(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParsePriority(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if tt.wantErr && !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("ParsePriority(%q) error = %v, want ErrInvalidInput", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParsePriority(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPackCosts(t *testing.T) {
+	costs := map[int]float64{5000: 10, 250: 1.5, 500: 0}
+	value := FormatPackCosts(costs)
+	if value != "250:1.5,500:0,5000:10" {
+		t.Errorf("FormatPackCosts() = %q, want sizes in order", value)
+	}
+	parsed, err := ParsePackCosts(value)
+	if err != nil {
+		t.Fatalf("ParsePackCosts() error = %v", err)
+	}
+	if len(parsed) != len(costs) || parsed[250] != 1.5 || parsed[500] != 0 || parsed[5000] != 10 {
+		t.Errorf("ParsePackCosts() = %v, want %v", parsed, costs)
+	}
+	for _, invalid := range []string{"", "250", "x:1", "250:cheap"} {
+		if _, err := ParsePackCosts(invalid); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("ParsePackCosts(%q) error = %v, want ErrInvalidInput", invalid, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		costs   map[int]float64
+		wantErr bool
+	}{
+		{name: "every size", costs: map[int]float64{250: 1, 500: 0}},
+		{name: "missing size", costs: map[int]float64{250: 1}, wantErr: true},
+		{name: "unknown size", costs: map[int]float64{250: 1, 500: 2, 750: 3}, wantErr: true},
+		{name: "negative", costs: map[int]float64{250: 1, 500: -1}, wantErr: true},
+		{name: "too large", costs: map[int]float64{250: 1, 500: MaxPackCost + 1}, wantErr: true},
+		{name: "NaN", costs: map[int]float64{250: 1, 500: math.NaN()}, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := ValidatePackCosts([]int{250, 500}, tt.costs)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidatePackCosts() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if tt.wantErr && !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: ValidatePackCosts() error = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+}
+
+func TestSolutionMarginalShortfall(t *testing.T) {
+	// 12001 covered by 2×5000 + 1×2000 + 1×250 = 12250 (overage 249)
+	solution := NewSolution(map[int]int{5000: 2, 2000: 1, 250: 1}, 12001)
+
+	tests := []struct {
+		name  string
+		sizes []int
+		want  map[int]int
+	}{
+		{
+			name:  "all sizes in breakdown",
+			sizes: []int{250, 2000, 5000},
+			want:  map[int]int{250: 1, 2000: 1751, 5000: 4751},
+		},
+		{
+			name:  "sizes not in breakdown are skipped",
+			sizes: []int{250, 500, 1000},
+			want:  map[int]int{250: 1},
+		},
+		{
+			name:  "no sizes",
+			sizes: nil,
+			want:  map[int]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := solution.MarginalShortfall(tt.sizes)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MarginalShortfall() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("pack smaller than overage leaves no shortfall", func(t *testing.T) {
+		s := NewSolution(map[int]int{500: 1, 250: 1}, 251)
+		got := s.MarginalShortfall([]int{250, 500})
+		want := map[int]int{250: 0, 500: 1}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("MarginalShortfall() = %v, want %v", got, want)
+		}
+	})
+}
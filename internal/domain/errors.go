@@ -35,6 +35,23 @@ var (
 
 	// ErrCacheUnavailable is returned when cache is unavailable
 	ErrCacheUnavailable = errors.New("cache unavailable")
+
+	// ErrSolverMismatch is returned when a solver result disagrees
+	// with an independently computed optimum (see usecase.VerifyingSolver)
+	ErrSolverMismatch = errors.New("solver result mismatch")
+
+	// ErrMemoryBudgetExceeded is returned when solving would allocate
+	// more memory than the solver's configured budget
+	ErrMemoryBudgetExceeded = errors.New("memory budget exceeded")
+
+	// ErrSearchTruncated is returned when the DP table had to be clipped below
+	// its natural bound: together with ErrNoSolution when no solution fit in
+	// the clipped search space, alone when the priority needs the full range
+	ErrSearchTruncated = errors.New("search space truncated")
+
+	// ErrCalculationIntegrity is returned when a stored calculation no longer
+	// forms a consistent solution, i.e. the row was modified after it was saved
+	ErrCalculationIntegrity = errors.New("calculation integrity check failed")
 )
 
 // ValidationError represents a validation error with additional context
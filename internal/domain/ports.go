@@ -25,6 +25,61 @@ type Solver interface {
 	Solve(ctx context.Context, sizes []int, amount int) (*Solution, error)
 }
 
+// StrictSolver defines the interface for exact-only solving
+type StrictSolver interface {
+	// SolveStrict finds the packing that hits amount exactly (zero overage)
+	// with the fewest packs.
+	//
+	// Errors:
+	//   - ErrInvalidInput: if input data fails validation
+	//   - ErrNoSolutionStrict: if no zero-overage combination exists
+	SolveStrict(ctx context.Context, sizes []int, amount int) (*Solution, error)
+}
+
+// RangeSolver defines the interface for solving when the amount is a range
+type RangeSolver interface {
+	// SolveRange finds the packing whose total lies within [minAmount, maxAmount]
+	// with the fewest packs. The returned solution's Amount is minAmount and
+	// Overage is measured against it.
+	//
+	// Errors:
+	//   - ErrInvalidInput: if input data fails validation or maxAmount < minAmount
+	//   - ErrNoSolution: if no total within the range is reachable
+	SolveRange(ctx context.Context, sizes []int, minAmount, maxAmount int) (*Solution, error)
+}
+
+// SeriesSolver defines the interface for solving many amounts at once
+type SeriesSolver interface {
+	// SolveSeries finds the optimal solution (least overage, then fewest
+	// packs) for each of amounts, sharing one DP table up to the largest.
+	// solutions[i] belongs to amounts[i].
+	//
+	// Errors:
+	//   - ErrInvalidInput: if input data fails validation or the shared table
+	//     would exceed the DP size limit
+	SolveSeries(ctx context.Context, sizes []int, amounts []int) ([]*Solution, error)
+}
+
+// DiagnosticSolver defines the interface for explaining a solution
+type DiagnosticSolver interface {
+	// UnusedSizes returns, in ascending order, the sizes that appear in no
+	// optimal packing for amount (least overage, then fewest packs), i.e. sizes
+	// that are always dominated by the others for this amount.
+	//
+	// Errors: the same as Solve
+	UnusedSizes(ctx context.Context, sizes []int, amount int) ([]int, error)
+}
+
+// TopKSolver defines the interface for listing alternative solutions
+type TopKSolver interface {
+	// SolveTopK returns up to k distinct solutions for amount, best first
+	// (least overage, then fewest packs). The first is the optimal solution;
+	// fewer than k are returned when no more reasonable packings exist.
+	//
+	// Errors: the same as Solve, plus ErrInvalidInput if k is out of range
+	SolveTopK(ctx context.Context, sizes []int, amount, k int) ([]*Solution, error)
+}
+
 // PackSizeRepository defines the interface for working with pack size sets
 // This interface represents a Port for the repository
 type PackSizeRepository interface {
@@ -32,13 +87,14 @@ type PackSizeRepository interface {
 	Create(ctx context.Context, packSizeSet *PackSizeSet) (*PackSizeSet, error)
 
 	// GetByID gets a pack size set by identifier
+	// Returns ErrPackSizeSetNotFound if there is no such set
 	GetByID(ctx context.Context, id int64) (*PackSizeSet, error)
 
 	// GetByName gets a pack size set by name
 	GetByName(ctx context.Context, name string) (*PackSizeSet, error)
 
-	// List returns a list of all pack size sets
-	List(ctx context.Context) ([]*PackSizeSet, error)
+	// List returns a page of pack size sets, newest first
+	List(ctx context.Context, limit, offset int) ([]*PackSizeSet, error)
 
 	// Update updates an existing pack size set
 	Update(ctx context.Context, packSizeSet *PackSizeSet) error
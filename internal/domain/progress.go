@@ -0,0 +1,19 @@
+package domain
+
+import "context"
+
+// ProgressFunc receives solver progress: done out of total units of work
+type ProgressFunc func(done, total int)
+
+type progressKey struct{}
+
+// WithProgress returns a context asking solvers to report progress to fn
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ProgressFromContext returns the progress callback set on the context (nil if none)
+func ProgressFromContext(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
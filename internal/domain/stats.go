@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// OverageBucket is one bucket of an overage histogram
+// Bounds are inclusive: the bucket counts calculations with Lower <= overage <= Upper
+type OverageBucket struct {
+	Lower int   `json:"lower"`
+	Upper int   `json:"upper"`
+	Count int64 `json:"count"`
+}
+
+// StoredCalculation is a calculation from the history
+type StoredCalculation struct {
+	ID           int64       `json:"id"`
+	PackSetID    *int64      `json:"pack_set_id,omitempty"` // Stored set the calculation was solved with, if any
+	PackSizes    []int       `json:"pack_sizes"`
+	Amount       int         `json:"amount"`
+	Breakdown    map[int]int `json:"breakdown"` // Pack size -> quantity
+	TotalPacks   int         `json:"total_packs"`
+	Overage      int         `json:"overage"`
+	CalculatedAt time.Time   `json:"calculated_at"`
+
+	CorrelationID string            `json:"correlation_id,omitempty"` // Request that produced the calculation
+	Options       map[string]string `json:"options,omitempty"`        // Request options the calculation was solved with
+	SolverVersion string            `json:"solver_version,omitempty"` // SolverVersion at save time; empty for older calculations
+}
+
+// CalculationFilter selects calculations from the history
+// Zero values do not filter
+type CalculationFilter struct {
+	PackSetID *int64    // Only calculations of this pack set
+	From      time.Time // Calculated at or after
+	To        time.Time // Calculated before
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "runtime/debug"
+
+// DefaultSolverVersion is the solver version when build info has no usable version
+const DefaultSolverVersion = "dev"
+
+// SolverVersion returns the solver version derived from build info:
+// the VCS revision when available, otherwise the main module version
+// It namespaces cached solutions, so a new build never serves results computed
+// by a previous solver version, and is stored with every calculation
+func SolverVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return DefaultSolverVersion
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" && setting.Value != "" {
+			revision := setting.Value
+			if len(revision) > 12 {
+				revision = revision[:12]
+			}
+			return revision
+		}
+	}
+
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	return DefaultSolverVersion
+}
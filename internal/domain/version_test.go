@@ -0,0 +1,13 @@
+package domain
+
+import "testing"
+
+func TestSolverVersion(t *testing.T) {
+	version := SolverVersion()
+	if version == "" {
+		t.Fatal("SolverVersion() must not be empty")
+	}
+	if len(version) > 64 {
+		t.Errorf("SolverVersion() = %q, longer than the calculations.solver_version column", version)
+	}
+}
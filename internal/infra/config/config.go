@@ -22,6 +22,9 @@ type ServerConfig struct {
 	WriteTimeout    time.Duration
 	IdleTimeout     time.Duration
 	ShutdownTimeout time.Duration
+	TLSCertFile     string // TLS is served when both files are set
+	TLSKeyFile      string
+	H2C             bool // Plaintext HTTP/2 behind a TLS-terminating proxy
 }
 
 // DatabaseConfig holds database configuration
@@ -48,8 +51,13 @@ type RedisConfig struct {
 
 // AppConfig holds application configuration
 type AppConfig struct {
-	Version     string
-	Environment string
+	Version      string
+	Environment  string
+	SolveTimeout time.Duration // Solver budget per solve request (0 = no bound)
+	TimeFormat   string        // Timestamp serialization in responses: rfc3339 or unix_ms
+
+	SolverMaxTableSize int // Maximum number of DP table elements per solve
+	SolverMaxTopN      int // Largest number of solutions listed with ?k=N
 }
 
 // LoggerConfig holds logger configuration
@@ -68,6 +76,9 @@ func Load() *Config {
 			WriteTimeout:    getDurationEnv("SERVER_WRITE_TIMEOUT", 15*time.Second),
 			IdleTimeout:     getDurationEnv("SERVER_IDLE_TIMEOUT", 60*time.Second),
 			ShutdownTimeout: getDurationEnv("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+			TLSCertFile:     getEnv("TLS_CERT_FILE", ""),
+			TLSKeyFile:      getEnv("TLS_KEY_FILE", ""),
+			H2C:             getEnv("HTTP2_H2C", "false") == "true",
 		},
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "localhost"),
@@ -88,8 +99,13 @@ func Load() *Config {
 			PoolSize: getIntEnv("REDIS_POOL_SIZE", 10),
 		},
 		App: AppConfig{
-			Version:     getEnv("VERSION", "dev"),
-			Environment: getEnv("ENVIRONMENT", "development"),
+			Version:      getEnv("VERSION", "dev"),
+			Environment:  getEnv("ENVIRONMENT", "development"),
+			SolveTimeout: getDurationEnv("SOLVE_TIMEOUT", 10*time.Second),
+			TimeFormat:   getEnv("TIME_FORMAT", "rfc3339"),
+
+			SolverMaxTableSize: getIntEnv("SOLVER_MAX_TABLE_SIZE", 10_000_000),
+			SolverMaxTopN:      getIntEnv("SOLVER_MAX_TOP_N", 10),
 		},
 		Logger: LoggerConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
@@ -0,0 +1,312 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// Repository is an in-memory store for pack size sets and calculations, for
+// tests and small installs without PostgreSQL
+// Safe for concurrent use; everything is lost on restart
+type Repository struct {
+	mu           sync.RWMutex
+	packSets     map[int64]*domain.PackSizeSet
+	calculations map[int64]domain.StoredCalculation
+
+	nextPackSetID     atomic.Int64
+	nextCalculationID atomic.Int64
+}
+
+// NewRepository creates an empty in-memory repository
+func NewRepository() *Repository {
+	return &Repository{
+		packSets:     make(map[int64]*domain.PackSizeSet),
+		calculations: make(map[int64]domain.StoredCalculation),
+	}
+}
+
+// PackSet operations
+
+// Create creates a new pack size set
+// Returns domain.ErrPackSizeSetAlreadyExists if the name is taken
+func (r *Repository) Create(ctx context.Context, packSizeSet *domain.PackSizeSet) (*domain.PackSizeSet, error) {
+	if err := packSizeSet.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pack size set: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkNameLocked(packSizeSet, 0); err != nil {
+		return nil, err
+	}
+
+	id := r.nextPackSetID.Add(1)
+	created := clonePackSet(packSizeSet)
+	created.ID = &id
+	r.packSets[id] = created
+
+	return clonePackSet(created), nil
+}
+
+// GetByID gets a pack size set by identifier
+func (r *Repository) GetByID(ctx context.Context, id int64) (*domain.PackSizeSet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	packSet, ok := r.packSets[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", domain.ErrPackSizeSetNotFound, id)
+	}
+	return clonePackSet(packSet), nil
+}
+
+// GetByName gets a pack size set by name
+func (r *Repository) GetByName(ctx context.Context, name string) (*domain.PackSizeSet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, packSet := range r.packSets {
+		if packSet.Name != nil && *packSet.Name == name {
+			return clonePackSet(packSet), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", domain.ErrPackSizeSetNotFound, name)
+}
+
+// List returns a page of pack size sets, newest first
+func (r *Repository) List(ctx context.Context, limit, offset int) ([]*domain.PackSizeSet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// IDs are assigned in insertion order
+	ids := make([]int64, 0, len(r.packSets))
+	for id := range r.packSets {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+
+	ids = page(ids, limit, offset)
+	packSets := make([]*domain.PackSizeSet, 0, len(ids))
+	for _, id := range ids {
+		packSets = append(packSets, clonePackSet(r.packSets[id]))
+	}
+	return packSets, nil
+}
+
+// Update updates an existing pack size set
+// Returns domain.ErrPackSizeSetAlreadyExists if the new name is taken
+func (r *Repository) Update(ctx context.Context, packSizeSet *domain.PackSizeSet) error {
+	if packSizeSet.ID == nil {
+		return fmt.Errorf("pack set ID is required for update")
+	}
+	if err := packSizeSet.Validate(); err != nil {
+		return fmt.Errorf("invalid pack size set: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := *packSizeSet.ID
+	if _, ok := r.packSets[id]; !ok {
+		return fmt.Errorf("%w: %d", domain.ErrPackSizeSetNotFound, id)
+	}
+	if err := r.checkNameLocked(packSizeSet, id); err != nil {
+		return err
+	}
+
+	r.packSets[id] = clonePackSet(packSizeSet)
+	return nil
+}
+
+// Delete deletes a pack size set by identifier
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.packSets[id]; !ok {
+		return fmt.Errorf("%w: %d", domain.ErrPackSizeSetNotFound, id)
+	}
+	delete(r.packSets, id)
+	return nil
+}
+
+// checkNameLocked returns domain.ErrPackSizeSetAlreadyExists if another set
+// than the one with the given ID uses the name; callers hold r.mu
+func (r *Repository) checkNameLocked(packSizeSet *domain.PackSizeSet, id int64) error {
+	if packSizeSet.Name == nil {
+		return nil
+	}
+	for existingID, existing := range r.packSets {
+		if existingID != id && existing.Name != nil && *existing.Name == *packSizeSet.Name {
+			return fmt.Errorf("%w: %s", domain.ErrPackSizeSetAlreadyExists, *packSizeSet.Name)
+		}
+	}
+	return nil
+}
+
+// Calculation operations
+
+// SaveCalculation saves a calculation (implements the HTTP handler repository)
+// Accepts the generic record built by the HTTP handler: pack_sizes, amount and
+// solution are required, pack_set_id, correlation_id and options are optional
+func (r *Repository) SaveCalculation(ctx context.Context, record interface{}) (int64, error) {
+	calculation, err := storedCalculationFromMap(record)
+	if err != nil {
+		return 0, err
+	}
+
+	calculation.ID = r.nextCalculationID.Add(1)
+	calculation.CalculatedAt = time.Now()
+	calculation.SolverVersion = domain.SolverVersion()
+
+	r.mu.Lock()
+	r.calculations[calculation.ID] = calculation
+	r.mu.Unlock()
+
+	return calculation.ID, nil
+}
+
+// ListCalculations returns a page of stored calculations, newest first
+// A non-nil packSetID restricts the page to that pack set
+func (r *Repository) ListCalculations(ctx context.Context, packSetID *int64, limit, offset int) ([]domain.StoredCalculation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	calculations := make([]domain.StoredCalculation, 0, len(r.calculations))
+	for _, calculation := range r.calculations {
+		if matchesPackSet(calculation, packSetID) {
+			calculations = append(calculations, cloneCalculation(calculation))
+		}
+	}
+	// Same order as the PostgreSQL repository: calculated_at DESC, id DESC
+	sort.Slice(calculations, func(i, j int) bool {
+		if !calculations[i].CalculatedAt.Equal(calculations[j].CalculatedAt) {
+			return calculations[i].CalculatedAt.After(calculations[j].CalculatedAt)
+		}
+		return calculations[i].ID > calculations[j].ID
+	})
+
+	return page(calculations, limit, offset), nil
+}
+
+// CountCalculations returns the number of stored calculations
+// A non-nil packSetID counts only that pack set
+func (r *Repository) CountCalculations(ctx context.Context, packSetID *int64) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, calculation := range r.calculations {
+		if matchesPackSet(calculation, packSetID) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// storedCalculationFromMap converts the generic record built by the HTTP handler
+func storedCalculationFromMap(record interface{}) (domain.StoredCalculation, error) {
+	recordMap, ok := record.(map[string]interface{})
+	if !ok {
+		return domain.StoredCalculation{}, fmt.Errorf("invalid record type")
+	}
+
+	packSizes, ok := recordMap["pack_sizes"].([]int)
+	if !ok {
+		return domain.StoredCalculation{}, fmt.Errorf("invalid pack_sizes type")
+	}
+	amount, ok := recordMap["amount"].(int)
+	if !ok {
+		return domain.StoredCalculation{}, fmt.Errorf("invalid amount type")
+	}
+	solution, ok := recordMap["solution"].(*domain.Solution)
+	if !ok || solution == nil {
+		return domain.StoredCalculation{}, fmt.Errorf("invalid solution type")
+	}
+	if err := solution.Validate(); err != nil {
+		return domain.StoredCalculation{}, fmt.Errorf("invalid solution: %w", err)
+	}
+
+	packSetID, _ := recordMap["pack_set_id"].(*int64)
+	correlationID, _ := recordMap["correlation_id"].(string)
+	options, _ := recordMap["options"].(domain.SolveOptions)
+
+	calculation := domain.StoredCalculation{
+		PackSizes:     slices.Clone(packSizes),
+		Amount:        amount,
+		Breakdown:     maps.Clone(solution.Breakdown),
+		TotalPacks:    solution.Packs,
+		Overage:       solution.Overage,
+		CorrelationID: correlationID,
+	}
+	if packSetID != nil {
+		id := *packSetID
+		calculation.PackSetID = &id
+	}
+	if len(options) > 0 {
+		calculation.Options = maps.Clone(options)
+	}
+	return calculation, nil
+}
+
+// matchesPackSet reports whether the calculation belongs to packSetID (any when nil)
+func matchesPackSet(calculation domain.StoredCalculation, packSetID *int64) bool {
+	return packSetID == nil || (calculation.PackSetID != nil && *calculation.PackSetID == *packSetID)
+}
+
+// page returns the [offset, offset+limit) window of items
+// Non-positive limits default to 100, as in the PostgreSQL repository
+func page[T any](items []T, limit, offset int) []T {
+	if limit <= 0 {
+		limit = 100
+	}
+	if offset < 0 || offset >= len(items) {
+		return items[:0]
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
+
+// clonePackSet returns a copy that shares no memory with packSet
+func clonePackSet(packSet *domain.PackSizeSet) *domain.PackSizeSet {
+	clone := &domain.PackSizeSet{
+		Sizes:      slices.Clone(packSet.Sizes),
+		Dimensions: maps.Clone(packSet.Dimensions),
+	}
+	if packSet.DefaultAmount != nil {
+		amount := *packSet.DefaultAmount
+		clone.DefaultAmount = &amount
+	}
+	if packSet.ID != nil {
+		id := *packSet.ID
+		clone.ID = &id
+	}
+	if packSet.Name != nil {
+		name := *packSet.Name
+		clone.Name = &name
+	}
+	return clone
+}
+
+// cloneCalculation returns a copy whose slices and maps are not shared with the store
+func cloneCalculation(calculation domain.StoredCalculation) domain.StoredCalculation {
+	calculation.PackSizes = slices.Clone(calculation.PackSizes)
+	calculation.Breakdown = maps.Clone(calculation.Breakdown)
+	calculation.Options = maps.Clone(calculation.Options)
+	if calculation.PackSetID != nil {
+		id := *calculation.PackSetID
+		calculation.PackSetID = &id
+	}
+	return calculation
+}
+
+// Ensure Repository implements domain.PackSizeRepository interface
+var _ domain.PackSizeRepository = (*Repository)(nil)
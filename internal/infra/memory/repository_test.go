@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// calculationRecord builds a record as the HTTP handler does
+func calculationRecord(amount int, breakdown map[int]int) map[string]interface{} {
+	return map[string]interface{}{
+		"pack_sizes":     []int{250, 500},
+		"amount":         amount,
+		"solution":       domain.NewSolution(breakdown, amount),
+		"correlation_id": "req-123",
+		"options":        domain.SolveOptions{domain.SolveOptionMaxOverage: "100"},
+	}
+}
+
+func TestRepository_SaveCalculation_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository()
+
+	const saves = 100
+	ids := make([]int64, saves)
+	var wg sync.WaitGroup
+	for i := range saves {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := repo.SaveCalculation(ctx, calculationRecord(251, map[int]int{500: 1}))
+			if err != nil {
+				t.Errorf("SaveCalculation() error = %v", err)
+			}
+			ids[i] = id
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, saves)
+	for _, id := range ids {
+		if id <= 0 || seen[id] {
+			t.Fatalf("ids = %v, want %d distinct positive ids", ids, saves)
+		}
+		seen[id] = true
+	}
+	if count, err := repo.CountCalculations(ctx, nil); err != nil || count != saves {
+		t.Errorf("CountCalculations() = %d, %v, want %d", count, err, saves)
+	}
+}
+
+func TestRepository_ListCalculations_Order(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository()
+
+	for _, amount := range []int{251, 501, 751} {
+		if _, err := repo.SaveCalculation(ctx, calculationRecord(amount, map[int]int{500: amount/500 + 1})); err != nil {
+			t.Fatalf("SaveCalculation() error = %v", err)
+		}
+	}
+
+	calculations, err := repo.ListCalculations(ctx, nil, 100, 0)
+	if err != nil {
+		t.Fatalf("ListCalculations() error = %v", err)
+	}
+	var amounts []int
+	for _, calculation := range calculations {
+		amounts = append(amounts, calculation.Amount)
+	}
+	if len(amounts) != 3 || amounts[0] != 751 || amounts[1] != 501 || amounts[2] != 251 {
+		t.Errorf("amounts = %v, want newest first [751 501 251]", amounts)
+	}
+
+	first := calculations[0]
+	if first.CorrelationID != "req-123" || first.Options[domain.SolveOptionMaxOverage] != "100" || first.SolverVersion == "" {
+		t.Errorf("calculation = %+v, want correlation_id, options and solver_version recorded", first)
+	}
+
+	// Returned calculations do not alias the store
+	first.Breakdown[500] = 99
+	again, _ := repo.ListCalculations(ctx, nil, 1, 0)
+	if again[0].Breakdown[500] != 2 {
+		t.Errorf("breakdown = %v after modifying a listed copy", again[0].Breakdown)
+	}
+
+	page, _ := repo.ListCalculations(ctx, nil, 2, 1)
+	if len(page) != 2 || page[0].Amount != 501 {
+		t.Errorf("page = %+v, want [501 251]", page)
+	}
+	if none, _ := repo.ListCalculations(ctx, nil, 10, 3); len(none) != 0 {
+		t.Errorf("page past the end = %+v, want empty", none)
+	}
+
+	packSetID := int64(1)
+	if count, _ := repo.CountCalculations(ctx, &packSetID); count != 0 {
+		t.Errorf("count for pack set = %d, want 0", count)
+	}
+}
+
+func TestRepository_SaveCalculation_InvalidRecord(t *testing.T) {
+	repo := NewRepository()
+
+	if _, err := repo.SaveCalculation(context.Background(), "not a record"); err == nil {
+		t.Error("expected error for invalid record type")
+	}
+	if _, err := repo.SaveCalculation(context.Background(), map[string]interface{}{"amount": 251}); err == nil {
+		t.Error("expected error for missing fields")
+	}
+}
+
+func TestRepository_PackSets(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository()
+
+	standard, custom := "standard", "custom"
+	created, err := repo.Create(ctx, &domain.PackSizeSet{Name: &standard, Sizes: []int{250, 500}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := repo.Create(ctx, &domain.PackSizeSet{Name: &custom, Sizes: []int{23, 31}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if _, err := repo.Create(ctx, &domain.PackSizeSet{Name: &standard, Sizes: []int{1000}}); !errors.Is(err, domain.ErrPackSizeSetAlreadyExists) {
+		t.Errorf("Create() duplicate error = %v, want ErrPackSizeSetAlreadyExists", err)
+	}
+	if _, err := repo.Create(ctx, &domain.PackSizeSet{Name: &custom, Sizes: []int{0}}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Create() invalid error = %v, want ErrInvalidInput", err)
+	}
+
+	got, err := repo.GetByName(ctx, "standard")
+	if err != nil || *got.ID != *created.ID {
+		t.Fatalf("GetByName() = %+v, %v, want id %d", got, err, *created.ID)
+	}
+	if _, err := repo.GetByName(ctx, "missing"); !errors.Is(err, domain.ErrPackSizeSetNotFound) {
+		t.Errorf("GetByName() missing error = %v, want ErrPackSizeSetNotFound", err)
+	}
+
+	sets, err := repo.List(ctx, 10, 0)
+	if err != nil || len(sets) != 2 || *sets[0].Name != "custom" {
+		t.Fatalf("List() = %+v, %v, want newest (custom) first", sets, err)
+	}
+
+	got.Sizes = []int{1000}
+	got.Name = &custom
+	if err := repo.Update(ctx, got); !errors.Is(err, domain.ErrPackSizeSetAlreadyExists) {
+		t.Errorf("Update() to a taken name error = %v, want ErrPackSizeSetAlreadyExists", err)
+	}
+	got.Name = &standard
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated, _ := repo.GetByID(ctx, *created.ID); len(updated.Sizes) != 1 || updated.Sizes[0] != 1000 {
+		t.Errorf("sizes after update = %v, want [1000]", updated.Sizes)
+	}
+
+	// The default amount is stored by value, not shared with the caller
+	amount := 751
+	got.DefaultAmount = &amount
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	amount = 1
+	if updated, _ := repo.GetByID(ctx, *created.ID); updated.DefaultAmount == nil || *updated.DefaultAmount != 751 {
+		t.Errorf("default amount after update = %v, want 751", updated.DefaultAmount)
+	}
+	invalid := 0
+	got.DefaultAmount = &invalid
+	if err := repo.Update(ctx, got); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Update() with zero default amount error = %v, want ErrInvalidInput", err)
+	}
+
+	if err := repo.Delete(ctx, *created.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.GetByID(ctx, *created.ID); !errors.Is(err, domain.ErrPackSizeSetNotFound) {
+		t.Errorf("GetByID() after delete error = %v, want ErrPackSizeSetNotFound", err)
+	}
+	if err := repo.Delete(ctx, *created.ID); !errors.Is(err, domain.ErrPackSizeSetNotFound) {
+		t.Errorf("Delete() twice error = %v, want ErrPackSizeSetNotFound", err)
+	}
+}
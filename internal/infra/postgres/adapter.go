@@ -19,36 +19,51 @@ func NewRepositoryAdapter(repo *Repository) *RepositoryAdapter {
 
 // SaveCalculation saves a calculation (implements interface for HTTP handler)
 func (a *RepositoryAdapter) SaveCalculation(ctx context.Context, record interface{}) (int64, error) {
+	calcRecord, err := calculationRecordFromMap(record)
+	if err != nil {
+		return 0, err
+	}
+
+	// Save to database
+	return a.repo.SaveCalculation(ctx, calcRecord)
+}
+
+// calculationRecordFromMap converts the generic record built by the HTTP handler
+// Requires pack_sizes, amount and solution; pack_set_id, correlation_id and options are optional
+func calculationRecordFromMap(record interface{}) (*CalculationRecord, error) {
 	// Convert generic record to typed structure
 	recordMap, ok := record.(map[string]interface{})
 	if !ok {
-		return 0, fmt.Errorf("invalid record type")
+		return nil, fmt.Errorf("invalid record type")
 	}
 
 	// Extract data
 	packSizes, ok := recordMap["pack_sizes"].([]int)
 	if !ok {
-		return 0, fmt.Errorf("invalid pack_sizes type")
+		return nil, fmt.Errorf("invalid pack_sizes type")
 	}
 
 	amount, ok := recordMap["amount"].(int)
 	if !ok {
-		return 0, fmt.Errorf("invalid amount type")
+		return nil, fmt.Errorf("invalid amount type")
 	}
 
 	solution, ok := recordMap["solution"].(*domain.Solution)
 	if !ok {
-		return 0, fmt.Errorf("invalid solution type")
+		return nil, fmt.Errorf("invalid solution type")
 	}
 
-	// Create record for saving
-	calcRecord := &CalculationRecord{
-		PackSetID: nil, // No link to pack_set yet
-		PackSizes: packSizes,
-		Amount:    amount,
-		Solution:  solution,
-	}
+	packSetID, _ := recordMap["pack_set_id"].(*int64)
+	correlationID, _ := recordMap["correlation_id"].(string)
+	options, _ := recordMap["options"].(domain.SolveOptions)
 
-	// Save to database
-	return a.repo.SaveCalculation(ctx, calcRecord)
+	// Create record for saving
+	return &CalculationRecord{
+		PackSetID:     packSetID,
+		PackSizes:     packSizes,
+		Amount:        amount,
+		Solution:      solution,
+		CorrelationID: correlationID,
+		Options:       options,
+	}, nil
 }
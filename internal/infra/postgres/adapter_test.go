@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+func TestCalculationRecordFromMap_CorrelationID(t *testing.T) {
+	solution := domain.NewSolution(map[int]int{500: 1}, 251)
+
+	record, err := calculationRecordFromMap(map[string]interface{}{
+		"pack_sizes":     []int{250, 500},
+		"amount":         251,
+		"solution":       solution,
+		"correlation_id": "req-123",
+	})
+	if err != nil {
+		t.Fatalf("calculationRecordFromMap() error = %v", err)
+	}
+
+	if model := record.ToCalculationModel(); model.CorrelationID != "req-123" {
+		t.Errorf("model correlation_id = %q, want req-123", model.CorrelationID)
+	}
+
+	// Records without a correlation ID are still accepted
+	record, err = calculationRecordFromMap(map[string]interface{}{
+		"pack_sizes": []int{250, 500},
+		"amount":     251,
+		"solution":   solution,
+	})
+	if err != nil {
+		t.Fatalf("calculationRecordFromMap() error = %v", err)
+	}
+	if record.CorrelationID != "" {
+		t.Errorf("correlation_id = %q, want empty", record.CorrelationID)
+	}
+}
+
+func TestCalculationRecordFromMap_OptionsRoundTrip(t *testing.T) {
+	options := domain.SolveOptions{domain.SolveOptionMaxOverage: "100", "strict": "true"}
+
+	record, err := calculationRecordFromMap(map[string]interface{}{
+		"pack_sizes": []int{250, 500},
+		"amount":     251,
+		"solution":   domain.NewSolution(map[int]int{500: 1}, 251),
+		"options":    options,
+	})
+	if err != nil {
+		t.Fatalf("calculationRecordFromMap() error = %v", err)
+	}
+
+	// Write the options as they are stored in the column, then read them back
+	value, err := record.ToCalculationModel().Options.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+	var stored OptionsMap
+	if err := stored.Scan(value); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	model := CalculationModel{Options: stored}
+	if got := model.ToStoredCalculation().Options; !reflect.DeepEqual(got, map[string]string(options)) {
+		t.Errorf("options = %v, want %v", got, options)
+	}
+}
+
+func TestCalculationRecordFromMap_PackSetID(t *testing.T) {
+	packSetID := int64(7)
+	solution := domain.NewSolution(map[int]int{500: 1}, 251)
+
+	record, err := calculationRecordFromMap(map[string]interface{}{
+		"pack_set_id": &packSetID,
+		"pack_sizes":  []int{250, 500},
+		"amount":      251,
+		"solution":    solution,
+	})
+	if err != nil {
+		t.Fatalf("calculationRecordFromMap() error = %v", err)
+	}
+	if model := record.ToCalculationModel(); model.PackSetID == nil || *model.PackSetID != 7 {
+		t.Errorf("model pack_set_id = %v, want 7", model.PackSetID)
+	}
+
+	// Inline sizes are stored without a set, as a typed nil from the handler
+	record, err = calculationRecordFromMap(map[string]interface{}{
+		"pack_set_id": (*int64)(nil),
+		"pack_sizes":  []int{250, 500},
+		"amount":      251,
+		"solution":    solution,
+	})
+	if err != nil {
+		t.Fatalf("calculationRecordFromMap() error = %v", err)
+	}
+	if record.PackSetID != nil {
+		t.Errorf("pack_set_id = %d, want nil", *record.PackSetID)
+	}
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -80,3 +81,106 @@ func RunMigrations(db *sqlx.DB, migrationsPath string) error {
 func Ping(ctx context.Context, db *sqlx.DB) error {
 	return db.PingContext(ctx)
 }
+
+// schemaTable describes a table and the columns the repository relies on
+type schemaTable struct {
+	Name    string
+	Columns []string
+}
+
+// requiredSchema lists the tables and columns created by the migrations
+// in deployments/migrations that the repository queries depend on
+var requiredSchema = []schemaTable{
+	{
+		Name:    "pack_sets",
+		Columns: []string{"id", "name", "sizes", "dimensions", "default_amount", "created_at", "updated_at"},
+	},
+	{
+		Name:    "calculations",
+		Columns: []string{"id", "pack_set_id", "pack_sizes", "amount", "breakdown", "total_packs", "overage", "calculated_at", "correlation_id", "options", "solver_version"},
+	},
+}
+
+// CacheMetricsTable holds the cache metrics snapshots (see SaveCacheMetrics)
+const CacheMetricsTable = "cache_metrics"
+
+// optionalSchema lists the tables only some features use, verified when
+// those features are enabled
+var optionalSchema = map[string]schemaTable{
+	CacheMetricsTable: {
+		Name:    CacheMetricsTable,
+		Columns: []string{"id", "hits", "misses", "recorded_at"},
+	},
+}
+
+// VerifySchema checks information_schema for the tables and columns required
+// by the repository, plus the optional tables named (e.g. CacheMetricsTable)
+// It is intended to run at startup so that a database without applied
+// migrations fails fast with a descriptive error
+func VerifySchema(ctx context.Context, db *sqlx.DB, optionalTables ...string) error {
+	tables, err := schemaTables(optionalTables)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		SELECT table_name, column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()
+	`
+
+	var rows []struct {
+		TableName  string `db:"table_name"`
+		ColumnName string `db:"column_name"`
+	}
+	if err := db.SelectContext(ctx, &rows, query); err != nil {
+		return fmt.Errorf("failed to read database schema: %w", err)
+	}
+
+	// Group existing columns by table
+	existing := make(map[string]map[string]bool)
+	for _, row := range rows {
+		if existing[row.TableName] == nil {
+			existing[row.TableName] = make(map[string]bool)
+		}
+		existing[row.TableName][row.ColumnName] = true
+	}
+
+	return checkSchema(existing, tables)
+}
+
+// schemaTables returns requiredSchema followed by the named optional tables
+func schemaTables(optionalTables []string) ([]schemaTable, error) {
+	tables := slices.Clone(requiredSchema)
+	for _, name := range optionalTables {
+		table, ok := optionalSchema[name]
+		if !ok {
+			return nil, fmt.Errorf("schema verification failed: unknown optional table %q", name)
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// checkSchema compares existing columns (table -> column set) with tables
+func checkSchema(existing map[string]map[string]bool, tables []schemaTable) error {
+	for _, table := range tables {
+		columns, ok := existing[table.Name]
+		if !ok {
+			return fmt.Errorf("schema verification failed: table %q does not exist (were migrations applied?)", table.Name)
+		}
+
+		var missing []string
+		for _, column := range table.Columns {
+			if !columns[column] {
+				missing = append(missing, column)
+			}
+		}
+
+		if len(missing) > 0 {
+			return fmt.Errorf("schema verification failed: table %q is missing columns %v (were migrations applied?)", table.Name, missing)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+// fullSchema builds the existing-columns map for the complete required schema
+func fullSchema() map[string]map[string]bool {
+	existing := make(map[string]map[string]bool)
+	for _, table := range requiredSchema {
+		existing[table.Name] = make(map[string]bool)
+		for _, column := range table.Columns {
+			existing[table.Name][column] = true
+		}
+	}
+	return existing
+}
+
+func TestCheckSchema(t *testing.T) {
+	t.Run("complete schema", func(t *testing.T) {
+		if err := checkSchema(fullSchema(), requiredSchema); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		existing := fullSchema()
+		delete(existing["calculations"], "overage")
+
+		err := checkSchema(existing, requiredSchema)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), `"calculations"`) || !strings.Contains(err.Error(), "overage") {
+			t.Errorf("error should name table and column, got: %v", err)
+		}
+	})
+
+	t.Run("missing table", func(t *testing.T) {
+		existing := fullSchema()
+		delete(existing, "pack_sets")
+
+		err := checkSchema(existing, requiredSchema)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), `"pack_sets" does not exist`) {
+			t.Errorf("error should name missing table, got: %v", err)
+		}
+	})
+
+	t.Run("optional table", func(t *testing.T) {
+		// fullSchema has only the required tables
+		tables, err := schemaTables([]string{CacheMetricsTable})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err = checkSchema(fullSchema(), tables)
+		if err == nil || !strings.Contains(err.Error(), `"cache_metrics" does not exist`) {
+			t.Errorf("error should name the missing optional table, got: %v", err)
+		}
+	})
+
+	t.Run("unknown optional table", func(t *testing.T) {
+		if _, err := schemaTables([]string{"nope"}); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,467 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// testEnv returns the environment variable or a default value
+func testEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// newTestRepository connects to the TEST_DB_* database, which must be migrated
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	db, err := Connect(Config{
+		Host:            testEnv("TEST_DB_HOST", "localhost"),
+		Port:            testEnv("TEST_DB_PORT", "5432"),
+		User:            testEnv("TEST_DB_USER", "postgres"),
+		Password:        testEnv("TEST_DB_PASSWORD", "postgres"),
+		Database:        testEnv("TEST_DB_NAME", "re_partners_test"),
+		SSLMode:         "disable",
+		MaxOpenConns:    5,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { Close(db) })
+
+	return NewRepository(db)
+}
+
+func TestRepository_ListCalculationsUsingSize(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	// Sizes unlikely to appear in other rows
+	const used, unused = 987_653, 987_659
+
+	save := func(breakdown map[int]int, amount int) int64 {
+		t.Helper()
+		packs := 0
+		for _, count := range breakdown {
+			packs += count
+		}
+		id, err := repo.SaveCalculation(ctx, &CalculationRecord{
+			PackSizes:     []int{used, unused},
+			Amount:        amount,
+			Solution:      &domain.Solution{Breakdown: breakdown, Packs: packs, Amount: amount},
+			CorrelationID: "integration-test",
+		})
+		if err != nil {
+			t.Fatalf("failed to save calculation: %v", err)
+		}
+		t.Cleanup(func() { repo.DeleteCalculation(ctx, id) })
+		return id
+	}
+
+	withSize := save(map[int]int{used: 2}, 2*used)
+	save(map[int]int{unused: 1}, unused)
+
+	calculations, err := repo.ListCalculationsUsingSize(ctx, used, 10, 0)
+	if err != nil {
+		t.Fatalf("ListCalculationsUsingSize() error = %v", err)
+	}
+	if len(calculations) != 1 || calculations[0].ID != withSize {
+		t.Fatalf("expected only calculation %d, got %+v", withSize, calculations)
+	}
+	if calculations[0].Breakdown[used] != 2 {
+		t.Errorf("breakdown = %v, want %d: 2", calculations[0].Breakdown, used)
+	}
+	if calculations[0].CorrelationID != "integration-test" {
+		t.Errorf("correlation_id = %q, want integration-test", calculations[0].CorrelationID)
+	}
+}
+
+func TestRepository_GetCalculation_RoundTripsOptions(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	options := domain.SolveOptions{
+		domain.SolveOptionMaxOverage: "100",
+		domain.SolveOptionPriority:   domain.PriorityPacksOverage.String(),
+	}
+	id, err := repo.SaveCalculation(ctx, &CalculationRecord{
+		PackSizes: []int{250, 500},
+		Amount:    251,
+		Solution:  domain.NewSolution(map[int]int{500: 1}, 251),
+		Options:   options,
+	})
+	if err != nil {
+		t.Fatalf("failed to save calculation: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteCalculation(ctx, id) })
+
+	model, err := repo.GetCalculation(ctx, id)
+	if err != nil {
+		t.Fatalf("GetCalculation() error = %v", err)
+	}
+	if !reflect.DeepEqual(map[string]string(model.Options), map[string]string(options)) {
+		t.Errorf("options = %v, want %v", model.Options, options)
+	}
+	if got := model.ToStoredCalculation().Options; !reflect.DeepEqual(got, map[string]string(options)) {
+		t.Errorf("stored calculation options = %v, want %v", got, options)
+	}
+
+	// Calculations saved without options read back as empty, not nil
+	id, err = repo.SaveCalculation(ctx, &CalculationRecord{
+		PackSizes: []int{250, 500},
+		Amount:    251,
+		Solution:  domain.NewSolution(map[int]int{500: 1}, 251),
+	})
+	if err != nil {
+		t.Fatalf("failed to save calculation: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteCalculation(ctx, id) })
+
+	if model, err = repo.GetCalculation(ctx, id); err != nil {
+		t.Fatalf("GetCalculation() error = %v", err)
+	}
+	if model.Options == nil || len(model.Options) != 0 {
+		t.Errorf("options = %#v, want empty", model.Options)
+	}
+}
+
+func TestRepository_CalculationsAreImmutable(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	id, err := repo.SaveCalculation(ctx, &CalculationRecord{
+		PackSizes: []int{250, 500},
+		Amount:    251,
+		Solution:  domain.NewSolution(map[int]int{500: 1}, 251),
+	})
+	if err != nil {
+		t.Fatalf("failed to save calculation: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteCalculation(ctx, id) })
+
+	// The trigger rejects any change to the solution
+	if _, err := repo.db.ExecContext(ctx, `UPDATE calculations SET amount = 200 WHERE id = $1`, id); err == nil {
+		t.Fatal("expected updating amount to fail")
+	}
+	if _, err := repo.db.ExecContext(ctx, `UPDATE calculations SET breakdown = '{"250": 2}' WHERE id = $1`, id); err == nil {
+		t.Fatal("expected updating breakdown to fail")
+	}
+
+	// A row tampered with behind the trigger's back is detected on read
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+	for _, stmt := range []string{
+		`ALTER TABLE calculations DISABLE TRIGGER calculations_immutable`,
+		`UPDATE calculations SET amount = 200 WHERE id = ` + strconv.FormatInt(id, 10),
+		`ALTER TABLE calculations ENABLE TRIGGER calculations_immutable`,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	if _, err := repo.GetCalculation(ctx, id); !errors.Is(err, domain.ErrCalculationIntegrity) {
+		t.Errorf("expected ErrCalculationIntegrity, got %v", err)
+	}
+}
+
+func TestRepository_GetPackSizeUsage(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	// Sizes unlikely to appear in other rows
+	const small, large = 987_661, 987_667
+	for _, breakdown := range []map[int]int{{large: 2, small: 1}, {large: 3}} {
+		amount := 0
+		for size, count := range breakdown {
+			amount += size * count
+		}
+		id, err := repo.SaveCalculation(ctx, &CalculationRecord{
+			PackSizes: []int{small, large},
+			Amount:    amount,
+			Solution:  domain.NewSolution(breakdown, amount),
+		})
+		if err != nil {
+			t.Fatalf("failed to save calculation: %v", err)
+		}
+		t.Cleanup(func() { repo.DeleteCalculation(ctx, id) })
+	}
+
+	usage, err := repo.GetPackSizeUsage(ctx)
+	if err != nil {
+		t.Fatalf("GetPackSizeUsage() error = %v", err)
+	}
+	if usage[large] != 5 || usage[small] != 1 {
+		t.Errorf("usage = {%d: %d, %d: %d}, want {%d: 5, %d: 1}", large, usage[large], small, usage[small], large, small)
+	}
+}
+
+func TestRepository_GetRecentAmounts(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	for _, amount := range []int{987_001, 987_002, 987_003} {
+		id, err := repo.SaveCalculation(ctx, &CalculationRecord{
+			PackSizes: []int{1},
+			Amount:    amount,
+			Solution:  domain.NewSolution(map[int]int{1: amount}, amount),
+		})
+		if err != nil {
+			t.Fatalf("failed to save calculation: %v", err)
+		}
+		t.Cleanup(func() { repo.DeleteCalculation(ctx, id) })
+	}
+
+	amounts, err := repo.GetRecentAmounts(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetRecentAmounts() error = %v", err)
+	}
+	if len(amounts) != 2 || amounts[0] != 987_003 || amounts[1] != 987_002 {
+		t.Errorf("amounts = %v, want [987003 987002]", amounts)
+	}
+}
+
+func TestRepository_CountCalculations(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	name := fmt.Sprintf("count-test-%d", time.Now().UnixNano())
+	packSet, err := repo.CreatePackSet(ctx, &domain.PackSizeSet{Name: &name, Sizes: []int{250, 500}})
+	if err != nil {
+		t.Fatalf("failed to create pack set: %v", err)
+	}
+	t.Cleanup(func() { repo.DeletePackSet(ctx, *packSet.ID) })
+
+	totalBefore, err := repo.CountCalculations(ctx, nil)
+	if err != nil {
+		t.Fatalf("CountCalculations(nil) error = %v", err)
+	}
+
+	save := func(packSetID *int64) {
+		t.Helper()
+		id, err := repo.SaveCalculation(ctx, &CalculationRecord{
+			PackSetID:     packSetID,
+			PackSizes:     []int{250, 500},
+			Amount:        251,
+			Solution:      &domain.Solution{Breakdown: map[int]int{500: 1}, Packs: 1, Amount: 251},
+			CorrelationID: "integration-test",
+		})
+		if err != nil {
+			t.Fatalf("failed to save calculation: %v", err)
+		}
+		t.Cleanup(func() { repo.DeleteCalculation(ctx, id) })
+	}
+	save(packSet.ID)
+	save(packSet.ID)
+	save(nil)
+
+	t.Run("without filter", func(t *testing.T) {
+		total, err := repo.CountCalculations(ctx, nil)
+		if err != nil {
+			t.Fatalf("CountCalculations(nil) error = %v", err)
+		}
+		if total != totalBefore+3 {
+			t.Errorf("total = %d, want %d", total, totalBefore+3)
+		}
+	})
+
+	t.Run("with pack set filter", func(t *testing.T) {
+		total, err := repo.CountCalculations(ctx, packSet.ID)
+		if err != nil {
+			t.Fatalf("CountCalculations(%d) error = %v", *packSet.ID, err)
+		}
+		if total != 2 {
+			t.Errorf("total = %d, want 2", total)
+		}
+
+		calculations, err := repo.ListCalculations(ctx, packSet.ID, 1, 1)
+		if err != nil {
+			t.Fatalf("ListCalculations() error = %v", err)
+		}
+		if len(calculations) != 1 {
+			t.Errorf("second page of pack set %d has %d calculations, want 1", *packSet.ID, len(calculations))
+		}
+	})
+}
+
+func TestRepository_PackSetDimensions(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	name := fmt.Sprintf("dimensions-test-%d", time.Now().UnixNano())
+	dimensions := map[int]domain.Dimensions{250: {Length: 30, Width: 20, Height: 10}}
+	created, err := repo.CreatePackSet(ctx, &domain.PackSizeSet{Name: &name, Sizes: []int{250, 500}, Dimensions: dimensions})
+	if err != nil {
+		t.Fatalf("failed to create pack set: %v", err)
+	}
+	t.Cleanup(func() { repo.DeletePackSet(ctx, *created.ID) })
+
+	got, err := repo.GetPackSet(ctx, *created.ID)
+	if err != nil {
+		t.Fatalf("GetPackSet() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Dimensions, dimensions) {
+		t.Errorf("dimensions = %v, want %v", got.Dimensions, dimensions)
+	}
+
+	// Clearing the dimensions reads back as none
+	got.Dimensions = nil
+	if err := repo.UpdatePackSet(ctx, got); err != nil {
+		t.Fatalf("UpdatePackSet() error = %v", err)
+	}
+	got, err = repo.GetPackSet(ctx, *created.ID)
+	if err != nil {
+		t.Fatalf("GetPackSet() error = %v", err)
+	}
+	if got.Dimensions != nil {
+		t.Errorf("dimensions = %v, want nil", got.Dimensions)
+	}
+}
+
+func TestRepository_PackSetDefaultAmount(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	name := fmt.Sprintf("default-amount-test-%d", time.Now().UnixNano())
+	amount := 751
+	created, err := repo.CreatePackSet(ctx, &domain.PackSizeSet{Name: &name, Sizes: []int{250, 500}, DefaultAmount: &amount})
+	if err != nil {
+		t.Fatalf("failed to create pack set: %v", err)
+	}
+	t.Cleanup(func() { repo.DeletePackSet(ctx, *created.ID) })
+
+	got, err := repo.GetPackSet(ctx, *created.ID)
+	if err != nil {
+		t.Fatalf("GetPackSet() error = %v", err)
+	}
+	if got.DefaultAmount == nil || *got.DefaultAmount != amount {
+		t.Errorf("default amount = %v, want %d", got.DefaultAmount, amount)
+	}
+
+	// Clearing the default reads back as none
+	got.DefaultAmount = nil
+	if err := repo.UpdatePackSet(ctx, got); err != nil {
+		t.Fatalf("UpdatePackSet() error = %v", err)
+	}
+	got, err = repo.GetPackSet(ctx, *created.ID)
+	if err != nil {
+		t.Fatalf("GetPackSet() error = %v", err)
+	}
+	if got.DefaultAmount != nil {
+		t.Errorf("default amount = %d, want nil", *got.DefaultAmount)
+	}
+}
+
+func TestRepository_ExportCalculations(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	name := fmt.Sprintf("export-test-%d", time.Now().UnixNano())
+	packSet, err := repo.CreatePackSet(ctx, &domain.PackSizeSet{Name: &name, Sizes: []int{250, 500}})
+	if err != nil {
+		t.Fatalf("failed to create pack set: %v", err)
+	}
+	t.Cleanup(func() { repo.DeletePackSet(ctx, *packSet.ID) })
+
+	id, err := repo.SaveCalculation(ctx, &CalculationRecord{
+		PackSetID:     packSet.ID,
+		PackSizes:     []int{250, 500},
+		Amount:        751,
+		Solution:      &domain.Solution{Breakdown: map[int]int{500: 2}, Packs: 2, Overage: 249, Amount: 751},
+		CorrelationID: "integration-test",
+	})
+	if err != nil {
+		t.Fatalf("failed to save calculation: %v", err)
+	}
+	t.Cleanup(func() { repo.DeleteCalculation(ctx, id) })
+
+	var exported []domain.StoredCalculation
+	err = repo.ExportCalculations(ctx, domain.CalculationFilter{PackSetID: packSet.ID, From: time.Now().Add(-time.Hour)}, func(calculation domain.StoredCalculation) error {
+		exported = append(exported, calculation)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportCalculations() error = %v", err)
+	}
+	if len(exported) != 1 || exported[0].ID != id || exported[0].PackSetID == nil || *exported[0].PackSetID != *packSet.ID {
+		t.Fatalf("exported = %+v, want only calculation %d of pack set %d", exported, id, *packSet.ID)
+	}
+	if exported[0].Breakdown[500] != 2 || exported[0].Overage != 249 {
+		t.Errorf("exported calculation = %+v", exported[0])
+	}
+
+	// A range ending before the calculation exports nothing
+	err = repo.ExportCalculations(ctx, domain.CalculationFilter{PackSetID: packSet.ID, To: time.Now().Add(-time.Hour)}, func(calculation domain.StoredCalculation) error {
+		t.Errorf("unexpected calculation %d", calculation.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportCalculations() error = %v", err)
+	}
+}
+
+func TestRepository_GetCalculation_SolverVersion(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	save := func(version string) int64 {
+		t.Helper()
+		id, err := repo.SaveCalculation(ctx, &CalculationRecord{
+			PackSizes:     []int{250, 500},
+			Amount:        251,
+			Solution:      domain.NewSolution(map[int]int{500: 1}, 251),
+			SolverVersion: version,
+		})
+		if err != nil {
+			t.Fatalf("failed to save calculation: %v", err)
+		}
+		t.Cleanup(func() { repo.DeleteCalculation(ctx, id) })
+		return id
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{name: "explicit", version: "abc123def456", want: "abc123def456"},
+		{name: "from build info", want: domain.SolverVersion()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now().Add(-time.Minute)
+			model, err := repo.GetCalculation(ctx, save(tt.version))
+			if err != nil {
+				t.Fatalf("GetCalculation() error = %v", err)
+			}
+			stored := model.ToStoredCalculation()
+			if stored.SolverVersion != tt.want {
+				t.Errorf("solver_version = %q, want %q", stored.SolverVersion, tt.want)
+			}
+			if stored.CalculatedAt.Before(before) {
+				t.Errorf("calculated_at = %v, want the save time", stored.CalculatedAt)
+			}
+		})
+	}
+}
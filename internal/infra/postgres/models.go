@@ -11,23 +11,36 @@ import (
 
 // PackSetModel represents the pack size set model in the database
 type PackSetModel struct {
-	ID        int64     `db:"id"`
-	Name      string    `db:"name"`
-	Sizes     IntArray  `db:"sizes"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID            int64         `db:"id"`
+	Name          string        `db:"name"`
+	Sizes         IntArray      `db:"sizes"`
+	Dimensions    DimensionsMap `db:"dimensions"`     // Empty when the set has no dimensions
+	DefaultAmount *int          `db:"default_amount"` // NULL when the set has no default amount
+	CreatedAt     time.Time     `db:"created_at"`
+	UpdatedAt     time.Time     `db:"updated_at"`
 }
 
 // CalculationModel represents the calculation model in the database
 type CalculationModel struct {
-	ID           int64        `db:"id"`
-	PackSetID    *int64       `db:"pack_set_id"`
-	PackSizes    IntArray     `db:"pack_sizes"`
-	Amount       int          `db:"amount"`
-	Breakdown    BreakdownMap `db:"breakdown"`
-	TotalPacks   int          `db:"total_packs"`
-	Overage      int          `db:"overage"`
-	CalculatedAt time.Time    `db:"calculated_at"`
+	ID            int64        `db:"id"`
+	PackSetID     *int64       `db:"pack_set_id"`
+	PackSizes     IntArray     `db:"pack_sizes"`
+	Amount        int          `db:"amount"`
+	Breakdown     BreakdownMap `db:"breakdown"`
+	TotalPacks    int          `db:"total_packs"`
+	Overage       int          `db:"overage"`
+	CalculatedAt  time.Time    `db:"calculated_at"`
+	CorrelationID string       `db:"correlation_id"` // Empty when the request had none
+	Options       OptionsMap   `db:"options"`        // Request options the calculation was solved with
+	SolverVersion string       `db:"solver_version"` // Empty for calculations saved before versions were recorded
+}
+
+// CacheMetricsModel represents a cache metrics snapshot in the database
+type CacheMetricsModel struct {
+	ID         int64     `db:"id"`
+	Hits       int64     `db:"hits"`
+	Misses     int64     `db:"misses"`
+	RecordedAt time.Time `db:"recorded_at"`
 }
 
 // IntArray represents an array of integers for JSONB
@@ -63,6 +76,7 @@ func (a *IntArray) Scan(value interface{}) error {
 }
 
 // BreakdownMap represents map[int]int for JSONB
+// Sizes are stored as decimal string keys ({"5000": 2}), which the ? operator matches
 type BreakdownMap map[int]int
 
 // Value implements driver.Valuer for BreakdownMap
@@ -94,21 +108,93 @@ func (m *BreakdownMap) Scan(value interface{}) error {
 	return nil
 }
 
+// OptionsMap represents domain.SolveOptions for JSONB
+type OptionsMap map[string]string
+
+// Value implements driver.Valuer for OptionsMap
+func (m OptionsMap) Value() (driver.Value, error) {
+	if m == nil {
+		return json.Marshal(map[string]string{})
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements sql.Scanner for OptionsMap
+func (m *OptionsMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = make(map[string]string)
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal OptionsMap value: %v", value)
+	}
+
+	result := make(map[string]string)
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal OptionsMap: %w", err)
+	}
+
+	*m = result
+	return nil
+}
+
+// DimensionsMap represents map[int]domain.Dimensions for JSONB
+// Sizes are stored as decimal string keys, as in BreakdownMap
+type DimensionsMap map[int]domain.Dimensions
+
+// Value implements driver.Valuer for DimensionsMap
+func (m DimensionsMap) Value() (driver.Value, error) {
+	if m == nil {
+		return json.Marshal(map[int]domain.Dimensions{})
+	}
+	return json.Marshal(map[int]domain.Dimensions(m))
+}
+
+// Scan implements sql.Scanner for DimensionsMap
+func (m *DimensionsMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = make(map[int]domain.Dimensions)
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal DimensionsMap value: %v", value)
+	}
+
+	result := make(map[int]domain.Dimensions)
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal DimensionsMap: %w", err)
+	}
+
+	*m = result
+	return nil
+}
+
 // ToPackSizeSet converts PackSetModel to domain.PackSizeSet
 func (m *PackSetModel) ToPackSizeSet() *domain.PackSizeSet {
 	id := m.ID
 	name := m.Name
-	return &domain.PackSizeSet{
-		ID:    &id,
-		Name:  &name,
-		Sizes: m.Sizes,
+	packSet := &domain.PackSizeSet{
+		ID:            &id,
+		Name:          &name,
+		Sizes:         m.Sizes,
+		DefaultAmount: m.DefaultAmount,
 	}
+	if len(m.Dimensions) > 0 {
+		packSet.Dimensions = m.Dimensions
+	}
+	return packSet
 }
 
 // FromPackSizeSet creates PackSetModel from domain.PackSizeSet
 func FromPackSizeSet(ps *domain.PackSizeSet) *PackSetModel {
 	model := &PackSetModel{
-		Sizes: ps.Sizes,
+		Sizes:         ps.Sizes,
+		Dimensions:    ps.Dimensions,
+		DefaultAmount: ps.DefaultAmount,
 	}
 
 	if ps.ID != nil {
@@ -124,10 +210,13 @@ func FromPackSizeSet(ps *domain.PackSizeSet) *PackSetModel {
 
 // CalculationRecord represents a calculation record for saving
 type CalculationRecord struct {
-	PackSetID *int64
-	PackSizes []int
-	Amount    int
-	Solution  *domain.Solution
+	PackSetID     *int64
+	PackSizes     []int
+	Amount        int
+	Solution      *domain.Solution
+	CorrelationID string              // Correlation ID of the originating request
+	Options       domain.SolveOptions // Request options, stored for reproducibility
+	SolverVersion string              // Solver that produced the solution; domain.SolverVersion when empty
 }
 
 // ToCalculationModel converts CalculationRecord to CalculationModel
@@ -139,6 +228,10 @@ func (r *CalculationRecord) ToCalculationModel() *CalculationModel {
 		Breakdown:  BreakdownMap(r.Solution.Breakdown),
 		TotalPacks: r.Solution.Packs,
 		Overage:    r.Solution.Overage,
+
+		CorrelationID: r.CorrelationID,
+		Options:       OptionsMap(r.Options),
+		SolverVersion: r.SolverVersion,
 	}
 }
 
@@ -151,3 +244,43 @@ func (m *CalculationModel) ToSolution() *domain.Solution {
 		Amount:    m.Amount,
 	}
 }
+
+// Verify checks that the stored totals still form a valid solution of the
+// stored amount using only the stored pack sizes
+// Calculations are never updated, so a failure means the row drifted after saving
+func (m *CalculationModel) Verify() error {
+	if err := m.ToSolution().Validate(); err != nil {
+		return fmt.Errorf("%w: calculation %d: %v", domain.ErrCalculationIntegrity, m.ID, err)
+	}
+
+	packSizes := make(map[int]bool, len(m.PackSizes))
+	for _, size := range m.PackSizes {
+		packSizes[size] = true
+	}
+	for size, count := range m.Breakdown {
+		if count > 0 && !packSizes[size] {
+			return fmt.Errorf("%w: calculation %d: breakdown uses size %d not in pack sizes %v",
+				domain.ErrCalculationIntegrity, m.ID, size, []int(m.PackSizes))
+		}
+	}
+
+	return nil
+}
+
+// ToStoredCalculation converts CalculationModel to domain.StoredCalculation
+func (m *CalculationModel) ToStoredCalculation() domain.StoredCalculation {
+	return domain.StoredCalculation{
+		ID:           m.ID,
+		PackSetID:    m.PackSetID,
+		PackSizes:    []int(m.PackSizes),
+		Amount:       m.Amount,
+		Breakdown:    map[int]int(m.Breakdown),
+		TotalPacks:   m.TotalPacks,
+		Overage:      m.Overage,
+		CalculatedAt: m.CalculatedAt,
+
+		CorrelationID: m.CorrelationID,
+		Options:       map[string]string(m.Options),
+		SolverVersion: m.SolverVersion,
+	}
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// uniqueViolationCode - PostgreSQL error code for unique constraint violations
+const uniqueViolationCode = "23505"
+
+// PackSizeRepositoryAdapter adapts the PostgreSQL repository to domain.PackSizeRepository
+type PackSizeRepositoryAdapter struct {
+	repo *Repository
+}
+
+// NewPackSizeRepositoryAdapter creates a new adapter
+func NewPackSizeRepositoryAdapter(repo *Repository) *PackSizeRepositoryAdapter {
+	return &PackSizeRepositoryAdapter{repo: repo}
+}
+
+// Create creates a new pack size set
+// Returns domain.ErrPackSizeSetAlreadyExists if the name is taken
+func (a *PackSizeRepositoryAdapter) Create(ctx context.Context, packSizeSet *domain.PackSizeSet) (*domain.PackSizeSet, error) {
+	created, err := a.repo.CreatePackSet(ctx, packSizeSet)
+	if err != nil {
+		return nil, mapUniqueViolation(err, packSizeSet)
+	}
+	return created, nil
+}
+
+// GetByID gets a pack size set by identifier
+func (a *PackSizeRepositoryAdapter) GetByID(ctx context.Context, id int64) (*domain.PackSizeSet, error) {
+	return a.repo.GetPackSet(ctx, id)
+}
+
+// GetByName gets a pack size set by name
+func (a *PackSizeRepositoryAdapter) GetByName(ctx context.Context, name string) (*domain.PackSizeSet, error) {
+	return a.repo.GetPackSetByName(ctx, name)
+}
+
+// List returns a page of pack size sets, newest first
+func (a *PackSizeRepositoryAdapter) List(ctx context.Context, limit, offset int) ([]*domain.PackSizeSet, error) {
+	return a.repo.ListPackSets(ctx, limit, offset)
+}
+
+// Update updates an existing pack size set
+// Returns domain.ErrPackSizeSetAlreadyExists if the new name is taken
+func (a *PackSizeRepositoryAdapter) Update(ctx context.Context, packSizeSet *domain.PackSizeSet) error {
+	if err := a.repo.UpdatePackSet(ctx, packSizeSet); err != nil {
+		return mapUniqueViolation(err, packSizeSet)
+	}
+	return nil
+}
+
+// Delete deletes a pack size set by identifier
+func (a *PackSizeRepositoryAdapter) Delete(ctx context.Context, id int64) error {
+	return a.repo.DeletePackSet(ctx, id)
+}
+
+// mapUniqueViolation maps a unique name violation to domain.ErrPackSizeSetAlreadyExists
+func mapUniqueViolation(err error, packSizeSet *domain.PackSizeSet) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode {
+		return fmt.Errorf("%w: %s", domain.ErrPackSizeSetAlreadyExists, derefName(packSizeSet.Name))
+	}
+	return err
+}
+
+// derefName returns the set name or an empty string
+func derefName(name *string) string {
+	if name == nil {
+		return ""
+	}
+	return *name
+}
+
+// Ensure PackSizeRepositoryAdapter implements domain.PackSizeRepository interface
+var _ domain.PackSizeRepository = (*PackSizeRepositoryAdapter)(nil)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
@@ -34,8 +36,8 @@ func (r *Repository) CreatePackSet(ctx context.Context, ps *domain.PackSizeSet)
 	model.UpdatedAt = now
 
 	query := `
-		INSERT INTO pack_sets (name, sizes, created_at, updated_at)
-		VALUES (:name, :sizes, :created_at, :updated_at)
+		INSERT INTO pack_sets (name, sizes, dimensions, default_amount, created_at, updated_at)
+		VALUES (:name, :sizes, :dimensions, :default_amount, :created_at, :updated_at)
 		RETURNING id
 	`
 
@@ -56,7 +58,7 @@ func (r *Repository) CreatePackSet(ctx context.Context, ps *domain.PackSizeSet)
 // GetPackSet получает набор размеров по ID
 func (r *Repository) GetPackSet(ctx context.Context, id int64) (*domain.PackSizeSet, error) {
 	query := `
-		SELECT id, name, sizes, created_at, updated_at
+		SELECT id, name, sizes, dimensions, default_amount, created_at, updated_at
 		FROM pack_sets
 		WHERE id = $1
 	`
@@ -65,7 +67,7 @@ func (r *Repository) GetPackSet(ctx context.Context, id int64) (*domain.PackSize
 	err := r.db.GetContext(ctx, &model, query, id)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("pack set not found: %d", id)
+		return nil, fmt.Errorf("%w: %d", domain.ErrPackSizeSetNotFound, id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pack set: %w", err)
@@ -77,7 +79,7 @@ func (r *Repository) GetPackSet(ctx context.Context, id int64) (*domain.PackSize
 // GetPackSetByName получает набор размеров по имени
 func (r *Repository) GetPackSetByName(ctx context.Context, name string) (*domain.PackSizeSet, error) {
 	query := `
-		SELECT id, name, sizes, created_at, updated_at
+		SELECT id, name, sizes, dimensions, default_amount, created_at, updated_at
 		FROM pack_sets
 		WHERE name = $1
 	`
@@ -86,7 +88,7 @@ func (r *Repository) GetPackSetByName(ctx context.Context, name string) (*domain
 	err := r.db.GetContext(ctx, &model, query, name)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("pack set not found: %s", name)
+		return nil, fmt.Errorf("%w: %s", domain.ErrPackSizeSetNotFound, name)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pack set by name: %w", err)
@@ -105,7 +107,7 @@ func (r *Repository) ListPackSets(ctx context.Context, limit, offset int) ([]*do
 	}
 
 	query := `
-		SELECT id, name, sizes, created_at, updated_at
+		SELECT id, name, sizes, dimensions, default_amount, created_at, updated_at
 		FROM pack_sets
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
@@ -140,7 +142,7 @@ func (r *Repository) UpdatePackSet(ctx context.Context, ps *domain.PackSizeSet)
 
 	query := `
 		UPDATE pack_sets
-		SET name = :name, sizes = :sizes, updated_at = :updated_at
+		SET name = :name, sizes = :sizes, dimensions = :dimensions, default_amount = :default_amount, updated_at = :updated_at
 		WHERE id = :id
 	`
 
@@ -155,7 +157,7 @@ func (r *Repository) UpdatePackSet(ctx context.Context, ps *domain.PackSizeSet)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("pack set not found: %d", *ps.ID)
+		return fmt.Errorf("%w: %d", domain.ErrPackSizeSetNotFound, *ps.ID)
 	}
 
 	return nil
@@ -176,7 +178,7 @@ func (r *Repository) DeletePackSet(ctx context.Context, id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("pack set not found: %d", id)
+		return fmt.Errorf("%w: %d", domain.ErrPackSizeSetNotFound, id)
 	}
 
 	return nil
@@ -196,10 +198,13 @@ func (r *Repository) SaveCalculation(ctx context.Context, record *CalculationRec
 
 	model := record.ToCalculationModel()
 	model.CalculatedAt = time.Now()
+	if model.SolverVersion == "" {
+		model.SolverVersion = domain.SolverVersion()
+	}
 
 	query := `
-		INSERT INTO calculations (pack_set_id, pack_sizes, amount, breakdown, total_packs, overage, calculated_at)
-		VALUES (:pack_set_id, :pack_sizes, :amount, :breakdown, :total_packs, :overage, :calculated_at)
+		INSERT INTO calculations (pack_set_id, pack_sizes, amount, breakdown, total_packs, overage, calculated_at, correlation_id, options, solver_version)
+		VALUES (:pack_set_id, :pack_sizes, :amount, :breakdown, :total_packs, :overage, :calculated_at, :correlation_id, :options, :solver_version)
 		RETURNING id
 	`
 
@@ -219,9 +224,11 @@ func (r *Repository) SaveCalculation(ctx context.Context, record *CalculationRec
 }
 
 // GetCalculation получает расчёт по ID
+// The row is checked with CalculationModel.Verify; drift is returned as
+// domain.ErrCalculationIntegrity instead of the tampered calculation
 func (r *Repository) GetCalculation(ctx context.Context, id int64) (*CalculationModel, error) {
 	query := `
-		SELECT id, pack_set_id, pack_sizes, amount, breakdown, total_packs, overage, calculated_at
+		SELECT id, pack_set_id, pack_sizes, amount, breakdown, total_packs, overage, calculated_at, correlation_id, options, solver_version
 		FROM calculations
 		WHERE id = $1
 	`
@@ -236,11 +243,16 @@ func (r *Repository) GetCalculation(ctx context.Context, id int64) (*Calculation
 		return nil, fmt.Errorf("failed to get calculation: %w", err)
 	}
 
+	if err := model.Verify(); err != nil {
+		return nil, err
+	}
+
 	return &model, nil
 }
 
 // ListCalculations получает список расчётов с фильтрацией
-func (r *Repository) ListCalculations(ctx context.Context, packSetID *int64, limit, offset int) ([]*CalculationModel, error) {
+// Newest first; packSetID (optional) filters as in CountCalculations
+func (r *Repository) ListCalculations(ctx context.Context, packSetID *int64, limit, offset int) ([]domain.StoredCalculation, error) {
 	if limit <= 0 {
 		limit = 100
 	}
@@ -248,30 +260,150 @@ func (r *Repository) ListCalculations(ctx context.Context, packSetID *int64, lim
 		offset = 0
 	}
 
+	where, args := calculationsFilter(domain.CalculationFilter{PackSetID: packSetID})
 	query := `
-		SELECT id, pack_set_id, pack_sizes, amount, breakdown, total_packs, overage, calculated_at
+		SELECT id, pack_set_id, pack_sizes, amount, breakdown, total_packs, overage, calculated_at, correlation_id, options, solver_version
 		FROM calculations
-	`
+	` + where + fmt.Sprintf(" ORDER BY calculated_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	var models []*CalculationModel
+	err := r.db.SelectContext(ctx, &models, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calculations: %w", err)
+	}
+
+	calculations := make([]domain.StoredCalculation, 0, len(models))
+	for _, model := range models {
+		calculations = append(calculations, model.ToStoredCalculation())
+	}
+
+	return calculations, nil
+}
+
+// CountCalculations returns the number of calculations ListCalculations pages
+// through with the same packSetID filter
+func (r *Repository) CountCalculations(ctx context.Context, packSetID *int64) (int64, error) {
+	where, args := calculationsFilter(domain.CalculationFilter{PackSetID: packSetID})
+	query := `SELECT COUNT(*) FROM calculations` + where
+
+	var total int64
+	if err := r.db.GetContext(ctx, &total, query, args...); err != nil {
+		return 0, fmt.Errorf("failed to count calculations: %w", err)
+	}
+
+	return total, nil
+}
+
+// ExportCalculations calls fn for every calculation matching filter, oldest
+// first, reading rows from a cursor instead of loading them all
+// Stops at the first error, from the query or from fn
+func (r *Repository) ExportCalculations(ctx context.Context, filter domain.CalculationFilter, fn func(domain.StoredCalculation) error) error {
+	where, args := calculationsFilter(filter)
+	query := `
+		SELECT id, pack_set_id, pack_sizes, amount, breakdown, total_packs, overage, calculated_at, correlation_id, options, solver_version
+		FROM calculations
+	` + where + " ORDER BY calculated_at, id"
+
+	rows, err := r.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export calculations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var model CalculationModel
+		if err := rows.StructScan(&model); err != nil {
+			return fmt.Errorf("failed to scan calculation: %w", err)
+		}
+		if err := fn(model.ToStoredCalculation()); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to export calculations: %w", err)
+	}
+
+	return nil
+}
 
+// calculationsFilter returns the WHERE clause (empty without conditions) and
+// arguments for filter, shared by ListCalculations, CountCalculations and
+// ExportCalculations
+func calculationsFilter(filter domain.CalculationFilter) (string, []interface{}) {
+	var conditions []string
 	var args []interface{}
-	argIndex := 1
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
 
-	if packSetID != nil {
-		query += fmt.Sprintf(" WHERE pack_set_id = $%d", argIndex)
-		args = append(args, *packSetID)
-		argIndex++
+	if filter.PackSetID != nil {
+		add("pack_set_id = $%d", *filter.PackSetID)
+	}
+	if !filter.From.IsZero() {
+		add("calculated_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("calculated_at < $%d", filter.To)
 	}
 
-	query += fmt.Sprintf(" ORDER BY calculated_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, limit, offset)
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// ListCalculationsUsingSize returns calculations whose breakdown contains size, newest first
+// Uses the JSONB ? operator on the breakdown keys (see BreakdownMap)
+func (r *Repository) ListCalculationsUsingSize(ctx context.Context, size, limit, offset int) ([]domain.StoredCalculation, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT id, pack_set_id, pack_sizes, amount, breakdown, total_packs, overage, calculated_at, correlation_id, options, solver_version
+		FROM calculations
+		WHERE breakdown ? $1
+		ORDER BY calculated_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
 
 	var models []*CalculationModel
-	err := r.db.SelectContext(ctx, &models, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list calculations: %w", err)
+	if err := r.db.SelectContext(ctx, &models, query, strconv.Itoa(size), limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to list calculations using size %d: %w", size, err)
 	}
 
-	return models, nil
+	calculations := make([]domain.StoredCalculation, 0, len(models))
+	for _, model := range models {
+		calculations = append(calculations, model.ToStoredCalculation())
+	}
+
+	return calculations, nil
+}
+
+// GetRecentAmounts returns the amounts of the latest limit calculations, newest first
+func (r *Repository) GetRecentAmounts(ctx context.Context, limit int) ([]int, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT amount
+		FROM calculations
+		ORDER BY calculated_at DESC, id DESC
+		LIMIT $1
+	`
+
+	var amounts []int
+	if err := r.db.SelectContext(ctx, &amounts, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to get recent amounts: %w", err)
+	}
+
+	return amounts, nil
 }
 
 // DeleteCalculation удаляет расчёт
@@ -339,3 +471,121 @@ func (r *Repository) GetCalculationStats(ctx context.Context) (map[string]interf
 
 	return stats, nil
 }
+
+// Cache metrics operations
+
+// SaveCacheMetrics stores a snapshot of cache hit/miss counters
+func (r *Repository) SaveCacheMetrics(ctx context.Context, hits, misses uint64) (int64, error) {
+	model := &CacheMetricsModel{
+		Hits:       int64(hits),
+		Misses:     int64(misses),
+		RecordedAt: time.Now(),
+	}
+
+	query := `
+		INSERT INTO cache_metrics (hits, misses, recorded_at)
+		VALUES (:hits, :misses, :recorded_at)
+		RETURNING id
+	`
+
+	stmt, err := r.db.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var id int64
+	if err := stmt.GetContext(ctx, &id, model); err != nil {
+		return 0, fmt.Errorf("failed to save cache metrics: %w", err)
+	}
+
+	return id, nil
+}
+
+// sizeUsage is the total number of packs of a size across calculations
+type sizeUsage struct {
+	Size  int   `db:"size"`
+	Packs int64 `db:"packs"`
+}
+
+// GetPackSizeUsage returns the total number of packs of each size used across
+// all calculations, expanding the breakdown JSONB with a jsonb_each lateral join
+// Sizes never used are absent; returns an empty map when there are no calculations
+func (r *Repository) GetPackSizeUsage(ctx context.Context) (map[int]int64, error) {
+	query := `
+		SELECT usage.key::int AS size, SUM(usage.value::bigint) AS packs
+		FROM calculations
+		CROSS JOIN LATERAL jsonb_each(breakdown) AS usage
+		GROUP BY usage.key
+	`
+
+	var rows []sizeUsage
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("failed to get pack size usage: %w", err)
+	}
+
+	usage := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		usage[row.Size] = row.Packs
+	}
+
+	return usage, nil
+}
+
+// overageCount is the number of calculations with a given overage
+type overageCount struct {
+	Overage int   `db:"overage"`
+	Count   int64 `db:"count"`
+}
+
+// GetOverageHistogram returns the distribution of overage across calculations
+// split into at most buckets equal-width buckets between the minimum and maximum overage
+// Returns an empty slice when there are no calculations
+func (r *Repository) GetOverageHistogram(ctx context.Context, buckets int) ([]domain.OverageBucket, error) {
+	if buckets <= 0 {
+		return nil, fmt.Errorf("%w: buckets must be greater than 0, got %d", domain.ErrInvalidInput, buckets)
+	}
+
+	// Aggregate in SQL, bucket in Go (distinct overage values are bounded by the largest pack size)
+	query := `
+		SELECT overage, COUNT(*) AS count
+		FROM calculations
+		GROUP BY overage
+		ORDER BY overage
+	`
+
+	var counts []overageCount
+	if err := r.db.SelectContext(ctx, &counts, query); err != nil {
+		return nil, fmt.Errorf("failed to get overage histogram: %w", err)
+	}
+
+	return bucketOverages(counts, buckets), nil
+}
+
+// bucketOverages groups overage counts (sorted by overage) into equal-width buckets
+func bucketOverages(counts []overageCount, buckets int) []domain.OverageBucket {
+	if len(counts) == 0 {
+		return []domain.OverageBucket{}
+	}
+
+	minOverage := counts[0].Overage
+	maxOverage := counts[len(counts)-1].Overage
+
+	// Width rounded up so that buckets cover the whole range
+	span := maxOverage - minOverage + 1
+	width := (span + buckets - 1) / buckets
+	n := (span + width - 1) / width
+
+	result := make([]domain.OverageBucket, n)
+	for i := range result {
+		result[i].Lower = minOverage + i*width
+		result[i].Upper = result[i].Lower + width - 1
+	}
+	result[n-1].Upper = maxOverage
+
+	for _, c := range counts {
+		result[(c.Overage-minOverage)/width].Count += c.Count
+	}
+
+	return result
+}
@@ -0,0 +1,231 @@
+package postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+func TestBucketOverages(t *testing.T) {
+	tests := []struct {
+		name    string
+		counts  []overageCount
+		buckets int
+		want    []domain.OverageBucket
+	}{
+		{
+			name:    "empty table",
+			counts:  nil,
+			buckets: 5,
+			want:    []domain.OverageBucket{},
+		},
+		{
+			name: "varied overages",
+			counts: []overageCount{
+				{Overage: 0, Count: 10},
+				{Overage: 49, Count: 2},
+				{Overage: 50, Count: 3},
+				{Overage: 150, Count: 1},
+				{Overage: 249, Count: 4},
+			},
+			buckets: 5,
+			want: []domain.OverageBucket{
+				{Lower: 0, Upper: 49, Count: 12},
+				{Lower: 50, Upper: 99, Count: 3},
+				{Lower: 100, Upper: 149, Count: 0},
+				{Lower: 150, Upper: 199, Count: 1},
+				{Lower: 200, Upper: 249, Count: 4},
+			},
+		},
+		{
+			name:    "single value",
+			counts:  []overageCount{{Overage: 7, Count: 5}},
+			buckets: 10,
+			want:    []domain.OverageBucket{{Lower: 7, Upper: 7, Count: 5}},
+		},
+		{
+			name: "range narrower than bucket count",
+			counts: []overageCount{
+				{Overage: 0, Count: 1},
+				{Overage: 2, Count: 1},
+			},
+			buckets: 10,
+			want: []domain.OverageBucket{
+				{Lower: 0, Upper: 0, Count: 1},
+				{Lower: 1, Upper: 1, Count: 0},
+				{Lower: 2, Upper: 2, Count: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bucketOverages(tt.counts, tt.buckets)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bucketOverages() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakdownMap_ValueUsesStringKeys(t *testing.T) {
+	value, err := BreakdownMap{5000: 2, 250: 1}.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	// ListCalculationsUsingSize matches keys with breakdown ? '5000'
+	if got, want := string(value.([]byte)), `{"250":1,"5000":2}`; got != want {
+		t.Errorf("Value() = %s, want %s", got, want)
+	}
+}
+
+func TestDimensionsMap_RoundTrip(t *testing.T) {
+	dimensions := DimensionsMap{250: {Length: 30, Width: 20, Height: 10.5}}
+
+	value, err := dimensions.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+	if got, want := string(value.([]byte)), `{"250":{"length":30,"width":20,"height":10.5}}`; got != want {
+		t.Errorf("Value() = %s, want %s", got, want)
+	}
+
+	var scanned DimensionsMap
+	if err := scanned.Scan(value); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if scanned[250] != dimensions[250] || len(scanned) != 1 {
+		t.Errorf("Scan() = %v, want %v", scanned, dimensions)
+	}
+}
+
+func TestPackSetModel_DefaultAmountRoundTrip(t *testing.T) {
+	id, name, amount := int64(7), "standard", 751
+	packSet := &domain.PackSizeSet{ID: &id, Name: &name, Sizes: []int{250, 500}, DefaultAmount: &amount}
+
+	got := FromPackSizeSet(packSet).ToPackSizeSet()
+	if got.DefaultAmount == nil || *got.DefaultAmount != amount {
+		t.Errorf("DefaultAmount = %v, want %d", got.DefaultAmount, amount)
+	}
+
+	// No default stays NULL rather than becoming zero
+	packSet.DefaultAmount = nil
+	if model := FromPackSizeSet(packSet); model.DefaultAmount != nil {
+		t.Errorf("model DefaultAmount = %d, want nil", *model.DefaultAmount)
+	}
+	if got := FromPackSizeSet(packSet).ToPackSizeSet(); got.DefaultAmount != nil {
+		t.Errorf("DefaultAmount = %d, want nil", *got.DefaultAmount)
+	}
+}
+
+func TestCalculationsFilter(t *testing.T) {
+	packSetID := int64(7)
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		filter    domain.CalculationFilter
+		wantWhere string
+		wantArgs  []interface{}
+	}{
+		{name: "none", filter: domain.CalculationFilter{}, wantWhere: ""},
+		{
+			name:      "pack set",
+			filter:    domain.CalculationFilter{PackSetID: &packSetID},
+			wantWhere: " WHERE pack_set_id = $1",
+			wantArgs:  []interface{}{packSetID},
+		},
+		{
+			name:      "date range",
+			filter:    domain.CalculationFilter{From: from, To: to},
+			wantWhere: " WHERE calculated_at >= $1 AND calculated_at < $2",
+			wantArgs:  []interface{}{from, to},
+		},
+		{
+			name:      "all",
+			filter:    domain.CalculationFilter{PackSetID: &packSetID, From: from, To: to},
+			wantWhere: " WHERE pack_set_id = $1 AND calculated_at >= $2 AND calculated_at < $3",
+			wantArgs:  []interface{}{packSetID, from, to},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := calculationsFilter(tt.filter)
+			if where != tt.wantWhere {
+				t.Errorf("where = %q, want %q", where, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCalculationRecord_SolverVersionRoundTrip(t *testing.T) {
+	record := &CalculationRecord{
+		PackSizes:     []int{250, 500},
+		Amount:        251,
+		Solution:      domain.NewSolution(map[int]int{500: 1}, 251),
+		SolverVersion: "abc123def456",
+	}
+
+	if got := record.ToCalculationModel().ToStoredCalculation().SolverVersion; got != "abc123def456" {
+		t.Errorf("solver_version = %q, want abc123def456", got)
+	}
+}
+
+func TestCalculationModel_Verify(t *testing.T) {
+	// 251 with 250/500: one 500, overage 249
+	valid := func() CalculationModel {
+		return CalculationModel{
+			ID:         7,
+			PackSizes:  IntArray{250, 500},
+			Amount:     251,
+			Breakdown:  BreakdownMap{500: 1},
+			TotalPacks: 1,
+			Overage:    249,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		tamper  func(m *CalculationModel)
+		wantErr bool
+	}{
+		{name: "untouched", tamper: func(m *CalculationModel) {}},
+		{name: "amount changed", tamper: func(m *CalculationModel) { m.Amount = 200 }, wantErr: true},
+		{name: "breakdown changed", tamper: func(m *CalculationModel) { m.Breakdown = BreakdownMap{250: 2} }, wantErr: true},
+		{name: "total packs changed", tamper: func(m *CalculationModel) { m.TotalPacks = 2 }, wantErr: true},
+		{name: "overage changed", tamper: func(m *CalculationModel) { m.Overage = 0 }, wantErr: true},
+		{
+			// Consistent totals, but 1000 was never an available size
+			name: "breakdown size not in pack sizes",
+			tamper: func(m *CalculationModel) {
+				m.Breakdown = BreakdownMap{1000: 1}
+				m.Overage = 749
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := valid()
+			tt.tamper(&model)
+
+			err := model.Verify()
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, domain.ErrCalculationIntegrity) {
+				t.Errorf("expected ErrCalculationIntegrity, got %v", err)
+			}
+		})
+	}
+}
@@ -2,16 +2,18 @@ package redis
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"sort"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
@@ -20,13 +22,53 @@ const (
 
 	// CacheKeyPrefix - prefix for cache keys
 	CacheKeyPrefix = "solver:"
+
+	// clearBatchSize - keys per SCAN page and per UNLINK when clearing the cache
+	clearBatchSize = 500
+
+	// DefaultRetryAttempts - default number of tries of a cache read or write
+	DefaultRetryAttempts = 3
+
+	// DefaultRetryBaseDelay - default delay before the first retry; doubles on each retry
+	DefaultRetryBaseDelay = 10 * time.Millisecond
 )
 
+// Prometheus metrics, mirroring the CachedSolver counters across all instances
+// Unlike GetMetrics, they are not reset by ResetMetrics
+var (
+	cacheHitsTotal = metrics.Register(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "cache_hits_total",
+		Help: "Total number of solver cache hits",
+	}))
+
+	cacheMissesTotal = metrics.Register(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "cache_misses_total",
+		Help: "Total number of solver cache misses",
+	}))
+
+	cacheErrorsTotal = metrics.Register(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "cache_errors_total",
+		Help: "Total number of failed Redis cache reads and writes, counting every retry",
+	}))
+)
+
+// TTLFunc chooses the TTL of a cache entry from the request and its solution,
+// e.g. a longer one for exact solutions; non-positive values use the default TTL
+type TTLFunc func(sizes []int, amount int, solution *domain.Solution) time.Duration
+
 // CachedSolver wraps Solver with Redis caching
 type CachedSolver struct {
-	solver domain.Solver
-	client *redis.Client
-	ttl    time.Duration
+	solver  domain.Solver
+	client  *redis.Client
+	ttl     time.Duration
+	ttlFunc TTLFunc // Optional per-entry TTL; nil uses ttl for every entry
+	version string  // Solver version namespace embedded in cache keys
+
+	retryAttempts  int           // Tries per cache read or write
+	retryBaseDelay time.Duration // Delay before the first retry, doubled on each retry
+
+	// Concurrent misses for the same cache key share one solve
+	misses singleflight.Group
 
 	// Metrics
 	cacheHits   atomic.Uint64
@@ -40,72 +82,246 @@ func NewCachedSolver(solver domain.Solver, client *redis.Client, ttl time.Durati
 	}
 
 	return &CachedSolver{
-		solver: solver,
-		client: client,
-		ttl:    ttl,
+		solver:  solver,
+		client:  client,
+		ttl:     ttl,
+		version: domain.SolverVersion(),
+
+		retryAttempts:  DefaultRetryAttempts,
+		retryBaseDelay: DefaultRetryBaseDelay,
+	}
+}
+
+// WithTTLFunc sets a per-entry TTL, chosen when a solution is cached
+// A nil ttlFunc restores the constant TTL given to NewCachedSolver
+func (cs *CachedSolver) WithTTLFunc(ttlFunc TTLFunc) *CachedSolver {
+	cs.ttlFunc = ttlFunc
+	return cs
+}
+
+// ExactTTL returns a TTLFunc caching exact (zero overage) solutions for
+// exact and all others for other; exact solutions never change for the same
+// input, while others may as the solver evolves
+func ExactTTL(exact, other time.Duration) TTLFunc {
+	return func(sizes []int, amount int, solution *domain.Solution) time.Duration {
+		if domain.IsSolutionStrict(solution) {
+			return exact
+		}
+		return other
+	}
+}
+
+// WithRetry sets how often a failed cache read or write is tried and the
+// delay before the first retry, which doubles on each further retry
+// Non-positive values fall back to DefaultRetryAttempts and DefaultRetryBaseDelay
+func (cs *CachedSolver) WithRetry(attempts int, baseDelay time.Duration) *CachedSolver {
+	if attempts <= 0 {
+		attempts = DefaultRetryAttempts
+	}
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+	cs.retryAttempts = attempts
+	cs.retryBaseDelay = baseDelay
+	return cs
+}
+
+// WithVersion overrides the solver version embedded in cache keys
+func (cs *CachedSolver) WithVersion(version string) *CachedSolver {
+	if version == "" {
+		version = domain.DefaultSolverVersion
 	}
+	cs.version = version
+	return cs
 }
 
 // Solve implements the domain.Solver interface with caching
+// A cache bypass set on the context (domain.WithCacheBypass) skips the read
+// and always recomputes; CacheBypassRefresh also overwrites the cached entry
 func (cs *CachedSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
-	// Generate cache key
-	cacheKey := cs.generateCacheKey(sizes, amount)
+	// Generate cache key (solve options from the context are part of the key)
+	cacheKey := cs.generateCacheKey(sizes, amount, domain.SolveOptionsFromContext(ctx))
+
+	bypass := domain.CacheBypassFromContext(ctx)
+	if bypass != domain.CacheBypassNone {
+		solution, err := cs.solver.Solve(ctx, sizes, amount)
+		if err != nil {
+			return nil, err
+		}
+		if bypass == domain.CacheBypassRefresh {
+			cs.saveAsync(cacheKey, cs.entryTTL(sizes, amount, solution), solution)
+		}
+		return solution, nil
+	}
 
 	// Try to get from cache
 	solution, err := cs.getFromCache(ctx, cacheKey)
 	if err == nil && solution != nil {
 		// Cache hit
 		cs.cacheHits.Add(1)
+		cacheHitsTotal.Inc()
 		return solution, nil
 	}
 
 	// Cache miss
 	cs.cacheMisses.Add(1)
+	cacheMissesTotal.Inc()
+
+	// Call the original solver once per key, however many requests miss at once
+	return cs.solveShared(ctx, cacheKey, sizes, amount)
+}
+
+// solveShared solves a cache miss, sharing the result (or the error) with
+// concurrent misses for the same key
+// Every caller gets its own copy of a shared solution and stops waiting when
+// its own context is done. A failure caused by the context of the caller that
+// ran the solve is not shared: the others solve again with their own context
+// A caller with a progress callback (domain.WithProgress) solves on its own,
+// since a shared solve reports only to the caller that ran it
+func (cs *CachedSolver) solveShared(ctx context.Context, cacheKey string, sizes []int, amount int) (*domain.Solution, error) {
+	if domain.ProgressFromContext(ctx) != nil {
+		solution, err := cs.solver.Solve(ctx, sizes, amount)
+		if err != nil {
+			return nil, err
+		}
+		cs.saveAsync(cacheKey, cs.entryTTL(sizes, amount, solution), solution)
+		return solution, nil
+	}
+
+	results := cs.misses.DoChan(cacheKey, func() (interface{}, error) {
+		solution, err := cs.solver.Solve(ctx, sizes, amount)
+		if err != nil {
+			// Don't cache errors
+			return nil, err
+		}
+
+		// Save to cache (asynchronously to not block the response)
+		cs.saveAsync(cacheKey, cs.entryTTL(sizes, amount, solution), solution)
+		return solution, nil
+	})
+
+	var result singleflight.Result
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result = <-results:
+	}
+
+	if result.Err != nil {
+		if result.Shared && ctx.Err() == nil && (errors.Is(result.Err, context.Canceled) || errors.Is(result.Err, context.DeadlineExceeded)) {
+			return cs.solver.Solve(ctx, sizes, amount)
+		}
+		return nil, result.Err
+	}
+
+	solution := result.Val.(*domain.Solution)
+	if result.Shared {
+		solution = solution.Copy()
+	}
+	return solution, nil
+}
+
+// Warm solves each amount for sizes and caches the result, so the first
+// requests for these pairs are cache hits; entries are written before returning
+// Solve options on ctx are part of the keys, as in Solve
+// Returns the number of amounts cached, with the failures joined (errors.Join);
+// a done ctx stops the remaining amounts
+func (cs *CachedSolver) Warm(ctx context.Context, sizes []int, amounts []int) (int, error) {
+	options := domain.SolveOptionsFromContext(ctx)
+
+	warmed := 0
+	var errs []error
+	for _, amount := range amounts {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("warm-up interrupted after %d amounts: %w", warmed, err))
+			break
+		}
 
-	// Call the original solver
-	solution, err = cs.solver.Solve(ctx, sizes, amount)
+		solution, err := cs.solver.Solve(ctx, sizes, amount)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("amount %d: %w", amount, err))
+			continue
+		}
+		key := cs.generateCacheKey(sizes, amount, options)
+		if err := cs.saveToCache(ctx, key, cs.entryTTL(sizes, amount, solution), solution); err != nil {
+			errs = append(errs, fmt.Errorf("amount %d: %w", amount, err))
+			continue
+		}
+		warmed++
+	}
+
+	return warmed, errors.Join(errs...)
+}
+
+// saveAsync writes a solution to cache in the background
+// The solution is marshaled before returning, so the caller owns it again at
+// once and may modify it (e.g. Normalize) while the write is in flight
+// Uses a separate context with timeout so the request context can end first
+func (cs *CachedSolver) saveAsync(key string, ttl time.Duration, solution *domain.Solution) {
+	data, err := json.Marshal(solution)
 	if err != nil {
-		// Don't cache errors
-		return nil, err
+		return
 	}
 
-	// Save to cache (asynchronously to not block the response)
-	// Use a separate context with timeout for cache write
 	go func() {
 		cacheCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		if err := cs.saveToCache(cacheCtx, cacheKey, solution); err != nil {
+		if err := cs.writeToCache(cacheCtx, key, ttl, data); err != nil {
 			// Log error, but don't return it to the user
 			// In production, this should use a proper logger
 			_ = err
 		}
 	}()
-
-	return solution, nil
 }
 
-// generateCacheKey generates a cache key: sha256(sorted sizes) + ":" + amount
-func (cs *CachedSolver) generateCacheKey(sizes []int, amount int) string {
-	// Copy and sort sizes for consistency
-	sortedSizes := make([]int, len(sizes))
-	copy(sortedSizes, sizes)
-	sort.Ints(sortedSizes)
+// versionPrefix returns the key prefix for the current solver version
+func (cs *CachedSolver) versionPrefix() string {
+	return CacheKeyPrefix + cs.version + ":"
+}
 
-	// Create string representation of sorted sizes
-	sizesStr := fmt.Sprintf("%v", sortedSizes)
+// generateCacheKey generates a cache key: "solver:" + version + ":" + domain.CanonicalHash(sizes, amount, options)
+func (cs *CachedSolver) generateCacheKey(sizes []int, amount int, options domain.SolveOptions) string {
+	return cs.versionPrefix() + domain.CanonicalHash(sizes, amount, options)
+}
 
-	// Calculate SHA256 hash
-	hash := sha256.Sum256([]byte(sizesStr))
-	hashStr := hex.EncodeToString(hash[:])
+// retry runs a Redis operation up to retryAttempts times with exponential backoff
+// redis.Nil (a miss) and context errors are returned at once; a retry that
+// could not start before the context deadline is skipped
+func (cs *CachedSolver) retry(ctx context.Context, op func(ctx context.Context) error) error {
+	delay := cs.retryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := op(ctx)
+		if err == nil || errors.Is(err, redis.Nil) {
+			return err
+		}
+		cacheErrorsTotal.Inc()
+		if attempt >= cs.retryAttempts || ctx.Err() != nil {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
 
-	// Form key: prefix + hash + ":" + amount
-	return fmt.Sprintf("%s%s:%d", CacheKeyPrefix, hashStr, amount)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay *= 2
+	}
 }
 
 // getFromCache retrieves a solution from cache
 func (cs *CachedSolver) getFromCache(ctx context.Context, key string) (*domain.Solution, error) {
-	data, err := cs.client.Get(ctx, key).Bytes()
+	var data []byte
+	err := cs.retry(ctx, func(ctx context.Context) error {
+		var err error
+		data, err = cs.client.Get(ctx, key).Bytes()
+		return err
+	})
 	if err != nil {
 		if err == redis.Nil {
 			return nil, fmt.Errorf("cache miss")
@@ -121,14 +337,31 @@ func (cs *CachedSolver) getFromCache(ctx context.Context, key string) (*domain.S
 	return &solution, nil
 }
 
-// saveToCache saves a solution to cache
-func (cs *CachedSolver) saveToCache(ctx context.Context, key string, solution *domain.Solution) error {
+// entryTTL returns the TTL of the cache entry for a solution (see WithTTLFunc)
+func (cs *CachedSolver) entryTTL(sizes []int, amount int, solution *domain.Solution) time.Duration {
+	if cs.ttlFunc != nil {
+		if ttl := cs.ttlFunc(sizes, amount, solution); ttl > 0 {
+			return ttl
+		}
+	}
+	return cs.ttl
+}
+
+// saveToCache saves a solution to cache for ttl
+func (cs *CachedSolver) saveToCache(ctx context.Context, key string, ttl time.Duration, solution *domain.Solution) error {
 	data, err := json.Marshal(solution)
 	if err != nil {
 		return fmt.Errorf("marshal error: %w", err)
 	}
+	return cs.writeToCache(ctx, key, ttl, data)
+}
 
-	if err := cs.client.Set(ctx, key, data, cs.ttl).Err(); err != nil {
+// writeToCache saves a marshaled solution to cache for ttl
+func (cs *CachedSolver) writeToCache(ctx context.Context, key string, ttl time.Duration, data []byte) error {
+	err := cs.retry(ctx, func(ctx context.Context) error {
+		return cs.client.Set(ctx, key, data, ttl).Err()
+	})
+	if err != nil {
 		return fmt.Errorf("redis set error: %w", err)
 	}
 
@@ -147,26 +380,75 @@ func (cs *CachedSolver) ResetMetrics() {
 }
 
 // ClearCache очищает весь кэш решений
-func (cs *CachedSolver) ClearCache(ctx context.Context) error {
-	// Используем SCAN для поиска всех ключей с префиксом
-	iter := cs.client.Scan(ctx, 0, CacheKeyPrefix+"*", 0).Iterator()
+// Returns the number of keys removed, also when interrupted (see clearKeys)
+func (cs *CachedSolver) ClearCache(ctx context.Context) (int, error) {
+	return cs.clearKeys(ctx, nil)
+}
 
-	var keys []string
-	for iter.Next(ctx) {
-		keys = append(keys, iter.Val())
-	}
+// ClearStaleVersions removes cached solutions written by other solver versions
+// (including keys from before versioning was introduced)
+func (cs *CachedSolver) ClearStaleVersions(ctx context.Context) (int, error) {
+	current := cs.versionPrefix()
+	return cs.clearKeys(ctx, func(key string) bool {
+		return !strings.HasPrefix(key, current)
+	})
+}
 
-	if err := iter.Err(); err != nil {
-		return fmt.Errorf("scan error: %w", err)
+// clearKeys deletes the cache keys accepted by match (all when nil) in
+// batches of clearBatchSize, so a huge keyspace never turns into one
+// blocking command; each batch is unlinked as soon as SCAN fills it
+// Cancelling ctx stops between batches, returning the count removed so far
+// with the context error
+func (cs *CachedSolver) clearKeys(ctx context.Context, match func(key string) bool) (int, error) {
+	iter := cs.client.Scan(ctx, 0, CacheKeyPrefix+"*", clearBatchSize).Iterator()
+
+	cleared := 0
+	batch := make([]string, 0, clearBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("clear interrupted after %d keys: %w", cleared, err)
+		}
+		removed, err := cs.unlink(ctx, batch)
+		cleared += removed
+		batch = batch[:0]
+		return err
 	}
 
-	if len(keys) > 0 {
-		if err := cs.client.Del(ctx, keys...).Err(); err != nil {
-			return fmt.Errorf("delete error: %w", err)
+	for iter.Next(ctx) {
+		if key := iter.Val(); match == nil || match(key) {
+			batch = append(batch, key)
+			if len(batch) == clearBatchSize {
+				if err := flush(); err != nil {
+					return cleared, err
+				}
+			}
 		}
 	}
+	if err := iter.Err(); err != nil {
+		return cleared, fmt.Errorf("scan error: %w", err)
+	}
+	if err := flush(); err != nil {
+		return cleared, err
+	}
 
-	return nil
+	return cleared, nil
+}
+
+// unlink removes keys with UNLINK, which frees memory in the background,
+// falling back to DEL on servers without it (Redis < 4.0)
+// Returns the number of keys that existed
+func (cs *CachedSolver) unlink(ctx context.Context, keys []string) (int, error) {
+	removed, err := cs.client.Unlink(ctx, keys...).Result()
+	if err != nil && strings.HasPrefix(err.Error(), "ERR unknown command") {
+		removed, err = cs.client.Del(ctx, keys...).Result()
+	}
+	if err != nil {
+		return 0, fmt.Errorf("delete error: %w", err)
+	}
+	return int(removed), nil
 }
 
 // Ensure CachedSolver implements domain.Solver interface
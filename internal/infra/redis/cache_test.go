@@ -0,0 +1,817 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	httpAdapter "github.com/evgenijurbanovskij/re-partners-assignment/internal/adapters/http"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestCachedSolver_GenerateCacheKey_Version(t *testing.T) {
+	oldSolver := NewCachedSolver(nil, nil, 0).WithVersion("v1")
+	newSolver := NewCachedSolver(nil, nil, 0).WithVersion("v2")
+
+	sizes := []int{250, 500, 1000}
+	amount := 12001
+
+	oldKey := oldSolver.generateCacheKey(sizes, amount, nil)
+	newKey := newSolver.generateCacheKey(sizes, amount, nil)
+
+	// A new solver version must never read entries written by the old one
+	if oldKey == newKey {
+		t.Fatalf("keys for different versions must differ, both are %q", oldKey)
+	}
+	if strings.HasPrefix(oldKey, newSolver.versionPrefix()) {
+		t.Errorf("old key %q is inside the new version namespace %q", oldKey, newSolver.versionPrefix())
+	}
+
+	// Same version: key is stable and independent of size order
+	if got := newSolver.generateCacheKey([]int{1000, 250, 500}, amount, nil); got != newKey {
+		t.Errorf("key depends on size order: %q != %q", got, newKey)
+	}
+}
+
+func TestCachedSolver_WithVersion_Empty(t *testing.T) {
+	if got := NewCachedSolver(nil, nil, 0).WithVersion("").version; got != domain.DefaultSolverVersion {
+		t.Errorf("empty version = %q, want %q", got, domain.DefaultSolverVersion)
+	}
+}
+
+// countingSolver returns a fixed solution and counts calls
+type countingSolver struct {
+	mu       sync.Mutex
+	calls    int
+	solution *domain.Solution
+}
+
+func (s *countingSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return s.solution.Copy(), nil
+}
+
+func (s *countingSolver) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// fakeRedisHook serves GET/SET, SCAN and UNLINK/DEL from memory so no Redis
+// server is needed
+type fakeRedisHook struct {
+	mu   sync.Mutex
+	data map[string]string
+	ttls map[string]time.Duration // Expiration of each SET
+	sets chan string
+
+	scanKeys    []string // Keys of the current SCAN
+	noUnlink    bool     // Reject UNLINK as Redis < 4.0 does
+	deletes     []int    // Keys per successful UNLINK/DEL, in call order
+	afterDelete func()   // Optional, called after every UNLINK/DEL
+	failures    int      // Commands still to fail with a transient error
+	calls       int      // Commands received, including failed ones
+}
+
+func (h *fakeRedisHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *fakeRedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func (h *fakeRedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		h.calls++
+		if h.failures > 0 {
+			h.failures--
+			err := errors.New("read tcp: connection reset by peer")
+			cmd.SetErr(err)
+			return err
+		}
+
+		args := cmd.Args()
+		switch c := cmd.(type) {
+		case *redis.StringCmd:
+			value, ok := h.data[args[1].(string)]
+			if !ok {
+				c.SetErr(redis.Nil)
+				return redis.Nil
+			}
+			c.SetVal(value)
+		case *redis.StatusCmd:
+			key := args[1].(string)
+			h.data[key] = string(args[2].([]byte))
+			if len(args) == 5 && args[3] == "ex" {
+				h.ttls[key] = time.Duration(args[4].(int64)) * time.Second
+			} else if len(args) == 5 && args[3] == "px" {
+				h.ttls[key] = time.Duration(args[4].(int64)) * time.Millisecond
+			}
+			c.SetVal("OK")
+			h.sets <- key
+		case *redis.ScanCmd:
+			// The cursor is an offset into the matching keys as of cursor 0,
+			// so deleting during the scan skips nothing, as with Redis
+			cursor, pattern, count := int(args[1].(uint64)), args[3].(string), int(args[5].(int64))
+			if cursor == 0 {
+				h.scanKeys = h.scanKeys[:0]
+				for key := range h.data {
+					if strings.HasPrefix(key, strings.TrimSuffix(pattern, "*")) {
+						h.scanKeys = append(h.scanKeys, key)
+					}
+				}
+				slices.Sort(h.scanKeys)
+			}
+			end := min(cursor+count, len(h.scanKeys))
+			next := uint64(end)
+			if end == len(h.scanKeys) {
+				next = 0
+			}
+			c.SetVal(h.scanKeys[cursor:end], next)
+		case *redis.IntCmd:
+			if args[0] == "unlink" && h.noUnlink {
+				err := errors.New("ERR unknown command 'unlink'")
+				c.SetErr(err)
+				return err
+			}
+			removed := 0
+			for _, arg := range args[1:] {
+				if _, ok := h.data[arg.(string)]; ok {
+					delete(h.data, arg.(string))
+					removed++
+				}
+			}
+			h.deletes = append(h.deletes, len(args)-1)
+			c.SetVal(int64(removed))
+			if h.afterDelete != nil {
+				h.afterDelete()
+			}
+		}
+		return nil
+	}
+}
+
+func newFakeRedisClient(t *testing.T) (*redis.Client, *fakeRedisHook) {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			t.Error("unexpected dial to Redis")
+			return nil, net.ErrClosed
+		},
+	})
+	hook := &fakeRedisHook{data: make(map[string]string), ttls: make(map[string]time.Duration), sets: make(chan string, 10)}
+	client.AddHook(hook)
+	t.Cleanup(func() { client.Close() })
+
+	return client, hook
+}
+
+func TestCachedSolver_Solve_CacheBypass(t *testing.T) {
+	sizes := []int{250, 500}
+	amount := 251
+
+	fresh := domain.NewSolution(map[int]int{500: 1}, amount)
+	poisoned := domain.NewSolution(map[int]int{250: 2}, amount)
+	poisonedJSON, _ := json.Marshal(poisoned)
+
+	tests := []struct {
+		name       string
+		bypass     domain.CacheBypass
+		wantCalls  int
+		wantResult *domain.Solution
+		wantWrite  bool
+	}{
+		{name: "no bypass serves cached entry", bypass: domain.CacheBypassNone, wantCalls: 0, wantResult: poisoned},
+		{name: "read bypass recomputes", bypass: domain.CacheBypassRead, wantCalls: 1, wantResult: fresh},
+		{name: "refresh recomputes and writes", bypass: domain.CacheBypassRefresh, wantCalls: 1, wantResult: fresh, wantWrite: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, hook := newFakeRedisClient(t)
+			solver := &countingSolver{solution: fresh}
+			cs := NewCachedSolver(solver, client, time.Minute).WithVersion("test")
+
+			key := cs.generateCacheKey(sizes, amount, nil)
+			hook.data[key] = string(poisonedJSON)
+
+			ctx := domain.WithCacheBypass(context.Background(), tt.bypass)
+			got, err := cs.Solve(ctx, sizes, amount)
+			if err != nil {
+				t.Fatalf("Solve() error = %v", err)
+			}
+
+			if calls := solver.Calls(); calls != tt.wantCalls {
+				t.Errorf("solver calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if got.Packs != tt.wantResult.Packs || got.Overage != tt.wantResult.Overage {
+				t.Errorf("Solve() = %+v, want %+v", got, tt.wantResult)
+			}
+
+			if tt.wantWrite {
+				select {
+				case <-hook.sets:
+				case <-time.After(time.Second):
+					t.Fatal("expected fresh result to be written to cache")
+				}
+				var cached domain.Solution
+				hook.mu.Lock()
+				_ = json.Unmarshal([]byte(hook.data[key]), &cached)
+				hook.mu.Unlock()
+				if cached.Packs != fresh.Packs {
+					t.Errorf("cached entry = %+v, want %+v", cached, fresh)
+				}
+			} else {
+				select {
+				case written := <-hook.sets:
+					t.Errorf("unexpected cache write to %q", written)
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+		})
+	}
+}
+
+func TestCachedSolver_Solve_OptionsInKey(t *testing.T) {
+	client, hook := newFakeRedisClient(t)
+	solver := &countingSolver{solution: domain.NewSolution(map[int]int{500: 1}, 251)}
+	cs := NewCachedSolver(solver, client, time.Minute).WithVersion("test")
+
+	solve := func(options domain.SolveOptions) {
+		t.Helper()
+		ctx := domain.WithSolveOptions(context.Background(), options)
+		if _, err := cs.Solve(ctx, []int{250, 500}, 251); err != nil {
+			t.Fatalf("Solve() error = %v", err)
+		}
+	}
+	waitForWrite := func() {
+		t.Helper()
+		select {
+		case <-hook.sets:
+		case <-time.After(time.Second):
+			t.Fatal("expected cache write")
+		}
+	}
+
+	solve(domain.SolveOptions{"strategy": "dp"})
+	waitForWrite()
+
+	// Identical request is served from cache
+	solve(domain.SolveOptions{"strategy": "dp"})
+	if calls := solver.Calls(); calls != 1 {
+		t.Errorf("identical request: solver calls = %d, want 1", calls)
+	}
+
+	// Differing options must not collide with the cached entry
+	solve(domain.SolveOptions{"strategy": "greedy"})
+	waitForWrite()
+	solve(nil)
+	if calls := solver.Calls(); calls != 3 {
+		t.Errorf("differing options: solver calls = %d, want 3", calls)
+	}
+}
+
+func TestCachedSolver_Solve_CountsHitsAndMisses(t *testing.T) {
+	client, hook := newFakeRedisClient(t)
+	solver := &countingSolver{solution: domain.NewSolution(map[int]int{500: 1}, 251)}
+	cs := NewCachedSolver(solver, client, time.Minute).WithVersion("test")
+	ctx := context.Background()
+
+	hitsBefore := testutil.ToFloat64(cacheHitsTotal)
+	missesBefore := testutil.ToFloat64(cacheMissesTotal)
+
+	if _, err := cs.Solve(ctx, []int{250, 500}, 251); err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+	select {
+	case <-hook.sets:
+	case <-time.After(time.Second):
+		t.Fatal("expected cache write")
+	}
+	if _, err := cs.Solve(ctx, []int{250, 500}, 251); err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+
+	if hits, misses := cs.GetMetrics(); hits != 1 || misses != 1 {
+		t.Errorf("GetMetrics() = (%d, %d), want (1, 1)", hits, misses)
+	}
+	if got := testutil.ToFloat64(cacheHitsTotal) - hitsBefore; got != 1 {
+		t.Errorf("cache_hits_total increased by %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(cacheMissesTotal) - missesBefore; got != 1 {
+		t.Errorf("cache_misses_total increased by %v, want 1", got)
+	}
+}
+
+func TestCachedSolver_ClearCache_Batches(t *testing.T) {
+	tests := []struct {
+		name     string
+		noUnlink bool
+	}{
+		{name: "unlink"},
+		{name: "del fallback", noUnlink: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, hook := newFakeRedisClient(t)
+			hook.noUnlink = tt.noUnlink
+			for i := 0; i < 1234; i++ {
+				hook.data[fmt.Sprintf("%sv1:%04d", CacheKeyPrefix, i)] = "{}"
+			}
+			hook.data["other:key"] = "kept"
+			cs := NewCachedSolver(nil, client, time.Minute)
+
+			cleared, err := cs.ClearCache(context.Background())
+			if err != nil {
+				t.Fatalf("ClearCache() error = %v", err)
+			}
+			if cleared != 1234 {
+				t.Errorf("cleared = %d, want 1234", cleared)
+			}
+			if len(hook.data) != 1 || hook.data["other:key"] != "kept" {
+				t.Errorf("remaining keys = %d, want only other:key", len(hook.data))
+			}
+
+			if want := []int{500, 500, 234}; !slices.Equal(hook.deletes, want) {
+				t.Errorf("keys per delete = %v, want %v", hook.deletes, want)
+			}
+		})
+	}
+}
+
+func TestCachedSolver_ClearCache_Cancelled(t *testing.T) {
+	client, hook := newFakeRedisClient(t)
+	for i := 0; i < 1234; i++ {
+		hook.data[fmt.Sprintf("%sv1:%04d", CacheKeyPrefix, i)] = "{}"
+	}
+	cs := NewCachedSolver(nil, client, time.Minute)
+
+	// Cancel once the first batch is deleted
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hook.afterDelete = cancel
+
+	cleared, err := cs.ClearCache(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ClearCache() error = %v, want context.Canceled", err)
+	}
+	if cleared != 500 || len(hook.data) != 734 {
+		t.Errorf("cleared = %d with %d keys left, want 500 and 734", cleared, len(hook.data))
+	}
+}
+
+func TestCachedSolver_ClearStaleVersions(t *testing.T) {
+	client, hook := newFakeRedisClient(t)
+	cs := NewCachedSolver(nil, client, time.Minute).WithVersion("v2")
+	for i := 0; i < 600; i++ {
+		hook.data[fmt.Sprintf("%sv1:%04d", CacheKeyPrefix, i)] = "{}"
+	}
+	current := cs.versionPrefix() + "current"
+	hook.data[current] = "{}"
+
+	removed, err := cs.ClearStaleVersions(context.Background())
+	if err != nil {
+		t.Fatalf("ClearStaleVersions() error = %v", err)
+	}
+	if removed != 600 {
+		t.Errorf("removed = %d, want 600", removed)
+	}
+	if _, ok := hook.data[current]; !ok || len(hook.data) != 1 {
+		t.Errorf("remaining keys = %d, want only the current version", len(hook.data))
+	}
+}
+
+func TestCachedSolver_Retry(t *testing.T) {
+	sizes := []int{250, 500}
+	amount := 251
+	cached := domain.NewSolution(map[int]int{500: 1}, amount)
+	cachedJSON, _ := json.Marshal(cached)
+
+	t.Run("read recovers after a transient error", func(t *testing.T) {
+		client, hook := newFakeRedisClient(t)
+		solver := &countingSolver{solution: cached}
+		cs := NewCachedSolver(solver, client, time.Minute).WithVersion("test").WithRetry(3, time.Millisecond)
+		hook.data[cs.generateCacheKey(sizes, amount, nil)] = string(cachedJSON)
+		hook.failures = 1
+		errorsBefore := testutil.ToFloat64(cacheErrorsTotal)
+
+		if _, err := cs.Solve(context.Background(), sizes, amount); err != nil {
+			t.Fatalf("Solve() error = %v", err)
+		}
+		if calls := solver.Calls(); calls != 0 {
+			t.Errorf("solver calls = %d, want 0 (served from cache)", calls)
+		}
+		if hits, _ := cs.GetMetrics(); hits != 1 {
+			t.Errorf("hits = %d, want 1", hits)
+		}
+		if got := testutil.ToFloat64(cacheErrorsTotal) - errorsBefore; got != 1 {
+			t.Errorf("cache_errors_total increased by %v, want 1", got)
+		}
+	})
+
+	t.Run("write recovers after a transient error", func(t *testing.T) {
+		client, hook := newFakeRedisClient(t)
+		cs := NewCachedSolver(&countingSolver{solution: cached}, client, time.Minute).WithVersion("test").WithRetry(3, time.Millisecond)
+		key := cs.generateCacheKey(sizes, amount, nil)
+		hook.failures = 1
+
+		if err := cs.saveToCache(context.Background(), key, time.Minute, cached); err != nil {
+			t.Fatalf("saveToCache() error = %v", err)
+		}
+		if _, ok := hook.data[key]; !ok || hook.calls != 2 {
+			t.Errorf("entry written = %v after %d SET calls, want written on the second", ok, hook.calls)
+		}
+	})
+
+	t.Run("miss is not retried", func(t *testing.T) {
+		client, hook := newFakeRedisClient(t)
+		cs := NewCachedSolver(nil, client, time.Minute).WithVersion("test").WithRetry(3, time.Millisecond)
+
+		if _, err := cs.getFromCache(context.Background(), cs.generateCacheKey(sizes, amount, nil)); err == nil {
+			t.Fatal("expected cache miss")
+		}
+		if hook.calls != 1 {
+			t.Errorf("GET calls = %d, want 1", hook.calls)
+		}
+	})
+
+	t.Run("gives up after the attempts", func(t *testing.T) {
+		client, hook := newFakeRedisClient(t)
+		solver := &countingSolver{solution: cached}
+		cs := NewCachedSolver(solver, client, time.Minute).WithVersion("test").WithRetry(2, time.Millisecond)
+		hook.failures = 2
+
+		if _, err := cs.getFromCache(context.Background(), cs.generateCacheKey(sizes, amount, nil)); err == nil {
+			t.Fatal("expected error after exhausting the attempts")
+		}
+		if hook.calls != 2 {
+			t.Errorf("GET calls = %d, want 2", hook.calls)
+		}
+	})
+
+	t.Run("stops at the context deadline", func(t *testing.T) {
+		client, hook := newFakeRedisClient(t)
+		cs := NewCachedSolver(nil, client, time.Minute).WithVersion("test").WithRetry(5, time.Hour)
+		hook.failures = 5
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if _, err := cs.getFromCache(ctx, cs.generateCacheKey(sizes, amount, nil)); err == nil {
+			t.Fatal("expected error")
+		}
+		if hook.calls != 1 {
+			t.Errorf("GET calls = %d, want 1 (the retry would pass the deadline)", hook.calls)
+		}
+	})
+}
+
+// gatedSolver blocks every solve until release is closed, counting calls
+type gatedSolver struct {
+	countingSolver
+	release chan struct{}
+	err     error
+}
+
+func (s *gatedSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	solution, _ := s.countingSolver.Solve(ctx, sizes, amount)
+	<-s.release
+	if s.err != nil {
+		return nil, s.err
+	}
+	return solution, nil
+}
+
+func TestCachedSolver_Solve_SharesConcurrentMisses(t *testing.T) {
+	const callers = 10
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{name: "solution"},
+		{name: "error", err: domain.ErrNoSolution, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, hook := newFakeRedisClient(t)
+			hook.sets = make(chan string, callers)
+			solver := &gatedSolver{
+				countingSolver: countingSolver{solution: domain.NewSolution(map[int]int{500: 1}, 251)},
+				release:        make(chan struct{}),
+				err:            tt.err,
+			}
+			cs := NewCachedSolver(solver, client, time.Minute).WithVersion("test")
+
+			solutions := make([]*domain.Solution, callers)
+			errs := make([]error, callers)
+			var wg sync.WaitGroup
+			for i := range callers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					solutions[i], errs[i] = cs.Solve(context.Background(), []int{250, 500}, 251)
+				}()
+			}
+
+			// Every caller has missed the cache before the solve completes
+			deadline := time.Now().Add(time.Second)
+			for {
+				hook.mu.Lock()
+				calls := hook.calls
+				hook.mu.Unlock()
+				if calls == callers || time.Now().After(deadline) {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(50 * time.Millisecond)
+			close(solver.release)
+			wg.Wait()
+
+			if calls := solver.Calls(); calls != 1 {
+				t.Errorf("solver calls = %d, want 1", calls)
+			}
+			for i := range callers {
+				if tt.wantErr {
+					if !errors.Is(errs[i], tt.err) || solutions[i] != nil {
+						t.Errorf("caller %d: Solve() = %v, %v, want error %v", i, solutions[i], errs[i], tt.err)
+					}
+					continue
+				}
+				if errs[i] != nil || solutions[i] == nil || solutions[i].Packs != 1 {
+					t.Errorf("caller %d: Solve() = %v, %v, want one pack", i, solutions[i], errs[i])
+				}
+			}
+			if !tt.wantErr && solutions[0] == solutions[1] {
+				t.Error("callers share one solution; each needs its own copy")
+			}
+		})
+	}
+}
+
+func TestCachedSolver_Solve_WaiterCancelled(t *testing.T) {
+	client, _ := newFakeRedisClient(t)
+	solver := &gatedSolver{
+		countingSolver: countingSolver{solution: domain.NewSolution(map[int]int{500: 1}, 251)},
+		release:        make(chan struct{}),
+	}
+	cs := NewCachedSolver(solver, client, time.Minute).WithVersion("test")
+
+	leader := make(chan error, 1)
+	go func() {
+		_, err := cs.Solve(context.Background(), []int{250, 500}, 251)
+		leader <- err
+	}()
+
+	// The leader's solve is in flight
+	deadline := time.Now().Add(time.Second)
+	for solver.Calls() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := cs.Solve(ctx, []int{250, 500}, 251)
+		waiter <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-waiter:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("waiter Solve() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter Solve() did not return after its context was cancelled")
+	}
+
+	close(solver.release)
+	if err := <-leader; err != nil {
+		t.Errorf("leader Solve() error = %v", err)
+	}
+	if calls := solver.Calls(); calls != 1 {
+		t.Errorf("solver calls = %d, want 1", calls)
+	}
+}
+
+func TestCachedSolver_Solve_ProgressNotShared(t *testing.T) {
+	client, _ := newFakeRedisClient(t)
+	solver := &gatedSolver{
+		countingSolver: countingSolver{solution: domain.NewSolution(map[int]int{500: 1}, 251)},
+		release:        make(chan struct{}),
+	}
+	cs := NewCachedSolver(solver, client, time.Minute).WithVersion("test")
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := domain.WithProgress(context.Background(), func(done, total int) {})
+			if _, err := cs.Solve(ctx, []int{250, 500}, 251); err != nil {
+				t.Errorf("Solve() error = %v", err)
+			}
+		}()
+	}
+
+	// Each caller reaches the solver with its own progress callback
+	deadline := time.Now().Add(time.Second)
+	for solver.Calls() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(solver.release)
+	wg.Wait()
+
+	if calls := solver.Calls(); calls != 2 {
+		t.Errorf("solver calls = %d, want 2", calls)
+	}
+}
+
+func TestCachedSolver_Warm(t *testing.T) {
+	client, hook := newFakeRedisClient(t)
+	hook.sets = make(chan string, 10)
+	solver := &countingSolver{solution: domain.NewSolution(map[int]int{500: 1}, 251)}
+	cs := NewCachedSolver(solver, client, time.Minute).WithVersion("test")
+	ctx := context.Background()
+	sizes := []int{250, 500}
+	amounts := []int{251, 501, 751}
+
+	warmed, err := cs.Warm(ctx, sizes, amounts)
+	if err != nil || warmed != len(amounts) {
+		t.Fatalf("Warm() = %d, %v, want %d", warmed, err, len(amounts))
+	}
+
+	// Size order does not matter, as for any cached solve
+	for _, amount := range amounts {
+		if _, err := cs.Solve(ctx, []int{500, 250}, amount); err != nil {
+			t.Fatalf("Solve(%d) error = %v", amount, err)
+		}
+	}
+	if hits, misses := cs.GetMetrics(); hits != uint64(len(amounts)) || misses != 0 {
+		t.Errorf("GetMetrics() = (%d, %d), want (%d, 0)", hits, misses, len(amounts))
+	}
+	if calls := solver.Calls(); calls != len(amounts) {
+		t.Errorf("solver calls = %d, want %d (warm-up only)", calls, len(amounts))
+	}
+
+	// Failures are reported without stopping the other amounts
+	hook.failures = 3 // Every try of the first SET
+	warmed, err = cs.Warm(ctx, sizes, []int{1001, 1251})
+	if warmed != 1 || err == nil || !strings.Contains(err.Error(), "amount 1001") {
+		t.Errorf("Warm() = %d, %v, want 1 warmed and an error for amount 1001", warmed, err)
+	}
+}
+
+func TestCachedSolver_TTLFunc(t *testing.T) {
+	const week, hour = 7 * 24 * time.Hour, time.Hour
+
+	tests := []struct {
+		name     string
+		ttlFunc  TTLFunc
+		solution *domain.Solution
+		wantTTL  time.Duration
+	}{
+		{name: "default", solution: domain.NewSolution(map[int]int{250: 2}, 500), wantTTL: time.Minute},
+		{name: "exact solution", ttlFunc: ExactTTL(week, hour), solution: domain.NewSolution(map[int]int{250: 2}, 500), wantTTL: week},
+		{name: "solution with overage", ttlFunc: ExactTTL(week, hour), solution: domain.NewSolution(map[int]int{500: 1}, 251), wantTTL: hour},
+		{
+			name:     "non-positive falls back",
+			ttlFunc:  func(sizes []int, amount int, solution *domain.Solution) time.Duration { return 0 },
+			solution: domain.NewSolution(map[int]int{500: 1}, 251),
+			wantTTL:  time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, hook := newFakeRedisClient(t)
+			cs := NewCachedSolver(&countingSolver{solution: tt.solution}, client, time.Minute).
+				WithVersion("test").
+				WithTTLFunc(tt.ttlFunc)
+
+			if _, err := cs.Solve(context.Background(), []int{250, 500}, tt.solution.Amount); err != nil {
+				t.Fatalf("Solve() error = %v", err)
+			}
+			var key string
+			select {
+			case key = <-hook.sets:
+			case <-time.After(time.Second):
+				t.Fatal("expected cache write")
+			}
+
+			hook.mu.Lock()
+			defer hook.mu.Unlock()
+			if got := hook.ttls[key]; got != tt.wantTTL {
+				t.Errorf("SET expiration = %v, want %v", got, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestCachedSolver_Solve_CallerModifiesWhileSaving(t *testing.T) {
+	const requests = 10
+
+	// The zero count makes the handler's Normalize delete from the breakdown
+	newSolution := func() *domain.Solution {
+		return &domain.Solution{Breakdown: map[int]int{500: 1, 250: 0}, Packs: 1}
+	}
+	logger := httpAdapter.NewSlogAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// solvePacks posts one solve request and checks the normalized response
+	solvePacks := func(t *testing.T, handler *httpAdapter.PackHandler, amount int) {
+		body := fmt.Sprintf(`{"sizes": [250, 500], "amount": %d}`, amount)
+		req := httptest.NewRequest(http.MethodPost, "/packs/solve", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.SolvePacks(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("amount %d: status = %d, body: %s", amount, rec.Code, rec.Body.String())
+		}
+		if strings.Contains(rec.Body.String(), `"size":250`) {
+			t.Errorf("amount %d: response lists an unused size: %s", amount, rec.Body.String())
+		}
+	}
+
+	// Run with -race: the handler must not modify a solution the cache is
+	// still saving or another caller is still copying
+	t.Run("misses", func(t *testing.T) {
+		client, hook := newFakeRedisClient(t)
+		hook.sets = make(chan string, requests)
+		cs := NewCachedSolver(&countingSolver{solution: newSolution()}, client, time.Minute).WithVersion("test")
+		handler := httpAdapter.NewPackHandler(cs, logger)
+
+		for i := range requests {
+			solvePacks(t, handler, 251+i)
+		}
+		for i := range requests {
+			select {
+			case <-hook.sets:
+			case <-time.After(time.Second):
+				t.Fatalf("only %d of %d solutions were cached", i, requests)
+			}
+		}
+	})
+
+	t.Run("shared miss", func(t *testing.T) {
+		client, hook := newFakeRedisClient(t)
+		solver := &gatedSolver{countingSolver: countingSolver{solution: newSolution()}, release: make(chan struct{})}
+		cs := NewCachedSolver(solver, client, time.Minute).WithVersion("test")
+		handler := httpAdapter.NewPackHandler(cs, logger)
+
+		var wg sync.WaitGroup
+		for range requests {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				solvePacks(t, handler, 251)
+			}()
+		}
+
+		// Every request has missed the cache before the solve completes
+		deadline := time.Now().Add(time.Second)
+		for {
+			hook.mu.Lock()
+			calls := hook.calls
+			hook.mu.Unlock()
+			if calls == requests || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(solver.release)
+		wg.Wait()
+
+		if calls := solver.Calls(); calls != 1 {
+			t.Errorf("solver calls = %d, want 1", calls)
+		}
+		select {
+		case <-hook.sets:
+		case <-time.After(time.Second):
+			t.Fatal("the shared solution was not cached")
+		}
+	})
+}
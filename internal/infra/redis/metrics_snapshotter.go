@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// DefaultSnapshotInterval - default interval between cache metrics snapshots
+const DefaultSnapshotInterval = time.Minute
+
+// MetricsSource provides cumulative cache hit/miss counters
+// Implemented by CachedSolver
+type MetricsSource interface {
+	GetMetrics() (hits, misses uint64)
+}
+
+// MetricsStore persists cache metrics snapshots
+// Implemented by postgres.Repository
+type MetricsStore interface {
+	SaveCacheMetrics(ctx context.Context, hits, misses uint64) (int64, error)
+}
+
+// MetricsSnapshotter periodically copies cache counters into persistent storage
+// for long-term cache efficiency trending
+type MetricsSnapshotter struct {
+	source   MetricsSource
+	store    MetricsStore
+	interval time.Duration
+}
+
+// NewMetricsSnapshotter creates a new snapshotter
+func NewMetricsSnapshotter(source MetricsSource, store MetricsStore, interval time.Duration) *MetricsSnapshotter {
+	if interval <= 0 {
+		interval = DefaultSnapshotInterval
+	}
+
+	return &MetricsSnapshotter{
+		source:   source,
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Snapshot reads the current counters and stores a single snapshot
+func (s *MetricsSnapshotter) Snapshot(ctx context.Context) error {
+	hits, misses := s.source.GetMetrics()
+
+	if _, err := s.store.SaveCacheMetrics(ctx, hits, misses); err != nil {
+		return fmt.Errorf("failed to snapshot cache metrics: %w", err)
+	}
+
+	return nil
+}
+
+// Run takes a snapshot every interval until ctx is canceled
+// Blocks, so it should be started in a separate goroutine
+func (s *MetricsSnapshotter) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Each snapshot gets its own timeout so a slow database does not stall the loop
+			snapshotCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			if err := s.Snapshot(snapshotCtx); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// Ensure CachedSolver can be used as a MetricsSource
+var _ MetricsSource = (*CachedSolver)(nil)
@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// Mock store for tests
+type mockMetricsStore struct {
+	hits   []uint64
+	misses []uint64
+	err    error
+}
+
+func (m *mockMetricsStore) SaveCacheMetrics(ctx context.Context, hits, misses uint64) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	m.hits = append(m.hits, hits)
+	m.misses = append(m.misses, misses)
+	return int64(len(m.hits)), nil
+}
+
+func TestMetricsSnapshotter_Snapshot(t *testing.T) {
+	cs := NewCachedSolver(nil, nil, 0)
+	cs.cacheHits.Add(7)
+	cs.cacheMisses.Add(3)
+
+	store := &mockMetricsStore{}
+	snapshotter := NewMetricsSnapshotter(cs, store, 0)
+
+	if err := snapshotter.Snapshot(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(store.hits) != 1 {
+		t.Fatalf("expected 1 snapshot, got %d", len(store.hits))
+	}
+	if store.hits[0] != 7 || store.misses[0] != 3 {
+		t.Errorf("snapshot = (%d, %d), want (7, 3)", store.hits[0], store.misses[0])
+	}
+}
+
+func TestMetricsSnapshotter_SnapshotError(t *testing.T) {
+	storeErr := errors.New("db down")
+	snapshotter := NewMetricsSnapshotter(NewCachedSolver(nil, nil, 0), &mockMetricsStore{err: storeErr}, 0)
+
+	if err := snapshotter.Snapshot(context.Background()); !errors.Is(err, storeErr) {
+		t.Errorf("expected wrapped store error, got %v", err)
+	}
+}
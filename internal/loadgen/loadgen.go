@@ -0,0 +1,121 @@
+// Package loadgen generates random valid solve inputs for load tests and benchmarks
+package loadgen
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// Amount distributions of generated load
+const (
+	DistributionUniform = "uniform" // Every amount in range equally likely
+	DistributionLog     = "log"     // Log-uniform: each order of magnitude equally likely, favouring small amounts
+)
+
+// DefaultSizePool is the size catalog generated requests draw from:
+// the documented sizes plus awkward primes that force deep searches
+var DefaultSizePool = []int{23, 31, 53, 97, 250, 500, 1000, 2000, 5000, 7919}
+
+// Input is one generated solve input
+type Input struct {
+	Sizes  []int
+	Amount int
+}
+
+// Config configures Generate
+type Config struct {
+	Count              int    // Requests to generate
+	MinAmount          int    // Smallest amount, at least 1
+	MaxAmount          int    // Largest amount, at most domain.MaxAmount
+	AmountDistribution string // DistributionUniform (default) or DistributionLog
+	SizePool           []int  // Sizes to draw from; DefaultSizePool when empty
+	MinSizes           int    // Fewest sizes per request, default 1
+	MaxSizes           int    // Most sizes per request, default all of the pool
+}
+
+// Generate returns cfg.Count random solve inputs for load tests and benchmarks
+// Each draws MinSizes..MaxSizes distinct sizes from the pool and an amount
+// from the configured range and distribution; every input passes
+// domain.ValidateSolverInput, and the same rng seed gives the same inputs
+func Generate(rng *rand.Rand, cfg Config) ([]Input, error) {
+	cfg, err := normalizeConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Input, 0, cfg.Count)
+	for range cfg.Count {
+		count := cfg.MinSizes + rng.IntN(cfg.MaxSizes-cfg.MinSizes+1)
+		sizes := make([]int, count)
+		for i, j := range rng.Perm(len(cfg.SizePool))[:count] {
+			sizes[i] = cfg.SizePool[j]
+		}
+		item := Input{Sizes: sizes, Amount: randomAmount(rng, cfg)}
+
+		// Cannot fail after normalization; guards the contract if the rules change
+		if err := domain.ValidateSolverInput(item.Sizes, item.Amount); err != nil {
+			return nil, fmt.Errorf("generated invalid input: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// normalizeConfig applies defaults and validates the configuration
+func normalizeConfig(cfg Config) (Config, error) {
+	if cfg.Count < 0 {
+		return cfg, fmt.Errorf("%w: count must not be negative, got %d", domain.ErrInvalidInput, cfg.Count)
+	}
+	if cfg.MinAmount == 0 {
+		cfg.MinAmount = 1
+	}
+	if err := domain.ValidateAmount(cfg.MinAmount); err != nil {
+		return cfg, fmt.Errorf("min amount: %w", err)
+	}
+	if err := domain.ValidateAmount(cfg.MaxAmount); err != nil {
+		return cfg, fmt.Errorf("max amount: %w", err)
+	}
+	if cfg.MinAmount > cfg.MaxAmount {
+		return cfg, fmt.Errorf("%w: min amount %d exceeds max amount %d", domain.ErrInvalidInput, cfg.MinAmount, cfg.MaxAmount)
+	}
+
+	switch cfg.AmountDistribution {
+	case "":
+		cfg.AmountDistribution = DistributionUniform
+	case DistributionUniform, DistributionLog:
+	default:
+		return cfg, fmt.Errorf("%w: unknown amount distribution %q: expected %s or %s", domain.ErrInvalidInput, cfg.AmountDistribution, DistributionUniform, DistributionLog)
+	}
+
+	if len(cfg.SizePool) == 0 {
+		cfg.SizePool = DefaultSizePool
+	}
+	if err := domain.ValidatePackSizes(cfg.SizePool); err != nil {
+		return cfg, fmt.Errorf("size pool: %w", err)
+	}
+	if cfg.MinSizes == 0 {
+		cfg.MinSizes = 1
+	}
+	if cfg.MaxSizes == 0 {
+		cfg.MaxSizes = len(cfg.SizePool)
+	}
+	if cfg.MinSizes < 1 || cfg.MinSizes > cfg.MaxSizes || cfg.MaxSizes > len(cfg.SizePool) {
+		return cfg, fmt.Errorf("%w: sizes per request must satisfy 1 <= min (%d) <= max (%d) <= pool size (%d)", domain.ErrInvalidInput, cfg.MinSizes, cfg.MaxSizes, len(cfg.SizePool))
+	}
+
+	return cfg, nil
+}
+
+// randomAmount draws an amount in [cfg.MinAmount, cfg.MaxAmount]
+func randomAmount(rng *rand.Rand, cfg Config) int {
+	if cfg.AmountDistribution == DistributionLog {
+		low, high := math.Log(float64(cfg.MinAmount)), math.Log(float64(cfg.MaxAmount)+1)
+		amount := int(math.Exp(low + rng.Float64()*(high-low)))
+		return min(max(amount, cfg.MinAmount), cfg.MaxAmount)
+	}
+	return cfg.MinAmount + rng.IntN(cfg.MaxAmount-cfg.MinAmount+1)
+}
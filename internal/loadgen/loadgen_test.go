@@ -0,0 +1,93 @@
+package loadgen
+
+import (
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "defaults", cfg: Config{Count: 500, MaxAmount: 1_000_000}},
+		{name: "log amounts", cfg: Config{Count: 500, MinAmount: 10, MaxAmount: domain.MaxAmount, AmountDistribution: DistributionLog}},
+		{name: "custom pool", cfg: Config{Count: 500, MaxAmount: 100, SizePool: []int{3, 5, 7}, MinSizes: 2, MaxSizes: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := Generate(rand.New(rand.NewPCG(1, 2)), tt.cfg)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if len(items) != tt.cfg.Count {
+				t.Fatalf("generated %d requests, want %d", len(items), tt.cfg.Count)
+			}
+
+			pool := tt.cfg.SizePool
+			if len(pool) == 0 {
+				pool = DefaultSizePool
+			}
+			for i, item := range items {
+				if err := domain.ValidateSolverInput(item.Sizes, item.Amount); err != nil {
+					t.Fatalf("request %d %+v is invalid: %v", i, item, err)
+				}
+				if item.Amount < max(tt.cfg.MinAmount, 1) || item.Amount > tt.cfg.MaxAmount {
+					t.Errorf("request %d: amount %d outside [%d, %d]", i, item.Amount, tt.cfg.MinAmount, tt.cfg.MaxAmount)
+				}
+				if tt.cfg.MaxSizes > 0 && len(item.Sizes) > tt.cfg.MaxSizes || len(item.Sizes) < max(tt.cfg.MinSizes, 1) {
+					t.Errorf("request %d: %d sizes outside the configured range", i, len(item.Sizes))
+				}
+				for _, size := range item.Sizes {
+					if !slices.Contains(pool, size) {
+						t.Errorf("request %d: size %d not in the pool", i, size)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	cfg := Config{Count: 50, MaxAmount: 100_000, AmountDistribution: DistributionLog}
+
+	first, err := Generate(rand.New(rand.NewPCG(7, 7)), cfg)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	second, err := Generate(rand.New(rand.NewPCG(7, 7)), cfg)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Error("same seed generated different requests")
+	}
+}
+
+func TestGenerate_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "missing max amount", cfg: Config{Count: 1}},
+		{name: "max amount too large", cfg: Config{Count: 1, MaxAmount: domain.MaxAmount + 1}},
+		{name: "empty amount range", cfg: Config{Count: 1, MinAmount: 10, MaxAmount: 5}},
+		{name: "unknown distribution", cfg: Config{Count: 1, MaxAmount: 10, AmountDistribution: "normal"}},
+		{name: "invalid pool", cfg: Config{Count: 1, MaxAmount: 10, SizePool: []int{5, 5}}},
+		{name: "more sizes than pool", cfg: Config{Count: 1, MaxAmount: 10, SizePool: []int{5}, MaxSizes: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(rand.New(rand.NewPCG(1, 2)), tt.cfg); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("Generate() error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+// Package metrics registers the service's Prometheus collectors
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Register registers a collector with the default registry
+// If an equal collector is already registered (e.g. the package was initialized
+// twice in one test process), the existing collector is reused instead of panicking
+func Register[T prometheus.Collector](c T) T {
+	return RegisterWith(prometheus.DefaultRegisterer, c)
+}
+
+// RegisterWith registers a collector with the given registerer,
+// returning the already registered collector on duplicate registration
+func RegisterWith[T prometheus.Collector](reg prometheus.Registerer, c T) T {
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegisterWith_DuplicateDoesNotPanic(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	newCounter := func() *prometheus.CounterVec {
+		return prometheus.NewCounterVec(
+			prometheus.CounterOpts{Name: "test_requests_total", Help: "Test counter"},
+			[]string{"status"},
+		)
+	}
+
+	first := RegisterWith(reg, newCounter())
+
+	var second *prometheus.CounterVec
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("second registration panicked: %v", r)
+			}
+		}()
+		second = RegisterWith(reg, newCounter())
+	}()
+
+	if first != second {
+		t.Error("expected duplicate registration to return the existing collector")
+	}
+
+	second.WithLabelValues("200").Inc()
+	if got := testutil.ToFloat64(first.WithLabelValues("200")); got != 1 {
+		t.Errorf("expected shared counter value 1, got %v", got)
+	}
+}
+
+func TestRegisterWith_ConflictingMetricPanics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	RegisterWith(reg, prometheus.NewCounter(prometheus.CounterOpts{Name: "test_conflict", Help: "Test counter"}))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for a conflicting metric with the same name")
+		}
+	}()
+	RegisterWith(reg, prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_conflict", Help: "Different help"}))
+}
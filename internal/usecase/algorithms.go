@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// Algorithm names in the registry
+const (
+	AlgorithmDP     = "dp"
+	AlgorithmGreedy = "greedy"
+	AlgorithmHybrid = "hybrid"
+)
+
+// Algorithm is a named solver available for comparison
+type Algorithm struct {
+	Name   string
+	Solver domain.Solver
+}
+
+// Algorithms returns the registered algorithms, AlgorithmDP (the exact
+// baseline) first; the heuristics trade optimality for speed and may return
+// more overage or packs than the optimum
+func Algorithms() []Algorithm {
+	dp := NewDPSolver()
+	return []Algorithm{
+		{Name: AlgorithmDP, Solver: dp},
+		{Name: AlgorithmGreedy, Solver: &GreedySolver{}},
+		{Name: AlgorithmHybrid, Solver: &HybridSolver{dp: dp}},
+	}
+}
+
+// GreedySolver takes as many of each size as fit, largest first, then covers
+// the remainder with one smallest pack
+// O(N) and allocation-free, but not optimal (e.g. 251 with 250/500 gives two 250s)
+type GreedySolver struct{}
+
+// Solve implements domain.Solver
+func (g *GreedySolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	if err := domain.ValidateSolverInput(sizes, amount); err != nil {
+		return nil, err
+	}
+	normalizedSizes, err := solverSizes(sizes, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := make(map[int]int)
+	remaining := amount
+	for i := len(normalizedSizes) - 1; i >= 0; i-- {
+		size := normalizedSizes[i]
+		if count := remaining / size; count > 0 {
+			breakdown[size] = count
+			remaining -= count * size
+		}
+	}
+	if remaining > 0 {
+		breakdown[normalizedSizes[0]]++
+	}
+
+	return domain.NewSolution(breakdown, amount), nil
+}
+
+// HybridSolver fills the bulk of large amounts with the largest size and runs
+// the DP only on a remainder of at least largest * smallest size, keeping the
+// table small; amounts below that window are solved by the DP alone
+// A heuristic: the bulk is never reconsidered, so the result may not be optimal
+type HybridSolver struct {
+	dp *DPSolver
+}
+
+// Solve implements domain.Solver
+func (h *HybridSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	if err := domain.ValidateSolverInput(sizes, amount); err != nil {
+		return nil, err
+	}
+	normalizedSizes, err := solverSizes(sizes, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	largest := normalizedSizes[len(normalizedSizes)-1]
+	window := largest * normalizedSizes[0]
+	bulk := 0
+	if amount > window {
+		bulk = (amount - window) / largest
+	}
+
+	solution, err := h.dp.Solve(ctx, normalizedSizes, amount-bulk*largest)
+	if err != nil {
+		return nil, err
+	}
+	if bulk == 0 {
+		return solution, nil
+	}
+
+	breakdown := make(map[int]int, len(solution.Breakdown)+1)
+	for size, count := range solution.Breakdown {
+		breakdown[size] = count
+	}
+	breakdown[largest] += bulk
+
+	return domain.NewSolution(breakdown, amount), nil
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+func TestAlgorithms_BaselineFirst(t *testing.T) {
+	algorithms := Algorithms()
+
+	names := make([]string, len(algorithms))
+	for i, algorithm := range algorithms {
+		names[i] = algorithm.Name
+	}
+	if len(names) != 3 || names[0] != AlgorithmDP || names[1] != AlgorithmGreedy || names[2] != AlgorithmHybrid {
+		t.Errorf("algorithms = %v, want [dp greedy hybrid]", names)
+	}
+}
+
+func TestHeuristicSolvers(t *testing.T) {
+	tests := []struct {
+		name   string
+		solver domain.Solver
+		sizes  []int
+		amount int
+		want   map[int]int
+	}{
+		// Not optimal: the DP picks one 500
+		{name: "greedy tops up with smallest", solver: &GreedySolver{}, sizes: []int{250, 500, 1000}, amount: 251, want: map[int]int{250: 2}},
+		{name: "greedy largest first", solver: &GreedySolver{}, sizes: []int{250, 500, 1000}, amount: 1250, want: map[int]int{1000: 1, 250: 1}},
+		// Below the 1000 * 250 window: the DP alone
+		{name: "hybrid small amount", solver: &HybridSolver{dp: NewDPSolver()}, sizes: []int{250, 500, 1000}, amount: 251, want: map[int]int{500: 1}},
+		// 9410 bulk 53s leave 1270 for the DP (window 53 * 23 = 1219)
+		{name: "hybrid bulk plus DP", solver: &HybridSolver{dp: NewDPSolver()}, sizes: []int{23, 31, 53}, amount: 500000, want: map[int]int{23: 2, 31: 7, 53: 9429}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solution, err := tt.solver.Solve(context.Background(), tt.sizes, tt.amount)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalBreakdown(solution.Breakdown, tt.want) {
+				t.Errorf("breakdown = %v, want %v", solution.Breakdown, tt.want)
+			}
+			if solution.TotalItems() < tt.amount {
+				t.Errorf("solution does not cover amount: %d < %d", solution.TotalItems(), tt.amount)
+			}
+		})
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		for _, solver := range []domain.Solver{&GreedySolver{}, &HybridSolver{dp: NewDPSolver()}} {
+			if _, err := solver.Solve(context.Background(), []int{250, 250}, 100); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("%T: expected ErrInvalidInput, got %v", solver, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"sort"
+)
+
+// AllocatePacks splits a combined breakdown between line items in proportion to their amounts
+// Largest remainder heuristic, applied per pack size: with count packs of a size and
+// total = sum(amounts), item i first gets floor(count * amounts[i] / total) packs; the
+// packs left over go one each to the items with the largest fractional shares, ties to
+// the earlier item. Every pack is allocated exactly once, but since packs are shared an
+// item's units may fall short of or exceed its own amount
+// Returns one breakdown (size -> count) per amount, in the same order
+func AllocatePacks(breakdown map[int]int, amounts []int) []map[int]int {
+	allocation := make([]map[int]int, len(amounts))
+	for i := range allocation {
+		allocation[i] = make(map[int]int)
+	}
+
+	total := 0
+	for _, amount := range amounts {
+		total += amount
+	}
+	if total <= 0 {
+		return allocation
+	}
+
+	remainders := make([]int, len(amounts))
+	order := make([]int, len(amounts))
+
+	for size, count := range breakdown {
+		if count <= 0 {
+			continue
+		}
+
+		assigned := 0
+		for i, amount := range amounts {
+			share := count * amount / total
+			remainders[i] = count * amount % total
+			order[i] = i
+			if share > 0 {
+				allocation[i][size] = share
+				assigned += share
+			}
+		}
+
+		// Hand out the leftover packs by largest fractional share
+		sort.SliceStable(order, func(a, b int) bool {
+			return remainders[order[a]] > remainders[order[b]]
+		})
+		for _, i := range order[:count-assigned] {
+			allocation[i][size]++
+		}
+	}
+
+	return allocation
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllocatePacks(t *testing.T) {
+	tests := []struct {
+		name      string
+		breakdown map[int]int
+		amounts   []int
+		want      []map[int]int
+	}{
+		{
+			name:      "two line items",
+			breakdown: map[int]int{5000: 2, 2000: 1, 250: 1}, // 12001 = 7000 + 5001
+			amounts:   []int{7000, 5001},
+			want: []map[int]int{
+				{5000: 1, 2000: 1, 250: 1},
+				{5000: 1},
+			},
+		},
+		{
+			name:      "exact proportions",
+			breakdown: map[int]int{10: 4},
+			amounts:   []int{30, 10},
+			want:      []map[int]int{{10: 3}, {10: 1}},
+		},
+		{
+			name:      "ties go to the earlier item",
+			breakdown: map[int]int{10: 1},
+			amounts:   []int{5, 5},
+			want:      []map[int]int{{10: 1}, {}},
+		},
+		{
+			name:      "single item takes everything",
+			breakdown: map[int]int{500: 1, 250: 1},
+			amounts:   []int{600},
+			want:      []map[int]int{{500: 1, 250: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AllocatePacks(tt.breakdown, tt.amounts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AllocatePacks() = %v, want %v", got, tt.want)
+			}
+
+			// Every pack is allocated exactly once
+			allocated := make(map[int]int)
+			for _, items := range got {
+				for size, count := range items {
+					allocated[size] += count
+				}
+			}
+			if !reflect.DeepEqual(allocated, tt.breakdown) {
+				t.Errorf("allocated packs %v, want %v", allocated, tt.breakdown)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// DefaultBatchConcurrency - default number of batch items solved concurrently
+const DefaultBatchConcurrency = 4
+
+// BatchItem is one solve request of a batch
+type BatchItem struct {
+	Sizes  []int
+	Amount int
+}
+
+// BatchResult is the outcome of one batch item
+type BatchResult struct {
+	Solution *domain.Solution
+	Err      error
+}
+
+// SolveBatch solves items with a pool of at most concurrency workers
+// (non-positive concurrency falls back to DefaultBatchConcurrency)
+// results[i] always belongs to items[i], whatever order the workers finish in
+// Cancelling ctx stops all workers; items not solved by then get ctx.Err()
+func SolveBatch(ctx context.Context, solver domain.Solver, items []BatchItem, concurrency int) []BatchResult {
+	results := make([]BatchResult, len(items))
+	if len(items) == 0 {
+		return results
+	}
+
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+	concurrency = min(concurrency, len(items))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				solution, err := solver.Solve(ctx, items[i].Sizes, items[i].Amount)
+				results[i] = BatchResult{Solution: solution, Err: err}
+			}
+		}()
+	}
+
+	// Hand out items in order until done or cancelled
+	next := 0
+dispatch:
+	for ; next < len(items); next++ {
+		select {
+		case jobs <- next:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := next; i < len(items); i++ {
+		results[i].Err = ctx.Err()
+	}
+
+	return results
+}
+
+// SolveBatchShared is SolveBatch for a solver that can also solve series:
+// items with the same sizes (in any order) are solved together with one DP
+// table, the rest go through SolveBatch
+// A group whose series solve fails (e.g. an amount too large for an unclipped
+// table) falls back to solving its items one by one, so per-item errors are
+// unchanged; grouped items do not pass through solver, so decorators such as
+// a cache or metrics do not see them
+func SolveBatchShared(ctx context.Context, solver domain.Solver, series domain.SeriesSolver, items []BatchItem, concurrency int) []BatchResult {
+	results := make([]BatchResult, len(items))
+
+	groups := make(map[string][]int)
+	var keys []string
+	for i, item := range items {
+		// Sorted, not normalized: an item with duplicate sizes must keep
+		// failing validation instead of borrowing another item's sizes
+		key := fmt.Sprint(slices.Sorted(slices.Values(item.Sizes)))
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	var single []int
+	for _, key := range keys {
+		indexes := groups[key]
+		if len(indexes) < 2 {
+			single = append(single, indexes...)
+			continue
+		}
+
+		amounts := make([]int, len(indexes))
+		for j, i := range indexes {
+			amounts[j] = items[i].Amount
+		}
+		solutions, err := series.SolveSeries(ctx, items[indexes[0]].Sizes, amounts)
+		if err != nil {
+			single = append(single, indexes...)
+			continue
+		}
+		for j, i := range indexes {
+			results[i].Solution = solutions[j]
+		}
+	}
+
+	singleItems := make([]BatchItem, len(single))
+	for j, i := range single {
+		singleItems[j] = items[i]
+	}
+	for j, result := range SolveBatch(ctx, solver, singleItems, concurrency) {
+		results[single[j]] = result
+	}
+
+	return results
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// concurrencyTrackingSolver records the peak number of concurrent Solve calls
+type concurrencyTrackingSolver struct {
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	delay    time.Duration
+}
+
+func (s *concurrencyTrackingSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	current := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+	for {
+		peak := s.peak.Load()
+		if current <= peak || s.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return domain.NewSolution(map[int]int{1: amount}, amount), nil
+}
+
+func TestSolveBatch_OrderedWithBoundedConcurrency(t *testing.T) {
+	solver := &concurrencyTrackingSolver{delay: time.Millisecond}
+
+	items := make([]BatchItem, 500)
+	for i := range items {
+		items[i] = BatchItem{Sizes: []int{1}, Amount: i + 1}
+	}
+
+	results := SolveBatch(context.Background(), solver, items, 8)
+
+	if len(results) != len(items) {
+		t.Fatalf("expected %d results, got %d", len(items), len(results))
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			t.Fatalf("item %d: unexpected error %v", i, result.Err)
+		}
+		if result.Solution.Amount != items[i].Amount {
+			t.Fatalf("item %d: result for amount %d, want %d", i, result.Solution.Amount, items[i].Amount)
+		}
+	}
+
+	if peak := solver.peak.Load(); peak > 8 {
+		t.Errorf("peak concurrency = %d, want at most 8", peak)
+	} else if peak < 2 {
+		t.Errorf("peak concurrency = %d, expected items to be solved concurrently", peak)
+	}
+}
+
+func TestSolveBatch_Cancellation(t *testing.T) {
+	solver := &concurrencyTrackingSolver{delay: time.Hour}
+
+	items := make([]BatchItem, 50)
+	for i := range items {
+		items[i] = BatchItem{Sizes: []int{1}, Amount: i + 1}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	done := make(chan []BatchResult)
+	go func() { done <- SolveBatch(ctx, solver, items, 4) }()
+
+	select {
+	case results := <-done:
+		for i, result := range results {
+			if !errors.Is(result.Err, context.DeadlineExceeded) {
+				t.Errorf("item %d: expected DeadlineExceeded, got %v", i, result.Err)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SolveBatch did not stop after cancellation")
+	}
+
+	if inFlight := solver.inFlight.Load(); inFlight != 0 {
+		t.Errorf("%d workers still solving after SolveBatch returned", inFlight)
+	}
+}
+
+func TestSolveBatchShared_MatchesSolveBatch(t *testing.T) {
+	solver := NewDPSolver()
+	ctx := context.Background()
+
+	// Two groups (sizes order does not matter), a single item, an item with
+	// duplicate sizes and a group too large for a shared table
+	items := []BatchItem{
+		{Sizes: []int{250, 500, 1000}, Amount: 251},
+		{Sizes: []int{23, 31, 53}, Amount: 500_000},
+		{Sizes: []int{1000, 500, 250}, Amount: 12_001},
+		{Sizes: []int{23, 31, 53}, Amount: 263},
+		{Sizes: []int{7}, Amount: 20},
+		{Sizes: []int{250, 500, 500, 1000}, Amount: 1},
+		{Sizes: []int{250, 500}, Amount: DefaultMaxTableSize},
+		{Sizes: []int{250, 500}, Amount: 751},
+	}
+
+	want := SolveBatch(ctx, solver, items, 2)
+	got := SolveBatchShared(ctx, solver, solver, items, 2)
+
+	if len(got) != len(items) {
+		t.Fatalf("expected %d results, got %d", len(items), len(got))
+	}
+	for i := range items {
+		if (got[i].Err == nil) != (want[i].Err == nil) {
+			t.Errorf("item %d: error = %v, want %v", i, got[i].Err, want[i].Err)
+			continue
+		}
+		if got[i].Err != nil {
+			continue
+		}
+		g, w := got[i].Solution, want[i].Solution
+		if g.Amount != w.Amount || g.Overage != w.Overage || g.Packs != w.Packs {
+			t.Errorf("item %d: got amount %d, overage %d, packs %d, want amount %d, overage %d, packs %d",
+				i, g.Amount, g.Overage, g.Packs, w.Amount, w.Overage, w.Packs)
+		}
+		if err := g.Validate(); err != nil {
+			t.Errorf("item %d: invalid solution: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/metrics"
+)
+
+// Prometheus metrics
+var (
+	solverSolveDuration = metrics.Register(prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "solver_solve_duration_seconds",
+			Help: "Solver computation time in seconds, by number of distinct pack sizes",
+			// 100µs .. ~26s: small inputs solve in microseconds, large amounts in seconds
+			Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10),
+		},
+		[]string{"sizes"},
+	))
+
+	solverOverage = metrics.Register(prometheus.NewSummary(prometheus.SummaryOpts{
+		Name:       "solver_overage",
+		Help:       "Overage (items shipped above the amount) of successful solves",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	}))
+
+	solverPacks = metrics.Register(prometheus.NewSummary(prometheus.SummaryOpts{
+		Name:       "solver_packs",
+		Help:       "Number of packs in successful solves",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	}))
+)
+
+// InstrumentedSolver is a domain.Solver decorator that records Prometheus
+// metrics for every solve: its duration (failed solves included) and the
+// overage and pack count of the solution
+// Wrap the computing solver, below any cache, so cache hits are not timed
+type InstrumentedSolver struct {
+	solver domain.Solver
+}
+
+// NewInstrumentedSolver creates a new instrumenting decorator
+func NewInstrumentedSolver(solver domain.Solver) *InstrumentedSolver {
+	return &InstrumentedSolver{solver: solver}
+}
+
+// Solve delegates to the wrapped solver and records the metrics
+func (s *InstrumentedSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	start := time.Now()
+	solution, err := s.solver.Solve(ctx, sizes, amount)
+	solverSolveDuration.WithLabelValues(sizeCountBucket(sizes)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	solverOverage.Observe(float64(solution.Overage))
+	solverPacks.Observe(float64(solution.Packs))
+	return solution, nil
+}
+
+// sizeCountBucket returns the duration label for the number of distinct sizes
+// Buckets keep the label cardinality fixed regardless of the request
+func sizeCountBucket(sizes []int) string {
+	distinct := make(map[int]struct{}, len(sizes))
+	for _, size := range sizes {
+		distinct[size] = struct{}{}
+	}
+
+	switch n := len(distinct); {
+	case n <= 1:
+		return "1"
+	case n <= 3:
+		return "2-3"
+	case n <= 7:
+		return "4-7"
+	case n <= 15:
+		return "8-15"
+	default:
+		return "16+"
+	}
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// sampleCount returns the number of observations of a histogram or summary
+func sampleCount(t *testing.T, metric prometheus.Metric) uint64 {
+	t.Helper()
+
+	var m dto.Metric
+	if err := metric.Write(&m); err != nil {
+		t.Fatalf("failed to read metric: %v", err)
+	}
+	if m.Histogram != nil {
+		return m.Histogram.GetSampleCount()
+	}
+	return m.Summary.GetSampleCount()
+}
+
+func TestInstrumentedSolver_RecordsMetrics(t *testing.T) {
+	ctx := context.Background()
+	solver := NewInstrumentedSolver(NewDPSolver())
+	duration := solverSolveDuration.WithLabelValues("2-3").(prometheus.Metric)
+
+	durationBefore := sampleCount(t, duration)
+	overageBefore := sampleCount(t, solverOverage)
+	packsBefore := sampleCount(t, solverPacks)
+
+	solution, err := solver.Solve(ctx, []int{250, 500, 1000}, 251)
+	if err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+	if solution.Packs != 1 || solution.Overage != 249 {
+		t.Errorf("Solve() = %+v, want 1 pack with overage 249", solution)
+	}
+
+	if got := sampleCount(t, duration) - durationBefore; got != 1 {
+		t.Errorf("duration observations = %d, want 1", got)
+	}
+	if got := sampleCount(t, solverOverage) - overageBefore; got != 1 {
+		t.Errorf("overage observations = %d, want 1", got)
+	}
+	if got := sampleCount(t, solverPacks) - packsBefore; got != 1 {
+		t.Errorf("packs observations = %d, want 1", got)
+	}
+
+	// Failed solves are timed, but have no solution to observe
+	if _, err := solver.Solve(ctx, []int{250, 500, 1000}, 0); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+	if got := sampleCount(t, duration) - durationBefore; got != 2 {
+		t.Errorf("duration observations = %d, want 2", got)
+	}
+	if got := sampleCount(t, solverOverage) - overageBefore; got != 1 {
+		t.Errorf("overage observations = %d, want 1", got)
+	}
+}
+
+func TestSizeCountBucket(t *testing.T) {
+	tests := []struct {
+		sizes []int
+		want  string
+	}{
+		{sizes: []int{250}, want: "1"},
+		{sizes: []int{250, 250, 250}, want: "1"},
+		{sizes: []int{250, 500, 1000}, want: "2-3"},
+		{sizes: []int{1, 2, 3, 4, 5, 6, 7}, want: "4-7"},
+		{sizes: []int{1, 2, 3, 4, 5, 6, 7, 8}, want: "8-15"},
+		{sizes: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, want: "16+"},
+	}
+
+	for _, tt := range tests {
+		if got := sizeCountBucket(tt.sizes); got != tt.want {
+			t.Errorf("sizeCountBucket(%v) = %q, want %q", tt.sizes, got, tt.want)
+		}
+	}
+}
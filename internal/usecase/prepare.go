@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// Warning codes reported by PrepareInput
+const (
+	WarningDuplicatesRemoved   = "duplicates_removed"
+	WarningInvalidSizesDropped = "invalid_sizes_dropped"
+	WarningCommonDivisor       = "common_divisor"
+)
+
+// InputWarning describes a non-fatal adjustment or observation about solver input
+type InputWarning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PreparedInput is the canonical solver input produced by PrepareInput
+type PreparedInput struct {
+	Sizes    []int          // Unique valid sizes in ascending order
+	Amount   int            // Required amount
+	Warnings []InputWarning // Adjustments made while canonicalizing
+}
+
+// PrepareInput validates and normalizes solver input without solving
+// Sizes outside (0, domain.MaxPackSize] are dropped and duplicates removed, each reported
+// as a warning; the canonical input is then validated
+// Returns an errors.Join of *domain.ValidationError (one per invalid field) when invalid
+func PrepareInput(sizes []int, amount int) (*PreparedInput, error) {
+	warnings := []InputWarning{}
+
+	// Drop sizes the validator would reject (same policy as the solver)
+	canonical, dropped := normalizeSizes(sizes)
+
+	if len(dropped) > 0 {
+		warnings = append(warnings, InputWarning{
+			Code:    WarningInvalidSizesDropped,
+			Message: fmt.Sprintf("dropped sizes outside 1..%d: %v", domain.MaxPackSize, dropped),
+		})
+	}
+
+	if removed := len(sizes) - len(dropped) - len(canonical); removed > 0 {
+		warnings = append(warnings, InputWarning{
+			Code:    WarningDuplicatesRemoved,
+			Message: fmt.Sprintf("removed %d duplicate sizes", removed),
+		})
+	}
+
+	// Validate canonical input, collecting an error per field
+	var errs []error
+	if err := domain.ValidatePackSizes(canonical); err != nil {
+		errs = append(errs, domain.NewValidationError("sizes", sizes, err.Error()))
+	}
+	if err := domain.ValidateAmount(amount); err != nil {
+		errs = append(errs, domain.NewValidationError("amount", amount, err.Error()))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// All sizes sharing a divisor means only multiples of it are reachable
+	// (trivially true for a single size, so only reported for several sizes)
+	if divisor := domain.SizesGCD(canonical); len(canonical) > 1 && divisor > 1 {
+		warnings = append(warnings, InputWarning{
+			Code:    WarningCommonDivisor,
+			Message: fmt.Sprintf("all sizes are multiples of %d; totals are always multiples of %d", divisor, divisor),
+		})
+	}
+
+	return &PreparedInput{
+		Sizes:    canonical,
+		Amount:   amount,
+		Warnings: warnings,
+	}, nil
+}
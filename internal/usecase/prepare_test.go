@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+func TestPrepareInput(t *testing.T) {
+	tests := []struct {
+		name         string
+		sizes        []int
+		amount       int
+		wantSizes    []int
+		wantWarnings []string // warning codes in order
+	}{
+		{
+			name:         "already canonical",
+			sizes:        []int{23, 31, 53},
+			amount:       500,
+			wantSizes:    []int{23, 31, 53},
+			wantWarnings: []string{},
+		},
+		{
+			name:         "unsorted with duplicates",
+			sizes:        []int{53, 23, 31, 23},
+			amount:       500,
+			wantSizes:    []int{23, 31, 53},
+			wantWarnings: []string{WarningDuplicatesRemoved},
+		},
+		{
+			name:         "invalid sizes dropped",
+			sizes:        []int{-1, 0, 23, 31, 2_000_000},
+			amount:       500,
+			wantSizes:    []int{23, 31},
+			wantWarnings: []string{WarningInvalidSizesDropped},
+		},
+		{
+			name:         "common divisor",
+			sizes:        []int{1000, 250, 500, 500},
+			amount:       1001,
+			wantSizes:    []int{250, 500, 1000},
+			wantWarnings: []string{WarningDuplicatesRemoved, WarningCommonDivisor},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prepared, err := PrepareInput(tt.sizes, tt.amount)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(prepared.Sizes, tt.wantSizes) {
+				t.Errorf("Sizes = %v, want %v", prepared.Sizes, tt.wantSizes)
+			}
+			if prepared.Amount != tt.amount {
+				t.Errorf("Amount = %d, want %d", prepared.Amount, tt.amount)
+			}
+
+			codes := make([]string, 0, len(prepared.Warnings))
+			for _, w := range prepared.Warnings {
+				codes = append(codes, w.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", codes, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestPrepareInput_Invalid(t *testing.T) {
+	// Both fields invalid: one validation error per field
+	_, err := PrepareInput([]int{-5, 0}, 0)
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected joined errors, got %T", err)
+	}
+
+	var fields []string
+	for _, e := range joined.Unwrap() {
+		var validationErr *domain.ValidationError
+		if errors.As(e, &validationErr) {
+			fields = append(fields, validationErr.Field)
+		}
+	}
+	if !reflect.DeepEqual(fields, []string{"sizes", "amount"}) {
+		t.Errorf("fields = %v, want [sizes amount]", fields)
+	}
+}
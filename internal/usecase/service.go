@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// Service implements domain.SolverService on top of a solver and stored pack size sets
+type Service struct {
+	solver     domain.Solver
+	repository domain.PackSizeRepository
+}
+
+// NewService creates a new solver service
+// Caching comes from the solver itself, e.g. a redis.CachedSolver
+func NewService(solver domain.Solver, repository domain.PackSizeRepository) *Service {
+	return &Service{
+		solver:     solver,
+		repository: repository,
+	}
+}
+
+// SolveWithCache solves the problem through the configured (possibly cached) solver
+func (s *Service) SolveWithCache(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	return s.solver.Solve(ctx, sizes, amount)
+}
+
+// SolveWithPackSizeSet loads the pack size set by ID and solves against its sizes
+// Returns domain.ErrPackSizeSetNotFound if there is no such set
+func (s *Service) SolveWithPackSizeSet(ctx context.Context, packSizeSetID int64, amount int) (*domain.Solution, error) {
+	packSizeSet, err := s.repository.GetByID(ctx, packSizeSetID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPackSizeSetNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to load pack size set %d: %w", packSizeSetID, err)
+	}
+
+	return s.solver.Solve(ctx, packSizeSet.Sizes, amount)
+}
+
+// ValidateInput validates input data before solving
+func (s *Service) ValidateInput(sizes []int, amount int) error {
+	return domain.ValidateSolverInput(sizes, amount)
+}
+
+// Ensure Service implements domain.SolverService interface
+var _ domain.SolverService = (*Service)(nil)
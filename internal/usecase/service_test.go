@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// memoryPackSizeRepository is an in-memory domain.PackSizeRepository keyed by ID
+type memoryPackSizeRepository struct {
+	sets map[int64]*domain.PackSizeSet
+}
+
+func (m *memoryPackSizeRepository) Create(ctx context.Context, ps *domain.PackSizeSet) (*domain.PackSizeSet, error) {
+	id := int64(len(m.sets) + 1)
+	created := &domain.PackSizeSet{ID: &id, Name: ps.Name, Sizes: ps.Sizes}
+	m.sets[id] = created
+	return created, nil
+}
+
+func (m *memoryPackSizeRepository) GetByID(ctx context.Context, id int64) (*domain.PackSizeSet, error) {
+	if ps, ok := m.sets[id]; ok {
+		return ps, nil
+	}
+	return nil, domain.ErrPackSizeSetNotFound
+}
+
+func (m *memoryPackSizeRepository) GetByName(ctx context.Context, name string) (*domain.PackSizeSet, error) {
+	for _, ps := range m.sets {
+		if ps.Name != nil && *ps.Name == name {
+			return ps, nil
+		}
+	}
+	return nil, domain.ErrPackSizeSetNotFound
+}
+
+func (m *memoryPackSizeRepository) List(ctx context.Context, limit, offset int) ([]*domain.PackSizeSet, error) {
+	sets := make([]*domain.PackSizeSet, 0, len(m.sets))
+	for _, ps := range m.sets {
+		sets = append(sets, ps)
+	}
+	return sets, nil
+}
+
+func (m *memoryPackSizeRepository) Update(ctx context.Context, ps *domain.PackSizeSet) error {
+	if _, ok := m.sets[*ps.ID]; !ok {
+		return domain.ErrPackSizeSetNotFound
+	}
+	m.sets[*ps.ID] = ps
+	return nil
+}
+
+func (m *memoryPackSizeRepository) Delete(ctx context.Context, id int64) error {
+	if _, ok := m.sets[id]; !ok {
+		return domain.ErrPackSizeSetNotFound
+	}
+	delete(m.sets, id)
+	return nil
+}
+
+// recordingSolver records the input it was called with and delegates to the DP solver
+type recordingSolver struct {
+	sizes  []int
+	amount int
+}
+
+func (r *recordingSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	r.sizes, r.amount = sizes, amount
+	return NewDPSolver().Solve(ctx, sizes, amount)
+}
+
+func TestService_SolveWithPackSizeSet(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryPackSizeRepository{sets: make(map[int64]*domain.PackSizeSet)}
+	name := "standard"
+	created, _ := repo.Create(ctx, &domain.PackSizeSet{Name: &name, Sizes: []int{250, 500, 1000}})
+
+	solver := &recordingSolver{}
+	service := NewService(solver, repo)
+
+	solution, err := service.SolveWithPackSizeSet(ctx, *created.ID, 251)
+	if err != nil {
+		t.Fatalf("SolveWithPackSizeSet() error = %v", err)
+	}
+	if !reflect.DeepEqual(solver.sizes, []int{250, 500, 1000}) || solver.amount != 251 {
+		t.Errorf("solver called with sizes %v amount %d", solver.sizes, solver.amount)
+	}
+	if !reflect.DeepEqual(solution.Breakdown, map[int]int{500: 1}) {
+		t.Errorf("breakdown = %v, want map[500:1]", solution.Breakdown)
+	}
+
+	if _, err := service.SolveWithPackSizeSet(ctx, 42, 251); !errors.Is(err, domain.ErrPackSizeSetNotFound) {
+		t.Errorf("missing set: expected ErrPackSizeSetNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// SimulationReport aggregates the solutions of a catalog against a set of amounts
+type SimulationReport struct {
+	Amounts      int     // Amounts simulated
+	Solved       int     // Amounts with a solution
+	Failed       int     // Amounts the solver failed on
+	AvgOverage   float64 // Mean overage of the solved amounts
+	AvgPacks     float64 // Mean pack count of the solved amounts
+	ExactPercent float64 // Share of solved amounts with zero overage, 0..100
+}
+
+// Simulate solves every amount with sizes (through SolveBatch, so solver
+// decorators such as the cache apply) and aggregates the results
+// Failed amounts are counted but excluded from the averages; a cancelled ctx
+// returns its error instead of a partial report
+func Simulate(ctx context.Context, solver domain.Solver, sizes []int, amounts []int, concurrency int) (*SimulationReport, error) {
+	items := make([]BatchItem, len(amounts))
+	for i, amount := range amounts {
+		items[i] = BatchItem{Sizes: sizes, Amount: amount}
+	}
+
+	results := SolveBatch(ctx, solver, items, concurrency)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &SimulationReport{Amounts: len(amounts)}
+	var overage, packs, exact int
+	for _, result := range results {
+		if result.Err != nil {
+			report.Failed++
+			continue
+		}
+		report.Solved++
+		overage += result.Solution.Overage
+		packs += result.Solution.Packs
+		if result.Solution.Overage == 0 {
+			exact++
+		}
+	}
+
+	if report.Solved > 0 {
+		solved := float64(report.Solved)
+		report.AvgOverage = float64(overage) / solved
+		report.AvgPacks = float64(packs) / solved
+		report.ExactPercent = float64(exact) * 100 / solved
+	}
+
+	return report, nil
+}
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	// 0 fails validation and is excluded from the averages
+	amounts := []int{250, 251, 0, 500, 1001}
+
+	report, err := Simulate(context.Background(), NewDPSolver(), []int{250, 500, 1000}, amounts, 2)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+
+	want := SimulationReport{Amounts: 5, Solved: 4, Failed: 1, AvgOverage: 124.5, AvgPacks: 1.25, ExactPercent: 50}
+	if *report != want {
+		t.Errorf("report = %+v, want %+v", *report, want)
+	}
+}
+
+func TestSimulate_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Simulate(ctx, NewDPSolver(), []int{250, 500}, []int{251, 751}, 2); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -2,7 +2,11 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"sort"
+	"strconv"
+	"unsafe"
 
 	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
 )
@@ -11,11 +15,34 @@ import (
 // Algorithm: one-dimensional DP over sum 0..W with ancestor reconstruction
 // Complexity: O(W * N) time, O(W) space
 // Priority: minimize overage, then minimize number of packs
-type DPSolver struct{}
+type DPSolver struct {
+	memoryBudget int // Maximum DP table size in bytes (0 = unlimited)
+	maxTableSize int // Maximum number of DP table elements
+	maxTopK      int // Maximum k for SolveTopK
+}
 
-// NewDPSolver creates a new instance of the DP solver
+// NewDPSolver creates a new instance of the DP solver with the default
+// table limit (DefaultMaxTableSize)
 func NewDPSolver() *DPSolver {
-	return &DPSolver{}
+	return NewDPSolverWithLimit(DefaultMaxTableSize)
+}
+
+// NewDPSolverWithLimit creates a DP solver whose table holds at most
+// maxTableSize elements; a non-positive limit uses DefaultMaxTableSize
+// Solve clips the search to the limit, the other solve modes reject
+// amounts beyond it
+func NewDPSolverWithLimit(maxTableSize int) *DPSolver {
+	if maxTableSize <= 0 {
+		maxTableSize = DefaultMaxTableSize
+	}
+	return &DPSolver{maxTableSize: maxTableSize, maxTopK: DefaultMaxTopK}
+}
+
+// WithMemoryBudget rejects requests whose DP table would exceed budget bytes
+// A budget of 0 disables the check
+func (s *DPSolver) WithMemoryBudget(budget int) *DPSolver {
+	s.memoryBudget = budget
+	return s
 }
 
 // dpState represents the DP state for a specific sum
@@ -25,8 +52,70 @@ type dpState struct {
 	parent int32 // Index of the pack size that led to this state (-1 if unreachable)
 }
 
+// SolveOptions tunes a single DPSolver.SolveWithOptions call
+type SolveOptions struct {
+	// MaxOverage caps the acceptable overage; nil uses the natural bound
+	// (smallest size - 1), which never excludes the optimum
+	MaxOverage *int
+
+	// Priority orders the optimization criteria; fewest packs first searches
+	// up to largest size - 1 of overage instead
+	Priority domain.Priority
+
+	// Costs maps each size to the cost of one pack; when set, the total cost
+	// is minimized instead of the overage (which breaks ties, then packs)
+	// Requires a cost for every size and the default Priority
+	Costs map[int]float64
+}
+
 // Solve finds the optimal solution using dynamic programming
+// Options set on the context via domain.WithSolveOptions are applied
+// (see SolveOptionsFromContext)
 func (s *DPSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	opts, err := SolveOptionsFromContext(ctx)
+	if err != nil {
+		return nil, domain.NewSolverError(sizes, amount, err.Error(), domain.ErrInvalidInput)
+	}
+	return s.SolveWithOptions(ctx, sizes, amount, opts)
+}
+
+// SolveOptionsFromContext decodes DPSolver options from domain.SolveOptions on the context
+func SolveOptionsFromContext(ctx context.Context) (SolveOptions, error) {
+	var opts SolveOptions
+
+	if raw, ok := domain.SolveOptionsFromContext(ctx)[domain.SolveOptionMaxOverage]; ok {
+		maxOverage, err := strconv.Atoi(raw)
+		if err != nil || maxOverage < 0 {
+			return opts, fmt.Errorf("%s must be a non-negative integer, got %q", domain.SolveOptionMaxOverage, raw)
+		}
+		opts.MaxOverage = &maxOverage
+	}
+
+	if raw, ok := domain.SolveOptionsFromContext(ctx)[domain.SolveOptionPriority]; ok {
+		priority, err := domain.ParsePriority(raw)
+		if err != nil {
+			return opts, fmt.Errorf("%s must be %s or %s, got %q", domain.SolveOptionPriority,
+				domain.PriorityOveragePacks, domain.PriorityPacksOverage, raw)
+		}
+		opts.Priority = priority
+	}
+
+	if raw, ok := domain.SolveOptionsFromContext(ctx)[domain.SolveOptionCosts]; ok {
+		costs, err := domain.ParsePackCosts(raw)
+		if err != nil {
+			return opts, fmt.Errorf("%s must be a list of size:cost, got %q", domain.SolveOptionCosts, raw)
+		}
+		opts.Costs = costs
+	}
+
+	return opts, nil
+}
+
+// SolveWithOptions finds the optimal solution using dynamic programming
+// With MaxOverage set, returns ErrNoSolution if no solution fits within the cap
+// With PriorityPacksOverage or Costs, returns ErrSearchTruncated if the search
+// range would exceed the DP table limit, since the optimum can't be guaranteed
+func (s *DPSolver) SolveWithOptions(ctx context.Context, sizes []int, amount int, opts SolveOptions) (*domain.Solution, error) {
 	// Check context
 	select {
 	case <-ctx.Done():
@@ -38,38 +127,393 @@ func (s *DPSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.
 	if err := domain.ValidateSolverInput(sizes, amount); err != nil {
 		return nil, err
 	}
+	if opts.MaxOverage != nil && *opts.MaxOverage < 0 {
+		return nil, fmt.Errorf("%w: max overage must not be negative, got %d", domain.ErrInvalidInput, *opts.MaxOverage)
+	}
 
-	// Normalize input sizes: remove duplicates and sort
-	normalizedSizes := normalizeSizes(sizes)
-	if len(normalizedSizes) == 0 {
-		return nil, domain.NewSolverError(sizes, amount, "no valid sizes after normalization", domain.ErrInvalidInput)
+	// Reject duplicate or out-of-range sizes and sort
+	normalizedSizes, err := solverSizes(sizes, amount)
+	if err != nil {
+		return nil, err
 	}
 
-	// Early exit: check for exact match with a single pack
-	for _, size := range normalizedSizes {
-		if size == amount {
-			breakdown := map[int]int{size: 1}
-			return domain.NewSolution(breakdown, amount), nil
-		}
+	// Costs replace the optimization criteria altogether
+	if len(opts.Costs) > 0 {
+		return s.solveWeighted(ctx, normalizedSizes, amount, opts)
+	}
+
+	// Early exit: amount equals one of the sizes
+	if solution := singlePackSolution(normalizedSizes, amount); solution != nil {
+		return solution, nil
+	}
+
+	// Early exit: below the smallest size, that size is the only reachable sum
+	// within reach, so one smallest pack is optimal under any priority
+	if solution := belowSmallestSolution(normalizedSizes, amount, opts.MaxOverage); solution != nil {
+		return solution, nil
 	}
 
 	// Determine the maximum sum for the DP table
 	// We need to cover amount, but may have overage
 	// Limit the search to a reasonable bound
-	maxSum := calculateMaxSum(amount, normalizedSizes)
+	maxSum := s.calculateMaxSum(amount, normalizedSizes, opts.Priority)
+	truncated := s.isTruncated(amount, normalizedSizes, opts.Priority)
+
+	// A lower overage cap shrinks the search; sums beyond it are never accepted
+	// (compared as a difference: amount + a huge cap would overflow int)
+	capped := opts.MaxOverage != nil && *opts.MaxOverage < maxSum-amount
+	if capped {
+		maxSum = amount + *opts.MaxOverage
+		truncated = false
+	}
+
+	// Fewer packs may hide beyond the clipped table, so a found solution proves nothing
+	if truncated && opts.Priority == domain.PriorityPacksOverage {
+		return nil, domain.NewSolverError(normalizedSizes, amount,
+			fmt.Sprintf("DP table limited to %d sums", s.maxTableSize), domain.ErrSearchTruncated)
+	}
+
+	// Every reachable sum is a multiple of the sizes' gcd: without one in
+	// [amount, maxSum] (a tight overage cap, or an amount beyond the DP table
+	// limit) the table would come back empty, so fail before building it
+	if firstCandidateSum(normalizedSizes, amount) > maxSum {
+		return nil, s.noSolutionError(normalizedSizes, amount, opts, capped, truncated)
+	}
+
+	// Reject before allocating if the DP table exceeds the memory budget
+	if s.memoryBudget > 0 {
+		if estimate := dpTableBytes(maxSum); estimate > s.memoryBudget {
+			return nil, domain.NewSolverError(normalizedSizes, amount,
+				fmt.Sprintf("DP table needs %d bytes, budget is %d", estimate, s.memoryBudget),
+				domain.ErrMemoryBudgetExceeded)
+		}
+	}
+
+	dp, err := fillDPTable(ctx, normalizedSizes, maxSum)
+	if err != nil {
+		return nil, err
+	}
+
+	bestSum := findBestSum(dp, amount, maxSum, opts.Priority)
+
+	// If no solution was found
+	if bestSum == -1 {
+		return nil, s.noSolutionError(normalizedSizes, amount, opts, capped, truncated)
+	}
+
+	// Reconstruct solution
+	breakdown := reconstructSolution(dp, normalizedSizes, bestSum)
+	solution := domain.NewSolution(breakdown, amount)
+
+	return solution, nil
+}
+
+// noSolutionError explains why SolveWithOptions found no sum in [amount, maxSum]
+func (s *DPSolver) noSolutionError(sizes []int, amount int, opts SolveOptions, capped, truncated bool) error {
+	if capped {
+		return domain.NewSolverError(sizes, amount,
+			fmt.Sprintf("no solution within max overage %d", *opts.MaxOverage), domain.ErrNoSolution)
+	}
+	// A clipped table can miss every sum >= amount; the optimum itself is
+	// never affected, since the first reachable sum is still the least overage
+	if truncated {
+		return domain.NewSolverError(sizes, amount,
+			fmt.Sprintf("DP table limited to %d sums", s.maxTableSize),
+			fmt.Errorf("%w: %w", domain.ErrNoSolution, domain.ErrSearchTruncated))
+	}
+	return domain.NewSolverError(sizes, amount, "no solution found", domain.ErrNoSolution)
+}
+
+// findBestSum picks the best reachable sum in [amount, maxSum], or -1 if none
+// PriorityOveragePacks: the first reachable sum (less overage); dp already
+// holds the fewest packs for it
+// PriorityPacksOverage: the sum with the fewest packs, ties going to the
+// smaller sum (less overage)
+func findBestSum(dp []dpState, amount, maxSum int, priority domain.Priority) int {
+	bestSum := -1
+	for sum := amount; sum <= maxSum; sum++ {
+		if dp[sum].packs == -1 {
+			continue
+		}
+		if priority == domain.PriorityOveragePacks {
+			return sum
+		}
+		if bestSum == -1 || dp[sum].packs < dp[bestSum].packs {
+			bestSum = sum
+		}
+	}
+	return bestSum
+}
+
+// SolveStrict finds the packing that hits amount exactly with the fewest packs
+// Returns ErrNoSolutionStrict instead of falling back to a solution with overage
+func (s *DPSolver) SolveStrict(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	// Validate input data
+	if err := domain.ValidateSolverInput(sizes, amount); err != nil {
+		return nil, err
+	}
+
+	normalizedSizes, err := solverSizes(sizes, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Early exit: amount equals one of the sizes
+	if solution := singlePackSolution(normalizedSizes, amount); solution != nil {
+		return solution, nil
+	}
+
+	// Sums above amount are never accepted, so the table stops at amount
+	if amount > s.maxTableSize {
+		return nil, fmt.Errorf("%w: amount must not exceed %d for strict solving, got %d",
+			domain.ErrInvalidInput, s.maxTableSize, amount)
+	}
+	if s.memoryBudget > 0 {
+		if estimate := dpTableBytes(amount); estimate > s.memoryBudget {
+			return nil, domain.NewSolverError(normalizedSizes, amount,
+				fmt.Sprintf("DP table needs %d bytes, budget is %d", estimate, s.memoryBudget),
+				domain.ErrMemoryBudgetExceeded)
+		}
+	}
+
+	dp, err := fillDPTable(ctx, normalizedSizes, amount)
+	if err != nil {
+		return nil, err
+	}
 
-	// Initialize DP table
-	// dp[i] = state for sum i
-	dp := make([]dpState, maxSum+1)
+	if dp[amount].packs == -1 {
+		return nil, domain.NewSolverError(normalizedSizes, amount, "no exact combination", domain.ErrNoSolutionStrict)
+	}
+
+	breakdown := reconstructSolution(dp, normalizedSizes, amount)
+	return domain.NewSolution(breakdown, amount), nil
+}
+
+// SolveRange finds the packing whose total lies within [minAmount, maxAmount]
+// with the fewest packs; ties are broken by the smaller total
+// Overage is measured against minAmount
+// Returns ErrNoSolution if no total in the range is reachable
+func (s *DPSolver) SolveRange(ctx context.Context, sizes []int, minAmount, maxAmount int) (*domain.Solution, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	// Validate input data
+	if err := domain.ValidatePackSizes(sizes); err != nil {
+		return nil, err
+	}
+	if err := domain.ValidateAmount(minAmount); err != nil {
+		return nil, err
+	}
+	if err := domain.ValidateAmount(maxAmount); err != nil {
+		return nil, err
+	}
+	if maxAmount < minAmount {
+		return nil, fmt.Errorf("%w: amount_max must not be less than amount_min, got %d < %d",
+			domain.ErrInvalidInput, maxAmount, minAmount)
+	}
+	if maxAmount > s.maxTableSize {
+		return nil, fmt.Errorf("%w: amount_max must not exceed %d for range solving, got %d",
+			domain.ErrInvalidInput, s.maxTableSize, maxAmount)
+	}
+
+	normalizedSizes, err := solverSizes(sizes, minAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	// The DP table spans exactly 0..maxAmount
+	if s.memoryBudget > 0 {
+		if estimate := dpTableBytes(maxAmount); estimate > s.memoryBudget {
+			return nil, domain.NewSolverError(normalizedSizes, minAmount,
+				fmt.Sprintf("DP table needs %d bytes, budget is %d", estimate, s.memoryBudget),
+				domain.ErrMemoryBudgetExceeded)
+		}
+	}
+
+	dp, err := fillDPTable(ctx, normalizedSizes, maxAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Scan the DP frontier within the range for the fewest packs
+	bestSum := -1
+	for sum := minAmount; sum <= maxAmount; sum++ {
+		if dp[sum].packs == -1 {
+			continue
+		}
+		if bestSum == -1 || dp[sum].packs < dp[bestSum].packs {
+			bestSum = sum
+		}
+	}
+
+	if bestSum == -1 {
+		return nil, domain.NewSolverError(normalizedSizes, minAmount,
+			fmt.Sprintf("no total reachable within [%d, %d]", minAmount, maxAmount), domain.ErrNoSolution)
+	}
+
+	breakdown := reconstructSolution(dp, normalizedSizes, bestSum)
+	return domain.NewSolution(breakdown, minAmount), nil
+}
+
+// SolveSeries solves every amount with one DP table spanning the largest
+// amount plus the smallest size - 1 (see solveMany), so each solution equals
+// Solve's with default options (options on the context are not applied)
+func (s *DPSolver) SolveSeries(ctx context.Context, sizes []int, amounts []int) ([]*domain.Solution, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	// Validate input data
+	if err := domain.ValidatePackSizes(sizes); err != nil {
+		return nil, err
+	}
+	if len(amounts) == 0 {
+		return nil, fmt.Errorf("%w: at least one amount is required", domain.ErrInvalidInput)
+	}
+	maxAmount := 0
+	for _, amount := range amounts {
+		if err := domain.ValidateAmount(amount); err != nil {
+			return nil, err
+		}
+		maxAmount = max(maxAmount, amount)
+	}
+
+	normalizedSizes, err := solverSizes(sizes, maxAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.solveMany(ctx, normalizedSizes, amounts)
+}
+
+// solveMany fills the DP table up to max(amounts) + smallest size - 1 once and
+// reconstructs every amount's solution from it; sizes must be normalized and
+// amounts validated
+// Each solution is checked with Validate, so a reconstruction bug surfaces as
+// ErrSolverMismatch instead of a wrong packing
+func (s *DPSolver) solveMany(ctx context.Context, sizes []int, amounts []int) ([]*domain.Solution, error) {
+	maxAmount := 0
+	for _, amount := range amounts {
+		maxAmount = max(maxAmount, amount)
+	}
+
+	// A reachable sum always lies within one smallest pack of each amount
+	// Unlike Solve, the table is never clipped: a clipped shared table would
+	// silently fail the largest amounts
+	bound := maxOverageBound(sizes, domain.PriorityOveragePacks)
+	maxSum := maxAmount + bound
+	if maxSum > s.maxTableSize {
+		return nil, fmt.Errorf("%w: largest amount must not exceed %d for series solving, got %d",
+			domain.ErrInvalidInput, s.maxTableSize-bound, maxAmount)
+	}
+	if s.memoryBudget > 0 {
+		if estimate := dpTableBytes(maxSum); estimate > s.memoryBudget {
+			return nil, domain.NewSolverError(sizes, maxAmount,
+				fmt.Sprintf("DP table needs %d bytes, budget is %d", estimate, s.memoryBudget),
+				domain.ErrMemoryBudgetExceeded)
+		}
+	}
+
+	dp, err := fillDPTable(ctx, sizes, maxSum)
+	if err != nil {
+		return nil, err
+	}
+
+	solutions := make([]*domain.Solution, len(amounts))
+	for i, amount := range amounts {
+		bestSum := findBestSum(dp, amount, amount+bound, domain.PriorityOveragePacks)
+		if bestSum == -1 {
+			return nil, domain.NewSolverError(sizes, amount, "no solution found", domain.ErrNoSolution)
+		}
+
+		solution := domain.NewSolution(reconstructSolution(dp, sizes, bestSum), amount)
+		if err := solution.Validate(); err != nil {
+			return nil, domain.NewSolverError(sizes, amount, fmt.Sprintf("invalid solution: %v", err), domain.ErrSolverMismatch)
+		}
+		solutions[i] = solution
+	}
+
+	return solutions, nil
+}
+
+// UnusedSizes returns, in ascending order, the sizes that appear in no optimal
+// packing for amount; options on the context apply as in Solve
+// Every optimal packing reaches the solution's total with the solution's pack
+// count, so walking back from that total through sums one pack cheaper visits
+// exactly the sizes some optimal packing uses (ties included)
+// The DP table is filled a second time, so this is meant for debugging only
+func (s *DPSolver) UnusedSizes(ctx context.Context, sizes []int, amount int) ([]int, error) {
+	solution, err := s.Solve(ctx, sizes, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizedSizes, err := solverSizes(sizes, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	total := solution.TotalItems()
+	dp, err := fillDPTable(ctx, normalizedSizes, total)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make([]bool, len(normalizedSizes))
+	visited := make([]bool, total+1)
+	visited[total] = true
+	pending := []int{total}
+	for len(pending) > 0 {
+		sum := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		for idx, size := range normalizedSizes {
+			prev := sum - size
+			if prev < 0 || dp[prev].packs == -1 || dp[prev].packs != dp[sum].packs-1 {
+				continue
+			}
+			used[idx] = true
+			if !visited[prev] {
+				visited[prev] = true
+				pending = append(pending, prev)
+			}
+		}
+	}
+
+	unused := []int{}
+	for idx, size := range normalizedSizes {
+		if !used[idx] {
+			unused = append(unused, size)
+		}
+	}
+	return unused, nil
+}
+
+// fillDPTable computes the minimum number of packs for every sum 0..maxSum
+// dp[i] = state for sum i; unreachable sums have packs == -1
+// Progress (sums scanned out of maxSum+1) is reported to the context's
+// ProgressFunc, if any, at the same interval as the context check
+func fillDPTable(ctx context.Context, sizes []int, maxSum int) ([]dpState, error) {
+	onProgress := domain.ProgressFromContext(ctx)
+	total := maxSum + 1
+
+	dp := make([]dpState, total)
 	for i := range dp {
 		dp[i] = dpState{packs: -1, parent: -1}
 	}
 	dp[0] = dpState{packs: 0, parent: -1}
 
-	// Fill DP table
-	bestSum := -1
-	bestPacks := int32(-1)
-
 	for sum := 0; sum <= maxSum; sum++ {
 		// Check context periodically
 		if sum%10000 == 0 {
@@ -78,6 +522,9 @@ func (s *DPSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.
 				return nil, ctx.Err()
 			default:
 			}
+			if onProgress != nil {
+				onProgress(sum, total)
+			}
 		}
 
 		// If current sum is unreachable, skip
@@ -86,7 +533,7 @@ func (s *DPSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.
 		}
 
 		// Try adding each pack
-		for idx, size := range normalizedSizes {
+		for idx, size := range sizes {
 			newSum := sum + size
 			if newSum > maxSum {
 				continue
@@ -99,98 +546,174 @@ func (s *DPSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.
 				dp[newSum].packs = newPacks
 				dp[newSum].parent = int32(idx)
 			}
-
-			// If we reached or exceeded amount, update best solution
-			if newSum >= amount {
-				overage := newSum - amount
-
-				// Update best solution by criteria:
-				// 1. Less overage
-				// 2. With equal overage - fewer packs
-				if bestSum == -1 {
-					bestSum = newSum
-					bestPacks = newPacks
-				} else {
-					currentOverage := bestSum - amount
-					if overage < currentOverage || (overage == currentOverage && newPacks < bestPacks) {
-						bestSum = newSum
-						bestPacks = newPacks
-					}
-				}
-
-				// Early exit: if we found an exact solution with minimum pack count
-				if overage == 0 {
-					// Check if we can find better (fewer packs)
-					// But for that we need to continue the search
-					// However, if this is the first exact match, remember it
-				}
-			}
 		}
 	}
 
-	// If no solution was found
-	if bestSum == -1 {
-		return nil, domain.NewSolverError(normalizedSizes, amount, "no solution found", domain.ErrNoSolution)
+	if onProgress != nil {
+		onProgress(total, total)
 	}
 
-	// Reconstruct solution
-	breakdown := reconstructSolution(dp, normalizedSizes, bestSum)
-	solution := domain.NewSolution(breakdown, amount)
-
-	return solution, nil
+	return dp, nil
 }
 
 // normalizeSizes removes duplicates and sorts sizes in ascending order
-func normalizeSizes(sizes []int) []int {
+// Sizes outside 1..domain.MaxPackSize - exactly those ValidatePackSizes rejects
+// by value - are never used; they are returned in dropped (in input order) so
+// callers can reject or report them instead of silently losing them
+func normalizeSizes(sizes []int) (normalized, dropped []int) {
 	if len(sizes) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Use map to remove duplicates
 	uniqueSizes := make(map[int]bool)
 	for _, size := range sizes {
-		if size > 0 {
-			uniqueSizes[size] = true
+		if size <= 0 || size > domain.MaxPackSize {
+			dropped = append(dropped, size)
+			continue
 		}
+		uniqueSizes[size] = true
 	}
 
 	// Convert to slice
-	result := make([]int, 0, len(uniqueSizes))
+	normalized = make([]int, 0, len(uniqueSizes))
 	for size := range uniqueSizes {
-		result = append(result, size)
+		normalized = append(normalized, size)
 	}
 
 	// Sort in ascending order
-	sort.Ints(result)
+	sort.Ints(normalized)
 
-	return result
+	return normalized, dropped
 }
 
-// calculateMaxSum calculates the maximum sum for the DP table
-// Limit the search to a reasonable bound to avoid excessive memory usage
-func calculateMaxSum(amount int, sizes []int) int {
+// sortSizes returns a copy of sizes in ascending order
+// Unlike normalizeSizes it keeps every entry, for input already validated
+func sortSizes(sizes []int) []int {
+	return slices.Sorted(slices.Values(sizes))
+}
+
+// solverSizes validates and sorts sizes for solving
+// Sizes normalizeSizes would drop and duplicates it would merge are input
+// errors, so unvalidated input fails loudly as it does at the HTTP edge
+// instead of being solved with a canonicalized copy
+func solverSizes(sizes []int, amount int) ([]int, error) {
 	if len(sizes) == 0 {
-		return amount
+		return nil, domain.NewSolverError(sizes, amount, "no sizes", domain.ErrInvalidInput)
+	}
+	normalized, dropped := normalizeSizes(sizes)
+	if len(dropped) > 0 {
+		return nil, domain.NewSolverError(sizes, amount,
+			fmt.Sprintf("sizes outside 1..%d: %v", domain.MaxPackSize, dropped), domain.ErrInvalidInput)
+	}
+	if len(normalized) < len(sizes) {
+		return nil, domain.NewSolverError(sizes, amount,
+			fmt.Sprintf("duplicate sizes: %v", duplicateSizes(sizes)), domain.ErrInvalidInput)
+	}
+	return sortSizes(sizes), nil
+}
+
+// duplicateSizes returns each size occurring more than once, in order of its
+// second occurrence
+func duplicateSizes(sizes []int) []int {
+	var duplicates []int
+	seen := make(map[int]int, len(sizes))
+	for _, size := range sizes {
+		seen[size]++
+		if seen[size] == 2 {
+			duplicates = append(duplicates, size)
+		}
 	}
+	return duplicates
+}
+
+// singlePackSolution is the single decision point for "amount equals a size"
+// It returns a one-pack solution when amount matches one of the sizes, nil otherwise
+// Such a solution is optimal under any priority: overage is zero (the minimum possible)
+// and one pack is the fewest possible for a positive amount, so it is also a valid
+// exact result for any mode that forbids overage
+func singlePackSolution(sizes []int, amount int) *domain.Solution {
+	for _, size := range sizes {
+		if size == amount {
+			return domain.NewSolution(map[int]int{size: 1}, amount)
+		}
+	}
+	return nil
+}
+
+// belowSmallestSolution returns one smallest pack when amount is below the
+// smallest size and its overage fits maxOverage (nil = no cap), nil otherwise
+// No other sum in [amount, smallest size] is reachable, and no reachable sum
+// has less overage or fewer packs, so the DP would return the same solution
+// sizes must be sorted and non-empty
+func belowSmallestSolution(sizes []int, amount int, maxOverage *int) *domain.Solution {
+	smallest := sizes[0]
+	if amount >= smallest || (maxOverage != nil && smallest-amount > *maxOverage) {
+		return nil
+	}
+	return domain.NewSolution(map[int]int{smallest: 1}, amount)
+}
+
+// firstCandidateSum returns the smallest sum >= amount that could be
+// reachable: every reachable sum is a multiple of the gcd of sizes
+func firstCandidateSum(sizes []int, amount int) int {
+	g := domain.SizesGCD(sizes)
+	return (amount + g - 1) / g * g
+}
+
+// EstimateMemory returns the number of bytes the DP table would allocate
+// for the given input with the default table limit; the early exit for
+// "amount equals a size" is not considered
+func EstimateMemory(sizes []int, amount int) int {
+	normalized, _ := normalizeSizes(sizes)
+	return dpTableBytes(NewDPSolver().calculateMaxSum(amount, normalized, domain.PriorityOveragePacks))
+}
 
-	minSize := sizes[0] // sizes are sorted
+// dpTableBytes returns the size in bytes of a DP table covering sums 0..maxSum
+func dpTableBytes(maxSum int) int {
+	return (maxSum + 1) * int(unsafe.Sizeof(dpState{}))
+}
 
-	// Maximum overage = minimum size - 1
-	// This guarantees we will find the optimal solution
-	maxOverage := minSize - 1
+// DefaultMaxTableSize - default maximum number of DP table elements (10M)
+const DefaultMaxTableSize = 10_000_000
+
+// calculateMaxSum calculates the maximum sum for the DP table
+// Limit the search to the solver's table limit to avoid excessive memory usage
+func (s *DPSolver) calculateMaxSum(amount int, sizes []int, priority domain.Priority) int {
+	if len(sizes) == 0 {
+		return amount
+	}
 
 	// Limit the maximum sum
-	maxSum := amount + maxOverage
+	maxSum := amount + maxOverageBound(sizes, priority)
 
 	// Additional check for reasonable memory limit
-	const maxDPSize = 10_000_000 // 10M elements
-	if maxSum > maxDPSize {
-		maxSum = maxDPSize
+	if maxSum > s.maxTableSize {
+		maxSum = s.maxTableSize
 	}
 
 	return maxSum
 }
 
+// maxOverageBound returns the largest overage the optimum can have
+// PriorityOveragePacks: minimum size - 1, since a reachable sum always lies
+// within one smallest pack of amount
+// PriorityPacksOverage: maximum size - 1, since dropping any pack from a
+// packing with more overage still covers amount with fewer packs
+// sizes must be sorted and non-empty
+func maxOverageBound(sizes []int, priority domain.Priority) int {
+	if priority == domain.PriorityPacksOverage {
+		return sizes[len(sizes)-1] - 1
+	}
+	return sizes[0] - 1
+}
+
+// isTruncated reports whether calculateMaxSum clipped the natural bound
+// (amount + maxOverageBound) to the solver's table limit
+func (s *DPSolver) isTruncated(amount int, sizes []int, priority domain.Priority) bool {
+	return len(sizes) > 0 && amount+maxOverageBound(sizes, priority) > s.maxTableSize
+}
+
 // reconstructSolution reconstructs the solution from the DP table
 func reconstructSolution(dp []dpState, sizes []int, targetSum int) map[int]int {
 	breakdown := make(map[int]int)
@@ -211,5 +734,10 @@ func reconstructSolution(dp []dpState, sizes []int, targetSum int) map[int]int {
 	return breakdown
 }
 
-// Ensure DPSolver implements domain.Solver interface
-var _ domain.Solver = (*DPSolver)(nil)
+// Ensure DPSolver implements the solver interfaces
+var (
+	_ domain.Solver       = (*DPSolver)(nil)
+	_ domain.StrictSolver = (*DPSolver)(nil)
+	_ domain.RangeSolver  = (*DPSolver)(nil)
+	_ domain.SeriesSolver = (*DPSolver)(nil)
+)
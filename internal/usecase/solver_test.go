@@ -2,6 +2,12 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -235,6 +241,49 @@ func BenchmarkDPSolver_LargeAmount(b *testing.B) {
 	}
 }
 
+// batchBenchAmounts are the demanded amounts of the shared-table benchmarks
+var batchBenchAmounts = []int{12_001, 25_000, 50_250, 75_999, 100_000, 99_999, 60_001, 31_337}
+
+// BenchmarkDPSolver_SeparateSolves solves batchBenchAmounts one Solve at a time
+func BenchmarkDPSolver_SeparateSolves(b *testing.B) {
+	solver := NewDPSolver()
+	ctx := context.Background()
+	sizes := []int{250, 500, 1000, 2000, 5000}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, amount := range batchBenchAmounts {
+			_, _ = solver.Solve(ctx, sizes, amount)
+		}
+	}
+}
+
+// BenchmarkDPSolver_SolveMany solves batchBenchAmounts from one shared table
+func BenchmarkDPSolver_SolveMany(b *testing.B) {
+	solver := NewDPSolver()
+	ctx := context.Background()
+	sizes := []int{250, 500, 1000, 2000, 5000}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = solver.solveMany(ctx, sizes, batchBenchAmounts)
+	}
+}
+
+// BenchmarkSolveLarge_EdgeCaseWithProgress measures the progress hook overhead
+func BenchmarkSolveLarge_EdgeCaseWithProgress(b *testing.B) {
+	solver := NewDPSolver()
+	calls := 0
+	ctx := domain.WithProgress(context.Background(), func(done, total int) { calls++ })
+	sizes := []int{23, 31, 53}
+	amount := 500_000
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = solver.Solve(ctx, sizes, amount)
+	}
+}
+
 // BenchmarkSolveLarge tests performance for large W≈500k
 func BenchmarkSolveLarge_EdgeCase(b *testing.B) {
 	solver := NewDPSolver()
@@ -264,3 +313,824 @@ func BenchmarkSolveLarge_EdgeCase(b *testing.B) {
 		b.Logf("WARNING: Single operation took %v, expected ≤500ms", avgTime)
 	}
 }
+
+func TestSinglePackSolution(t *testing.T) {
+	tests := []struct {
+		name   string
+		sizes  []int
+		amount int
+		want   map[int]int // nil means no single-pack solution
+	}{
+		{name: "equals smallest size", sizes: []int{250, 500, 1000}, amount: 250, want: map[int]int{250: 1}},
+		{name: "equals middle size", sizes: []int{250, 500, 1000}, amount: 500, want: map[int]int{500: 1}},
+		{name: "equals largest size", sizes: []int{250, 500, 1000}, amount: 1000, want: map[int]int{1000: 1}},
+		{name: "between sizes", sizes: []int{250, 500, 1000}, amount: 750, want: nil},
+		{name: "above largest size", sizes: []int{250, 500, 1000}, amount: 2000, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := singlePackSolution(tt.sizes, tt.amount)
+
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("expected nil, got %v", got.Breakdown)
+				}
+				return
+			}
+
+			if got == nil {
+				t.Fatal("expected solution, got nil")
+			}
+			if !equalBreakdown(got.Breakdown, tt.want) || got.Packs != 1 || got.Overage != 0 {
+				t.Errorf("got %+v, want breakdown %v with 1 pack and 0 overage", got, tt.want)
+			}
+
+			// The solver must return the same result through the full path
+			solution, err := NewDPSolver().Solve(context.Background(), tt.sizes, tt.amount)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalBreakdown(solution.Breakdown, tt.want) {
+				t.Errorf("Solve() breakdown = %v, want %v", solution.Breakdown, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateMemory(t *testing.T) {
+	tests := []struct {
+		name   string
+		sizes  []int
+		amount int
+		want   int
+	}{
+		// maxSum = 12001 + (250 - 1) = 12250; 12251 states × 8 bytes
+		{name: "known amount", sizes: []int{250, 500, 1000}, amount: 12001, want: 12251 * 8},
+		{name: "unsorted sizes with duplicates", sizes: []int{1000, 250, 250}, amount: 12001, want: 12251 * 8},
+		// maxSum is capped at 10M elements
+		{name: "capped table", sizes: []int{1}, amount: 1_000_000_000, want: 10_000_001 * 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateMemory(tt.sizes, tt.amount); got != tt.want {
+				t.Errorf("EstimateMemory() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDPSolver_MemoryBudget(t *testing.T) {
+	sizes := []int{250, 500, 1000}
+	amount := 12001
+	estimate := EstimateMemory(sizes, amount)
+
+	t.Run("over budget is rejected", func(t *testing.T) {
+		_, err := NewDPSolver().WithMemoryBudget(estimate-1).Solve(context.Background(), sizes, amount)
+		if !errors.Is(err, domain.ErrMemoryBudgetExceeded) {
+			t.Errorf("expected ErrMemoryBudgetExceeded, got %v", err)
+		}
+	})
+
+	t.Run("within budget is solved", func(t *testing.T) {
+		solution, err := NewDPSolver().WithMemoryBudget(estimate).Solve(context.Background(), sizes, amount)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if solution.Overage != 249 {
+			t.Errorf("expected overage 249, got %d", solution.Overage)
+		}
+	})
+
+	t.Run("single pack needs no table", func(t *testing.T) {
+		if _, err := NewDPSolver().WithMemoryBudget(1).Solve(context.Background(), sizes, 500); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestDPSolver_TruncatedSearch(t *testing.T) {
+	solver := NewDPSolver()
+
+	t.Run("no solution in clipped table is flagged", func(t *testing.T) {
+		_, err := solver.Solve(context.Background(), []int{7}, 20_000_000)
+		if !errors.Is(err, domain.ErrSearchTruncated) {
+			t.Errorf("expected ErrSearchTruncated, got %v", err)
+		}
+		if !errors.Is(err, domain.ErrNoSolution) {
+			t.Errorf("expected ErrNoSolution, got %v", err)
+		}
+	})
+
+	t.Run("solution in clipped table is still optimal", func(t *testing.T) {
+		// Natural bound 10_000_001 is clipped, but 7 * 1_428_571 fits
+		solution, err := solver.Solve(context.Background(), []int{7}, 9_999_995)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if solution.Overage != 2 {
+			t.Errorf("expected overage 2, got %d", solution.Overage)
+		}
+	})
+}
+
+func TestDPSolver_TableLimit(t *testing.T) {
+	ctx := context.Background()
+
+	// Natural bound is 1_000_000 + 7 - 1, which a 1M table clips
+	_, err := NewDPSolverWithLimit(1_000_000).Solve(ctx, []int{7}, 1_000_000)
+	if !errors.Is(err, domain.ErrSearchTruncated) || !errors.Is(err, domain.ErrNoSolution) {
+		t.Errorf("low limit: expected ErrSearchTruncated and ErrNoSolution, got %v", err)
+	}
+
+	solution, err := NewDPSolverWithLimit(2_000_000).Solve(ctx, []int{7}, 1_000_000)
+	if err != nil {
+		t.Fatalf("high limit: unexpected error: %v", err)
+	}
+	// 7 * 142_858 = 1_000_006 lies beyond the 1M table
+	if solution.Overage != 6 {
+		t.Errorf("high limit: expected overage 6, got %d", solution.Overage)
+	}
+
+	// Non-positive limits fall back to the default
+	if s := NewDPSolverWithLimit(0); s.maxTableSize != DefaultMaxTableSize {
+		t.Errorf("maxTableSize = %d, want %d", s.maxTableSize, DefaultMaxTableSize)
+	}
+
+	if _, err := NewDPSolverWithLimit(1000).SolveStrict(ctx, []int{7}, 1001); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("strict beyond the limit: expected ErrInvalidInput, got %v", err)
+	}
+}
+
+// fullDPSolve solves with the DP table alone, bypassing every early exit
+func fullDPSolve(t *testing.T, sizes []int, amount int, opts SolveOptions) *domain.Solution {
+	t.Helper()
+	normalized, _ := normalizeSizes(sizes)
+	maxSum := NewDPSolver().calculateMaxSum(amount, normalized, opts.Priority)
+	if opts.MaxOverage != nil {
+		maxSum = min(maxSum, amount+*opts.MaxOverage)
+	}
+	dp, err := fillDPTable(context.Background(), normalized, maxSum)
+	if err != nil {
+		t.Fatalf("fillDPTable() error = %v", err)
+	}
+	bestSum := findBestSum(dp, amount, maxSum, opts.Priority)
+	if bestSum == -1 {
+		return nil
+	}
+	return domain.NewSolution(reconstructSolution(dp, normalized, bestSum), amount)
+}
+
+func TestDPSolver_FastPaths(t *testing.T) {
+	solver := NewDPSolver()
+	overage := func(n int) *int { return &n }
+
+	t.Run("amounts below the smallest size match the full DP", func(t *testing.T) {
+		cases := []struct {
+			sizes  []int
+			amount int
+			opts   SolveOptions
+		}{
+			{sizes: []int{4, 6}, amount: 1},
+			{sizes: []int{4, 6}, amount: 3},
+			{sizes: []int{250, 500, 1000}, amount: 1},
+			{sizes: []int{250, 500, 1000}, amount: 249},
+			{sizes: []int{23, 31, 53}, amount: 22, opts: SolveOptions{Priority: domain.PriorityPacksOverage}},
+			{sizes: []int{250, 500}, amount: 200, opts: SolveOptions{MaxOverage: overage(50)}},
+		}
+
+		for _, c := range cases {
+			// A table-free path must not need a table's memory
+			got, err := solver.SolveWithOptions(domain.WithProgress(context.Background(), func(done, total int) {
+				t.Errorf("sizes %v, amount %d: DP table built", c.sizes, c.amount)
+			}), c.sizes, c.amount, c.opts)
+			if err != nil {
+				t.Fatalf("sizes %v, amount %d: unexpected error: %v", c.sizes, c.amount, err)
+			}
+			want := fullDPSolve(t, c.sizes, c.amount, c.opts)
+			if !reflect.DeepEqual(got.Breakdown, want.Breakdown) || got.Overage != want.Overage || got.Packs != want.Packs {
+				t.Errorf("sizes %v, amount %d: got %+v, want %+v", c.sizes, c.amount, got, want)
+			}
+		}
+	})
+
+	t.Run("overage cap below the smallest size still fails", func(t *testing.T) {
+		_, err := solver.SolveWithOptions(context.Background(), []int{250, 500}, 200, SolveOptions{MaxOverage: overage(49)})
+		if !errors.Is(err, domain.ErrNoSolution) {
+			t.Errorf("expected ErrNoSolution, got %v", err)
+		}
+	})
+
+	t.Run("no multiple of the gcd in range fails without a table", func(t *testing.T) {
+		cases := []struct {
+			name   string
+			sizes  []int
+			amount int
+			opts   SolveOptions
+			want   error
+		}{
+			// 251..261 holds no multiple of 250
+			{name: "tight cap", sizes: []int{250, 500}, amount: 251, opts: SolveOptions{MaxOverage: overage(10)}, want: domain.ErrNoSolution},
+			// Beyond the table limit, as in TestDPSolver_TruncatedSearch
+			{name: "amount beyond the table", sizes: []int{7}, amount: 20_000_000, want: domain.ErrSearchTruncated},
+		}
+
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				ctx := domain.WithProgress(context.Background(), func(done, total int) {
+					t.Error("DP table built")
+				})
+				if _, err := solver.SolveWithOptions(ctx, c.sizes, c.amount, c.opts); !errors.Is(err, c.want) {
+					t.Errorf("expected %v, got %v", c.want, err)
+				}
+				if fullDPSolve(t, c.sizes, c.amount, c.opts) != nil {
+					t.Error("full DP found a solution")
+				}
+			})
+		}
+	})
+}
+
+func TestDPSolver_SolveRange(t *testing.T) {
+	solver := NewDPSolver()
+
+	tests := []struct {
+		name        string
+		sizes       []int
+		min, max    int
+		want        map[int]int
+		wantOverage int
+		wantErr     error
+	}{
+		{
+			// 106 = 2×53 is the only 2-pack total in [100, 110]
+			name:  "exact mid-range sum",
+			sizes: []int{23, 31, 53}, min: 100, max: 110,
+			want: map[int]int{53: 2}, wantOverage: 6,
+		},
+		{
+			name:  "single pack at range start",
+			sizes: []int{250, 500, 1000}, min: 1000, max: 1100,
+			want: map[int]int{1000: 1}, wantOverage: 0,
+		},
+		{
+			// 10, 12 and 14 all take 2 packs; the smallest total wins
+			name:  "ties broken by smaller total",
+			sizes: []int{5, 7}, min: 10, max: 14,
+			want: map[int]int{5: 2}, wantOverage: 0,
+		},
+		{
+			name:  "degenerate range",
+			sizes: []int{250, 500}, min: 750, max: 750,
+			want: map[int]int{250: 1, 500: 1}, wantOverage: 0,
+		},
+		{
+			name:  "nothing fits",
+			sizes: []int{500}, min: 100, max: 400,
+			wantErr: domain.ErrNoSolution,
+		},
+		{
+			name:  "inverted range",
+			sizes: []int{250}, min: 500, max: 400,
+			wantErr: domain.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solution, err := solver.SolveRange(context.Background(), tt.sizes, tt.min, tt.max)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !equalBreakdown(solution.Breakdown, tt.want) {
+				t.Errorf("breakdown = %v, want %v", solution.Breakdown, tt.want)
+			}
+			if solution.Overage != tt.wantOverage {
+				t.Errorf("overage = %d, want %d", solution.Overage, tt.wantOverage)
+			}
+			if solution.Amount != tt.min {
+				t.Errorf("amount = %d, want %d", solution.Amount, tt.min)
+			}
+		})
+	}
+}
+
+func TestDPSolver_SolveSeries(t *testing.T) {
+	solver := NewDPSolver()
+	ctx := context.Background()
+
+	t.Run("matches Solve for every amount", func(t *testing.T) {
+		// Includes amounts equal to a size, below the smallest size and
+		// unordered, so the shared table is not just read at its end
+		cases := []struct {
+			sizes   []int
+			amounts []int
+		}{
+			{sizes: []int{250, 500, 1000, 2000, 5000}, amounts: []int{1000, 2000, 3000, 4000, 5000, 6000, 7000, 8000, 9000, 10000}},
+			{sizes: []int{23, 31, 53}, amounts: []int{500_000, 1, 263, 53, 1000}},
+			{sizes: []int{250, 500}, amounts: []int{251, 251, 750}},
+		}
+
+		for _, c := range cases {
+			solutions, err := solver.SolveSeries(ctx, c.sizes, c.amounts)
+			if err != nil {
+				t.Fatalf("SolveSeries(%v) error = %v", c.sizes, err)
+			}
+			if len(solutions) != len(c.amounts) {
+				t.Fatalf("got %d solutions for %d amounts", len(solutions), len(c.amounts))
+			}
+
+			for i, amount := range c.amounts {
+				want, err := solver.Solve(ctx, c.sizes, amount)
+				if err != nil {
+					t.Fatalf("Solve(%v, %d) error = %v", c.sizes, amount, err)
+				}
+				got := solutions[i]
+				if got.Amount != amount || got.Overage != want.Overage || got.Packs != want.Packs {
+					t.Errorf("amount %d: got overage %d, packs %d (amount %d), want overage %d, packs %d",
+						amount, got.Overage, got.Packs, got.Amount, want.Overage, want.Packs)
+				}
+				if err := got.Validate(); err != nil {
+					t.Errorf("amount %d: invalid solution: %v", amount, err)
+				}
+			}
+		}
+	})
+
+	t.Run("fills one table up to the largest amount", func(t *testing.T) {
+		var fills, lastTotal int
+		progressCtx := domain.WithProgress(ctx, func(done, total int) {
+			if done == total {
+				fills++
+			}
+			lastTotal = total
+		})
+
+		if _, err := solver.SolveSeries(progressCtx, []int{250, 500, 1000}, []int{1000, 3000, 2000}); err != nil {
+			t.Fatalf("SolveSeries() error = %v", err)
+		}
+		if fills != 1 {
+			t.Errorf("table filled %d times, want 1", fills)
+		}
+		// Sums 0..3000 + 249
+		if lastTotal != 3250 {
+			t.Errorf("table size = %d, want 3250", lastTotal)
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		cases := []struct {
+			name    string
+			sizes   []int
+			amounts []int
+		}{
+			{name: "no amounts", sizes: []int{250}, amounts: nil},
+			{name: "non-positive amount", sizes: []int{250}, amounts: []int{500, 0}},
+			{name: "invalid sizes", sizes: []int{0}, amounts: []int{500}},
+			// 10M + 249 exceeds the table limit, which Solve would clip instead
+			{name: "table too large", sizes: []int{250}, amounts: []int{1000, DefaultMaxTableSize}},
+		}
+
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				if _, err := solver.SolveSeries(ctx, c.sizes, c.amounts); !errors.Is(err, domain.ErrInvalidInput) {
+					t.Errorf("expected ErrInvalidInput, got %v", err)
+				}
+			})
+		}
+	})
+}
+
+func TestDPSolver_SolveMany(t *testing.T) {
+	solver := NewDPSolver()
+	ctx := context.Background()
+	sizes := []int{23, 31, 53}
+
+	amounts := make([]int, 300)
+	for i := range amounts {
+		amounts[i] = 1 + i*97
+	}
+
+	solutions, err := solver.solveMany(ctx, sizes, amounts)
+	if err != nil {
+		t.Fatalf("solveMany() error = %v", err)
+	}
+
+	for i, amount := range amounts {
+		if err := solutions[i].Validate(); err != nil {
+			t.Errorf("amount %d: invalid solution: %v", amount, err)
+		}
+		want, err := solver.Solve(ctx, sizes, amount)
+		if err != nil {
+			t.Fatalf("Solve(%d) error = %v", amount, err)
+		}
+		if solutions[i].Overage != want.Overage || solutions[i].Packs != want.Packs {
+			t.Errorf("amount %d: got overage %d, packs %d, want overage %d, packs %d",
+				amount, solutions[i].Overage, solutions[i].Packs, want.Overage, want.Packs)
+		}
+	}
+}
+
+func TestDPSolver_ProgressCallback(t *testing.T) {
+	solver := NewDPSolver()
+	sizes := []int{23, 31, 53}
+	amount := 500_000
+
+	t.Run("invoked and reaches 100%", func(t *testing.T) {
+		var calls, lastDone, lastTotal int
+		monotonic := true
+		ctx := domain.WithProgress(context.Background(), func(done, total int) {
+			if done < lastDone {
+				monotonic = false
+			}
+			calls++
+			lastDone, lastTotal = done, total
+		})
+
+		if _, err := solver.Solve(ctx, sizes, amount); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// maxSum = 500000 + 22; one call per 10000 sums plus the final one
+		wantTotal := amount + 23
+		if lastTotal != wantTotal || lastDone != lastTotal {
+			t.Errorf("last progress = %d/%d, want %d/%d", lastDone, lastTotal, wantTotal, wantTotal)
+		}
+		if wantCalls := wantTotal/10000 + 2; calls != wantCalls {
+			t.Errorf("callback invoked %d times, want %d", calls, wantCalls)
+		}
+		if !monotonic {
+			t.Error("progress must not go backwards")
+		}
+	})
+
+	t.Run("nil callback", func(t *testing.T) {
+		ctx := domain.WithProgress(context.Background(), nil)
+		if _, err := solver.Solve(ctx, sizes, 1000); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("single pack reports nothing", func(t *testing.T) {
+		calls := 0
+		ctx := domain.WithProgress(context.Background(), func(done, total int) { calls++ })
+		if _, err := solver.Solve(ctx, sizes, 53); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if calls != 0 {
+			t.Errorf("expected no progress for the single-pack early exit, got %d calls", calls)
+		}
+	})
+}
+
+func TestDPSolver_SolveStrict(t *testing.T) {
+	solver := NewDPSolver()
+
+	tests := []struct {
+		name    string
+		sizes   []int
+		amount  int
+		want    map[int]int
+		wantErr error
+	}{
+		{name: "no exact combination", sizes: []int{3, 5}, amount: 7, wantErr: domain.ErrNoSolutionStrict},
+		{name: "exact combination", sizes: []int{3, 5}, amount: 8, want: map[int]int{5: 1, 3: 1}},
+		{name: "single pack", sizes: []int{3, 5}, amount: 5, want: map[int]int{5: 1}},
+		{name: "fewest packs among exact", sizes: []int{3, 5}, amount: 15, want: map[int]int{5: 3}},
+		{name: "below smallest size", sizes: []int{250, 500}, amount: 1, wantErr: domain.ErrNoSolutionStrict},
+		{name: "invalid input", sizes: []int{3, 5}, amount: 0, wantErr: domain.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solution, err := solver.SolveStrict(context.Background(), tt.sizes, tt.amount)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalBreakdown(solution.Breakdown, tt.want) || solution.Overage != 0 {
+				t.Errorf("got %v (overage %d), want %v (overage 0)", solution.Breakdown, solution.Overage, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeSizes_ConsistentWithValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		sizes       []int
+		wantSizes   []int
+		wantDropped []int
+	}{
+		{name: "zero and negative", sizes: []int{0, -5, 250}, wantSizes: []int{250}, wantDropped: []int{0, -5}},
+		{name: "above maximum", sizes: []int{250, domain.MaxPackSize + 1}, wantSizes: []int{250}, wantDropped: []int{domain.MaxPackSize + 1}},
+		{name: "duplicates are not dropped entries", sizes: []int{500, 250, 500}, wantSizes: []int{250, 500}},
+		{name: "maximum is kept", sizes: []int{domain.MaxPackSize}, wantSizes: []int{domain.MaxPackSize}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSizes, gotDropped := normalizeSizes(tt.sizes)
+			if !reflect.DeepEqual(gotSizes, tt.wantSizes) || !reflect.DeepEqual(gotDropped, tt.wantDropped) {
+				t.Errorf("normalizeSizes() = %v, %v, want %v, %v", gotSizes, gotDropped, tt.wantSizes, tt.wantDropped)
+			}
+
+			// Each size is dropped exactly when validation rejects it on its own
+			for _, size := range tt.sizes {
+				rejected := domain.ValidatePackSizes([]int{size}) != nil
+				if dropped := slices.Contains(gotDropped, size); dropped != rejected {
+					t.Errorf("size %d: dropped = %v, validation rejects = %v", size, dropped, rejected)
+				}
+			}
+		})
+	}
+
+	// Both paths report {0, -5, 250} as invalid input instead of solving with {250}
+	t.Run("solver and prepare reject the same input", func(t *testing.T) {
+		sizes := []int{0, -5, 250}
+
+		if err := domain.ValidatePackSizes(sizes); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("ValidatePackSizes() = %v, want ErrInvalidInput", err)
+		}
+		if _, err := solverSizes(sizes, 500); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("solverSizes() = %v, want ErrInvalidInput", err)
+		}
+
+		prepared, err := PrepareInput(sizes, 500)
+		if err != nil {
+			t.Fatalf("PrepareInput() error = %v", err)
+		}
+		if !reflect.DeepEqual(prepared.Sizes, []int{250}) {
+			t.Errorf("PrepareInput() sizes = %v, want [250]", prepared.Sizes)
+		}
+		if len(prepared.Warnings) == 0 || prepared.Warnings[0].Code != WarningInvalidSizesDropped {
+			t.Errorf("PrepareInput() warnings = %v, want %s first", prepared.Warnings, WarningInvalidSizesDropped)
+		}
+	})
+}
+
+func TestDPSolver_RejectsDuplicateSizes(t *testing.T) {
+	solver := NewDPSolver()
+	ctx := context.Background()
+	sizes := []int{500, 250, 500}
+
+	calls := map[string]func() error{
+		"Solve": func() error {
+			_, err := solver.Solve(ctx, sizes, 750)
+			return err
+		},
+		"SolveStrict": func() error {
+			_, err := solver.SolveStrict(ctx, sizes, 750)
+			return err
+		},
+		"SolveWithOptions": func() error {
+			_, err := solver.SolveWithOptions(ctx, sizes, 750, SolveOptions{Priority: domain.PriorityPacksOverage})
+			return err
+		},
+		"SolveRange": func() error {
+			_, err := solver.SolveRange(ctx, sizes, 700, 800)
+			return err
+		},
+		"SolveSeries": func() error {
+			_, err := solver.SolveSeries(ctx, sizes, []int{250, 750})
+			return err
+		},
+		// The early exit for an amount equal to a size must not skip the check
+		"Solve exact size": func() error {
+			_, err := solver.Solve(ctx, sizes, 500)
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			if err := call(); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+
+	// solverSizes rejects on its own instead of merging the duplicates, so an
+	// entry point without up-front validation cannot solve a canonicalized copy
+	if _, err := solverSizes(sizes, 750); !errors.Is(err, domain.ErrInvalidInput) || !strings.Contains(err.Error(), "duplicate sizes: [500]") {
+		t.Errorf("solverSizes() = %v, want ErrInvalidInput naming 500", err)
+	}
+
+	// Unsorted but distinct sizes are still accepted
+	solution, err := solver.Solve(ctx, []int{500, 250}, 750)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !equalBreakdown(solution.Breakdown, map[int]int{500: 1, 250: 1}) {
+		t.Errorf("breakdown = %v, want map[250:1 500:1]", solution.Breakdown)
+	}
+}
+
+func TestDPSolver_SolveWithOptions_MaxOverage(t *testing.T) {
+	solver := NewDPSolver()
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name       string
+		sizes      []int
+		amount     int
+		maxOverage *int
+		want       map[int]int
+		wantErr    error
+	}{
+		{
+			// Natural optimum is 500 (overage 249)
+			name:  "cap excludes natural optimum",
+			sizes: []int{250, 500, 1000}, amount: 251, maxOverage: intPtr(100),
+			wantErr: domain.ErrNoSolution,
+		},
+		{
+			name:  "cap at natural optimum",
+			sizes: []int{250, 500, 1000}, amount: 251, maxOverage: intPtr(249),
+			want: map[int]int{500: 1},
+		},
+		{
+			name:  "cap 0 behaves like strict mode without exact combination",
+			sizes: []int{3, 5}, amount: 7, maxOverage: intPtr(0),
+			wantErr: domain.ErrNoSolution,
+		},
+		{
+			name:  "cap 0 behaves like strict mode with exact combination",
+			sizes: []int{3, 5}, amount: 8, maxOverage: intPtr(0),
+			want: map[int]int{5: 1, 3: 1},
+		},
+		{
+			name:  "no cap",
+			sizes: []int{3, 5}, amount: 7,
+			want: map[int]int{5: 1, 3: 1},
+		},
+		{
+			name:  "negative cap",
+			sizes: []int{3, 5}, amount: 7, maxOverage: intPtr(-1),
+			wantErr: domain.ErrInvalidInput,
+		},
+		{
+			// amount + cap would wrap to a negative table bound
+			name:  "cap near int max",
+			sizes: []int{250, 500, 1000}, amount: 251, maxOverage: intPtr(math.MaxInt),
+			want: map[int]int{500: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solution, err := solver.SolveWithOptions(context.Background(), tt.sizes, tt.amount, SolveOptions{MaxOverage: tt.maxOverage})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				var solverErr *domain.SolverError
+				if tt.maxOverage != nil && errors.As(err, &solverErr) && !strings.Contains(solverErr.Message, strconv.Itoa(*tt.maxOverage)) {
+					t.Errorf("error message %q should mention the bound %d", solverErr.Message, *tt.maxOverage)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalBreakdown(solution.Breakdown, tt.want) {
+				t.Errorf("breakdown = %v, want %v", solution.Breakdown, tt.want)
+			}
+		})
+	}
+
+	t.Run("from context", func(t *testing.T) {
+		ctx := domain.WithSolveOptions(context.Background(), domain.SolveOptions{domain.SolveOptionMaxOverage: "100"})
+		if _, err := solver.Solve(ctx, []int{250, 500, 1000}, 251); !errors.Is(err, domain.ErrNoSolution) {
+			t.Errorf("expected ErrNoSolution, got %v", err)
+		}
+
+		ctx = domain.WithSolveOptions(context.Background(), domain.SolveOptions{domain.SolveOptionMaxOverage: "abc"})
+		if _, err := solver.Solve(ctx, []int{250, 500, 1000}, 251); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for malformed option, got %v", err)
+		}
+	})
+}
+
+func TestDPSolver_SolveWithOptions_Priority(t *testing.T) {
+	solver := NewDPSolver()
+
+	tests := []struct {
+		name        string
+		amount      int
+		wantOverage map[int]int // PriorityOveragePacks
+		wantPacks   map[int]int // PriorityPacksOverage
+	}{
+		// Two packs is already the minimum, and 1250 the least overage for it
+		{name: "same result", amount: 1001, wantOverage: map[int]int{1000: 1, 250: 1}, wantPacks: map[int]int{1000: 1, 250: 1}},
+		// Overage 250 exceeds smallest size - 1, so packs-first searches further
+		{name: "different results", amount: 750, wantOverage: map[int]int{500: 1, 250: 1}, wantPacks: map[int]int{1000: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sizes := []int{250, 500, 1000}
+
+			got, err := solver.SolveWithOptions(context.Background(), sizes, tt.amount, SolveOptions{Priority: domain.PriorityOveragePacks})
+			if err != nil {
+				t.Fatalf("overage_packs: unexpected error: %v", err)
+			}
+			if !equalBreakdown(got.Breakdown, tt.wantOverage) {
+				t.Errorf("overage_packs: breakdown = %v, want %v", got.Breakdown, tt.wantOverage)
+			}
+
+			got, err = solver.SolveWithOptions(context.Background(), sizes, tt.amount, SolveOptions{Priority: domain.PriorityPacksOverage})
+			if err != nil {
+				t.Fatalf("packs_overage: unexpected error: %v", err)
+			}
+			if !equalBreakdown(got.Breakdown, tt.wantPacks) {
+				t.Errorf("packs_overage: breakdown = %v, want %v", got.Breakdown, tt.wantPacks)
+			}
+		})
+	}
+
+	t.Run("matches brute force", func(t *testing.T) {
+		sizes := []int{3, 7, 11}
+		for amount := 1; amount <= 200; amount++ {
+			got, err := solver.SolveWithOptions(context.Background(), sizes, amount, SolveOptions{Priority: domain.PriorityPacksOverage})
+			if err != nil {
+				t.Fatalf("amount %d: unexpected error: %v", amount, err)
+			}
+			want := bruteForceSolve(sizes, amount, domain.PriorityPacksOverage, nil)
+			if got.Packs != want.Packs || got.Overage != want.Overage {
+				t.Fatalf("amount %d: got packs %d overage %d, want packs %d overage %d",
+					amount, got.Packs, got.Overage, want.Packs, want.Overage)
+			}
+		}
+	})
+
+	t.Run("truncated range is rejected", func(t *testing.T) {
+		_, err := solver.SolveWithOptions(context.Background(), []int{7, 1000}, 9_999_995, SolveOptions{Priority: domain.PriorityPacksOverage})
+		if !errors.Is(err, domain.ErrSearchTruncated) {
+			t.Errorf("expected ErrSearchTruncated, got %v", err)
+		}
+	})
+
+	t.Run("from context", func(t *testing.T) {
+		ctx := domain.WithSolveOptions(context.Background(), domain.SolveOptions{domain.SolveOptionPriority: "packs_overage"})
+		solution, err := NewVerifyingSolver(solver, 0, 0).Solve(ctx, []int{250, 500, 1000}, 750)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !equalBreakdown(solution.Breakdown, map[int]int{1000: 1}) {
+			t.Errorf("breakdown = %v, want map[1000:1]", solution.Breakdown)
+		}
+
+		ctx = domain.WithSolveOptions(context.Background(), domain.SolveOptions{domain.SolveOptionPriority: "abc"})
+		if _, err := solver.Solve(ctx, []int{250, 500, 1000}, 750); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for malformed option, got %v", err)
+		}
+	})
+}
+
+func TestDPSolver_UnusedSizes(t *testing.T) {
+	solver := NewDPSolver()
+
+	tests := []struct {
+		name   string
+		sizes  []int
+		amount int
+		want   []int
+	}{
+		// 12250 = 2x5000 + 2000 + 250; 500 and 1000 never help
+		{name: "dominated sizes", sizes: []int{250, 500, 1000, 2000, 5000}, amount: 12001, want: []int{500, 1000}},
+		// 6 = 3+3 = 2+4: both optimal packings count
+		{name: "ties use every size", sizes: []int{2, 3, 4}, amount: 6, want: []int{}},
+		{name: "single pack", sizes: []int{250, 500, 1000}, amount: 500, want: []int{250, 1000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := solver.UnusedSizes(context.Background(), tt.sizes, tt.amount)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnusedSizes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		if _, err := solver.UnusedSizes(context.Background(), []int{250, 250}, 100); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// DefaultMaxTopK is the largest number of solutions SolveTopK returns unless
+// set with WithMaxTopK
+const DefaultMaxTopK = 10
+
+// WithMaxTopK sets the largest k SolveTopK accepts; a non-positive value uses
+// DefaultMaxTopK
+func (s *DPSolver) WithMaxTopK(maxTopK int) *DPSolver {
+	if maxTopK <= 0 {
+		maxTopK = DefaultMaxTopK
+	}
+	s.maxTopK = maxTopK
+	return s
+}
+
+// MaxTopK returns the largest k SolveTopK accepts
+func (s *DPSolver) MaxTopK() int {
+	return s.maxTopK
+}
+
+// SolveTopK returns up to k distinct packings covering amount, best first by
+// the standard criteria: least overage, then fewest packs
+// Only packings no pack can be dropped from are candidates (overage below the
+// smallest size used): any other one is a better packing plus redundant packs
+// There are finitely many of those, so fewer than k may be returned
+// The first solution is Solve's with default options (options on the context
+// are not applied), read from the same DP table; ties after it keep more of
+// the larger sizes first
+func (s *DPSolver) SolveTopK(ctx context.Context, sizes []int, amount, k int) ([]*domain.Solution, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	// Validate input data
+	if err := domain.ValidateSolverInput(sizes, amount); err != nil {
+		return nil, err
+	}
+	if k < 1 || k > s.maxTopK {
+		return nil, fmt.Errorf("%w: k must be between 1 and %d, got %d", domain.ErrInvalidInput, s.maxTopK, k)
+	}
+
+	normalizedSizes, err := solverSizes(sizes, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// A packing whose overage reaches its smallest size has a droppable pack,
+	// so every candidate total lies below amount + largest size
+	bound := maxOverageBound(normalizedSizes, domain.PriorityPacksOverage)
+	maxSum := amount + bound
+	if maxSum > s.maxTableSize {
+		return nil, fmt.Errorf("%w: amount must not exceed %d for top-k solving, got %d",
+			domain.ErrInvalidInput, s.maxTableSize-bound, amount)
+	}
+	if s.memoryBudget > 0 {
+		if estimate := dpTableBytes(maxSum); estimate > s.memoryBudget {
+			return nil, domain.NewSolverError(normalizedSizes, amount,
+				fmt.Sprintf("DP table needs %d bytes, budget is %d", estimate, s.memoryBudget),
+				domain.ErrMemoryBudgetExceeded)
+		}
+	}
+
+	dp, err := fillDPTable(ctx, normalizedSizes, maxSum)
+	if err != nil {
+		return nil, err
+	}
+
+	// The best solution comes from the same table, as in Solve: the first
+	// reachable total, reconstructed with its fewest packs
+	bestSum := findBestSum(dp, amount, maxSum, domain.PriorityOveragePacks)
+	if bestSum == -1 {
+		return nil, domain.NewSolverError(normalizedSizes, amount, "no solution found", domain.ErrNoSolution)
+	}
+	best := domain.NewSolution(reconstructSolution(dp, normalizedSizes, bestSum), amount)
+	solutions := []*domain.Solution{best}
+
+	// Every packing of a total beats every packing of a larger one, so totals
+	// are searched in order, each for the packings with the fewest packs
+	for sum := amount; sum <= maxSum && len(solutions) < k; sum++ {
+		if dp[sum].packs == -1 {
+			continue
+		}
+
+		// Sizes up to the overage could be dropped, as could they from any larger total
+		lo := sort.SearchInts(normalizedSizes, sum-amount+1)
+		if lo == len(normalizedSizes) {
+			break
+		}
+
+		// One more than needed, in case the best solution is among them
+		search := newPackingSearch(ctx, dp, normalizedSizes, lo, k-len(solutions)+1)
+		search.visit(len(normalizedSizes)-1, sum, 0)
+		if search.err != nil {
+			return nil, search.err
+		}
+
+		for _, found := range search.found {
+			if len(solutions) == k {
+				break
+			}
+			if maps.Equal(found.breakdown, best.Breakdown) {
+				continue
+			}
+			solutions = append(solutions, domain.NewSolution(found.breakdown, amount))
+		}
+	}
+
+	return solutions, nil
+}
+
+// packing is one packing found by packingSearch
+type packing struct {
+	breakdown map[int]int
+	packs     int
+}
+
+// packingSearch finds the packings of one total with the fewest packs by
+// branch and bound over the sizes, largest first
+type packingSearch struct {
+	ctx    context.Context
+	dp     []dpState // Fewest packs per sum over all sizes: a lower bound for any subset
+	sizes  []int     // Sorted ascending
+	lo     int       // Index of the smallest size packings may use
+	gcds   []int     // gcds[i] = gcd of sizes[lo..i]; every remainder must be a multiple
+	limit  int       // Packings to keep
+	counts []int     // Packs per size of the packing being built
+	found  []packing // Best packings so far, fewest packs first
+	nodes  int
+	err    error
+}
+
+// newPackingSearch creates a search keeping the limit best packings of sizes[lo:]
+func newPackingSearch(ctx context.Context, dp []dpState, sizes []int, lo, limit int) *packingSearch {
+	gcds := make([]int, len(sizes))
+	for i := lo; i < len(sizes); i++ {
+		gcds[i] = domain.SizesGCD(sizes[lo : i+1])
+	}
+	return &packingSearch{
+		ctx:    ctx,
+		dp:     dp,
+		sizes:  sizes,
+		lo:     lo,
+		gcds:   gcds,
+		limit:  limit,
+		counts: make([]int, len(sizes)),
+	}
+}
+
+// visit tries every count of sizes[idx] for a remainder of rem, packs packs
+// already placed, most packs first, recursing to the next smaller size
+func (p *packingSearch) visit(idx, rem, packs int) {
+	if p.err != nil {
+		return
+	}
+	p.nodes++
+	if p.nodes%10000 == 0 {
+		if err := p.ctx.Err(); err != nil {
+			p.err = err
+			return
+		}
+	}
+
+	if rem == 0 {
+		p.add(packs)
+		return
+	}
+	if rem%p.gcds[idx] != 0 {
+		return
+	}
+
+	size := p.sizes[idx]
+	if idx == p.lo {
+		p.counts[idx] = rem / size
+		p.add(packs + rem/size)
+		p.counts[idx] = 0
+		return
+	}
+
+	next := p.sizes[idx-1]
+	for count := rem / size; count >= 0; count-- {
+		left := rem - count*size
+
+		// Fewer of this size leaves more for smaller ones, so the bound only
+		// grows from here on
+		if p.full() && packs+count+(left+next-1)/next >= p.worst() {
+			break
+		}
+		if p.dp[left].packs == -1 || (p.full() && packs+count+int(p.dp[left].packs) >= p.worst()) {
+			continue
+		}
+
+		p.counts[idx] = count
+		p.visit(idx-1, left, packs+count)
+		p.counts[idx] = 0
+	}
+}
+
+// full reports whether limit packings have been found
+func (p *packingSearch) full() bool {
+	return len(p.found) == p.limit
+}
+
+// worst returns the pack count of the worst packing kept; the search must be full
+func (p *packingSearch) worst() int {
+	return p.found[len(p.found)-1].packs
+}
+
+// add records the current counts as a packing of packs packs, after any
+// found packing with as few packs, dropping the worst beyond limit
+func (p *packingSearch) add(packs int) {
+	breakdown := make(map[int]int)
+	for idx, count := range p.counts {
+		if count > 0 {
+			breakdown[p.sizes[idx]] = count
+		}
+	}
+
+	at, _ := slices.BinarySearchFunc(p.found, packs+1, func(found packing, target int) int {
+		return found.packs - target
+	})
+	p.found = slices.Insert(p.found, at, packing{breakdown: breakdown, packs: packs})
+	if len(p.found) > p.limit {
+		p.found = p.found[:p.limit]
+	}
+}
+
+// Ensure DPSolver implements domain.TopKSolver
+var _ domain.TopKSolver = (*DPSolver)(nil)
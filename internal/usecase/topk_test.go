@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+func TestDPSolver_SolveTopK(t *testing.T) {
+	solver := NewDPSolver()
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		sizes  []int
+		amount int
+		k      int
+		want   []map[int]int
+	}{
+		{
+			name:   "single",
+			sizes:  []int{250, 500, 1000},
+			amount: 251,
+			k:      1,
+			want:   []map[int]int{{500: 1}},
+		},
+		{
+			// Nothing else covers 251 without a droppable pack
+			name:   "fewer than k",
+			sizes:  []int{250, 500, 1000},
+			amount: 251,
+			k:      5,
+			want:   []map[int]int{{500: 1}, {250: 2}, {1000: 1}},
+		},
+		{
+			name:   "same overage, more packs",
+			sizes:  []int{250, 500, 1000, 2000, 5000},
+			amount: 12001,
+			k:      3,
+			want: []map[int]int{
+				{5000: 2, 2000: 1, 250: 1},
+				{5000: 2, 1000: 2, 250: 1},
+				{5000: 2, 1000: 1, 500: 2, 250: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := solver.SolveTopK(ctx, tt.sizes, tt.amount, tt.k)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d solutions %v, want %d", len(got), got, len(tt.want))
+			}
+			for i, solution := range got {
+				if !equalBreakdown(solution.Breakdown, tt.want[i]) {
+					t.Errorf("solution %d = %v, want %v", i, solution.Breakdown, tt.want[i])
+				}
+			}
+		})
+	}
+
+	t.Run("matches brute force", func(t *testing.T) {
+		for _, sizes := range [][]int{{3, 5, 7}, {4, 6, 9}, {5, 12}, {23, 31, 53}} {
+			for amount := 1; amount <= 120; amount++ {
+				got, err := solver.SolveTopK(ctx, sizes, amount, DefaultMaxTopK)
+				if err != nil {
+					t.Fatalf("sizes %v amount %d: unexpected error: %v", sizes, amount, err)
+				}
+
+				// The first solution is the single-solve optimum
+				optimum, err := solver.Solve(ctx, sizes, amount)
+				if err != nil {
+					t.Fatalf("sizes %v amount %d: Solve() error: %v", sizes, amount, err)
+				}
+				if !equalBreakdown(got[0].Breakdown, optimum.Breakdown) {
+					t.Fatalf("sizes %v amount %d: first = %v, want the optimum %v", sizes, amount, got[0].Breakdown, optimum.Breakdown)
+				}
+
+				// Every solution is valid, distinct and has no droppable pack
+				seen := make(map[string]bool)
+				for _, solution := range got {
+					if err := solution.Validate(); err != nil {
+						t.Fatalf("sizes %v amount %d: invalid solution %v: %v", sizes, amount, solution.Breakdown, err)
+					}
+					if solution.Amount != amount || solution.Overage >= slices.Min(slices.Collect(maps.Keys(solution.Breakdown))) {
+						t.Fatalf("sizes %v amount %d: solution %v has a droppable pack", sizes, amount, solution.Breakdown)
+					}
+					key := fmt.Sprint(solution.Breakdown)
+					if seen[key] {
+						t.Fatalf("sizes %v amount %d: duplicate solution %v", sizes, amount, solution.Breakdown)
+					}
+					seen[key] = true
+				}
+
+				// Ranked as the best (overage, packs) among all such packings
+				want := bruteForceTopK(sizes, amount, DefaultMaxTopK)
+				if gotKeys := solutionKeys(got); !slices.Equal(gotKeys, want) {
+					t.Fatalf("sizes %v amount %d: (overage, packs) = %v, want %v", sizes, amount, gotKeys, want)
+				}
+			}
+		}
+	})
+
+	t.Run("invalid k", func(t *testing.T) {
+		for _, k := range []int{0, -1, DefaultMaxTopK + 1} {
+			if _, err := solver.SolveTopK(ctx, []int{250, 500}, 251, k); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("k %d: expected ErrInvalidInput, got %v", k, err)
+			}
+		}
+	})
+
+	t.Run("configured cap", func(t *testing.T) {
+		capped := NewDPSolver().WithMaxTopK(2)
+		got, err := capped.SolveTopK(ctx, []int{250, 500, 1000}, 251, 2)
+		if err != nil {
+			t.Fatalf("k at the cap: unexpected error: %v", err)
+		}
+		if len(got) != 2 {
+			t.Errorf("k at the cap: got %d solutions, want 2", len(got))
+		}
+		if _, err := capped.SolveTopK(ctx, []int{250, 500, 1000}, 251, 3); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("k above the cap: expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("amount beyond the table limit", func(t *testing.T) {
+		limited := NewDPSolverWithLimit(1000)
+		if _, err := limited.SolveTopK(ctx, []int{250, 500}, 600, 3); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, err := solver.SolveTopK(ctx, []int{250, 500}, 251, 3); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+// bruteForceTopK returns the (overage, packs) of the k best packings covering
+// amount from which no pack can be dropped, enumerating every pack count
+func bruteForceTopK(sizes []int, amount, k int) [][2]int {
+	var keys [][2]int
+	counts := make([]int, len(sizes))
+	var search func(idx, total int)
+	search = func(idx, total int) {
+		if idx == len(sizes) {
+			smallest, packs := 0, 0
+			for i, count := range counts {
+				if count > 0 && smallest == 0 {
+					smallest = sizes[i]
+				}
+				packs += count
+			}
+			if total >= amount && packs > 0 && total-amount < smallest {
+				keys = append(keys, [2]int{total - amount, packs})
+			}
+			return
+		}
+		for count := 0; total+count*sizes[idx] < amount+sizes[len(sizes)-1]; count++ {
+			counts[idx] = count
+			search(idx+1, total+count*sizes[idx])
+		}
+		counts[idx] = 0
+	}
+	search(0, 0)
+
+	slices.SortFunc(keys, func(a, b [2]int) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+	})
+	return keys[:min(k, len(keys))]
+}
+
+// solutionKeys returns the (overage, packs) of each solution
+func solutionKeys(solutions []*domain.Solution) [][2]int {
+	keys := make([][2]int, len(solutions))
+	for i, solution := range solutions {
+		keys[i] = [2]int{solution.Overage, solution.Packs}
+	}
+	return keys
+}
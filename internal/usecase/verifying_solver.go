@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+const (
+	// DefaultVerifyMaxAmount - default largest amount checked by the oracle
+	DefaultVerifyMaxAmount = 1000
+
+	// DefaultVerifyMaxSizes - default largest number of pack sizes checked by the oracle
+	DefaultVerifyMaxSizes = 5
+)
+
+// VerifyingSolver is a domain.Solver decorator that cross-checks results
+// of the wrapped solver against a brute-force oracle
+// Verification only runs when the input is small enough (amount and number of sizes
+// within thresholds) to keep the exhaustive search cheap; larger inputs pass through
+// Intended for staging/debug use to catch regressions in the DP logic on real traffic
+type VerifyingSolver struct {
+	solver    domain.Solver
+	maxAmount int
+	maxSizes  int
+}
+
+// NewVerifyingSolver creates a new verifying decorator
+// Non-positive thresholds fall back to DefaultVerifyMaxAmount and DefaultVerifyMaxSizes
+func NewVerifyingSolver(solver domain.Solver, maxAmount, maxSizes int) *VerifyingSolver {
+	if maxAmount <= 0 {
+		maxAmount = DefaultVerifyMaxAmount
+	}
+	if maxSizes <= 0 {
+		maxSizes = DefaultVerifyMaxSizes
+	}
+
+	return &VerifyingSolver{
+		solver:    solver,
+		maxAmount: maxAmount,
+		maxSizes:  maxSizes,
+	}
+}
+
+// Solve delegates to the wrapped solver and verifies the result when the input is small
+// Returns domain.ErrSolverMismatch (wrapped in SolverError) if the result is not optimal
+func (v *VerifyingSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	solution, err := v.solver.Solve(ctx, sizes, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizedSizes, _ := normalizeSizes(sizes)
+	if amount > v.maxAmount || len(normalizedSizes) > v.maxSizes {
+		return solution, nil
+	}
+
+	// Solution must be internally consistent
+	if err := solution.Validate(); err != nil {
+		return nil, domain.NewSolverError(sizes, amount, fmt.Sprintf("invalid solution: %v", err), domain.ErrSolverMismatch)
+	}
+
+	// Solution may only use the requested sizes
+	for size, count := range solution.Breakdown {
+		if count > 0 && !containsSize(normalizedSizes, size) {
+			return nil, domain.NewSolverError(sizes, amount, fmt.Sprintf("solution uses unknown size %d", size), domain.ErrSolverMismatch)
+		}
+	}
+
+	// Compare optimality metrics; breakdowns may legitimately differ on ties
+	// The oracle knows no costs, so cost-minimizing solutions are not compared
+	opts, _ := SolveOptionsFromContext(ctx)
+	if len(opts.Costs) > 0 {
+		return solution, nil
+	}
+	if opts.MaxOverage != nil && solution.Overage > *opts.MaxOverage {
+		return nil, domain.NewSolverError(sizes, amount, fmt.Sprintf(
+			"solution overage %d exceeds max overage %d", solution.Overage, *opts.MaxOverage,
+		), domain.ErrSolverMismatch)
+	}
+	oracle := bruteForceSolve(normalizedSizes, amount, opts.Priority, opts.MaxOverage)
+	if oracle == nil {
+		return solution, nil
+	}
+
+	if solution.Overage != oracle.Overage || solution.Packs != oracle.Packs {
+		return nil, domain.NewSolverError(sizes, amount, fmt.Sprintf(
+			"solution (overage %d, packs %d) differs from oracle (overage %d, packs %d)",
+			solution.Overage, solution.Packs, oracle.Overage, oracle.Packs,
+		), domain.ErrSolverMismatch)
+	}
+
+	return solution, nil
+}
+
+// bruteForceSolve finds the optimum by enumerating every multiset of packs
+// that reaches the amount without a redundant last pack, skipping those with
+// more overage than maxOverage when it is set (nil if none remain)
+// Exponential in the worst case, only meant for small inputs
+func bruteForceSolve(sizes []int, amount int, priority domain.Priority, maxOverage *int) *domain.Solution {
+	if len(sizes) == 0 {
+		return nil
+	}
+
+	// Enumerate in descending order so each multiset is visited once
+	desc := make([]int, len(sizes))
+	copy(desc, sizes)
+	sort.Sort(sort.Reverse(sort.IntSlice(desc)))
+
+	var best *domain.Solution
+	counts := make(map[int]int)
+
+	var search func(start, remaining, packs int)
+	search = func(start, remaining, packs int) {
+		// Amount covered: adding more packs can only increase overage
+		if remaining <= 0 {
+			if maxOverage != nil && -remaining > *maxOverage {
+				return
+			}
+			breakdown := make(map[int]int, len(counts))
+			for size, count := range counts {
+				if count > 0 {
+					breakdown[size] = count
+				}
+			}
+			best = domain.CompareSolutionsBy(best, domain.NewSolution(breakdown, amount), priority)
+			return
+		}
+
+		// Covering the amount takes at least one more pack, which can't beat
+		// an exact best solution, nor any best solution when packs come first
+		if best != nil && packs >= best.Packs && (best.Overage == 0 || priority == domain.PriorityPacksOverage) {
+			return
+		}
+
+		for i := start; i < len(desc); i++ {
+			counts[desc[i]]++
+			search(i, remaining-desc[i], packs+1)
+			counts[desc[i]]--
+		}
+	}
+
+	search(0, amount, 0)
+	return best
+}
+
+// containsSize reports whether size is present in sorted sizes
+func containsSize(sizes []int, size int) bool {
+	idx := sort.SearchInts(sizes, size)
+	return idx < len(sizes) && sizes[idx] == size
+}
+
+// Ensure VerifyingSolver implements domain.Solver interface
+var _ domain.Solver = (*VerifyingSolver)(nil)
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// fixedSolver always returns the same solution (deliberately wrong in tests)
+type fixedSolver struct {
+	breakdown map[int]int
+}
+
+func (f *fixedSolver) Solve(ctx context.Context, sizes []int, amount int) (*domain.Solution, error) {
+	return domain.NewSolution(f.breakdown, amount), nil
+}
+
+func TestVerifyingSolver_DetectsWrongSolver(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		breakdown map[int]int
+		sizes     []int
+		amount    int
+	}{
+		{
+			name:      "suboptimal overage",
+			breakdown: map[int]int{1000: 1}, // optimum is 500x1
+			sizes:     []int{250, 500, 1000},
+			amount:    251,
+		},
+		{
+			name:      "suboptimal pack count",
+			breakdown: map[int]int{250: 2}, // optimum is 500x1
+			sizes:     []int{250, 500, 1000},
+			amount:    500,
+		},
+		{
+			name:      "unknown size",
+			breakdown: map[int]int{300: 1},
+			sizes:     []int{250, 500, 1000},
+			amount:    300,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solver := NewVerifyingSolver(&fixedSolver{breakdown: tt.breakdown}, 0, 0)
+
+			_, err := solver.Solve(ctx, tt.sizes, tt.amount)
+			if !errors.Is(err, domain.ErrSolverMismatch) {
+				t.Errorf("expected ErrSolverMismatch, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyingSolver_AcceptsDPSolver(t *testing.T) {
+	solver := NewVerifyingSolver(NewDPSolver(), 0, 0)
+	ctx := context.Background()
+
+	cases := []struct {
+		sizes  []int
+		amount int
+	}{
+		{[]int{250, 500, 1000}, 251},
+		{[]int{250, 500, 1000}, 1001},
+		{[]int{3, 5}, 7},
+		{[]int{23, 31, 53}, 263},
+		{[]int{6, 9, 20}, 43},
+	}
+
+	for _, c := range cases {
+		if _, err := solver.Solve(ctx, c.sizes, c.amount); err != nil {
+			t.Errorf("Solve(%v, %d) unexpected error: %v", c.sizes, c.amount, err)
+		}
+	}
+}
+
+func TestVerifyingSolver_SkipsAboveThreshold(t *testing.T) {
+	// Wrong result is passed through when amount exceeds the threshold
+	solver := NewVerifyingSolver(&fixedSolver{breakdown: map[int]int{1000: 2}}, 100, 0)
+
+	if _, err := solver.Solve(context.Background(), []int{250, 500, 1000}, 251); err != nil {
+		t.Errorf("expected verification to be skipped, got %v", err)
+	}
+}
+
+func TestVerifyingSolver_PriorityWithMaxOverage(t *testing.T) {
+	solver := NewVerifyingSolver(NewDPSolver(), 0, 0)
+
+	// Packs first would pick 13x1 (overage 1); the cap leaves only 4x3
+	ctx := domain.WithSolveOptions(context.Background(), domain.SolveOptions{
+		domain.SolveOptionPriority:   domain.PriorityPacksOverage.String(),
+		domain.SolveOptionMaxOverage: "0",
+	})
+	solution, err := solver.Solve(ctx, []int{4, 13}, 12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if solution.Overage != 0 || solution.Packs != 3 {
+		t.Errorf("got overage %d packs %d, want overage 0 packs 3", solution.Overage, solution.Packs)
+	}
+
+	// The cap applies to results of any solver
+	wrong := NewVerifyingSolver(&fixedSolver{breakdown: map[int]int{13: 1}}, 0, 0)
+	if _, err := wrong.Solve(ctx, []int{4, 13}, 12); !errors.Is(err, domain.ErrSolverMismatch) {
+		t.Errorf("expected ErrSolverMismatch for a result above the cap, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"unsafe"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+// costTolerance - relative difference below which two total costs are equal,
+// so float rounding (3 * 0.1 != 0.3) never outweighs the overage tiebreak
+const costTolerance = 1e-9
+
+// solveWeighted finds the cheapest packing covering amount under opts.Costs
+// Ties go to less overage, then to fewer packs
+// sizes must be normalized; the early exits of SolveWithOptions do not apply,
+// since a cheaper combination can beat a single pack of exactly amount
+func (s *DPSolver) solveWeighted(ctx context.Context, sizes []int, amount int, opts SolveOptions) (*domain.Solution, error) {
+	if err := domain.ValidatePackCosts(sizes, opts.Costs); err != nil {
+		return nil, err
+	}
+	if opts.Priority != domain.PriorityOveragePacks {
+		return nil, fmt.Errorf("%w: costs cannot be combined with priority %s", domain.ErrInvalidInput, opts.Priority)
+	}
+
+	// Costs are non-negative, so dropping any pack from a packing with at
+	// least the largest size of overage still covers amount at no higher cost
+	// and with less overage: the optimum lies within largest size - 1 of amount
+	maxSum := s.calculateMaxSum(amount, sizes, domain.PriorityPacksOverage)
+	truncated := s.isTruncated(amount, sizes, domain.PriorityPacksOverage)
+
+	capped := opts.MaxOverage != nil && *opts.MaxOverage < maxSum-amount
+	if capped {
+		maxSum = amount + *opts.MaxOverage
+		truncated = false
+	}
+
+	// A cheaper packing may hide beyond the clipped table
+	if truncated {
+		return nil, domain.NewSolverError(sizes, amount,
+			fmt.Sprintf("DP table limited to %d sums", s.maxTableSize), domain.ErrSearchTruncated)
+	}
+
+	if firstCandidateSum(sizes, amount) > maxSum {
+		return nil, s.noSolutionError(sizes, amount, opts, capped, truncated)
+	}
+
+	if s.memoryBudget > 0 {
+		if estimate := weightedTableBytes(maxSum); estimate > s.memoryBudget {
+			return nil, domain.NewSolverError(sizes, amount,
+				fmt.Sprintf("DP table needs %d bytes, budget is %d", estimate, s.memoryBudget),
+				domain.ErrMemoryBudgetExceeded)
+		}
+	}
+
+	costs := make([]float64, len(sizes))
+	for idx, size := range sizes {
+		costs[idx] = opts.Costs[size]
+	}
+
+	dp, totals, err := fillWeightedTable(ctx, sizes, costs, maxSum)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first of equally cheap sums has the least overage
+	bestSum := -1
+	for sum := amount; sum <= maxSum; sum++ {
+		if dp[sum].packs == -1 {
+			continue
+		}
+		if bestSum == -1 || costLess(totals[sum], totals[bestSum]) {
+			bestSum = sum
+		}
+	}
+	if bestSum == -1 {
+		return nil, s.noSolutionError(sizes, amount, opts, capped, truncated)
+	}
+
+	return domain.NewSolution(reconstructSolution(dp, sizes, bestSum), amount), nil
+}
+
+// fillWeightedTable fills the DP table with the cheapest way to reach each sum
+// 0..maxSum; totals holds that cost, dp its pack count (fewest among equally
+// cheap combinations) and the last pack for reconstructSolution
+func fillWeightedTable(ctx context.Context, sizes []int, costs []float64, maxSum int) ([]dpState, []float64, error) {
+	onProgress := domain.ProgressFromContext(ctx)
+	total := maxSum + 1
+
+	dp := make([]dpState, total)
+	totals := make([]float64, total)
+	for i := range dp {
+		dp[i] = dpState{packs: -1, parent: -1}
+	}
+	dp[0] = dpState{packs: 0, parent: -1}
+
+	for sum := 0; sum <= maxSum; sum++ {
+		// Check context periodically
+		if sum%10000 == 0 {
+			select {
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			default:
+			}
+			if onProgress != nil {
+				onProgress(sum, total)
+			}
+		}
+
+		if dp[sum].packs == -1 {
+			continue
+		}
+
+		for idx, size := range sizes {
+			newSum := sum + size
+			if newSum > maxSum {
+				continue
+			}
+
+			newCost := totals[sum] + costs[idx]
+			newPacks := dp[sum].packs + 1
+
+			// Update on the first reach, a lower cost, or fewer packs at the same cost
+			if dp[newSum].packs == -1 || costLess(newCost, totals[newSum]) ||
+				(!costLess(totals[newSum], newCost) && newPacks < dp[newSum].packs) {
+				dp[newSum].packs = newPacks
+				dp[newSum].parent = int32(idx)
+				totals[newSum] = newCost
+			}
+		}
+	}
+
+	if onProgress != nil {
+		onProgress(total, total)
+	}
+
+	return dp, totals, nil
+}
+
+// costLess reports whether cost a is lower than b beyond costTolerance
+func costLess(a, b float64) bool {
+	return a < b-costTolerance*math.Max(1, math.Abs(b))
+}
+
+// weightedTableBytes returns the size in bytes of the DP table and cost
+// totals covering sums 0..maxSum
+func weightedTableBytes(maxSum int) int {
+	return dpTableBytes(maxSum) + (maxSum+1)*int(unsafe.Sizeof(float64(0)))
+}
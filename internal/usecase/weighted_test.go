@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/evgenijurbanovskij/re-partners-assignment/internal/domain"
+)
+
+func TestDPSolver_SolveWithOptions_Costs(t *testing.T) {
+	solver := NewDPSolver()
+	ctx := context.Background()
+	overage := func(n int) *int { return &n }
+
+	tests := []struct {
+		name       string
+		sizes      []int
+		amount     int
+		costs      map[int]float64
+		maxOverage *int
+		want       map[int]int
+		wantPacks  map[int]int // Without costs
+	}{
+		{
+			// Two cheap small packs beat one expensive exact pack
+			name:      "cheaper than exact",
+			sizes:     []int{250, 500},
+			amount:    500,
+			costs:     map[int]float64{250: 1, 500: 5},
+			want:      map[int]int{250: 2},
+			wantPacks: map[int]int{500: 1},
+		},
+		{
+			// A bulk pack is cheaper despite far more overage
+			name:      "cheaper with more overage",
+			sizes:     []int{250, 5000},
+			amount:    251,
+			costs:     map[int]float64{250: 100, 5000: 10},
+			want:      map[int]int{5000: 1},
+			wantPacks: map[int]int{250: 2},
+		},
+		{
+			name:       "overage cap excludes the cheapest",
+			sizes:      []int{250, 5000},
+			amount:     251,
+			costs:      map[int]float64{250: 100, 5000: 10},
+			maxOverage: overage(300),
+			want:       map[int]int{250: 2},
+			wantPacks:  map[int]int{250: 2},
+		},
+		{
+			// Equal cost per item: the cost ties on every packing of the
+			// same total, so overage and then packs decide as without costs
+			name:      "cost proportional to size",
+			sizes:     []int{250, 500, 1000, 2000, 5000},
+			amount:    12001,
+			costs:     map[int]float64{250: 250, 500: 500, 1000: 1000, 2000: 2000, 5000: 5000},
+			want:      map[int]int{5000: 2, 2000: 1, 250: 1},
+			wantPacks: map[int]int{5000: 2, 2000: 1, 250: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := solver.SolveWithOptions(ctx, tt.sizes, tt.amount, SolveOptions{Costs: tt.costs, MaxOverage: tt.maxOverage})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalBreakdown(got.Breakdown, tt.want) {
+				t.Errorf("breakdown = %v, want %v", got.Breakdown, tt.want)
+			}
+
+			got, err = solver.SolveWithOptions(ctx, tt.sizes, tt.amount, SolveOptions{MaxOverage: tt.maxOverage})
+			if err != nil {
+				t.Fatalf("without costs: unexpected error: %v", err)
+			}
+			if !equalBreakdown(got.Breakdown, tt.wantPacks) {
+				t.Errorf("without costs: breakdown = %v, want %v", got.Breakdown, tt.wantPacks)
+			}
+		})
+	}
+
+	t.Run("matches brute force", func(t *testing.T) {
+		sizes := []int{4, 7, 9}
+		costs := map[int]float64{4: 5, 7: 6, 9: 9.5}
+		for amount := 1; amount <= 120; amount++ {
+			got, err := solver.SolveWithOptions(ctx, sizes, amount, SolveOptions{Costs: costs})
+			if err != nil {
+				t.Fatalf("amount %d: unexpected error: %v", amount, err)
+			}
+			wantCost, wantOverage := bruteForceCheapest(sizes, costs, amount)
+			if cost := breakdownCost(got.Breakdown, costs); cost != wantCost || got.Overage != wantOverage {
+				t.Fatalf("amount %d: got cost %v overage %d, want cost %v overage %d",
+					amount, cost, got.Overage, wantCost, wantOverage)
+			}
+		}
+	})
+
+	t.Run("invalid costs", func(t *testing.T) {
+		cases := []struct {
+			name string
+			opts SolveOptions
+		}{
+			{name: "missing size", opts: SolveOptions{Costs: map[int]float64{250: 1}}},
+			{name: "unknown size", opts: SolveOptions{Costs: map[int]float64{250: 1, 500: 2, 750: 3}}},
+			{name: "negative cost", opts: SolveOptions{Costs: map[int]float64{250: 1, 500: -2}}},
+			{name: "with packs priority", opts: SolveOptions{Costs: map[int]float64{250: 1, 500: 2}, Priority: domain.PriorityPacksOverage}},
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				if _, err := solver.SolveWithOptions(ctx, []int{250, 500}, 751, c.opts); !errors.Is(err, domain.ErrInvalidInput) {
+					t.Errorf("expected ErrInvalidInput, got %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("truncated range is rejected", func(t *testing.T) {
+		costs := map[int]float64{7: 1, 1000: 100}
+		_, err := solver.SolveWithOptions(ctx, []int{7, 1000}, 9_999_995, SolveOptions{Costs: costs})
+		if !errors.Is(err, domain.ErrSearchTruncated) {
+			t.Errorf("expected ErrSearchTruncated, got %v", err)
+		}
+	})
+
+	t.Run("from context", func(t *testing.T) {
+		ctx := domain.WithSolveOptions(ctx, domain.SolveOptions{domain.SolveOptionCosts: "250:100,5000:10"})
+		solution, err := NewVerifyingSolver(solver, 0, 0).Solve(ctx, []int{250, 5000}, 251)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !equalBreakdown(solution.Breakdown, map[int]int{5000: 1}) {
+			t.Errorf("breakdown = %v, want map[5000:1]", solution.Breakdown)
+		}
+
+		ctx = domain.WithSolveOptions(context.Background(), domain.SolveOptions{domain.SolveOptionCosts: "250=1"})
+		if _, err := solver.Solve(ctx, []int{250}, 251); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for malformed option, got %v", err)
+		}
+	})
+}
+
+// bruteForceCheapest returns the least cost of covering amount, and the least
+// overage among packings of that cost, by enumerating every pack count
+// Costs are exact in binary here, so totals are compared exactly
+func bruteForceCheapest(sizes []int, costs map[int]float64, amount int) (float64, int) {
+	bestCost, bestOverage := -1.0, 0
+	var search func(idx, total int, cost float64)
+	search = func(idx, total int, cost float64) {
+		if idx == len(sizes) {
+			if total < amount {
+				return
+			}
+			overage := total - amount
+			if bestCost < 0 || cost < bestCost || (cost == bestCost && overage < bestOverage) {
+				bestCost, bestOverage = cost, overage
+			}
+			return
+		}
+		// Beyond amount + largest size no extra pack can pay off
+		for count := 0; total+count*sizes[idx] < amount+sizes[len(sizes)-1]+sizes[idx]; count++ {
+			search(idx+1, total+count*sizes[idx], cost+float64(count)*costs[sizes[idx]])
+		}
+	}
+	search(0, 0, 0)
+	return bestCost, bestOverage
+}
+
+// breakdownCost returns the total cost of a breakdown
+func breakdownCost(breakdown map[int]int, costs map[int]float64) float64 {
+	var cost float64
+	for size, count := range breakdown {
+		cost += float64(count) * costs[size]
+	}
+	return cost
+}